@@ -0,0 +1,24 @@
+package genetlink
+
+// An Addr is the netlink address to which a Conn is bound.
+//
+// To bind a Conn to a well-known port ID, for example to receive unicast
+// messages from a cooperating process, or to subscribe to multicast groups
+// using the legacy group bitmask, set the PID and Groups fields of the
+// netlink.Config passed to Dial.
+type Addr struct {
+	// PID is the netlink port ID of the socket. Despite its name, it need
+	// not be a process ID.
+	PID uint32
+
+	// Groups is the legacy bitmask of multicast groups 1 to 32 which the
+	// socket is subscribed to. Groups joined using JoinGroup are not
+	// reported.
+	Groups uint32
+}
+
+// Addr returns the netlink address to which c is bound, as reported by the
+// kernel.
+func (c *Conn) Addr() (Addr, error) {
+	return c.addr()
+}
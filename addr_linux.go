@@ -0,0 +1,42 @@
+//go:build linux
+// +build linux
+
+package genetlink
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// addr retrieves the socket's bound address using getsockname.
+func (c *Conn) addr() (Addr, error) {
+	rc, err := c.SyscallConn()
+	if err != nil {
+		return Addr{}, err
+	}
+
+	var (
+		sa   unix.Sockaddr
+		serr error
+	)
+	if err := rc.Control(func(fd uintptr) {
+		sa, serr = unix.Getsockname(int(fd))
+	}); err != nil {
+		return Addr{}, err
+	}
+	if serr != nil {
+		return Addr{}, os.NewSyscallError("getsockname", serr)
+	}
+
+	nsa, ok := sa.(*unix.SockaddrNetlink)
+	if !ok {
+		return Addr{}, fmt.Errorf("genetlink: unexpected socket address type: %T", sa)
+	}
+
+	return Addr{
+		PID:    nsa.Pid,
+		Groups: nsa.Groups,
+	}, nil
+}
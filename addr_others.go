@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package genetlink
+
+// addr always returns an error.
+func (c *Conn) addr() (Addr, error) {
+	return Addr{}, errUnimplemented
+}
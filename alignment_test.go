@@ -0,0 +1,101 @@
+package genetlink_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+)
+
+// The decoding tests in this file feed buffers which begin at odd addresses
+// to each decode path. Decoding which dereferences unaligned pointers, as
+// the integer functions of nlenc do, faults on strict-alignment
+// architectures such as mips, where CI runs these tests.
+
+// misalign returns a copy of b which begins at an odd address.
+func misalign(b []byte) []byte {
+	buf := make([]byte, len(b)+1)
+	copy(buf[1:], b)
+	return buf[1:]
+}
+
+func TestAttributeViewUnaligned(t *testing.T) {
+	nested, err := netlink.MarshalAttributes([]netlink.Attribute{
+		{Type: 1, Data: nlenc.Uint64Bytes(0x0102030405060708)},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal nested attributes: %v", err)
+	}
+
+	b, err := netlink.MarshalAttributes([]netlink.Attribute{
+		{Type: 1, Data: nlenc.Uint16Bytes(0x0102)},
+		{Type: 2, Data: nlenc.Uint32Bytes(0x01020304)},
+		{Type: 3, Data: nlenc.Uint64Bytes(0x0102030405060708)},
+		{Type: 4 | netlink.Nested, Data: nested},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal attributes: %v", err)
+	}
+
+	v := genetlink.AttributeView(misalign(b))
+
+	u16, ok := v.Uint16(1)
+	if diff := cmp.Diff(uint16(0x0102), u16); !ok || diff != "" {
+		t.Fatalf("unexpected uint16 (ok: %v) (-want +got):\n%s", ok, diff)
+	}
+
+	u32, ok := v.Uint32(2)
+	if diff := cmp.Diff(uint32(0x01020304), u32); !ok || diff != "" {
+		t.Fatalf("unexpected uint32 (ok: %v) (-want +got):\n%s", ok, diff)
+	}
+
+	u64, ok := v.Uint64(3)
+	if diff := cmp.Diff(uint64(0x0102030405060708), u64); !ok || diff != "" {
+		t.Fatalf("unexpected uint64 (ok: %v) (-want +got):\n%s", ok, diff)
+	}
+
+	nv, ok := v.Nested(4)
+	if !ok {
+		t.Fatal("nested attributes not found")
+	}
+
+	u64, ok = nv.Uint64(1)
+	if diff := cmp.Diff(uint64(0x0102030405060708), u64); !ok || diff != "" {
+		t.Fatalf("unexpected nested uint64 (ok: %v) (-want +got):\n%s", ok, diff)
+	}
+
+	if err := v.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDecodeLimitsCheckUnaligned(t *testing.T) {
+	nested, err := netlink.MarshalAttributes([]netlink.Attribute{
+		{Type: 1, Data: nlenc.Uint32Bytes(1)},
+		{Type: 2, Data: nlenc.Uint32Bytes(2)},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal nested attributes: %v", err)
+	}
+
+	b, err := netlink.MarshalAttributes([]netlink.Attribute{
+		{Type: 1, Data: nlenc.Uint16Bytes(1)},
+		{Type: 2 | netlink.Nested, Data: nested},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal attributes: %v", err)
+	}
+
+	if err := (genetlink.DecodeLimits{}).Check(misalign(b)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The nested attributes are still counted when unaligned.
+	err = (genetlink.DecodeLimits{MaxAttributes: 3}).Check(misalign(b))
+	if !errors.Is(err, genetlink.ErrDecodeLimit) {
+		t.Fatalf("expected decode limit error, but got: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package genetlink
+
+import "github.com/mdlayher/netlink"
+
+// defaultArenaChunkSize is the default size of each chunk of memory
+// allocated by an Arena.
+const defaultArenaChunkSize = 64 * 1024
+
+// An Arena is a grow-only region of memory from which the Data of many
+// Messages can be allocated, and which is freed as a unit by Reset. Reusing
+// an Arena for each of a series of large dumps, such as those performed by a
+// periodic exporter, avoids allocating memory for every dump and reduces
+// pressure on the garbage collector.
+//
+// The zero value of an Arena is ready to use. An Arena is not safe for
+// concurrent use.
+type Arena struct {
+	// ChunkSize is the size of each chunk of memory allocated by the Arena.
+	// If zero, chunks of 64KiB are allocated. Allocations larger than
+	// ChunkSize are made in chunks of their own.
+	ChunkSize int
+
+	chunks [][]byte
+	cur    int
+	off    int
+}
+
+// Alloc allocates a byte slice of length n from the Arena. Memory reused
+// after Reset is not zeroed. Appending to the returned slice causes it to be
+// reallocated outside of the Arena.
+func (a *Arena) Alloc(n int) []byte {
+	size := a.ChunkSize
+	if size <= 0 {
+		size = defaultArenaChunkSize
+	}
+
+	for a.cur < len(a.chunks) {
+		c := a.chunks[a.cur]
+		if a.off+n <= len(c) {
+			b := c[a.off : a.off+n : a.off+n]
+			a.off += n
+			return b
+		}
+
+		// This chunk is exhausted; move on to the next, if any.
+		a.cur++
+		a.off = 0
+	}
+
+	if n > size {
+		size = n
+	}
+
+	a.chunks = append(a.chunks, make([]byte, size))
+	a.off = n
+
+	return a.chunks[a.cur][:n:n]
+}
+
+// Clone returns a copy of m whose Data is allocated from the Arena.
+func (a *Arena) Clone(m Message) Message {
+	c := Message{Header: m.Header}
+	if m.Data != nil {
+		c.Data = a.Alloc(len(m.Data))
+		copy(c.Data, m.Data)
+	}
+
+	return c
+}
+
+// Reset frees all memory allocated from the Arena for reuse, retaining the
+// Arena's chunks. Messages whose Data was allocated from the Arena must not
+// be used after Reset.
+func (a *Arena) Reset() {
+	a.cur, a.off = 0, 0
+}
+
+// DumpArena is like Dump, but the Data of the returned Messages is copied
+// into a, so that the memory used to receive the replies is no longer
+// referenced once DumpArena returns. Callers typically call a.Reset once
+// they have finished with the Messages, before the next dump.
+func (c *Conn) DumpArena(a *Arena, cmd uint8, family Family, attrs func(ae *netlink.AttributeEncoder) error) ([]Message, error) {
+	msgs, err := c.Dump(cmd, family, attrs)
+	for i := range msgs {
+		msgs[i] = a.Clone(msgs[i])
+	}
+
+	return msgs, err
+}
@@ -0,0 +1,79 @@
+package genetlink_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestArena(t *testing.T) {
+	a := &genetlink.Arena{ChunkSize: 8}
+
+	b1 := a.Alloc(4)
+	b2 := a.Alloc(4)
+	first := &b1[0]
+	copy(b1, "abcd")
+	copy(b2, "efgh")
+
+	// Allocations must not overlap, even when appended to.
+	b1 = append(b1, 'x')
+	if !bytes.Equal(b2, []byte("efgh")) {
+		t.Fatalf("allocations overlap: %q", b2)
+	}
+
+	// Oversized allocations get a chunk of their own.
+	if big := a.Alloc(16); len(big) != 16 {
+		t.Fatalf("unexpected allocation length: %d", len(big))
+	}
+
+	m := genetlink.Message{
+		Header: genetlink.Header{Command: 1},
+		Data:   []byte{0x01, 0x02},
+	}
+
+	c := a.Clone(m)
+	if diff := cmp.Diff(m, c); diff != "" {
+		t.Fatalf("unexpected clone (-want +got):\n%s", diff)
+	}
+
+	m.Data[0] = 0xff
+	if c.Data[0] != 0x01 {
+		t.Fatal("clone shares memory with original message")
+	}
+
+	// After Reset, memory is reused from the first chunk.
+	a.Reset()
+	if b := a.Alloc(4); &b[0] != first {
+		t.Fatal("expected memory to be reused")
+	}
+}
+
+func TestConnDumpArena(t *testing.T) {
+	replies := []genetlink.Message{
+		{Header: genetlink.Header{Command: 1}, Data: []byte{0x01, 0x02, 0x03, 0x04}},
+		{Header: genetlink.Header{Command: 1}, Data: []byte{0x05, 0x06, 0x07, 0x08}},
+	}
+
+	c := genltest.Dial(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return replies, nil
+	})
+	defer c.Close()
+
+	var a genetlink.Arena
+	for i := 0; i < 2; i++ {
+		msgs, err := c.DumpArena(&a, 1, genetlink.Family{ID: 0x20}, nil)
+		if err != nil {
+			t.Fatalf("failed to dump: %v", err)
+		}
+
+		if diff := cmp.Diff(replies, msgs); diff != "" {
+			t.Fatalf("unexpected messages (-want +got):\n%s", diff)
+		}
+
+		a.Reset()
+	}
+}
@@ -0,0 +1,40 @@
+package genetlink
+
+import (
+	"encoding/binary"
+
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+)
+
+// AttributeType returns the attribute type typ without the netlink.Nested
+// and netlink.NetByteOrder flags. Attribute types should be masked before
+// they are compared, since families may set the flags on any attribute.
+func AttributeType(typ uint16) uint16 {
+	return typ & nlaTypeMask
+}
+
+// AttributeByteOrder returns the byte order of the integer data of an
+// attribute with type typ: big endian if typ has the netlink.NetByteOrder
+// flag set, and native endian otherwise.
+func AttributeByteOrder(typ uint16) binary.ByteOrder {
+	if typ&netlink.NetByteOrder != 0 {
+		return binary.BigEndian
+	}
+
+	return nlenc.NativeEndian()
+}
+
+// DecodeNext advances ad to its next attribute as ad.Next does, and sets
+// ad.ByteOrder according to the attribute's netlink.NetByteOrder flag, so
+// that the integer methods of ad decode the attribute correctly. Use
+// DecodeNext in place of ad.Next when a family may send integers in either
+// byte order.
+func DecodeNext(ad *netlink.AttributeDecoder) bool {
+	if !ad.Next() {
+		return false
+	}
+
+	ad.ByteOrder = AttributeByteOrder(ad.TypeFlags())
+	return true
+}
@@ -0,0 +1,104 @@
+package genetlink_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"github.com/mdlayher/netlink/nltest"
+)
+
+func TestAttributeType(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  uint16
+		want uint16
+		be   bool
+	}{
+		{
+			name: "none",
+			typ:  1,
+			want: 1,
+		},
+		{
+			name: "nested",
+			typ:  2 | netlink.Nested,
+			want: 2,
+		},
+		{
+			name: "network byte order",
+			typ:  3 | netlink.NetByteOrder,
+			want: 3,
+			be:   true,
+		},
+		{
+			name: "both",
+			typ:  4 | netlink.Nested | netlink.NetByteOrder,
+			want: 4,
+			be:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, genetlink.AttributeType(tt.typ)); diff != "" {
+				t.Fatalf("unexpected type (-want +got):\n%s", diff)
+			}
+
+			be := genetlink.AttributeByteOrder(tt.typ) == binary.ByteOrder(binary.BigEndian)
+			if diff := cmp.Diff(tt.be, be); diff != "" {
+				t.Fatalf("unexpected big endian byte order (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAttributeViewNetByteOrder(t *testing.T) {
+	b := nltest.MustMarshalAttributes([]netlink.Attribute{
+		{Type: 1 | netlink.NetByteOrder, Data: []byte{0x00, 0x50}},
+		{Type: 2 | netlink.NetByteOrder, Data: []byte{0x0a, 0x00, 0x00, 0x01}},
+		{Type: 3 | netlink.NetByteOrder, Data: []byte{0, 0, 0, 0, 0, 0, 0x01, 0x00}},
+		{Type: 4, Data: nlenc.Uint32Bytes(10)},
+	})
+
+	v := genetlink.AttributeView(b)
+	if n, ok := v.Uint16(1); !ok || n != 80 {
+		t.Fatalf("unexpected uint16: %d, %v", n, ok)
+	}
+	if n, ok := v.Uint32(2); !ok || n != 0x0a000001 {
+		t.Fatalf("unexpected uint32: %#x, %v", n, ok)
+	}
+	if n, ok := v.Uint64(3); !ok || n != 256 {
+		t.Fatalf("unexpected uint64: %d, %v", n, ok)
+	}
+	if n, ok := v.Uint32(4); !ok || n != 10 {
+		t.Fatalf("unexpected native uint32: %d, %v", n, ok)
+	}
+}
+
+func TestDecodeNext(t *testing.T) {
+	b := nltest.MustMarshalAttributes([]netlink.Attribute{
+		{Type: 1 | netlink.NetByteOrder, Data: []byte{0x00, 0x50}},
+		{Type: 2, Data: nlenc.Uint16Bytes(80)},
+	})
+
+	ad, err := netlink.NewAttributeDecoder(b)
+	if err != nil {
+		t.Fatalf("failed to create attribute decoder: %v", err)
+	}
+
+	var got []uint16
+	for genetlink.DecodeNext(ad) {
+		got = append(got, ad.Uint16())
+	}
+	if err := ad.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]uint16{80, 80}, got); diff != "" {
+		t.Fatalf("unexpected values (-want +got):\n%s", diff)
+	}
+}
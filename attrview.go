@@ -0,0 +1,193 @@
+package genetlink
+
+import (
+	"errors"
+
+	"github.com/mdlayher/genetlink/internal/endian"
+	"github.com/mdlayher/netlink/nlenc"
+)
+
+// errInvalidAttribute is returned when an attribute is malformed.
+var errInvalidAttribute = errors.New("netlink attribute is invalid or too short")
+
+// Netlink attribute header length, alignment, and type flags.
+const (
+	nlaHeaderLen = 4
+	nlaAlignTo   = 4
+	nlaTypeMask  = 0x3fff // ^(unix.NLA_F_NESTED | unix.NLA_F_NET_BYTEORDER)
+)
+
+func nlaAlign(n int) int {
+	return (n + nlaAlignTo - 1) & ^(nlaAlignTo - 1)
+}
+
+// An AttributeView is a lightweight, read-only view of netlink attributes,
+// such as those in Message.Data. Unlike netlink.AttributeDecoder, which
+// decodes every attribute in order, an AttributeView locates attributes on
+// demand by scanning their headers, without allocating. This suits
+// consumers which read only a few attributes from each of many large dump
+// records.
+//
+// Lookups return the first attribute of a given type, ignoring the nested
+// and network byte order flags. Malformed attributes end the scan, so
+// attributes which follow them are not found. Use Err to check whether the
+// attributes are well formed.
+type AttributeView []byte
+
+// Attributes returns an AttributeView of m's Data.
+func (m Message) Attributes() AttributeView {
+	return AttributeView(m.Data)
+}
+
+// Lookup returns the data of the first attribute of type typ, and reports
+// whether it was found. The data refers to the memory of v.
+func (v AttributeView) Lookup(typ uint16) ([]byte, bool) {
+	_, data, ok := v.lookup(typ)
+	return data, ok
+}
+
+// lookup returns the type with flags and the data of the first attribute of
+// type typ, and reports whether it was found.
+func (v AttributeView) lookup(typ uint16) (uint16, []byte, bool) {
+	b := []byte(v)
+	for len(b) > 0 {
+		t, data, rest, err := nextAttribute(b)
+		if err != nil {
+			return 0, nil, false
+		}
+		if AttributeType(t) == typ {
+			return t, data, true
+		}
+
+		b = rest
+	}
+
+	return 0, nil, false
+}
+
+// Each invokes fn for each attribute in order until fn returns false.
+func (v AttributeView) Each(fn func(typ uint16, data []byte) bool) {
+	b := []byte(v)
+	for len(b) > 0 {
+		t, data, rest, err := nextAttribute(b)
+		if err != nil || !fn(AttributeType(t), data) {
+			return
+		}
+
+		b = rest
+	}
+}
+
+// Err returns an error if the attributes of v are malformed.
+func (v AttributeView) Err() error {
+	b := []byte(v)
+	for len(b) > 0 {
+		_, _, rest, err := nextAttribute(b)
+		if err != nil {
+			return err
+		}
+
+		b = rest
+	}
+
+	return nil
+}
+
+// nextAttribute parses the attribute at the start of b, returning its type
+// with flags, its data, and the remaining bytes following its padding.
+func nextAttribute(b []byte) (typ uint16, data, rest []byte, err error) {
+	if len(b) < nlaHeaderLen {
+		return 0, nil, nil, errInvalidAttribute
+	}
+
+	l := int(endian.Uint16(b[0:2]))
+	if l < nlaHeaderLen || l > len(b) {
+		return 0, nil, nil, errInvalidAttribute
+	}
+
+	if n := nlaAlign(l); n < len(b) {
+		rest = b[n:]
+	}
+
+	return endian.Uint16(b[2:4]), b[nlaHeaderLen:l], rest, nil
+}
+
+// Uint8 returns the value of the first attribute of type typ as a uint8,
+// and reports whether it was found with the correct length.
+func (v AttributeView) Uint8(typ uint16) (uint8, bool) {
+	b, ok := v.Lookup(typ)
+	if !ok || len(b) != 1 {
+		return 0, false
+	}
+
+	return b[0], true
+}
+
+// Uint16 returns the value of the first attribute of type typ as a uint16,
+// and reports whether it was found with the correct length. The value is
+// decoded in network byte order if the attribute has the
+// netlink.NetByteOrder flag set, and in native byte order otherwise.
+func (v AttributeView) Uint16(typ uint16) (uint16, bool) {
+	t, b, ok := v.lookup(typ)
+	if !ok || len(b) != 2 {
+		return 0, false
+	}
+
+	return AttributeByteOrder(t).Uint16(b), true
+}
+
+// Uint32 returns the value of the first attribute of type typ as a uint32,
+// and reports whether it was found with the correct length. The value is
+// decoded in network byte order if the attribute has the
+// netlink.NetByteOrder flag set, and in native byte order otherwise.
+func (v AttributeView) Uint32(typ uint16) (uint32, bool) {
+	t, b, ok := v.lookup(typ)
+	if !ok || len(b) != 4 {
+		return 0, false
+	}
+
+	return AttributeByteOrder(t).Uint32(b), true
+}
+
+// Uint64 returns the value of the first attribute of type typ as a uint64,
+// and reports whether it was found with the correct length. The value is
+// decoded in network byte order if the attribute has the
+// netlink.NetByteOrder flag set, and in native byte order otherwise.
+func (v AttributeView) Uint64(typ uint16) (uint64, bool) {
+	t, b, ok := v.lookup(typ)
+	if !ok || len(b) != 8 {
+		return 0, false
+	}
+
+	return AttributeByteOrder(t).Uint64(b), true
+}
+
+// String returns the value of the first attribute of type typ as a string,
+// with any trailing NULL bytes removed, and reports whether it was found.
+func (v AttributeView) String(typ uint16) (string, bool) {
+	b, ok := v.Lookup(typ)
+	if !ok {
+		return "", false
+	}
+
+	return nlenc.String(b), true
+}
+
+// Flag reports whether a flag attribute of type typ is present. Flag
+// attributes carry no data, so an attribute of type typ with data is not
+// considered a flag.
+func (v AttributeView) Flag(typ uint16) bool {
+	b, ok := v.Lookup(typ)
+	return ok && len(b) == 0
+}
+
+// Nested returns an AttributeView of the attributes nested within the first
+// attribute of type typ, and reports whether it was found.
+func (v AttributeView) Nested(typ uint16) (AttributeView, bool) {
+	b, ok := v.Lookup(typ)
+	if !ok {
+		return nil, false
+	}
+
+	return AttributeView(b), true
+}
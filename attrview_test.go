@@ -0,0 +1,124 @@
+package genetlink_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+)
+
+func TestAttributeView(t *testing.T) {
+	ae := netlink.NewAttributeEncoder()
+	ae.String(1, "foo")
+	ae.Uint8(2, 1)
+	ae.Uint16(3, 2)
+	ae.Uint32(4, 3)
+	ae.Uint64(5, 4)
+	ae.Nested(6, func(nae *netlink.AttributeEncoder) error {
+		nae.Uint32(1, 5)
+		return nil
+	})
+	ae.Uint32(4, 6)
+	ae.Flag(7, true)
+
+	b, err := ae.Encode()
+	if err != nil {
+		t.Fatalf("failed to encode attributes: %v", err)
+	}
+
+	v := genetlink.Message{Data: b}.Attributes()
+	if err := v.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if s, ok := v.String(1); !ok || s != "foo" {
+		t.Fatalf("unexpected string: %q, %v", s, ok)
+	}
+	if n, ok := v.Uint8(2); !ok || n != 1 {
+		t.Fatalf("unexpected uint8: %d, %v", n, ok)
+	}
+	if n, ok := v.Uint16(3); !ok || n != 2 {
+		t.Fatalf("unexpected uint16: %d, %v", n, ok)
+	}
+	// The first attribute of a type is returned.
+	if n, ok := v.Uint32(4); !ok || n != 3 {
+		t.Fatalf("unexpected uint32: %d, %v", n, ok)
+	}
+	if n, ok := v.Uint64(5); !ok || n != 4 {
+		t.Fatalf("unexpected uint64: %d, %v", n, ok)
+	}
+
+	// The nested flag set by the encoder is ignored.
+	nv, ok := v.Nested(6)
+	if !ok {
+		t.Fatal("expected nested attributes to be found")
+	}
+	if n, ok := nv.Uint32(1); !ok || n != 5 {
+		t.Fatalf("unexpected nested uint32: %d, %v", n, ok)
+	}
+
+	if !v.Flag(7) {
+		t.Fatal("expected flag 7 to be set")
+	}
+	if v.Flag(8) {
+		t.Fatal("expected flag 8 not to be set")
+	}
+	if v.Flag(1) {
+		t.Fatal("expected attribute with data not to be a flag")
+	}
+
+	if _, ok := v.Lookup(8); ok {
+		t.Fatal("expected attribute 8 not to be found")
+	}
+	if _, ok := v.Uint8(4); ok {
+		t.Fatal("expected attribute with incorrect length not to be found")
+	}
+
+	var types []uint16
+	v.Each(func(typ uint16, _ []byte) bool {
+		types = append(types, typ)
+		return typ != 5
+	})
+
+	if diff := cmp.Diff([]uint16{1, 2, 3, 4, 5}, types); diff != "" {
+		t.Fatalf("unexpected attribute types (-want +got):\n%s", diff)
+	}
+
+	if n := testing.AllocsPerRun(100, func() { _, _ = v.Uint32(4) }); n != 0 {
+		t.Fatalf("expected no allocations, but got %v", n)
+	}
+}
+
+func TestAttributeViewMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		b    []byte
+	}{
+		{
+			name: "short header",
+			b:    []byte{0x04, 0x00},
+		},
+		{
+			name: "short length",
+			b:    []byte{0x02, 0x00, 0x01, 0x00},
+		},
+		{
+			name: "length exceeds data",
+			b:    []byte{0x08, 0x00, 0x01, 0x00, 0xff},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := genetlink.AttributeView(tt.b)
+			if err := v.Err(); err == nil {
+				t.Fatal("expected an error, but none occurred")
+			}
+
+			if _, ok := v.Lookup(1); ok {
+				t.Fatal("expected malformed attribute not to be found")
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package genetlink
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mdlayher/netlink"
+)
+
+// An AuditRecord is a structured record of a request which may change kernel
+// state, passed to an AuditFunc.
+type AuditRecord struct {
+	// Time is the time at which the request completed, according to the
+	// Conn's Clock.
+	Time time.Time
+
+	// UID and PID identify the process which sent the request, as reported
+	// by os.Getuid and os.Getpid.
+	UID, PID int
+
+	// Family is the name of the request's family if it was retrieved by the
+	// Conn using GetFamily or ListFamilies, and FamilyID is its ID.
+	Family   string
+	FamilyID uint16
+
+	// Command, Version, Flags, and Sequence describe the request.
+	Command, Version uint8
+	Flags            netlink.HeaderFlags
+	Sequence         uint32
+
+	// Attributes summarizes the request's attributes. Attribute values are
+	// not recorded, since they may contain sensitive data such as keys.
+	Attributes []AuditAttribute
+
+	// Err is the result of the request. For requests sent using Send, Err
+	// only reports whether the request was sent; the kernel's reply must be
+	// received separately.
+	Err error
+}
+
+// An AuditAttribute summarizes an attribute of an audited request.
+type AuditAttribute struct {
+	// Type is the attribute's type, without its flags.
+	Type uint16
+
+	// Length is the length of the attribute's value.
+	Length int
+}
+
+// An AuditFunc is invoked with an AuditRecord for each audited request.
+// AuditFuncs may be invoked concurrently and should not block.
+type AuditFunc func(r AuditRecord)
+
+// SetAuditFunc sets an AuditFunc which is invoked with a record of each
+// request made by the Conn which may change kernel state, so that
+// applications in regulated environments can log who changed kernel
+// networking state and when. If fn is nil, auditing is disabled.
+//
+// Generic netlink does not distinguish requests which change state from
+// those which only retrieve it, so every request which is not a dump is
+// audited, apart from requests to the generic netlink controller itself.
+// fn can filter records by Family and Command.
+//
+// SetAuditFunc must be called before the Conn is used concurrently.
+func (c *Conn) SetAuditFunc(fn AuditFunc) {
+	if fn == nil {
+		c.audit = nil
+		return
+	}
+
+	c.audit = &auditor{
+		fn:    fn,
+		names: make(map[uint16]string),
+	}
+}
+
+// An auditor produces AuditRecords for a Conn.
+type auditor struct {
+	fn AuditFunc
+
+	mu    sync.Mutex
+	names map[uint16]string
+}
+
+// family records a resolved family so that its name can be included in
+// later records.
+func (a *auditor) family(f Family) {
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.names[f.ID] = f.Name
+}
+
+// request produces a record for the request nm which completed at time now
+// with result err, if the request may change kernel state.
+func (a *auditor) request(now time.Time, nm netlink.Message, err error) {
+	if a == nil {
+		return
+	}
+
+	id := uint16(nm.Header.Type)
+	if id == ctrlID || nm.Header.Flags&netlink.Dump == netlink.Dump {
+		return
+	}
+
+	a.mu.Lock()
+	name := a.names[id]
+	a.mu.Unlock()
+
+	r := AuditRecord{
+		Time:     now,
+		UID:      os.Getuid(),
+		PID:      os.Getpid(),
+		Family:   name,
+		FamilyID: id,
+		Flags:    nm.Header.Flags,
+		Sequence: nm.Header.Sequence,
+		Err:      err,
+	}
+
+	if len(nm.Data) >= headerLen {
+		r.Command, r.Version = nm.Data[0], nm.Data[1]
+
+		// Families with a family-specific header cannot be decoded, so
+		// their attributes are not summarized.
+		attrs, aerr := netlink.UnmarshalAttributes(nm.Data[headerLen:])
+		if aerr == nil {
+			for _, attr := range attrs {
+				r.Attributes = append(r.Attributes, AuditAttribute{
+					Type:   AttributeType(attr.Type),
+					Length: len(attr.Data),
+				})
+			}
+		}
+	}
+
+	a.fn(r)
+}
@@ -0,0 +1,200 @@
+package genetlink_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestConnAuditFunc(t *testing.T) {
+	family := genetlink.Family{
+		ID:      0x20,
+		Version: 1,
+		Name:    "foo",
+	}
+
+	c := genltest.Dial(genltest.ServeFamily(family,
+		func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			if greq.Header.Command == 2 {
+				return nil, genltest.ErrorPermission()
+			}
+
+			return []genetlink.Message{greq}, nil
+		},
+	))
+	defer c.Close()
+
+	clk := genltest.NewClock(time.Unix(1, 0))
+	c.SetClock(clk)
+
+	var records []genetlink.AuditRecord
+	c.SetAuditFunc(func(r genetlink.AuditRecord) {
+		records = append(records, r)
+	})
+
+	f, err := c.GetFamily("foo")
+	if err != nil {
+		t.Fatalf("failed to get family: %v", err)
+	}
+
+	ae := netlink.NewAttributeEncoder()
+	ae.String(1, "eth0")
+	ae.Uint32(2, 1)
+	b, err := ae.Encode()
+	if err != nil {
+		t.Fatalf("failed to encode attributes: %v", err)
+	}
+
+	// Dumps are not audited, but other requests are, whether or not they
+	// succeed.
+	reqs := []struct {
+		command uint8
+		flags   netlink.HeaderFlags
+		ok      bool
+	}{
+		{command: 1, flags: netlink.Request | netlink.Acknowledge, ok: true},
+		{command: 3, flags: netlink.Request | netlink.Dump, ok: true},
+		{command: 2, flags: netlink.Request | netlink.Acknowledge},
+	}
+
+	for _, r := range reqs {
+		m := genetlink.Message{
+			Header: genetlink.Header{Command: r.command, Version: f.Version},
+			Data:   b,
+		}
+
+		_, err := c.Execute(m, f.ID, r.flags)
+		if r.ok && err != nil {
+			t.Fatalf("failed to execute: %v", err)
+		}
+		if !r.ok && err == nil {
+			t.Fatal("expected an error, but none occurred")
+		}
+	}
+
+	attrs := []genetlink.AuditAttribute{
+		{Type: 1, Length: 5},
+		{Type: 2, Length: 4},
+	}
+
+	want := []genetlink.AuditRecord{
+		{
+			Time:       time.Unix(1, 0),
+			UID:        os.Getuid(),
+			PID:        os.Getpid(),
+			Family:     "foo",
+			FamilyID:   0x20,
+			Command:    1,
+			Version:    1,
+			Flags:      netlink.Request | netlink.Acknowledge,
+			Attributes: attrs,
+		},
+		{
+			Time:       time.Unix(1, 0),
+			UID:        os.Getuid(),
+			PID:        os.Getpid(),
+			Family:     "foo",
+			FamilyID:   0x20,
+			Command:    2,
+			Version:    1,
+			Flags:      netlink.Request | netlink.Acknowledge,
+			Attributes: attrs,
+		},
+	}
+
+	if len(records) != len(want) {
+		t.Fatalf("unexpected number of records: %d", len(records))
+	}
+
+	if !errors.Is(records[1].Err, os.ErrPermission) {
+		t.Fatalf("expected permission denied error, but got: %v", records[1].Err)
+	}
+
+	opts := cmpopts.IgnoreFields(genetlink.AuditRecord{}, "Sequence", "Err")
+	if diff := cmp.Diff(want, records, opts); diff != "" {
+		t.Fatalf("unexpected records (-want +got):\n%s", diff)
+	}
+}
+
+func TestConnAuditExecuteAllDecodeError(t *testing.T) {
+	const family = 0x20
+
+	echo := func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return []genetlink.Message{greq}, nil
+	}
+
+	c := genltest.Dial(func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		if greq.Header.Command == 2 {
+			// Deliver a reply which is too short to decode.
+			return genltest.Truncate(2, echo)(greq, nreq)
+		}
+
+		return echo(greq, nreq)
+	})
+	defer c.Close()
+
+	var records []genetlink.AuditRecord
+	c.SetAuditFunc(func(r genetlink.AuditRecord) {
+		records = append(records, r)
+	})
+
+	msgs := []genetlink.Message{
+		{Header: genetlink.Header{Command: 1}},
+		{Header: genetlink.Header{Command: 2}},
+		{Header: genetlink.Header{Command: 3}},
+	}
+
+	if _, err := c.ExecuteAll(msgs, family, netlink.Request); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+
+	// Every request was sent and may have been applied, so every request
+	// must be audited.
+	if len(records) != len(msgs) {
+		t.Fatalf("unexpected number of records: %d", len(records))
+	}
+
+	for i, r := range records {
+		if r.Command != msgs[i].Header.Command {
+			t.Fatalf("record %d: unexpected command: %d", i, r.Command)
+		}
+
+		if ok := r.Err == nil; ok != (i == 0) {
+			t.Fatalf("record %d: unexpected error: %v", i, r.Err)
+		}
+	}
+}
+
+func TestConnAuditSendError(t *testing.T) {
+	c := genetlink.NewTransportConn(&closedTransport{})
+	defer c.Close()
+
+	// Without validation, the request is sent and its replies received
+	// separately, and a failure to send must still be audited.
+	c.SetValidation(false)
+
+	var records []genetlink.AuditRecord
+	c.SetAuditFunc(func(r genetlink.AuditRecord) {
+		records = append(records, r)
+	})
+
+	req := genetlink.Message{Header: genetlink.Header{Command: 1}}
+	if _, err := c.Execute(req, 0x20, netlink.Request|netlink.Acknowledge); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("expected closed error, but got: %v", err)
+	}
+
+	if len(records) != 1 {
+		t.Fatalf("unexpected number of records: %d", len(records))
+	}
+
+	if !errors.Is(records[0].Err, os.ErrClosed) {
+		t.Fatalf("expected closed error, but got: %v", records[0].Err)
+	}
+}
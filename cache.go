@@ -0,0 +1,178 @@
+package genetlink
+
+import (
+	"sync"
+	"time"
+)
+
+// SetFamilyCache enables or disables caching of the families retrieved by
+// GetFamily, so that frequent lookups of the same family do not query the
+// generic netlink controller each time. Disabling the cache discards its
+// contents.
+//
+// Family IDs and multicast group IDs can change when a kernel module is
+// unloaded and loaded again. Applications which cache families and react to
+// such events should use InvalidateFamily or InvalidateFamilies to force the
+// families to be retrieved again.
+//
+// SetFamilyCache must be called before the Conn is used concurrently.
+func (c *Conn) SetFamilyCache(enable bool) {
+	if !enable {
+		c.cache = nil
+		return
+	}
+
+	c.cache = newFamilyCache(c.now)
+}
+
+// SetFamilyCacheTTL enables the family cache, as with SetFamilyCache, and
+// sets the time after which cached families expire and are retrieved again.
+// A TTL of 0 means that families never expire.
+//
+// A TTL bounds the time for which stale families are used after a kernel
+// module is reloaded, without the need to invalidate the cache explicitly.
+//
+// SetFamilyCacheTTL must be called before the Conn is used concurrently.
+func (c *Conn) SetFamilyCacheTTL(ttl time.Duration) {
+	if c.cache == nil {
+		c.cache = newFamilyCache(c.now)
+	}
+
+	c.cache.setTTL(ttl)
+}
+
+// InvalidateFamily removes the family with the specified name from the
+// family cache, if it is enabled.
+func (c *Conn) InvalidateFamily(name string) {
+	c.cache.invalidate(name)
+}
+
+// InvalidateFamilies removes all families from the family cache, if it is
+// enabled.
+func (c *Conn) InvalidateFamilies() {
+	c.cache.invalidateAll()
+}
+
+// A familyCache caches Families by name. A nil *familyCache caches nothing.
+type familyCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	now      func() time.Time
+	families map[string]cachedFamily
+
+	// gen is incremented by each invalidation, so that Families fetched
+	// concurrently with an invalidation are not cached.
+	gen uint64
+}
+
+// A cachedFamily is a Family and the time at which it expires.
+type cachedFamily struct {
+	f       Family
+	expires time.Time
+}
+
+// newFamilyCache creates an empty familyCache with no TTL which uses now to
+// determine the current time.
+func newFamilyCache(now func() time.Time) *familyCache {
+	return &familyCache{
+		now:      now,
+		families: make(map[string]cachedFamily),
+	}
+}
+
+// setTTL sets the TTL of Families added to the cache.
+func (fc *familyCache) setTTL(ttl time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.ttl = ttl
+}
+
+// setClock sets the function used to determine the current time.
+func (fc *familyCache) setClock(now func() time.Time) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.now = now
+}
+
+// get returns the cached Family with the specified name, or retrieves it
+// using fetch and caches it.
+func (fc *familyCache) get(name string, fetch func(name string) (Family, error)) (Family, error) {
+	if fc == nil {
+		return fetch(name)
+	}
+
+	fc.mu.Lock()
+	cf, ok := fc.families[name]
+	if ok && !cf.expires.IsZero() && !fc.now().Before(cf.expires) {
+		delete(fc.families, name)
+		ok = false
+	}
+	gen := fc.gen
+	fc.mu.Unlock()
+	if ok {
+		return copyFamily(cf.f), nil
+	}
+
+	// Don't hold the lock while waiting on I/O.
+	f, err := fetch(name)
+	if err != nil {
+		return Family{}, err
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if fc.gen != gen {
+		// The cache was invalidated during the fetch, so f may be stale.
+		return copyFamily(f), nil
+	}
+
+	cf = cachedFamily{f: f}
+	if fc.ttl > 0 {
+		cf.expires = fc.now().Add(fc.ttl)
+	}
+	fc.families[name] = cf
+
+	return copyFamily(f), nil
+}
+
+// invalidate removes the Family with the specified name.
+func (fc *familyCache) invalidate(name string) {
+	if fc == nil {
+		return
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	delete(fc.families, name)
+	fc.gen++
+}
+
+// invalidateAll removes all Families.
+func (fc *familyCache) invalidateAll() {
+	if fc == nil {
+		return
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.families = make(map[string]cachedFamily)
+	fc.gen++
+}
+
+// copyFamily returns a deep copy of f, so that callers cannot modify cached
+// Families.
+func copyFamily(f Family) Family {
+	if f.Groups != nil {
+		f.Groups = append([]MulticastGroup(nil), f.Groups...)
+	}
+	if f.Ops != nil {
+		f.Ops = append([]Op(nil), f.Ops...)
+	}
+
+	return f
+}
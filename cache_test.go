@@ -0,0 +1,146 @@
+package genetlink
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFamilyCacheTTL(t *testing.T) {
+	var (
+		now   = time.Unix(0, 0)
+		calls int
+	)
+
+	fetch := func(name string) (Family, error) {
+		calls++
+		return Family{Name: name, ID: uint16(calls)}, nil
+	}
+
+	fc := newFamilyCache(func() time.Time { return now })
+	fc.setTTL(time.Minute)
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		id      uint16
+	}{
+		{
+			name: "miss",
+			id:   1,
+		},
+		{
+			name:    "hit",
+			advance: 59 * time.Second,
+			id:      1,
+		},
+		{
+			name:    "expired",
+			advance: time.Second,
+			id:      2,
+		},
+		{
+			name:    "hit after refresh",
+			advance: 30 * time.Second,
+			id:      2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+
+			f, err := fc.get("foo", fetch)
+			if err != nil {
+				t.Fatalf("failed to get family: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.id, f.ID); diff != "" {
+				t.Fatalf("unexpected family ID (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// Without a TTL, families never expire.
+	fc.setTTL(0)
+	fc.invalidateAll()
+
+	want, err := fc.get("foo", fetch)
+	if err != nil {
+		t.Fatalf("failed to get family: %v", err)
+	}
+
+	now = now.Add(24 * time.Hour)
+	got, err := fc.get("foo", fetch)
+	if err != nil {
+		t.Fatalf("failed to get family: %v", err)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected family (-want +got):\n%s", diff)
+	}
+}
+
+func TestFamilyCacheInvalidateDuringFetch(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate func(fc *familyCache)
+	}{
+		{
+			name:       "family",
+			invalidate: func(fc *familyCache) { fc.invalidate("foo") },
+		},
+		{
+			name:       "all",
+			invalidate: (*familyCache).invalidateAll,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := newFamilyCache(time.Now)
+
+			var (
+				fetching = make(chan struct{})
+				resume   = make(chan struct{})
+				calls    uint16
+			)
+
+			fetch := func(name string) (Family, error) {
+				calls++
+				if calls == 1 {
+					close(fetching)
+					<-resume
+				}
+
+				return Family{Name: name, ID: calls}, nil
+			}
+
+			errC := make(chan error, 1)
+			go func() {
+				_, err := fc.get("foo", fetch)
+				errC <- err
+			}()
+
+			// Invalidate the family while it is being fetched, so the
+			// fetched family may be stale and must not be cached.
+			<-fetching
+			tt.invalidate(fc)
+			close(resume)
+
+			if err := <-errC; err != nil {
+				t.Fatalf("failed to get family: %v", err)
+			}
+
+			f, err := fc.get("foo", fetch)
+			if err != nil {
+				t.Fatalf("failed to get family: %v", err)
+			}
+
+			if diff := cmp.Diff(uint16(2), f.ID); diff != "" {
+				t.Fatalf("unexpected family ID (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
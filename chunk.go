@@ -0,0 +1,186 @@
+package genetlink
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrChunkBuffer is wrapped by errors returned by a ChunkWriter when chunks
+// which arrive out of order exceed its buffering limit.
+var ErrChunkBuffer = errors.New("genetlink: chunk buffering limit exceeded")
+
+// A Chunk is a piece of a larger payload, such as a firmware image or a
+// memory region, which a family transfers split across multiple attributes
+// or messages. Offset is the position of Data within the payload.
+type Chunk struct {
+	Offset uint64
+	Data   []byte
+}
+
+// A ChunkWriter reassembles a payload from Chunks, writing it to an
+// io.Writer as soon as each part of the payload is contiguous. Chunks which
+// arrive out of order are buffered, up to a limit, until the chunks which
+// precede them arrive, so reassembling a payload of any size uses bounded
+// memory.
+type ChunkWriter struct {
+	w       io.Writer
+	max     int
+	next    uint64
+	n       int
+	pending map[uint64][]byte
+}
+
+// NewChunkWriter creates a ChunkWriter which writes a payload beginning at
+// offset 0 to w, and buffers at most maxBuffered bytes of out of order
+// chunks. If maxBuffered is 0, chunks must arrive in order.
+func NewChunkWriter(w io.Writer, maxBuffered int) *ChunkWriter {
+	return &ChunkWriter{
+		w:       w,
+		max:     maxBuffered,
+		pending: make(map[uint64][]byte),
+	}
+}
+
+// WriteChunk writes c to the payload. Chunks may not overlap, so a chunk
+// which overlaps the payload written so far or a buffered chunk is rejected
+// with an error. If c arrives out of order and cannot be buffered,
+// WriteChunk returns an error wrapping ErrChunkBuffer.
+func (cw *ChunkWriter) WriteChunk(c Chunk) error {
+	if len(c.Data) == 0 {
+		return nil
+	}
+
+	if c.Offset < cw.next {
+		return fmt.Errorf("genetlink: chunk at offset %d overlaps payload written up to offset %d",
+			c.Offset, cw.next)
+	}
+	if off, ok := cw.overlap(c); ok {
+		return fmt.Errorf("genetlink: chunk at offset %d overlaps buffered chunk at offset %d",
+			c.Offset, off)
+	}
+
+	if c.Offset > cw.next {
+		if cw.n+len(c.Data) > cw.max {
+			return fmt.Errorf("%w: chunk at offset %d, want offset %d",
+				ErrChunkBuffer, c.Offset, cw.next)
+		}
+
+		// The chunk's data may refer to a buffer which will be reused, such
+		// as that of Conn.ReceiveInto, so it must be copied.
+		cw.pending[c.Offset] = append([]byte(nil), c.Data...)
+		cw.n += len(c.Data)
+		return nil
+	}
+
+	if err := cw.write(c.Data); err != nil {
+		return err
+	}
+
+	// Write any buffered chunks which are now contiguous.
+	for {
+		b, ok := cw.pending[cw.next]
+		if !ok {
+			return nil
+		}
+
+		delete(cw.pending, cw.next)
+		cw.n -= len(b)
+
+		if err := cw.write(b); err != nil {
+			return err
+		}
+	}
+}
+
+// overlap reports the offset of a buffered chunk which overlaps c, if any.
+func (cw *ChunkWriter) overlap(c Chunk) (uint64, bool) {
+	end := c.Offset + uint64(len(c.Data))
+	for off, b := range cw.pending {
+		if c.Offset < off+uint64(len(b)) && off < end {
+			return off, true
+		}
+	}
+
+	return 0, false
+}
+
+// write writes b at the end of the payload.
+func (cw *ChunkWriter) write(b []byte) error {
+	n, err := cw.w.Write(b)
+	cw.next += uint64(n)
+	return err
+}
+
+// WriteMessages writes the Chunks decoded from each of msgs by fn to the
+// payload, stopping at the first error.
+func (cw *ChunkWriter) WriteMessages(msgs []Message, fn func(m Message) ([]Chunk, error)) error {
+	for _, m := range msgs {
+		cs, err := fn(m)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range cs {
+			if err := cw.WriteChunk(c); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Written returns the number of contiguous bytes of the payload written so
+// far.
+func (cw *ChunkWriter) Written() uint64 {
+	return cw.next
+}
+
+// Close reports an error if any buffered chunks could not be written
+// because the chunks which precede them never arrived. Close does not close
+// the underlying io.Writer.
+func (cw *ChunkWriter) Close() error {
+	if len(cw.pending) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("genetlink: payload missing data at offset %d, %d bytes of chunks not written",
+		cw.next, cw.n)
+}
+
+// SplitChunks reads a payload from r and invokes fn with each Chunk of at
+// most size bytes, in order, such as to encode each chunk as an attribute of
+// a request. The Data of each Chunk is reused by the next, so fn must copy
+// it if it is retained, such as before passing it to
+// netlink.AttributeEncoder.Bytes. Only one chunk is read into memory at a
+// time.
+func SplitChunks(r io.Reader, size int, fn func(c Chunk) error) error {
+	if size <= 0 {
+		return fmt.Errorf("genetlink: invalid chunk size %d", size)
+	}
+
+	var (
+		b   = make([]byte, size)
+		off uint64
+	)
+
+	for {
+		n, err := io.ReadFull(r, b)
+		if n > 0 {
+			if ferr := fn(Chunk{Offset: off, Data: b[:n]}); ferr != nil {
+				return ferr
+			}
+
+			off += uint64(n)
+		}
+
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			return nil
+		default:
+			return err
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package genetlink_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"github.com/mdlayher/netlink/nltest"
+)
+
+func TestChunkWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		max    int
+		chunks []genetlink.Chunk
+		want   string
+		ok     bool
+		buffer bool
+	}{
+		{
+			name: "in order",
+			chunks: []genetlink.Chunk{
+				{Offset: 0, Data: []byte("foo")},
+				{Offset: 3, Data: []byte("bar")},
+			},
+			want: "foobar",
+			ok:   true,
+		},
+		{
+			name: "out of order",
+			max:  6,
+			chunks: []genetlink.Chunk{
+				{Offset: 6, Data: []byte("baz")},
+				{Offset: 3, Data: []byte("bar")},
+				{Offset: 0, Data: []byte("foo")},
+			},
+			want: "foobarbaz",
+			ok:   true,
+		},
+		{
+			name: "buffer exceeded",
+			max:  5,
+			chunks: []genetlink.Chunk{
+				{Offset: 6, Data: []byte("baz")},
+				{Offset: 3, Data: []byte("bar")},
+			},
+			buffer: true,
+		},
+		{
+			name: "overlap",
+			chunks: []genetlink.Chunk{
+				{Offset: 0, Data: []byte("foo")},
+				{Offset: 2, Data: []byte("bar")},
+			},
+			want: "foo",
+		},
+		{
+			name: "overlap buffered",
+			max:  6,
+			chunks: []genetlink.Chunk{
+				{Offset: 6, Data: []byte("baz")},
+				{Offset: 5, Data: []byte("xyz")},
+				{Offset: 0, Data: []byte("fooba")},
+			},
+		},
+		{
+			name: "overlap buffered in order",
+			max:  3,
+			chunks: []genetlink.Chunk{
+				{Offset: 3, Data: []byte("bar")},
+				{Offset: 0, Data: []byte("food")},
+			},
+		},
+		{
+			name: "gap",
+			max:  3,
+			chunks: []genetlink.Chunk{
+				{Offset: 0, Data: []byte("foo")},
+				{Offset: 6, Data: []byte("baz")},
+			},
+			want: "foo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			cw := genetlink.NewChunkWriter(&buf, tt.max)
+
+			var err error
+			for _, c := range tt.chunks {
+				if err = cw.WriteChunk(c); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = cw.Close()
+			}
+
+			if tt.ok && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.ok && err == nil {
+				t.Fatal("expected an error, but none occurred")
+			}
+
+			if diff := cmp.Diff(tt.buffer, errors.Is(err, genetlink.ErrChunkBuffer)); diff != "" {
+				t.Fatalf("unexpected buffer error (-want +got):\n%s\nerror: %v", diff, err)
+			}
+
+			if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+				t.Fatalf("unexpected payload (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSplitChunksRoundTrip(t *testing.T) {
+	const (
+		attrData   = 1
+		attrOffset = 2
+	)
+
+	payload := bytes.Repeat([]byte("genetlink"), 100)
+
+	// Split the payload into one message per chunk, as a family might
+	// transfer a large binary object.
+	var msgs []genetlink.Message
+	err := genetlink.SplitChunks(bytes.NewReader(payload), 64, func(c genetlink.Chunk) error {
+		msgs = append(msgs, genetlink.Message{
+			Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+				{Type: attrData, Data: append([]byte(nil), c.Data...)},
+				{Type: attrOffset, Data: nlenc.Uint64Bytes(c.Offset)},
+			}),
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to split chunks: %v", err)
+	}
+
+	if diff := cmp.Diff(15, len(msgs)); diff != "" {
+		t.Fatalf("unexpected number of messages (-want +got):\n%s", diff)
+	}
+
+	// Deliver the messages in reverse to exercise buffering.
+	for i, j := 0, len(msgs)-1; i < j; i, j = i+1, j-1 {
+		msgs[i], msgs[j] = msgs[j], msgs[i]
+	}
+
+	var buf bytes.Buffer
+	cw := genetlink.NewChunkWriter(&buf, len(payload))
+	err = cw.WriteMessages(msgs, func(m genetlink.Message) ([]genetlink.Chunk, error) {
+		v := m.Attributes()
+		data, _ := v.Lookup(attrData)
+		off, _ := v.Uint64(attrOffset)
+
+		return []genetlink.Chunk{{Offset: off, Data: data}}, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to write messages: %v", err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	if diff := cmp.Diff(uint64(len(payload)), cw.Written()); diff != "" {
+		t.Fatalf("unexpected written bytes (-want +got):\n%s", diff)
+	}
+
+	if !bytes.Equal(payload, buf.Bytes()) {
+		t.Fatal("reassembled payload does not match")
+	}
+}
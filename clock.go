@@ -0,0 +1,70 @@
+package genetlink
+
+import "time"
+
+// A Clock provides the current time and tickers to the time-dependent
+// features of this package, such as family cache TTLs, subscription
+// deduplication windows, health checks, and watermark sampling. A Clock can
+// be replaced, for example by genltest.Clock, so that such behavior can be
+// tested deterministically.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTicker returns a Ticker which ticks with period d, as with
+	// time.NewTicker.
+	NewTicker(d time.Duration) Ticker
+}
+
+// A Ticker delivers ticks at intervals, as with time.Ticker.
+type Ticker interface {
+	// Chan returns the channel on which ticks are delivered.
+	Chan() <-chan time.Time
+
+	// Stop turns off the Ticker.
+	Stop()
+}
+
+// SystemClock returns a Clock which uses the time package.
+func SystemClock() Clock { return systemClock{} }
+
+var _ Clock = systemClock{}
+
+// A systemClock is a Clock which uses the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+// A systemTicker adapts a *time.Ticker to the Ticker interface.
+type systemTicker struct {
+	t *time.Ticker
+}
+
+func (t systemTicker) Chan() <-chan time.Time { return t.t.C }
+func (t systemTicker) Stop()                  { t.t.Stop() }
+
+// SetClock sets the Clock used by the time-dependent features of the Conn
+// and of Monitors which use the Conn. By default, SystemClock is used.
+//
+// SetClock must be called before the Conn is used concurrently.
+func (c *Conn) SetClock(clk Clock) {
+	c.clk = clk
+}
+
+// clock returns the Conn's Clock.
+func (c *Conn) clock() Clock {
+	if c.clk == nil {
+		return systemClock{}
+	}
+
+	return c.clk
+}
+
+// now returns the current time according to the Conn's Clock.
+func (c *Conn) now() time.Time {
+	return c.clock().Now()
+}
@@ -0,0 +1,31 @@
+// Command genlagent is a generic netlink agent which performs operations on
+// behalf of a genlremote client connected via its standard input and output.
+//
+// genlagent is typically invoked over SSH:
+//
+//	c, err := genlremote.DialCommand(exec.Command("ssh", "host", "genlagent"))
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/mdlayher/genetlink/genlremote"
+)
+
+func main() {
+	// Standard output carries the protocol, so log to standard error.
+	log.SetOutput(os.Stderr)
+
+	if err := genlremote.Serve(stdio{r: os.Stdin, w: os.Stdout}); err != nil {
+		log.Fatalf("genlagent: %v", err)
+	}
+}
+
+// stdio is an io.ReadWriter using standard input and output.
+type stdio struct {
+	r, w *os.File
+}
+
+func (s stdio) Read(b []byte) (int, error)  { return s.r.Read(b) }
+func (s stdio) Write(b []byte) (int, error) { return s.w.Write(b) }
@@ -0,0 +1,245 @@
+// Command genlsh is an interactive shell for exploring generic netlink
+// families. It can resolve families, compose attributes symbolically, send
+// commands, and pretty-print the responses.
+//
+// Type "help" at the prompt for a list of commands.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+)
+
+const usage = `commands:
+  families                         list all registered families
+  family <name>                    show a family's ID, version, ops, and groups
+  send <family> <command> [args]   send a command and print the replies
+  help                             show this help
+  quit                             exit the shell
+
+send arguments:
+  dump, ack, root, match, atomic   set netlink header flags (request is implied)
+  version=<n>                      set the generic netlink command version
+  <type>:<kind>=<value>            append an attribute, where kind is one of:
+                                   u8, u16, u32, u64, string, flag, hex
+  <type>:nest=(<attrs...>)         append a nested attribute
+
+example:
+  send nlctrl 3 2:string=nlctrl`
+
+func main() {
+	c, err := genetlink.Dial(nil)
+	if err != nil {
+		log.Fatalf("failed to dial generic netlink: %v", err)
+	}
+	defer c.Close()
+
+	sh := &shell{
+		c:        c,
+		w:        os.Stdout,
+		families: make(map[string]genetlink.Family),
+	}
+
+	if err := sh.run(os.Stdin); err != nil {
+		log.Fatalf("failed to read input: %v", err)
+	}
+}
+
+// A shell is an interactive generic netlink shell.
+type shell struct {
+	c        *genetlink.Conn
+	w        io.Writer
+	families map[string]genetlink.Family
+}
+
+// run reads and executes commands from r until EOF or "quit".
+func (sh *shell) run(r io.Reader) error {
+	s := bufio.NewScanner(r)
+	for {
+		fmt.Fprint(sh.w, "genlsh> ")
+		if !s.Scan() {
+			fmt.Fprintln(sh.w)
+			return s.Err()
+		}
+
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if fields[0] == "quit" || fields[0] == "exit" {
+			return nil
+		}
+
+		if err := sh.exec(fields[0], fields[1:]); err != nil {
+			fmt.Fprintf(sh.w, "error: %v\n", err)
+		}
+	}
+}
+
+// exec executes a single shell command.
+func (sh *shell) exec(cmd string, args []string) error {
+	switch cmd {
+	case "help":
+		fmt.Fprintln(sh.w, usage)
+		return nil
+	case "families":
+		fs, err := sh.c.ListFamilies()
+		if err != nil {
+			return err
+		}
+
+		for _, f := range fs {
+			sh.families[f.Name] = f
+			fmt.Fprintf(sh.w, "%5d  %-20s version %d\n", f.ID, f.Name, f.Version)
+		}
+
+		return nil
+	case "family":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: family <name>")
+		}
+
+		f, err := sh.family(args[0])
+		if err != nil {
+			return err
+		}
+
+		printFamily(sh.w, f)
+		return nil
+	case "send":
+		return sh.send(args)
+	default:
+		return fmt.Errorf("unknown command %q, try \"help\"", cmd)
+	}
+}
+
+// family resolves a family by name, caching the result.
+func (sh *shell) family(name string) (genetlink.Family, error) {
+	if f, ok := sh.families[name]; ok {
+		return f, nil
+	}
+
+	f, err := sh.c.GetFamily(name)
+	if err != nil {
+		return genetlink.Family{}, err
+	}
+
+	sh.families[name] = f
+	return f, nil
+}
+
+// send implements the "send" command.
+func (sh *shell) send(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: send <family> <command> [args]")
+	}
+
+	f, err := sh.family(args[0])
+	if err != nil {
+		return err
+	}
+
+	cmd, err := strconv.ParseUint(args[1], 0, 8)
+	if err != nil {
+		return fmt.Errorf("invalid command %q: %v", args[1], err)
+	}
+
+	req, err := parseRequest(args[2:])
+	if err != nil {
+		return err
+	}
+
+	req.msg.Header.Command = uint8(cmd)
+	if req.msg.Header.Version == 0 {
+		req.msg.Header.Version = f.Version
+	}
+
+	msgs, err := sh.c.Execute(req.msg, f.ID, req.flags)
+	if err != nil {
+		return err
+	}
+
+	for i, m := range msgs {
+		fmt.Fprintf(sh.w, "reply %d: command %d, version %d\n", i, m.Header.Command, m.Header.Version)
+		printAttributes(sh.w, m.Data, 1)
+	}
+
+	if len(msgs) == 0 {
+		fmt.Fprintln(sh.w, "no replies")
+	}
+
+	return nil
+}
+
+// printFamily pretty-prints a family.
+func printFamily(w io.Writer, f genetlink.Family) {
+	fmt.Fprintf(w, "name:    %s\nid:      %d\nversion: %d\n", f.Name, f.ID, f.Version)
+
+	if len(f.Ops) > 0 {
+		fmt.Fprintln(w, "ops:")
+		for _, o := range f.Ops {
+			fmt.Fprintf(w, "  %3d  %s\n", o.ID, opFlags(o.Flags))
+		}
+	}
+
+	if len(f.Groups) > 0 {
+		fmt.Fprintln(w, "groups:")
+		for _, g := range f.Groups {
+			fmt.Fprintf(w, "  %3d  %s\n", g.ID, g.Name)
+		}
+	}
+}
+
+// opFlags returns a human-readable representation of OpFlags.
+func opFlags(f genetlink.OpFlags) string {
+	var s []string
+	for _, fl := range []struct {
+		f    genetlink.OpFlags
+		name string
+	}{
+		{f: genetlink.OpCapDo, name: "do"},
+		{f: genetlink.OpCapDump, name: "dump"},
+		{f: genetlink.OpCapHasPolicy, name: "policy"},
+		{f: genetlink.OpAdminPerm, name: "admin"},
+		{f: genetlink.OpUnsAdminPerm, name: "ns-admin"},
+	} {
+		if f&fl.f != 0 {
+			s = append(s, fl.name)
+		}
+	}
+
+	return strings.Join(s, ",")
+}
+
+// printAttributes pretty-prints the attributes packed in b. Values which
+// appear to be nested attributes are printed recursively.
+func printAttributes(w io.Writer, b []byte, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil {
+		fmt.Fprintf(w, "%sdata: [%# x]\n", indent, b)
+		return
+	}
+
+	for _, a := range attrs {
+		typ := a.Type &^ (netlink.Nested | netlink.NetByteOrder)
+
+		if a.Type&netlink.Nested != 0 || looksNested(a.Data) {
+			fmt.Fprintf(w, "%s%d:\n", indent, typ)
+			printAttributes(w, a.Data, depth+1)
+			continue
+		}
+
+		fmt.Fprintf(w, "%s%d: %s\n", indent, typ, formatValue(a.Data))
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/internal/endian"
+	"github.com/mdlayher/netlink"
+)
+
+// A request is a parsed "send" command.
+type request struct {
+	msg   genetlink.Message
+	flags netlink.HeaderFlags
+}
+
+// parseRequest parses the arguments of a "send" command into a request.
+func parseRequest(args []string) (request, error) {
+	req := request{flags: netlink.Request}
+
+	var attrs []string
+	for _, arg := range args {
+		switch {
+		case arg == "dump":
+			req.flags |= netlink.Dump
+		case arg == "ack":
+			req.flags |= netlink.Acknowledge
+		case arg == "root":
+			req.flags |= netlink.Root
+		case arg == "match":
+			req.flags |= netlink.Match
+		case arg == "atomic":
+			req.flags |= netlink.Atomic
+		case strings.HasPrefix(arg, "version="):
+			v, err := strconv.ParseUint(strings.TrimPrefix(arg, "version="), 0, 8)
+			if err != nil {
+				return request{}, fmt.Errorf("invalid version %q: %v", arg, err)
+			}
+
+			req.msg.Header.Version = uint8(v)
+		default:
+			attrs = append(attrs, arg)
+		}
+	}
+
+	ae := netlink.NewAttributeEncoder()
+	if err := encodeAttributes(ae, strings.Join(attrs, " ")); err != nil {
+		return request{}, err
+	}
+
+	b, err := ae.Encode()
+	if err != nil {
+		return request{}, err
+	}
+
+	req.msg.Data = b
+	return req, nil
+}
+
+// encodeAttributes encodes a space-separated list of symbolic attributes
+// using ae.
+func encodeAttributes(ae *netlink.AttributeEncoder, s string) error {
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		i := strings.IndexByte(s, '=')
+		if i == -1 {
+			return fmt.Errorf("invalid attribute %q: expected <type>:<kind>=<value>", s)
+		}
+
+		spec := s[:i]
+		s = s[i+1:]
+
+		var value string
+		if strings.HasPrefix(s, "(") {
+			end, err := matchParen(s)
+			if err != nil {
+				return err
+			}
+
+			value, s = s[1:end], s[end+1:]
+		} else {
+			end := strings.IndexFunc(s, unicode.IsSpace)
+			if end == -1 {
+				end = len(s)
+			}
+
+			value, s = s[:end], s[end:]
+		}
+
+		if err := encodeAttribute(ae, spec, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeAttribute encodes a single attribute with the specified
+// "<type>:<kind>" spec and value.
+func encodeAttribute(ae *netlink.AttributeEncoder, spec, value string) error {
+	ts, kind, ok := strings.Cut(spec, ":")
+	if !ok {
+		return fmt.Errorf("invalid attribute %q: expected <type>:<kind>", spec)
+	}
+
+	t, err := strconv.ParseUint(ts, 0, 14)
+	if err != nil {
+		return fmt.Errorf("invalid attribute type %q: %v", ts, err)
+	}
+	typ := uint16(t)
+
+	parse := func(bits int) (uint64, error) {
+		v, err := strconv.ParseUint(value, 0, bits)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s value %q: %v", kind, value, err)
+		}
+
+		return v, nil
+	}
+
+	switch kind {
+	case "u8":
+		v, err := parse(8)
+		if err != nil {
+			return err
+		}
+		ae.Uint8(typ, uint8(v))
+	case "u16":
+		v, err := parse(16)
+		if err != nil {
+			return err
+		}
+		ae.Uint16(typ, uint16(v))
+	case "u32":
+		v, err := parse(32)
+		if err != nil {
+			return err
+		}
+		ae.Uint32(typ, uint32(v))
+	case "u64":
+		v, err := parse(64)
+		if err != nil {
+			return err
+		}
+		ae.Uint64(typ, v)
+	case "string":
+		ae.String(typ, value)
+	case "flag":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid flag value %q: %v", value, err)
+		}
+		ae.Flag(typ, v)
+	case "hex":
+		b, err := hex.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("invalid hex value %q: %v", value, err)
+		}
+		ae.Bytes(typ, b)
+	case "nest":
+		var nerr error
+		ae.Nested(typ, func(nae *netlink.AttributeEncoder) error {
+			nerr = encodeAttributes(nae, value)
+			return nerr
+		})
+		return nerr
+	default:
+		return fmt.Errorf("unknown attribute kind %q", kind)
+	}
+
+	return nil
+}
+
+// matchParen returns the index of the parenthesis which closes the one at the
+// beginning of s.
+func matchParen(s string) (int, error) {
+	var depth int
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("unbalanced parentheses in %q", s)
+}
+
+// formatValue formats an attribute value for display, decoding it as a string
+// or integer where it appears to be one.
+func formatValue(b []byte) string {
+	if isString(b) {
+		return fmt.Sprintf("%q", strings.TrimRight(string(b), "\x00"))
+	}
+
+	switch len(b) {
+	case 0:
+		return "(flag)"
+	case 2:
+		return fmt.Sprintf("%d [%# x]", endian.Uint16(b), b)
+	case 4:
+		if !looksNested(b) {
+			return fmt.Sprintf("%d [%# x]", endian.Uint32(b), b)
+		}
+	case 8:
+		if !looksNested(b) {
+			return fmt.Sprintf("%d [%# x]", endian.Uint64(b), b)
+		}
+	}
+
+	return fmt.Sprintf("[%# x]", b)
+}
+
+// isString reports whether b appears to be a NUL-terminated printable string.
+func isString(b []byte) bool {
+	if len(b) < 2 || b[len(b)-1] != 0x00 {
+		return false
+	}
+
+	for _, c := range b[:len(b)-1] {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// looksNested reports whether b appears to contain nested attributes: it must
+// decode cleanly with each attribute's length consuming the buffer exactly.
+func looksNested(b []byte) bool {
+	if len(b) < 4 {
+		return false
+	}
+
+	for len(b) > 0 {
+		if len(b) < 4 {
+			return false
+		}
+
+		l := int(endian.Uint16(b[0:2]))
+		if l < 4 || l > len(b) {
+			return false
+		}
+
+		l = (l + 3) &^ 3
+		if l > len(b) {
+			l = len(b)
+		}
+		b = b[l:]
+	}
+
+	return true
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"github.com/mdlayher/netlink/nltest"
+)
+
+func TestParseRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		req  request
+		ok   bool
+	}{
+		{
+			name: "bad attribute",
+			args: []string{"1:u8"},
+		},
+		{
+			name: "bad kind",
+			args: []string{"1:foo=1"},
+		},
+		{
+			name: "overflow",
+			args: []string{"1:u8=256"},
+		},
+		{
+			name: "unbalanced",
+			args: []string{"1:nest=(2:u8=1"},
+		},
+		{
+			name: "empty",
+			req: request{
+				msg:   genetlink.Message{Data: []byte{}},
+				flags: netlink.Request,
+			},
+			ok: true,
+		},
+		{
+			name: "OK",
+			args: []string{
+				"dump", "ack", "version=2",
+				"1:u16=0x10", "2:string=nlctrl",
+				"3:nest=(1:u8=1", "2:hex=ff00)",
+			},
+			req: request{
+				msg: genetlink.Message{
+					Header: genetlink.Header{Version: 2},
+					Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+						{
+							Type: 1,
+							Data: nlenc.Uint16Bytes(0x10),
+						},
+						{
+							Type: 2,
+							Data: nlenc.Bytes("nlctrl"),
+						},
+						{
+							Type: 3 | netlink.Nested,
+							Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+								{
+									Type: 1,
+									Data: []byte{0x01},
+								},
+								{
+									Type: 2,
+									Data: []byte{0xff, 0x00},
+								},
+							}),
+						},
+					}),
+				},
+				flags: netlink.Request | netlink.Dump | netlink.Acknowledge,
+			},
+			ok: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := parseRequest(tt.args)
+
+			if err != nil && tt.ok {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err == nil && !tt.ok {
+				t.Fatal("expected an error, but none occurred")
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.req, req, cmp.AllowUnexported(request{})); diff != "" {
+				t.Fatalf("unexpected request (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFormatValueUnaligned(t *testing.T) {
+	tests := []struct {
+		name string
+		b    []byte
+		want string
+	}{
+		{
+			name: "u16",
+			b:    nlenc.Uint16Bytes(0x0102),
+			want: fmt.Sprintf("%d [%# x]", uint16(0x0102), nlenc.Uint16Bytes(0x0102)),
+		},
+		{
+			name: "u32",
+			b:    nlenc.Uint32Bytes(0x01020304),
+			want: fmt.Sprintf("%d [%# x]", uint32(0x01020304), nlenc.Uint32Bytes(0x01020304)),
+		},
+		{
+			name: "u64",
+			b:    nlenc.Uint64Bytes(0x0102030405060708),
+			want: fmt.Sprintf("%d [%# x]", uint64(0x0102030405060708), nlenc.Uint64Bytes(0x0102030405060708)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Format a copy of the value which begins at an odd address, so
+			// that decoding which dereferences unaligned pointers faults on
+			// strict-alignment architectures such as mips.
+			buf := make([]byte, len(tt.b)+1)
+			copy(buf[1:], tt.b)
+
+			if diff := cmp.Diff(tt.want, formatValue(buf[1:])); diff != "" {
+				t.Fatalf("unexpected value (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+// Command genlsoak is a long-running soak test and diagnostic for generic
+// netlink. It subscribes to multicast groups, performs periodic dumps, and
+// periodically reports event counts, receive buffer overruns, socket drops,
+// and dump latency, both to test the stability of this package over hours
+// and to help users chase intermittent event loss.
+//
+// For example, to watch nl80211 events and dump its interfaces every ten
+// seconds:
+//
+//	genlsoak -group nl80211/mlme -dump nl80211/5 -dump-interval 10s
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+)
+
+func main() {
+	var (
+		groups, dumps specs
+
+		dumpInterval = flag.Duration("dump-interval", 30*time.Second, "interval between periodic dumps")
+		report       = flag.Duration("report", time.Minute, "interval between statistics reports")
+		duration     = flag.Duration("duration", 0, "duration of the soak test; if 0, run until interrupted")
+		readBuffer   = flag.Int("read-buffer", 0, "socket receive buffer size in bytes for events; if 0, use the default")
+	)
+
+	flag.Var(&groups, "group", "multicast group to join as family/group; may be repeated")
+	flag.Var(&dumps, "dump", "dump to perform periodically as family/command; may be repeated")
+	flag.Parse()
+
+	if len(groups) == 0 && len(dumps) == 0 {
+		log.Fatal("at least one -group or -dump must be specified")
+	}
+
+	for _, d := range dumps {
+		if _, err := d.command(); err != nil {
+			log.Fatalf("invalid dump %s: %v", d, err)
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if *duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	var st stats
+	st.start = time.Now()
+
+	done := make(chan struct{})
+	if len(groups) > 0 {
+		mc, err := genetlink.Dial(nil)
+		if err != nil {
+			log.Fatalf("failed to dial generic netlink: %v", err)
+		}
+
+		if *readBuffer > 0 {
+			if err := mc.SetReadBuffer(*readBuffer); err != nil {
+				log.Fatalf("failed to set read buffer: %v", err)
+			}
+		}
+
+		m := genetlink.NewMonitor(mc)
+		for _, g := range groups {
+			if err := m.Subscribe(g.family, g.name); err != nil {
+				log.Fatalf("failed to join group %s: %v", g, err)
+			}
+		}
+
+		st.drops = func() (uint32, error) {
+			mi, err := mc.MemInfo()
+			return mi.Drops, err
+		}
+
+		go func() {
+			defer close(done)
+			monitor(ctx, m, &st)
+		}()
+
+		go func() {
+			<-ctx.Done()
+			_ = st.closeDrops(mc.Close)
+		}()
+	} else {
+		close(done)
+	}
+
+	if len(dumps) > 0 {
+		dc, err := genetlink.Dial(nil)
+		if err != nil {
+			log.Fatalf("failed to dial generic netlink: %v", err)
+		}
+		defer dc.Close()
+
+		go dumpLoop(ctx, dc, dumps, *dumpInterval, &st)
+	}
+
+	t := time.NewTicker(*report)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			log.Print(st.report())
+		case <-ctx.Done():
+			<-done
+			log.Printf("final: %s", st.report())
+			return
+		}
+	}
+}
+
+// monitor receives events until ctx is canceled, recording each event and
+// restarting after receive buffer overruns.
+func monitor(ctx context.Context, m *genetlink.Monitor, st *stats) {
+	for {
+		err := m.Run(func(e genetlink.Event) error {
+			st.event(e.Family)
+			return nil
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		if errors.Is(err, syscall.ENOBUFS) {
+			// The kernel dropped events because the receive buffer
+			// overflowed. Keep going, as a long-running monitor would.
+			st.overrun()
+			continue
+		}
+
+		log.Fatalf("failed to receive events: %v", err)
+	}
+}
+
+// dumpLoop performs each dump at the specified interval until ctx is
+// canceled, recording its latency and outcome.
+func dumpLoop(ctx context.Context, c *genetlink.Conn, dumps specs, interval time.Duration, st *stats) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		for _, d := range dumps {
+			f, err := c.GetFamily(d.family)
+			if err != nil {
+				log.Printf("failed to get family %q: %v", d.family, err)
+				st.dump(0, 0, err)
+				continue
+			}
+
+			// Commands were validated at startup.
+			cmd, _ := d.command()
+
+			start := time.Now()
+			msgs, err := c.Dump(cmd, f, nil)
+			st.dump(time.Since(start), len(msgs), err)
+		}
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// A spec is a family and a multicast group name or command, specified as
+// family/name.
+type spec struct {
+	family, name string
+}
+
+func (s spec) String() string { return s.family + "/" + s.name }
+
+// command parses the spec's name as a generic netlink command.
+func (s spec) command() (uint8, error) {
+	cmd, err := strconv.ParseUint(s.name, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("command must be an integer: %v", err)
+	}
+
+	return uint8(cmd), nil
+}
+
+// specs is a flag.Value which accumulates repeated specs.
+type specs []spec
+
+var _ flag.Value = &specs{}
+
+func (ss *specs) String() string {
+	strs := make([]string, 0, len(*ss))
+	for _, s := range *ss {
+		strs = append(strs, s.String())
+	}
+
+	return strings.Join(strs, ",")
+}
+
+func (ss *specs) Set(v string) error {
+	family, name, ok := strings.Cut(v, "/")
+	if !ok || family == "" || name == "" {
+		return fmt.Errorf("invalid specification %q, want family/name", v)
+	}
+
+	*ss = append(*ss, spec{family: family, name: name})
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSpecs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		ss   specs
+		ok   bool
+	}{
+		{
+			name: "no separator",
+			args: []string{"nl80211"},
+		},
+		{
+			name: "no family",
+			args: []string{"/mlme"},
+		},
+		{
+			name: "no name",
+			args: []string{"nl80211/"},
+		},
+		{
+			name: "OK",
+			args: []string{"nl80211/mlme", "nlctrl/3"},
+			ss: specs{
+				{family: "nl80211", name: "mlme"},
+				{family: "nlctrl", name: "3"},
+			},
+			ok: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ss specs
+			for _, a := range tt.args {
+				if err := ss.Set(a); err != nil {
+					if tt.ok {
+						t.Fatalf("failed to set: %v", err)
+					}
+
+					return
+				}
+			}
+			if !tt.ok {
+				t.Fatal("expected an error, but none occurred")
+			}
+
+			if diff := cmp.Diff(tt.ss, ss, cmp.AllowUnexported(spec{})); diff != "" {
+				t.Fatalf("unexpected specs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSpecCommand(t *testing.T) {
+	if _, err := (spec{family: "nlctrl", name: "notify"}).command(); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+
+	cmd, err := (spec{family: "nlctrl", name: "3"}).command()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(uint8(3), cmd); diff != "" {
+		t.Fatalf("unexpected command (-want +got):\n%s", diff)
+	}
+}
+
+func TestStatsReport(t *testing.T) {
+	st := stats{
+		start: time.Now(),
+		drops: func() (uint32, error) { return 2, nil },
+	}
+
+	st.event("nl80211")
+	st.event("nl80211")
+	st.event("nlctrl")
+	st.overrun()
+	st.dump(10*time.Millisecond, 4, nil)
+	st.dump(30*time.Millisecond, 6, nil)
+	st.dump(0, 0, errors.New("permission denied"))
+
+	want := "elapsed 0s, events 3 (nl80211=2 nlctrl=1), overruns 1, socket drops 2, " +
+		"dumps 3 (1 failed, 10 messages), dump latency min/avg/max 10ms/20ms/30ms, " +
+		"last error: permission denied"
+
+	if diff := cmp.Diff(want, st.report()); diff != "" {
+		t.Fatalf("unexpected report (-want +got):\n%s", diff)
+	}
+}
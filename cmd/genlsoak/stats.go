@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stats accumulates the statistics of a soak test.
+type stats struct {
+	start time.Time
+
+	// drops reports the socket's dropped message count, if set. The last
+	// count is retained in case the socket is closed.
+	drops     func() (uint32, error)
+	lastDrops *uint32
+
+	mu        sync.Mutex
+	events    map[string]uint64
+	overruns  uint64
+	dumps     uint64
+	failures  uint64
+	messages  uint64
+	latency   time.Duration
+	minLat    time.Duration
+	maxLat    time.Duration
+	lastError error
+}
+
+// event records an event from the named family.
+func (s *stats) event(family string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.events == nil {
+		s.events = make(map[string]uint64)
+	}
+	s.events[family]++
+}
+
+// overrun records a receive buffer overrun.
+func (s *stats) overrun() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overruns++
+}
+
+// dump records a dump which took d and returned n messages, or failed with
+// err.
+func (s *stats) dump(d time.Duration, n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dumps++
+	if err != nil {
+		s.failures++
+		s.lastError = err
+		return
+	}
+
+	s.messages += uint64(n)
+	s.latency += d
+	if s.minLat == 0 || d < s.minLat {
+		s.minLat = d
+	}
+	if d > s.maxLat {
+		s.maxLat = d
+	}
+}
+
+// sampleDrops records the socket's dropped message count, if available.
+// s.mu must be held.
+func (s *stats) sampleDrops() {
+	if s.drops == nil {
+		return
+	}
+
+	if n, err := s.drops(); err == nil {
+		s.lastDrops = &n
+	}
+}
+
+// closeDrops records the socket's final dropped message count and then
+// invokes close to close the socket.
+func (s *stats) closeDrops(close func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sampleDrops()
+	return close()
+}
+
+// report summarizes the statistics accumulated so far.
+func (s *stats) report() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "elapsed %s", time.Since(s.start).Round(time.Second))
+
+	var total uint64
+	names := make([]string, 0, len(s.events))
+	for name, n := range s.events {
+		names = append(names, name)
+		total += n
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(&b, ", events %d", total)
+	if len(names) > 0 {
+		parts := make([]string, 0, len(names))
+		for _, name := range names {
+			parts = append(parts, fmt.Sprintf("%s=%d", name, s.events[name]))
+		}
+
+		fmt.Fprintf(&b, " (%s)", strings.Join(parts, " "))
+	}
+
+	fmt.Fprintf(&b, ", overruns %d", s.overruns)
+
+	s.sampleDrops()
+	if s.lastDrops != nil {
+		fmt.Fprintf(&b, ", socket drops %d", *s.lastDrops)
+	}
+
+	if s.dumps > 0 {
+		fmt.Fprintf(&b, ", dumps %d (%d failed, %d messages)", s.dumps, s.failures, s.messages)
+
+		if ok := s.dumps - s.failures; ok > 0 {
+			fmt.Fprintf(&b, ", dump latency min/avg/max %s/%s/%s",
+				s.minLat, s.latency/time.Duration(ok), s.maxLat)
+		}
+
+		if s.lastError != nil {
+			fmt.Fprintf(&b, ", last error: %v", s.lastError)
+		}
+	}
+
+	return b.String()
+}
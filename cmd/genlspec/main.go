@@ -0,0 +1,62 @@
+// Command genlspec queries the running kernel for generic netlink family
+// information and prints YAML netlink specification documents for each
+// requested family.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genlspec"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [family ...]\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "If no families are specified, all registered families are printed.")
+	}
+	flag.Parse()
+
+	c, err := genetlink.Dial(nil)
+	if err != nil {
+		log.Fatalf("failed to dial generic netlink: %v", err)
+	}
+	defer c.Close()
+
+	var families []genetlink.Family
+	if flag.NArg() == 0 {
+		families, err = c.ListFamilies()
+		if err != nil {
+			log.Fatalf("failed to list families: %v", err)
+		}
+	} else {
+		for _, name := range flag.Args() {
+			f, err := c.GetFamily(name)
+			if err != nil {
+				log.Fatalf("failed to get family %q: %v", name, err)
+			}
+
+			families = append(families, f)
+		}
+	}
+
+	for i, f := range families {
+		// Policy information is unavailable on older kernels, so continue
+		// without it rather than failing outright.
+		p, err := c.GetPolicy(f.Name)
+		if err != nil {
+			log.Printf("failed to get policy for family %q, continuing without attribute sets: %v", f.Name, err)
+		}
+
+		if i > 0 {
+			fmt.Println("---")
+		}
+
+		if err := genlspec.Encode(os.Stdout, f, p); err != nil {
+			log.Fatalf("failed to encode family %q: %v", f.Name, err)
+		}
+	}
+}
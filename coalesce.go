@@ -0,0 +1,112 @@
+package genetlink
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mdlayher/netlink"
+)
+
+// SetDumpCoalescing enables or disables coalescing of identical concurrent
+// dump requests made using Dump or DumpContext. When enabled, if a dump
+// request is made while an identical request (with the same family, command,
+// version, and attributes) is already in progress, the request is not sent,
+// and the replies to the request in progress are returned to both callers
+// instead.
+// This greatly reduces the load on the kernel and the Conn when, for
+// example, an exporter is scraped by several clients at once.
+//
+// Coalesced callers share the Data of the returned Messages, which must not
+// be modified.
+//
+// SetDumpCoalescing must be called before the Conn is used concurrently.
+func (c *Conn) SetDumpCoalescing(enable bool) {
+	if !enable {
+		c.dumps = nil
+		return
+	}
+
+	c.dumps = &dumpGroup{}
+}
+
+// dumpKey identifies a dump request by its family, generic netlink header,
+// and attributes.
+func dumpKey(nm netlink.Message) string {
+	b := make([]byte, 2, 2+len(nm.Data))
+	b[0], b[1] = byte(nm.Header.Type>>8), byte(nm.Header.Type)
+	return string(append(b, nm.Data...))
+}
+
+// A dumpGroup coalesces concurrent dumps with the same key.
+type dumpGroup struct {
+	mu    sync.Mutex
+	calls map[string]*dumpCall
+}
+
+// A dumpCall is a dump in progress.
+type dumpCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	msgs    []Message
+	err     error
+}
+
+// do invokes fn for key, or joins the invocation already in progress for
+// key, and waits for its result or for ctx to be done.
+//
+// The shared invocation runs under a context of its own rather than that of
+// any one caller, so that a caller's deadline cannot abort the dump for the
+// others. The shared context is canceled once every caller has given up
+// waiting.
+func (g *dumpGroup) do(ctx context.Context, key string, fn func(ctx context.Context) ([]Message, error)) ([]Message, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*dumpCall)
+	}
+
+	call, ok := g.calls[key]
+	if !ok {
+		dctx, cancel := context.WithCancel(context.Background())
+
+		call = &dumpCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+
+		go func() {
+			defer cancel()
+
+			call.msgs, call.err = fn(dctx)
+
+			g.mu.Lock()
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+			g.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		// Each caller receives its own slice of the shared Messages.
+		return append([]Message(nil), call.msgs...), call.err
+	case <-ctx.Done():
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	call.waiters--
+	if call.waiters == 0 {
+		// Nobody is waiting for the dump, so abort it, and ensure that later
+		// callers start a new one.
+		call.cancel()
+		if g.calls[key] == call {
+			delete(g.calls, key)
+		}
+	}
+
+	return nil, ctx.Err()
+}
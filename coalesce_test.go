@@ -0,0 +1,171 @@
+package genetlink
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDumpGroupCoalesce(t *testing.T) {
+	const n = 5
+
+	var (
+		g       dumpGroup
+		calls   int
+		release = make(chan struct{})
+		want    = []Message{{Header: Header{Command: 1}, Data: []byte{0xff}}}
+	)
+
+	fn := func(_ context.Context) ([]Message, error) {
+		calls++
+		<-release
+		return want, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([][]Message, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			results[i], errs[i] = g.do(context.Background(), "foo", fn)
+		}(i)
+	}
+
+	// Wait for all callers to join the dump in progress.
+	for waiters(&g, "foo") != n {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if diff := cmp.Diff(1, calls); diff != "" {
+		t.Fatalf("unexpected number of dumps (-want +got):\n%s", diff)
+	}
+
+	for i, got := range results {
+		if err := errs[i]; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("unexpected messages (-want +got):\n%s", diff)
+		}
+	}
+
+	// The dump is no longer in progress, so a new call runs fn again.
+	if _, err := g.do(context.Background(), "foo", fn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(2, calls); diff != "" {
+		t.Fatalf("unexpected number of dumps (-want +got):\n%s", diff)
+	}
+}
+
+func TestDumpGroupCancel(t *testing.T) {
+	var (
+		g       dumpGroup
+		release = make(chan struct{})
+		dctxC   = make(chan context.Context, 1)
+		want    = []Message{{Header: Header{Command: 1}}}
+	)
+
+	fn := func(ctx context.Context) ([]Message, error) {
+		dctxC <- ctx
+		select {
+		case <-release:
+			return want, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	type result struct {
+		msgs []Message
+		err  error
+	}
+
+	// The first caller gives up early, but the second waits for the dump.
+	ctx, cancel := context.WithCancel(context.Background())
+	firstC := make(chan result, 1)
+	go func() {
+		msgs, err := g.do(ctx, "foo", fn)
+		firstC <- result{msgs, err}
+	}()
+
+	dctx := <-dctxC
+
+	secondC := make(chan result, 1)
+	go func() {
+		msgs, err := g.do(context.Background(), "foo", fn)
+		secondC <- result{msgs, err}
+	}()
+
+	for waiters(&g, "foo") != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if r := <-firstC; !errors.Is(r.err, context.Canceled) {
+		t.Fatalf("expected canceled, but got: %v", r.err)
+	}
+
+	// The first caller's cancelation does not abort the dump for the second.
+	if err := dctx.Err(); err != nil {
+		t.Fatalf("shared dump was canceled: %v", err)
+	}
+
+	close(release)
+	r := <-secondC
+	if r.err != nil {
+		t.Fatalf("unexpected error: %v", r.err)
+	}
+	if diff := cmp.Diff(want, r.msgs); diff != "" {
+		t.Fatalf("unexpected messages (-want +got):\n%s", diff)
+	}
+}
+
+func TestDumpGroupCancelAll(t *testing.T) {
+	var (
+		g     dumpGroup
+		dctxC = make(chan context.Context, 1)
+	)
+
+	fn := func(ctx context.Context) ([]Message, error) {
+		dctxC <- ctx
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := g.do(ctx, "foo", fn); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, but got: %v", err)
+	}
+
+	// Once nobody is waiting, the shared dump is aborted.
+	<-(<-dctxC).Done()
+
+	if diff := cmp.Diff(0, waiters(&g, "foo")); diff != "" {
+		t.Fatalf("unexpected waiters (-want +got):\n%s", diff)
+	}
+}
+
+// waiters returns the number of callers waiting on the dump in progress for
+// key.
+func waiters(g *dumpGroup, key string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if call, ok := g.calls[key]; ok {
+		return call.waiters
+	}
+
+	return 0
+}
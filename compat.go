@@ -0,0 +1,53 @@
+package genetlink
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+)
+
+// DialCompat dials a generic netlink connection in legacy compatibility
+// mode, for applications targeting old or embedded kernels on which
+// enabling extended acknowledgements or strict request checking fails or
+// changes the semantics of requests. config is used as with Dial.
+//
+// In compatibility mode, the Strict field of config is ignored, and the
+// netlink.ExtendedAcknowledge and netlink.GetStrictCheck options are
+// explicitly disabled. Kernels which predate those options are tolerated.
+// Attempts to enable either option using SetOption return an error, so that
+// libraries sharing the Conn cannot enable them inadvertently.
+func DialCompat(config *netlink.Config) (*Conn, error) {
+	var cfg netlink.Config
+	if config != nil {
+		cfg = *config
+	}
+	cfg.Strict = false
+
+	c, err := Dial(&cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.disableStrict(); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+
+	c.compat = true
+	return c, nil
+}
+
+// checkCompatOption returns an error if option cannot be enabled because the
+// Conn is in compatibility mode.
+func (c *Conn) checkCompatOption(option netlink.ConnOption, enable bool) error {
+	if !c.compat || !enable {
+		return nil
+	}
+
+	switch option {
+	case netlink.ExtendedAcknowledge, netlink.GetStrictCheck:
+		return fmt.Errorf("genetlink: option %d cannot be enabled in compatibility mode", option)
+	}
+
+	return nil
+}
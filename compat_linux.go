@@ -0,0 +1,27 @@
+//go:build linux
+// +build linux
+
+package genetlink
+
+import (
+	"errors"
+
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// disableStrict disables extended acknowledgements and strict checking,
+// ignoring kernels which do not support the options.
+func (c *Conn) disableStrict() error {
+	for _, o := range []netlink.ConnOption{
+		netlink.ExtendedAcknowledge,
+		netlink.GetStrictCheck,
+	} {
+		err := c.SetOption(o, false)
+		if err != nil && !errors.Is(err, unix.ENOPROTOOPT) {
+			return err
+		}
+	}
+
+	return nil
+}
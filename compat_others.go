@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package genetlink
+
+// disableStrict always returns an error.
+func (c *Conn) disableStrict() error {
+	return errUnimplemented
+}
@@ -1,6 +1,10 @@
 package genetlink
 
 import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -17,30 +21,108 @@ const Protocol = 0x10 // unix.NETLINK_GENERIC
 // A Conn is safe for concurrent use, but to avoid contention in
 // high-throughput applications, the caller should almost certainly create a
 // pool of Conns and distribute them among workers.
+//
+// If a system call is denied with EACCES or EPERM although the process holds
+// CAP_NET_ADMIN, a Conn's operations return an *LSMError, possibly within a
+// *netlink.OpError, describing the security policy permission likely
+// involved.
 type Conn struct {
-	// Operating system-specific netlink connection.
-	c *netlink.Conn
+	// Operating system-specific netlink connection, or another Transport.
+	c Transport
+
+	// Optional scheduler for request/reply operations.
+	sched *scheduler
+
+	// Optional cache for GetFamily.
+	cache *familyCache
+
+	// Optional coalescing of concurrent identical dumps.
+	dumps *dumpGroup
+
+	// Optional Clock for time-dependent behavior.
+	clk Clock
+
+	// Optional limits on the replies to a request.
+	limits ResponseLimits
+
+	// Sequence numbers for requests which may be abandoned, and the request
+	// whose remaining replies must be drained, if any.
+	seq       uint32
+	abandoned abandonedRequest
+
+	// Tracks in-flight requests for CloseWrite and Shutdown.
+	reqs requestTracker
+
+	// Coordinates use of the socket, and the deadlines set by the caller.
+	io ioLock
+	dl deadlines
+
+	// Set atomically to 1 when reply validation is disabled.
+	noValidate int32
+
+	// Set by DialCompat for legacy compatibility mode.
+	compat bool
+
+	// Optional auditing of requests which may change kernel state.
+	audit *auditor
+
+	// Optional debugging output, enabled by DebugEnv.
+	d *debugger
+
+	// Creates a Transport to the same backend, set by Dial.
+	dial func() (Transport, error)
 }
 
 // Dial dials a generic netlink connection.  Config specifies optional
 // configuration for the underlying netlink connection.  If config is
 // nil, a default configuration will be used.
+//
+// To bind the connection to a chosen port ID or legacy multicast group
+// bitmask, set the PID and Groups fields of config. The bound address can be
+// retrieved using Conn.Addr.
+//
+// If the environment does not support or denies generic netlink sockets,
+// the returned error is an *EnvironmentError which matches ErrUnavailable.
+// Errors which report that permission was denied although the process holds
+// CAP_NET_ADMIN are returned as an *LSMError, as with the Conn's operations.
 func Dial(config *netlink.Config) (*Conn, error) {
 	c, err := netlink.Dial(Protocol, config)
 	if err != nil {
-		return nil, err
+		return nil, lsmError(environmentError(err))
 	}
 
-	return NewConn(c), nil
+	// Further sockets share the configuration, such as the network
+	// namespace, but are bound to an address of their own.
+	var dcfg netlink.Config
+	if config != nil {
+		dcfg = *config
+	}
+	dcfg.PID, dcfg.Groups = 0, 0
+
+	nc := NewConn(c)
+	nc.dial = func() (Transport, error) {
+		c, err := netlink.Dial(Protocol, &dcfg)
+		if err != nil {
+			return nil, lsmError(environmentError(err))
+		}
+
+		return c, nil
+	}
+
+	return nc, nil
 }
 
 // NewConn creates a Conn that wraps an existing *netlink.Conn for
 // generic netlink communications.
 //
 // NewConn is primarily useful for tests. Most applications should use
-// Dial instead.
+// Dial instead. To use a backend other than a *netlink.Conn, see
+// NewTransportConn.
 func NewConn(c *netlink.Conn) *Conn {
-	return &Conn{c: c}
+	return &Conn{
+		c: c,
+		d: newDebugger(debugArgs),
+	}
 }
 
 // Close closes the connection and unblocks any pending read operations.
@@ -48,54 +130,195 @@ func (c *Conn) Close() error {
 	return c.c.Close()
 }
 
+// ErrWriteClosed is returned when sending a request using a Conn which was
+// closed for writing by CloseWrite.
+var ErrWriteClosed = errors.New("genetlink: connection closed for writing")
+
+// CloseWrite stops the Conn from sending any further requests, while still
+// allowing pending multicast messages and replies to be received. After
+// CloseWrite, all operations which send requests return ErrWriteClosed.
+//
+// CloseWrite supports graceful shutdown of daemons which receive multicast
+// messages: call CloseWrite so that no new requests are started, LeaveGroup
+// to stop new messages from arriving, and Receive to drain the messages
+// which are already queued (optionally bounded by SetReadDeadline), before
+// finally calling Close.
+func (c *Conn) CloseWrite() error {
+	c.reqs.closeWrite()
+	return nil
+}
+
 // GetFamily retrieves a generic netlink family with the specified name.
 //
 // If the family does not exist, the error value can be checked using
 // `errors.Is(err, os.ErrNotExist)`.
+//
+// If the family cache is enabled by SetFamilyCache, the family is retrieved
+// from the cache when possible.
 func (c *Conn) GetFamily(name string) (Family, error) {
-	return c.getFamily(name)
+	return c.getFamilyUsing(name, c.getFamily)
+}
+
+// getFamilyUsing implements GetFamily, retrieving families which are not
+// cached using fetch.
+func (c *Conn) getFamilyUsing(name string, fetch func(name string) (Family, error)) (Family, error) {
+	f, err := c.cache.get(name, fetch)
+	if err == nil {
+		c.audit.family(f)
+		c.debug(func(d *debugger) { d.family(f) })
+	}
+
+	return f, err
+}
+
+// GetFamilyInfo retrieves a generic netlink family with the specified name,
+// along with the optional information requested by opts. If opts is nil,
+// only the Family is retrieved, as with GetFamily.
+//
+// If the family does not exist, the error value can be checked using
+// `errors.Is(err, os.ErrNotExist)`.
+func (c *Conn) GetFamilyInfo(name string, opts *GetFamilyOptions) (FamilyInfo, error) {
+	if opts == nil {
+		opts = &GetFamilyOptions{}
+	}
+
+	f, err := c.GetFamily(name)
+	if err != nil {
+		return FamilyInfo{}, err
+	}
+
+	info := FamilyInfo{Family: f}
+	if !opts.Policy {
+		return info, nil
+	}
+
+	// Families without any policies have nothing to retrieve, and older
+	// kernels may not support the request at all.
+	var hasPolicy bool
+	for _, o := range f.Ops {
+		if o.Flags&OpCapHasPolicy != 0 {
+			hasPolicy = true
+			break
+		}
+	}
+	if !hasPolicy {
+		info.Policy = &Policy{}
+		return info, nil
+	}
+
+	p, err := c.getPolicy(name)
+	if err != nil {
+		return FamilyInfo{}, err
+	}
+	info.Policy = &p
+
+	return info, nil
+}
+
+// GetPolicy retrieves the attribute validation policies for the generic
+// netlink family with the specified name. GetPolicy requires Linux 5.10+.
+//
+// If the family does not exist, the error value can be checked using
+// `errors.Is(err, os.ErrNotExist)`.
+func (c *Conn) GetPolicy(name string) (Policy, error) {
+	return c.getPolicy(name)
 }
 
 // ListFamilies retrieves all registered generic netlink families.
 func (c *Conn) ListFamilies() ([]Family, error) {
-	return c.listFamilies()
+	fs, err := c.listFamilies()
+	if err == nil {
+		for _, f := range fs {
+			c.audit.family(f)
+		}
+
+		c.debug(func(d *debugger) {
+			for _, f := range fs {
+				d.family(f)
+			}
+		})
+	}
+
+	return fs, err
 }
 
 // JoinGroup joins a netlink multicast group by its ID.
 func (c *Conn) JoinGroup(group uint32) error {
-	return c.c.JoinGroup(group)
+	gjl, ok := c.c.(groupJoinLeaver)
+	if !ok {
+		return notSupported("join-group")
+	}
+
+	return gjl.JoinGroup(group)
 }
 
 // LeaveGroup leaves a netlink multicast group by its ID.
 func (c *Conn) LeaveGroup(group uint32) error {
-	return c.c.LeaveGroup(group)
+	gjl, ok := c.c.(groupJoinLeaver)
+	if !ok {
+		return notSupported("leave-group")
+	}
+
+	return gjl.LeaveGroup(group)
 }
 
 // SetBPF attaches an assembled BPF program to a Conn.
 func (c *Conn) SetBPF(filter []bpf.RawInstruction) error {
-	return c.c.SetBPF(filter)
+	bs, ok := c.c.(bpfSetter)
+	if !ok {
+		return notSupported("set-bpf")
+	}
+
+	return bs.SetBPF(filter)
 }
 
 // RemoveBPF removes a BPF filter from a Conn.
 func (c *Conn) RemoveBPF() error {
-	return c.c.RemoveBPF()
+	bs, ok := c.c.(bpfSetter)
+	if !ok {
+		return notSupported("remove-bpf")
+	}
+
+	return bs.RemoveBPF()
 }
 
 // SetOption enables or disables a netlink socket option for the Conn.
+//
+// In compatibility mode, the netlink.ExtendedAcknowledge and
+// netlink.GetStrictCheck options cannot be enabled. See DialCompat.
 func (c *Conn) SetOption(option netlink.ConnOption, enable bool) error {
-	return c.c.SetOption(option, enable)
+	if err := c.checkCompatOption(option, enable); err != nil {
+		return err
+	}
+
+	so, ok := c.c.(optionSetter)
+	if !ok {
+		return notSupported("set-option")
+	}
+
+	return so.SetOption(option, enable)
 }
 
 // SetReadBuffer sets the size of the operating system's receive buffer
 // associated with the Conn.
 func (c *Conn) SetReadBuffer(bytes int) error {
-	return c.c.SetReadBuffer(bytes)
+	bs, ok := c.c.(bufferSetter)
+	if !ok {
+		return notSupported("set-read-buffer")
+	}
+
+	return bs.SetReadBuffer(bytes)
 }
 
 // SetWriteBuffer sets the size of the operating system's transmit buffer
 // associated with the Conn.
 func (c *Conn) SetWriteBuffer(bytes int) error {
-	return c.c.SetWriteBuffer(bytes)
+	bs, ok := c.c.(bufferSetter)
+	if !ok {
+		return notSupported("set-write-buffer")
+	}
+
+	return bs.SetWriteBuffer(bytes)
 }
 
 // SyscallConn returns a raw network connection. This implements the
@@ -108,22 +331,32 @@ func (c *Conn) SetWriteBuffer(bytes int) error {
 // performed using Conn and the syscall.RawConn do not conflict with
 // each other.
 func (c *Conn) SyscallConn() (syscall.RawConn, error) {
-	return c.c.SyscallConn()
+	sc, ok := c.c.(syscall.Conn)
+	if !ok {
+		return nil, notSupported("syscall-conn")
+	}
+
+	return sc.SyscallConn()
 }
 
 // SetDeadline sets the read and write deadlines associated with the connection.
+//
+// Operations which apply a deadline of their own, such as ExecuteContext,
+// apply the earlier of the two deadlines while they run, and restore the
+// deadlines set using SetDeadline, SetReadDeadline, and SetWriteDeadline
+// before they return.
 func (c *Conn) SetDeadline(t time.Time) error {
-	return c.c.SetDeadline(t)
+	return c.setDeadlines("set-deadline", true, true, t)
 }
 
 // SetReadDeadline sets the read deadline associated with the connection.
 func (c *Conn) SetReadDeadline(t time.Time) error {
-	return c.c.SetReadDeadline(t)
+	return c.setDeadlines("set-read-deadline", true, false, t)
 }
 
 // SetWriteDeadline sets the write deadline associated with the connection.
 func (c *Conn) SetWriteDeadline(t time.Time) error {
-	return c.c.SetWriteDeadline(t)
+	return c.setDeadlines("set-write-deadline", false, true, t)
 }
 
 // Send sends a single Message to netlink, wrapping it in a netlink.Message
@@ -131,25 +364,57 @@ func (c *Conn) SetWriteDeadline(t time.Time) error {
 // returns a copy of the netlink.Message with all parameters populated, for
 // later validation.
 func (c *Conn) Send(m Message, family uint16, flags netlink.HeaderFlags) (netlink.Message, error) {
+	return c.SendSequence(m, family, flags, 0)
+}
+
+// SendSequence is like Send, but sends the Message with the specified netlink
+// sequence number rather than the next one chosen by the Conn, for callers
+// which correlate requests and replies themselves or replay captured
+// requests. The sequence number used is available in the returned
+// netlink.Message.
+//
+// Netlink reserves a sequence number of 0 to mean "unset", so if seq is 0,
+// the Conn chooses the sequence number as with Send.
+func (c *Conn) SendSequence(m Message, family uint16, flags netlink.HeaderFlags, seq uint32) (netlink.Message, error) {
 	nm, err := packMessage(m, family, flags)
 	if err != nil {
 		return netlink.Message{}, err
 	}
+	nm.Header.Sequence = seq
+
+	if err := c.reqs.begin(); err != nil {
+		return netlink.Message{}, err
+	}
+	defer c.reqs.end()
 
+	release := c.io.rlock()
 	reqnm, err := c.c.Send(nm)
+	release()
 	if err != nil {
-		return netlink.Message{}, err
+		c.debug(func(d *debugger) { d.debugf(1, "send: err: %v", err) })
+		c.audit.request(c.now(), nm, err)
+		return netlink.Message{}, lsmError(err)
 	}
 
+	c.audit.request(c.now(), reqnm, nil)
+
+	c.debug(func(d *debugger) { d.request("send", reqnm) })
 	return reqnm, nil
 }
 
 // Receive receives one or more Messages from netlink.  The netlink.Messages
 // used to wrap each Message are available for later validation.
 func (c *Conn) Receive() ([]Message, []netlink.Message, error) {
+	release := c.io.rlock()
 	msgs, err := c.c.Receive()
+	release()
+	return received(msgs, err)
+}
+
+// received unpacks the netlink messages returned by a receive.
+func received(msgs []netlink.Message, err error) ([]Message, []netlink.Message, error) {
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, lsmError(err)
 	}
 
 	gmsgs, err := unpackMessages(msgs)
@@ -160,6 +425,55 @@ func (c *Conn) Receive() ([]Message, []netlink.Message, error) {
 	return gmsgs, msgs, nil
 }
 
+// ReceiveInto is like Receive, but stores the received Messages in the
+// storage of msgs, growing it only if it is too small, and returns the
+// resulting slice. Passing the previously returned slice to each call
+// allows a high-frequency event loop to avoid allocating a slice of
+// Messages for each receive:
+//
+//	var msgs []genetlink.Message
+//	for {
+//		var err error
+//		msgs, _, err = c.ReceiveInto(msgs)
+//		if err != nil {
+//			// ...
+//		}
+//
+//		// Process msgs before the next call.
+//	}
+//
+// The Data of each Message refers to the memory of the received netlink
+// message, so Messages must be processed (or cloned using Message.Clone)
+// before the next call to ReceiveInto overwrites them. The read buffer is
+// managed by the underlying netlink.Conn and cannot be supplied by the
+// caller.
+func (c *Conn) ReceiveInto(msgs []Message) ([]Message, []netlink.Message, error) {
+	release := c.io.rlock()
+	nmsgs, err := c.c.Receive()
+	release()
+	if err != nil {
+		return msgs[:0], nil, lsmError(err)
+	}
+
+	gmsgs, err := unpackMessagesInto(msgs[:0], nmsgs)
+	if err != nil {
+		return msgs[:0], nil, err
+	}
+
+	return gmsgs, nmsgs, nil
+}
+
+// ReceiveRaw receives one or more netlink.Messages from netlink without
+// unpacking them as generic netlink Messages. ReceiveRaw is useful for
+// families whose messages do not follow the usual layout of a generic
+// netlink header followed by attributes.
+func (c *Conn) ReceiveRaw() ([]netlink.Message, error) {
+	release := c.io.rlock()
+	msgs, err := c.c.Receive()
+	release()
+	return msgs, lsmError(err)
+}
+
 // Execute sends a single Message to netlink using Send, receives one or more
 // replies using Receive, and then checks the validity of the replies against
 // the request using netlink.Validate.
@@ -171,13 +485,79 @@ func (c *Conn) Receive() ([]Message, []netlink.Message, error) {
 // See the documentation of Send, Receive, and netlink.Validate for details
 // about each function.
 func (c *Conn) Execute(m Message, family uint16, flags netlink.HeaderFlags) ([]Message, error) {
+	release, err := c.sched.acquire(context.Background(), defaultPriority(flags))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return c.execute(context.Background(), m, family, flags)
+}
+
+// ExecuteFn executes a request for the specified command and version of a
+// family using Execute. If fn is not nil, it is invoked to encode the
+// request's attributes, and any error it returns is returned by ExecuteFn.
+//
+// ExecuteFn combines the common sequence of encoding attributes, building a
+// Message, and executing it into a single call:
+//
+//	msgs, err := c.ExecuteFn(cmdGet, family.Version, family.ID, netlink.Request,
+//		func(ae *netlink.AttributeEncoder) error {
+//			ae.Uint32(attrIfindex, 1)
+//			return nil
+//		})
+func (c *Conn) ExecuteFn(command, version uint8, family uint16, flags netlink.HeaderFlags,
+	fn func(ae *netlink.AttributeEncoder) error) ([]Message, error) {
+	m, err := encodeRequest(command, version, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Execute(m, family, flags)
+}
+
+// encodeRequest builds a request Message, invoking attrs to encode its
+// attributes if it is not nil.
+func encodeRequest(command, version uint8, attrs func(ae *netlink.AttributeEncoder) error) (Message, error) {
+	m := Message{
+		Header: Header{
+			Command: command,
+			Version: version,
+		},
+	}
+
+	if attrs == nil {
+		return m, nil
+	}
+
+	ae := netlink.NewAttributeEncoder()
+	if err := attrs(ae); err != nil {
+		return Message{}, err
+	}
+
+	b, err := ae.Encode()
+	if err != nil {
+		return Message{}, err
+	}
+	m.Data = b
+
+	return m, nil
+}
+
+// execute implements Execute, applying ctx to the exchange of the request
+// and its replies.
+func (c *Conn) execute(ctx context.Context, m Message, family uint16, flags netlink.HeaderFlags) ([]Message, error) {
 	nm, err := packMessage(m, family, flags)
 	if err != nil {
 		return nil, err
 	}
 
-	// Locking behavior handled by netlink.Conn.Execute.
-	msgs, err := c.c.Execute(nm)
+	if err := c.reqs.begin(); err != nil {
+		return nil, err
+	}
+	defer c.reqs.end()
+
+	msgs, err := c.roundTrip(ctx, nm)
 	if err != nil {
 		return nil, err
 	}
@@ -185,6 +565,372 @@ func (c *Conn) Execute(m Message, family uint16, flags netlink.HeaderFlags) ([]M
 	return unpackMessages(msgs)
 }
 
+// A BatchResult is the result of executing a single Message with
+// Conn.ExecuteAll.
+type BatchResult struct {
+	// Messages contains any replies to the Message, excluding its
+	// acknowledgement.
+	Messages []Message
+
+	// Err is the error returned by netlink for the Message, if any.
+	Err error
+}
+
+// ExecuteAll sends several Messages to netlink as a single batch, using the
+// specified generic netlink family and flags, and waits for the replies and
+// acknowledgement to each. The netlink.Acknowledge flag is always set so
+// that the outcome of every Message is known.
+//
+// ExecuteAll returns a BatchResult for each Message in the same order as
+// msgs, so that the caller can determine exactly which Messages failed. The
+// kernel processes each Message in order, and a failing Message does not
+// prevent the following Messages from being processed. A non-nil error is
+// returned only if the batch could not be sent or its replies could not be
+// received.
+//
+// Dump requests are not supported by ExecuteAll. ExecuteAll must not be
+// called concurrently with Receive, which could consume its replies.
+func (c *Conn) ExecuteAll(msgs []Message, family uint16, flags netlink.HeaderFlags) ([]BatchResult, error) {
+	if flags&netlink.Dump != 0 {
+		return nil, errors.New("genetlink: ExecuteAll does not support dump requests")
+	}
+
+	nms := make([]netlink.Message, 0, len(msgs))
+	for _, m := range msgs {
+		nm, err := packMessage(m, family, flags|netlink.Acknowledge)
+		if err != nil {
+			return nil, err
+		}
+
+		nms = append(nms, nm)
+	}
+
+	if err := c.reqs.begin(); err != nil {
+		return nil, err
+	}
+	defer c.reqs.end()
+
+	release, err := c.sched.acquire(context.Background(), PriorityNormal)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var results []BatchResult
+	err = c.exclusive(context.Background(), func() error {
+		var err error
+		results, err = c.batch(nms)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// batch sends a batch of requests and receives the replies to each. It must
+// be called with exclusive use of the socket.
+func (c *Conn) batch(nms []netlink.Message) ([]BatchResult, error) {
+	// Discard the leftovers of an abandoned request before sending another.
+	if err := c.drain(); err != nil {
+		return nil, err
+	}
+
+	reqs, err := c.c.SendMessages(nms)
+	if err != nil {
+		for _, nm := range nms {
+			c.audit.request(c.now(), nm, err)
+		}
+
+		return nil, lsmError(err)
+	}
+
+	c.debug(func(d *debugger) {
+		for _, req := range reqs {
+			d.request("batch", req)
+		}
+	})
+
+	// Correlate replies with requests by sequence number.
+	index := make(map[uint32]int, len(reqs))
+	for i, req := range reqs {
+		index[req.Header.Sequence] = i
+	}
+
+	var (
+		results = make([]BatchResult, len(reqs))
+		done    = make([]bool, len(reqs))
+		next    int
+	)
+
+	// complete marks request i as complete and finds the next request which
+	// is awaiting a reply.
+	complete := func(i int) {
+		done[i] = true
+		for next < len(done) && done[next] {
+			next++
+		}
+	}
+
+	// fail audits every request in the batch once err prevents the batch
+	// from completing. The outcome of the remaining requests is unknown.
+	fail := func(err error) {
+		for i, req := range reqs {
+			rerr := results[i].Err
+			if !done[i] {
+				rerr = err
+			}
+
+			c.audit.request(c.now(), req, rerr)
+		}
+	}
+
+	for next < len(reqs) {
+		nmsgs, err := c.c.Receive()
+		if err != nil {
+			if !isMessageError(err) {
+				if isReceiveTimeout(err) {
+					// The kernel replies to requests in order, so the
+					// remaining replies end with the last acknowledgement.
+					c.abandon(reqs[len(reqs)-1].Header)
+				}
+
+				err = lsmError(err)
+				fail(err)
+				return nil, err
+			}
+
+			// The kernel replies to requests in order and each error is
+			// delivered separately, so an error belongs to the oldest
+			// request which has not yet been acknowledged.
+			results[next].Err = lsmError(err)
+			complete(next)
+			continue
+		}
+
+		for _, nm := range nmsgs {
+			i, ok := index[nm.Header.Sequence]
+			if !ok || done[i] {
+				// Not a reply to this batch.
+				continue
+			}
+
+			if nm.Header.Type == netlink.Error {
+				// Acknowledgement.
+				complete(i)
+				continue
+			}
+
+			var gm Message
+			if err := gm.UnmarshalBinary(nm.Data); err != nil {
+				fail(err)
+				return nil, err
+			}
+
+			results[i].Messages = append(results[i].Messages, gm)
+		}
+	}
+
+	for i, req := range reqs {
+		c.audit.request(c.now(), req, results[i].Err)
+	}
+
+	return results, nil
+}
+
+// isMessageError reports whether err was produced by a netlink error message,
+// rather than by a system call.
+func isMessageError(err error) bool {
+	var oerr *netlink.OpError
+	if !errors.As(err, &oerr) {
+		return false
+	}
+
+	var serr *os.SyscallError
+	return !errors.As(oerr.Err, &serr)
+}
+
+// ErrDumpInterrupted is returned by Dump when the kernel reports that the data
+// being dumped changed while the dump was in progress, so the results may be
+// inconsistent. The messages which were received are returned along with
+// ErrDumpInterrupted, and callers will typically retry the dump.
+var ErrDumpInterrupted = errors.New("genetlink: dump interrupted")
+
+// Dump executes a dump request for the specified command and family, and
+// returns all of the messages received in reply. The request uses the
+// family's ID and version, and if attrs is not nil, it is invoked to encode
+// the request's attributes.
+//
+// Dump sets the netlink.Request and netlink.Dump flags, and the accumulation
+// of multi-part replies is handled by Execute. If any of the replies carry
+// the netlink.DumpInterrupted flag, the replies are returned along with
+// ErrDumpInterrupted.
+func (c *Conn) Dump(cmd uint8, family Family, attrs func(ae *netlink.AttributeEncoder) error) ([]Message, error) {
+	return c.dumpContext(context.Background(), cmd, family, attrs)
+}
+
+// dumpContext implements Dump and DumpContext.
+func (c *Conn) dumpContext(ctx context.Context, cmd uint8, family Family,
+	attrs func(ae *netlink.AttributeEncoder) error) ([]Message, error) {
+	req, err := encodeRequest(cmd, family.Version, attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	nm, err := packMessage(req, family.ID, netlink.Request|netlink.Dump)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.dumps != nil {
+		return c.dumps.do(ctx, dumpKey(nm), func(ctx context.Context) ([]Message, error) {
+			return c.dump(ctx, nm)
+		})
+	}
+
+	return c.dump(ctx, nm)
+}
+
+// dump implements Dump, applying ctx to the exchange of the request and its
+// replies.
+func (c *Conn) dump(ctx context.Context, nm netlink.Message) ([]Message, error) {
+	if err := c.reqs.begin(); err != nil {
+		return nil, err
+	}
+	defer c.reqs.end()
+
+	release, err := c.sched.acquire(ctx, PriorityLow)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	msgs, err := c.roundTrip(ctx, nm)
+	if err != nil {
+		return nil, err
+	}
+
+	gmsgs, err := unpackMessages(msgs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range msgs {
+		if m.Header.Flags&netlink.DumpInterrupted != 0 {
+			return gmsgs, ErrDumpInterrupted
+		}
+	}
+
+	return gmsgs, nil
+}
+
+// SetValidation enables or disables validation of the sequence numbers and
+// port IDs of the replies received by Execute and Dump. Validation is
+// enabled by default.
+//
+// With validation disabled, messages which are not correlated with the
+// request, such as multicast messages received by the same socket, are
+// returned as replies rather than causing a validation error. This mirrors
+// the kernel's NETLINK_NO_SEQ_CHECK behavior and is intended for consumers
+// such as multicast listeners which legitimately receive such messages.
+//
+// Disabling validation also disables the locking which ensures consistency
+// between requests and replies, so Execute and Dump must not be called
+// concurrently while validation is disabled.
+func (c *Conn) SetValidation(enable bool) {
+	var v int32
+	if !enable {
+		v = 1
+	}
+
+	atomic.StoreInt32(&c.noValidate, v)
+}
+
+// roundTrip sends a request and receives its replies using exchange. If ctx
+// can be canceled, or the replies are streamed to enforce the response
+// limits, the exchange has exclusive use of the socket, so that the context
+// does not interrupt other operations and no other operation reads the
+// replies.
+func (c *Conn) roundTrip(ctx context.Context, nm netlink.Message) ([]netlink.Message, error) {
+	pr, stream := c.parts()
+	if ctx.Done() == nil && !stream {
+		release := c.io.rlock()
+		defer release()
+
+		return c.exchange(nm, nil)
+	}
+
+	var msgs []netlink.Message
+	err := c.exclusive(ctx, func() error {
+		var err error
+		msgs, err = c.exchange(nm, pr)
+		return err
+	})
+
+	return msgs, err
+}
+
+// exchange sends a request and receives its replies, validating them unless
+// validation is disabled, and checks them against the response limits. If
+// pr is not nil, the replies are streamed using pr. If the replies cannot be
+// received before a deadline, the request is recorded as abandoned so that
+// its remaining replies are drained later.
+func (c *Conn) exchange(nm netlink.Message, pr partReceiver) ([]netlink.Message, error) {
+	// Discard the leftovers of an abandoned request before sending another.
+	if err := c.drain(); err != nil {
+		return nil, err
+	}
+
+	if nm.Header.Sequence == 0 && nm.Header.Flags&(netlink.Dump|netlink.Acknowledge) != 0 {
+		// Choose the sequence number so the replies can be identified if
+		// the request is abandoned.
+		nm.Header.Sequence = c.nextSequence()
+	}
+
+	c.debug(func(d *debugger) { d.request("execute", nm) })
+
+	var (
+		msgs []netlink.Message
+		err  error
+		sent = true
+	)
+
+	switch {
+	case pr != nil:
+		// The limits are checked as the replies arrive.
+		msgs, err = c.stream(pr, nm)
+	case atomic.LoadInt32(&c.noValidate) == 0:
+		// Locking behavior handled by netlink.Conn.Execute.
+		msgs, err = c.c.Execute(nm)
+	default:
+		if _, err = c.c.Send(nm); err != nil {
+			// The request was not sent, so no replies will follow.
+			sent = false
+			break
+		}
+
+		msgs, err = c.c.Receive()
+	}
+	c.debug(func(d *debugger) { d.replies(msgs, err) })
+	c.audit.request(c.now(), nm, err)
+	if err != nil {
+		if sent && isReceiveTimeout(err) {
+			c.abandon(nm.Header)
+		}
+
+		return nil, lsmError(err)
+	}
+
+	if pr == nil {
+		if err := c.limits.check(msgs); err != nil {
+			return nil, err
+		}
+	}
+
+	return msgs, nil
+}
+
 // packMessage packs a generic netlink Message into a netlink.Message with the
 // appropriate generic netlink family and netlink flags.
 func packMessage(m Message, family uint16, flags netlink.HeaderFlags) (netlink.Message, error) {
@@ -206,7 +952,11 @@ func packMessage(m Message, family uint16, flags netlink.HeaderFlags) (netlink.M
 
 // unpackMessages unpacks generic netlink Messages from a slice of netlink.Messages.
 func unpackMessages(msgs []netlink.Message) ([]Message, error) {
-	gmsgs := make([]Message, 0, len(msgs))
+	return unpackMessagesInto(make([]Message, 0, len(msgs)), msgs)
+}
+
+// unpackMessagesInto unpacks msgs, appending them to gmsgs.
+func unpackMessagesInto(gmsgs []Message, msgs []netlink.Message) ([]Message, error) {
 	for _, nm := range msgs {
 		var gm Message
 		if err := (&gm).UnmarshalBinary(nm.Data); err != nil {
@@ -4,6 +4,7 @@
 package genetlink_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -52,6 +53,65 @@ func TestIntegrationConnListFamilies(t *testing.T) {
 	}
 }
 
+func TestIntegrationProbe(t *testing.T) {
+	r := genetlink.Probe("nlctrl")
+	if !r.OK() {
+		t.Fatalf("generic netlink is not available: %s", r)
+	}
+
+	if !genetlink.Available() {
+		t.Fatal(errNLCtrlMissing)
+	}
+
+	r = genetlink.Probe("genetlinknope")
+	if r.OK() || len(r.Missing) != 1 {
+		t.Fatalf("expected a missing family, but got: %s", r)
+	}
+}
+
+func TestIntegrationConnExecuteAll(t *testing.T) {
+	c, err := genetlink.Dial(nil)
+	if err != nil {
+		t.Fatalf("failed to dial generic netlink: %v", err)
+	}
+	defer c.Close()
+
+	names := []string{"nlctrl", "genetlinknope", "nlctrl"}
+
+	msgs := make([]genetlink.Message, 0, len(names))
+	for _, n := range names {
+		m, _, err := genetlink.NewRequest(genetlink.Family{Version: 1}, unix.CTRL_CMD_GETFAMILY).
+			Attributes(func(ae *netlink.AttributeEncoder) error {
+				ae.String(unix.CTRL_ATTR_FAMILY_NAME, n)
+				return nil
+			}).
+			Build()
+		if err != nil {
+			t.Fatalf("failed to build request: %v", err)
+		}
+
+		msgs = append(msgs, m)
+	}
+
+	results, err := c.ExecuteAll(msgs, unix.GENL_ID_CTRL, netlink.Request)
+	if err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+
+	for i, r := range results {
+		if names[i] == "genetlinknope" {
+			if !errors.Is(r.Err, os.ErrNotExist) {
+				t.Fatalf("result %d: expected not exist, but got: %v", i, r.Err)
+			}
+			continue
+		}
+
+		if r.Err != nil || len(r.Messages) != 1 {
+			t.Fatalf("result %d: unexpected result: %+v", i, r)
+		}
+	}
+}
+
 func TestIntegrationConnConcurrentRaceFree(t *testing.T) {
 	c, err := genetlink.Dial(nil)
 	if err != nil {
@@ -344,3 +404,313 @@ func TestIntegrationConnNL80211(t *testing.T) {
 func panicf(format string, a ...interface{}) {
 	panic(fmt.Sprintf(format, a...))
 }
+
+func TestIntegrationConnAddr(t *testing.T) {
+	// Choose a port ID which is unlikely to be in use.
+	pid := uint32(0x80000000) | uint32(os.Getpid())
+
+	c, err := genetlink.Dial(&netlink.Config{PID: pid})
+	if err != nil {
+		t.Fatalf("failed to dial generic netlink: %v", err)
+	}
+	defer c.Close()
+
+	addr, err := c.Addr()
+	if err != nil {
+		t.Fatalf("failed to get address: %v", err)
+	}
+
+	if diff := cmp.Diff(genetlink.Addr{PID: pid}, addr); diff != "" {
+		t.Fatalf("unexpected address (-want +got):\n%s", diff)
+	}
+
+	// The port ID is now in use and cannot be bound again.
+	if _, err := genetlink.Dial(&netlink.Config{PID: pid}); !errors.Is(err, unix.EADDRINUSE) {
+		t.Fatalf("expected EADDRINUSE, but got: %v", err)
+	}
+}
+
+func TestIntegrationConnMemInfo(t *testing.T) {
+	c, err := genetlink.Dial(nil)
+	if err != nil {
+		t.Fatalf("failed to dial generic netlink: %v", err)
+	}
+	defer c.Close()
+
+	before, err := c.MemInfo()
+	if err != nil {
+		t.Fatalf("failed to get memory info: %v", err)
+	}
+
+	if before.RcvBuf == 0 || before.RMemAlloc != 0 {
+		t.Fatalf("unexpected memory info for idle socket: %+v", before)
+	}
+
+	// The kernel queues the reply to a request before Send returns, so it
+	// occupies the receive queue until it is read.
+	req := genetlink.Message{
+		Header: genetlink.Header{
+			Command: unix.CTRL_CMD_GETFAMILY,
+			Version: 1,
+		},
+	}
+	if _, err := c.Send(req, unix.GENL_ID_CTRL, netlink.Request|netlink.Dump); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+
+	after, err := c.MemInfo()
+	if err != nil {
+		t.Fatalf("failed to get memory info: %v", err)
+	}
+
+	if after.RMemAlloc == 0 {
+		t.Fatalf("expected queued messages to use memory: %+v", after)
+	}
+}
+
+func TestIntegrationConnFeatures(t *testing.T) {
+	c, err := genetlink.Dial(&netlink.Config{Strict: true})
+	if err != nil {
+		t.Fatalf("failed to dial generic netlink: %v", err)
+	}
+	defer c.Close()
+
+	if err := c.SetOption(netlink.NoENOBUFS, true); err != nil {
+		t.Fatalf("failed to set option: %v", err)
+	}
+
+	f, err := c.Features()
+	if err != nil {
+		t.Fatalf("failed to get features: %v", err)
+	}
+
+	want := genetlink.Features{
+		ExtendedAcknowledge: true,
+		StrictCheck:         true,
+		NoENOBUFS:           true,
+	}
+
+	if diff := cmp.Diff(want, f); diff != "" {
+		t.Fatalf("unexpected features (-want +got):\n%s", diff)
+	}
+}
+
+func TestIntegrationDialCompat(t *testing.T) {
+	c, err := genetlink.DialCompat(&netlink.Config{Strict: true})
+	if err != nil {
+		t.Fatalf("failed to dial generic netlink: %v", err)
+	}
+	defer c.Close()
+
+	f, err := c.Features()
+	if err != nil {
+		t.Fatalf("failed to get features: %v", err)
+	}
+
+	if diff := cmp.Diff(genetlink.Features{}, f); diff != "" {
+		t.Fatalf("unexpected features (-want +got):\n%s", diff)
+	}
+
+	if err := c.SetOption(netlink.ExtendedAcknowledge, true); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+
+	// Other options and disabling options are unaffected.
+	if err := c.SetOption(netlink.NoENOBUFS, true); err != nil {
+		t.Fatalf("failed to set option: %v", err)
+	}
+	if err := c.SetOption(netlink.GetStrictCheck, false); err != nil {
+		t.Fatalf("failed to set option: %v", err)
+	}
+
+	if _, err := c.GetFamily("nlctrl"); err != nil {
+		t.Fatalf("failed to get family: %v", err)
+	}
+}
+
+func TestIntegrationDialRestricted(t *testing.T) {
+	c, restrictions, err := genetlink.DialRestricted(&netlink.Config{Strict: true})
+	if err != nil {
+		t.Fatalf("failed to dial generic netlink: %v", err)
+	}
+	defer c.Close()
+
+	// Restrictions are not expected outside of sandboxes, but the Conn must
+	// be usable either way.
+	for _, r := range restrictions {
+		t.Logf("restriction: %v", r)
+	}
+
+	if _, err := c.GetFamily("nlctrl"); err != nil {
+		t.Fatalf("failed to get family: %v", err)
+	}
+}
+
+func TestIntegrationConnPing(t *testing.T) {
+	c, err := genetlink.Dial(nil)
+	if err != nil {
+		t.Fatalf("failed to dial generic netlink: %v", err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := c.Ping(ctx); err != nil {
+		t.Fatalf("failed to ping: %v", err)
+	}
+}
+
+func TestIntegrationConnShutdown(t *testing.T) {
+	c, err := genetlink.Dial(nil)
+	if err != nil {
+		t.Fatalf("failed to dial generic netlink: %v", err)
+	}
+
+	const workers = 4
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	defer wg.Wait()
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			// Requests either succeed or are rejected once Shutdown begins,
+			// but never fail due to the socket being closed under them.
+			for {
+				_, err := c.GetFamily("nlctrl")
+				if errors.Is(err, genetlink.ErrWriteClosed) {
+					return
+				}
+				if err != nil {
+					panicf("failed to get family: %v", err)
+				}
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := c.Shutdown(ctx); err != nil {
+		t.Fatalf("failed to shut down: %v", err)
+	}
+}
+
+func TestIntegrationConnTryReceive(t *testing.T) {
+	c, err := genetlink.Dial(nil)
+	if err != nil {
+		t.Fatalf("failed to dial generic netlink: %v", err)
+	}
+	defer c.Close()
+
+	if _, _, err := c.TryReceive(); !errors.Is(err, genetlink.ErrWouldBlock) {
+		t.Fatalf("expected would block error for idle socket, but got: %v", err)
+	}
+
+	// Drive the Conn from an external epoll instance, as a custom event loop
+	// would.
+	epfd, err := unix.EpollCreate1(unix.EPOLL_CLOEXEC)
+	if err != nil {
+		t.Fatalf("failed to create epoll instance: %v", err)
+	}
+	defer unix.Close(epfd)
+
+	rc, err := c.SyscallConn()
+	if err != nil {
+		t.Fatalf("failed to get syscall conn: %v", err)
+	}
+
+	var eerr error
+	if err := rc.Control(func(fd uintptr) {
+		eerr = unix.EpollCtl(epfd, unix.EPOLL_CTL_ADD, int(fd), &unix.EpollEvent{
+			Events: unix.EPOLLIN,
+			Fd:     int32(fd),
+		})
+	}); err != nil {
+		t.Fatalf("failed to control: %v", err)
+	}
+	if eerr != nil {
+		t.Fatalf("failed to register with epoll: %v", eerr)
+	}
+
+	req := genetlink.Message{
+		Header: genetlink.Header{
+			Command: unix.CTRL_CMD_GETFAMILY,
+			Version: 1,
+		},
+	}
+	if _, err := c.Send(req, unix.GENL_ID_CTRL, netlink.Request|netlink.Dump); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+
+	events := make([]unix.EpollEvent, 1)
+	n, err := unix.EpollWait(epfd, events, 5000)
+	if err != nil {
+		t.Fatalf("failed to wait for epoll: %v", err)
+	}
+	if n != 1 {
+		t.Fatal("expected the socket to be readable")
+	}
+
+	msgs, _, err := c.TryReceive()
+	if err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+	if len(msgs) == 0 {
+		t.Fatal("expected at least one family")
+	}
+
+	if _, _, err := c.TryReceive(); !errors.Is(err, genetlink.ErrWouldBlock) {
+		t.Fatalf("expected would block error after dump, but got: %v", err)
+	}
+}
+
+func TestIntegrationStandby(t *testing.T) {
+	var resyncs int
+	s, err := genetlink.NewStandby(genetlink.StandbyConfig{
+		Groups: []genetlink.StandbyGroup{{Family: "nlctrl", Group: "notify"}},
+		Resync: func(c *genetlink.Conn) error {
+			resyncs++
+			_, err := c.GetFamily("nlctrl")
+			return err
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create standby: %v", err)
+	}
+	defer s.Close()
+
+	// Kill the primary's socket, and verify that requests fail over to the
+	// standby.
+	if err := s.Conn().Close(); err != nil {
+		t.Fatalf("failed to close primary: %v", err)
+	}
+
+	req := genetlink.Message{
+		Header: genetlink.Header{
+			Command: unix.CTRL_CMD_GETFAMILY,
+			Version: 1,
+		},
+	}
+
+	msgs, err := s.Execute(req, unix.GENL_ID_CTRL, netlink.Request|netlink.Dump)
+	if err != nil {
+		t.Fatalf("failed to execute after failover: %v", err)
+	}
+	if len(msgs) == 0 {
+		t.Fatal("expected at least one family")
+	}
+
+	if diff := cmp.Diff(1, s.Failovers()); diff != "" {
+		t.Fatalf("unexpected number of failovers (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(1, resyncs); diff != "" {
+		t.Fatalf("unexpected number of resyncs (-want +got):\n%s", diff)
+	}
+}
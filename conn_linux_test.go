@@ -5,8 +5,13 @@ package genetlink_test
 
 import (
 	"encoding"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/mdlayher/genetlink"
@@ -60,6 +65,80 @@ func TestConnExecute(t *testing.T) {
 	}
 }
 
+func TestConnExecuteFn(t *testing.T) {
+	errEncode := errors.New("encode failed")
+
+	tests := []struct {
+		name string
+		fn   func(ae *netlink.AttributeEncoder) error
+		data []byte
+		err  error
+	}{
+		{
+			name: "no attributes",
+		},
+		{
+			name: "attributes",
+			fn: func(ae *netlink.AttributeEncoder) error {
+				ae.Uint8(1, 0xff)
+				return nil
+			},
+			data: nltest.MustMarshalAttributes([]netlink.Attribute{{
+				Type: 1,
+				Data: []byte{0xff},
+			}}),
+		},
+		{
+			name: "error",
+			fn: func(_ *netlink.AttributeEncoder) error {
+				return errEncode
+			},
+			err: errEncode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			c := genltest.Dial(genltest.CheckRequest(0x20, 3, netlink.Request,
+				func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+					requests++
+
+					want := genetlink.Message{
+						Header: genetlink.Header{Command: 3, Version: 2},
+						Data:   tt.data,
+					}
+					if diff := cmp.Diff(want, greq); diff != "" {
+						t.Fatalf("unexpected request (-want +got):\n%s", diff)
+					}
+
+					return []genetlink.Message{greq}, nil
+				},
+			))
+			defer c.Close()
+
+			msgs, err := c.ExecuteFn(3, 2, 0x20, netlink.Request, tt.fn)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if requests != 0 {
+					t.Fatal("expected no request to be sent")
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to execute: %v", err)
+			}
+
+			if len(msgs) != 1 {
+				t.Fatalf("expected 1 reply, but got %d", len(msgs))
+			}
+		})
+	}
+}
+
 func TestConnSend(t *testing.T) {
 	const (
 		length = 24
@@ -103,6 +182,41 @@ func TestConnSend(t *testing.T) {
 	}
 }
 
+func TestConnSendSequence(t *testing.T) {
+	var got []uint32
+	c := genltest.Dial(func(_ genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		got = append(got, nreq.Header.Sequence)
+		return nil, nil
+	})
+	defer c.Close()
+
+	req := genetlink.Message{Header: genetlink.Header{Command: 1}}
+	for _, seq := range []uint32{100, 100, 0xffffffff} {
+		nlreq, err := c.SendSequence(req, unix.GENL_ID_CTRL, netlink.Request, seq)
+		if err != nil {
+			t.Fatalf("failed to send: %v", err)
+		}
+
+		if diff := cmp.Diff(seq, nlreq.Header.Sequence); diff != "" {
+			t.Fatalf("unexpected returned sequence (-want +got):\n%s", diff)
+		}
+	}
+
+	// A sequence number of 0 is chosen by the Conn.
+	nlreq, err := c.SendSequence(req, unix.GENL_ID_CTRL, netlink.Request, 0)
+	if err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+	if nlreq.Header.Sequence == 0 {
+		t.Fatal("expected a sequence number to be chosen")
+	}
+
+	want := []uint32{100, 100, 0xffffffff, nlreq.Header.Sequence}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected sent sequences (-want +got):\n%s", diff)
+	}
+}
+
 func TestConnReceive(t *testing.T) {
 	gmsgs := []genetlink.Message{
 		{
@@ -137,6 +251,245 @@ func TestConnReceive(t *testing.T) {
 	}
 }
 
+func TestConnReceiveInto(t *testing.T) {
+	want := []genetlink.Message{
+		{Header: genetlink.Header{Command: 1}, Data: []byte{0x01}},
+		{Header: genetlink.Header{Command: 2}, Data: []byte{0x02}},
+	}
+
+	c := genltest.Dial(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return want, nil
+	})
+	defer c.Close()
+
+	storage := make([]genetlink.Message, 0, 4)
+
+	var msgs []genetlink.Message
+	for i := 0; i < 3; i++ {
+		var err error
+		msgs, _, err = c.ReceiveInto(storage)
+		if err != nil {
+			t.Fatalf("failed to receive messages: %v", err)
+		}
+
+		if diff := cmp.Diff(want, msgs); diff != "" {
+			t.Fatalf("unexpected messages (-want +got):\n%s", diff)
+		}
+
+		if &msgs[0] != &storage[:1][0] {
+			t.Fatal("expected messages to be stored in caller-provided storage")
+		}
+	}
+}
+
+func TestConnReceiveRaw(t *testing.T) {
+	// A payload which is too short to contain a generic netlink header.
+	c := genltest.Dial(genltest.Truncate(2, func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return []genetlink.Message{{Header: genetlink.Header{Command: 1, Version: 2}}}, nil
+	}))
+	defer c.Close()
+
+	if _, _, err := c.Receive(); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+
+	msgs, err := c.ReceiveRaw()
+	if err != nil {
+		t.Fatalf("failed to receive messages: %v", err)
+	}
+
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, but got %d", len(msgs))
+	}
+
+	if diff := cmp.Diff([]byte{0x01, 0x02}, msgs[0].Data); diff != "" {
+		t.Fatalf("unexpected data (-want +got):\n%s", diff)
+	}
+}
+
+func TestConnDump(t *testing.T) {
+	family := genetlink.Family{
+		ID:      0x20,
+		Version: 2,
+		Name:    "foo",
+	}
+
+	replies := []genetlink.Message{
+		{
+			Header: genetlink.Header{Command: 2, Version: 2},
+			Data:   []byte{0x01, 0x02, 0x03, 0x04},
+		},
+		{
+			Header: genetlink.Header{Command: 2, Version: 2},
+			Data:   []byte{0x05, 0x06, 0x07, 0x08},
+		},
+		{
+			Header: genetlink.Header{Command: 2, Version: 2},
+			Data:   []byte{0x09, 0x0a, 0x0b, 0x0c},
+		},
+	}
+
+	wantreq := genetlink.Message{
+		Header: genetlink.Header{Command: 1, Version: 2},
+		Data: nltest.MustMarshalAttributes([]netlink.Attribute{{
+			Type: 1,
+			Data: []byte{0xff},
+		}}),
+	}
+
+	tests := []struct {
+		name      string
+		interrupt bool
+		err       error
+	}{
+		{
+			name: "OK",
+		},
+		{
+			name:      "interrupted",
+			interrupt: true,
+			err:       genetlink.ErrDumpInterrupted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := genltest.CheckRequest(family.ID, 1, netlink.Request|netlink.Dump,
+				func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+					if diff := cmp.Diff(wantreq, greq); diff != "" {
+						t.Fatalf("unexpected request (-want +got):\n%s", diff)
+					}
+
+					return replies, nil
+				},
+			)
+			if tt.interrupt {
+				fn = genltest.InterruptDump(1, fn)
+			}
+
+			// Deliver the dump across multiple reads.
+			c := genltest.DialConfig(fn, &genltest.Config{BufferSize: 32})
+			defer c.Close()
+
+			msgs, err := c.Dump(1, family, func(ae *netlink.AttributeEncoder) error {
+				ae.Uint8(1, 0xff)
+				return nil
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(replies, msgs); diff != "" {
+				t.Fatalf("unexpected replies (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConnExecuteAll(t *testing.T) {
+	const family = 0x20
+
+	c := genltest.Dial(genltest.CheckRequest(family, 0, netlink.Request|netlink.Acknowledge,
+		func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			switch greq.Header.Command {
+			case 2:
+				return nil, genltest.Error(int(unix.EINVAL))
+			case 3:
+				// Acknowledgement only.
+				return nil, io.EOF
+			default:
+				return []genetlink.Message{greq}, nil
+			}
+		},
+	))
+	defer c.Close()
+
+	msgs := []genetlink.Message{
+		{Header: genetlink.Header{Command: 1}, Data: []byte{0x01}},
+		{Header: genetlink.Header{Command: 2}},
+		{Header: genetlink.Header{Command: 3}},
+		{Header: genetlink.Header{Command: 4}, Data: []byte{0x04}},
+	}
+
+	results, err := c.ExecuteAll(msgs, family, netlink.Request)
+	if err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+
+	if l := len(results); l != len(msgs) {
+		t.Fatalf("unexpected number of results: %d", l)
+	}
+
+	for i, r := range results {
+		switch i {
+		case 1:
+			if !errors.Is(r.Err, unix.EINVAL) {
+				t.Fatalf("result %d: expected EINVAL, but got: %v", i, r.Err)
+			}
+		case 2:
+			if r.Err != nil || len(r.Messages) != 0 {
+				t.Fatalf("result %d: unexpected result: %+v", i, r)
+			}
+		default:
+			if r.Err != nil {
+				t.Fatalf("result %d: unexpected error: %v", i, r.Err)
+			}
+
+			if diff := cmp.Diff([]genetlink.Message{msgs[i]}, r.Messages); diff != "" {
+				t.Fatalf("result %d: unexpected replies (-want +got):\n%s", i, diff)
+			}
+		}
+	}
+}
+
+func TestConnExecuteAllAbandoned(t *testing.T) {
+	const family = 0x20
+
+	g := genltest.NewGate()
+	fail := g.Block(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, genltest.Error(int(unix.EINVAL))
+	})
+
+	c := genltest.Dial(func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		if greq.Header.Command == 1 {
+			return fail(greq, nreq)
+		}
+
+		return []genetlink.Message{greq}, nil
+	})
+	defer c.Close()
+
+	// A request times out and is abandoned, and its error reply arrives
+	// later.
+	req := genetlink.Message{Header: genetlink.Header{Command: 1}}
+	_, err := c.ExecuteTimeout(10*time.Millisecond, req, family, netlink.Request|netlink.Acknowledge)
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, but got: %v", err)
+	}
+	g.Release()
+
+	// The late error must be drained rather than attributed to the batch.
+	msgs := []genetlink.Message{
+		{Header: genetlink.Header{Command: 2}},
+		{Header: genetlink.Header{Command: 3}},
+	}
+
+	results, err := c.ExecuteAll(msgs, family, netlink.Request)
+	if err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+
+	for i, r := range results {
+		if r.Err != nil {
+			t.Fatalf("result %d: unexpected error: %v", i, r.Err)
+		}
+
+		if diff := cmp.Diff([]genetlink.Message{msgs[i]}, r.Messages); diff != "" {
+			t.Fatalf("result %d: unexpected replies (-want +got):\n%s", i, diff)
+		}
+	}
+}
+
 func mustMarshal(m encoding.BinaryMarshaler) []byte {
 	b, err := m.MarshalBinary()
 	if err != nil {
@@ -154,3 +507,325 @@ func diffNetlinkMessages(want, got netlink.Message) string {
 
 	return cmp.Diff(want, got)
 }
+
+func TestConnPriorityScheduling(t *testing.T) {
+	// Echo the command of each request so that replies can be matched with
+	// requests.
+	c := genltest.Dial(func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return []genetlink.Message{{Header: greq.Header}}, nil
+	})
+	defer c.Close()
+
+	c.SetPriorityScheduling(true)
+
+	family := genetlink.Family{ID: 0x20, Version: 1}
+
+	var wg sync.WaitGroup
+	errC := make(chan error, 30)
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+
+		go func() {
+			defer wg.Done()
+			msgs, err := c.Dump(1, family, nil)
+			errC <- checkCommand(msgs, err, 1)
+		}()
+
+		go func() {
+			defer wg.Done()
+			msgs, err := c.Execute(genetlink.Message{Header: genetlink.Header{Command: 2}}, family.ID, netlink.Request)
+			errC <- checkCommand(msgs, err, 2)
+		}()
+
+		go func() {
+			defer wg.Done()
+			msgs, err := c.ExecutePriority(genetlink.PriorityHigh,
+				genetlink.Message{Header: genetlink.Header{Command: 3}}, family.ID, netlink.Request)
+			errC <- checkCommand(msgs, err, 3)
+		}()
+	}
+
+	wg.Wait()
+	close(errC)
+
+	for err := range errC {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+// checkCommand verifies that msgs is a single reply to command.
+func checkCommand(msgs []genetlink.Message, err error, command uint8) error {
+	if err != nil {
+		return err
+	}
+
+	if len(msgs) != 1 || msgs[0].Header.Command != command {
+		return fmt.Errorf("unexpected replies to command %d: %v", command, msgs)
+	}
+
+	return nil
+}
+
+func TestConnCloseWrite(t *testing.T) {
+	var requests int
+	c := genltest.Dial(func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		if nreq.Header.Type == 0 {
+			// Multicast messages continue to be delivered.
+			return []genetlink.Message{{Header: genetlink.Header{Command: 1}}}, nil
+		}
+
+		requests++
+		return []genetlink.Message{{Header: greq.Header}}, nil
+	})
+	defer c.Close()
+
+	if err := c.CloseWrite(); err != nil {
+		t.Fatalf("failed to close for writing: %v", err)
+	}
+
+	var (
+		req    = genetlink.Message{Header: genetlink.Header{Command: 2}}
+		family = genetlink.Family{ID: 0x20}
+	)
+
+	tests := []struct {
+		name string
+		fn   func() error
+	}{
+		{
+			name: "Send",
+			fn: func() error {
+				_, err := c.Send(req, family.ID, netlink.Request)
+				return err
+			},
+		},
+		{
+			name: "Execute",
+			fn: func() error {
+				_, err := c.Execute(req, family.ID, netlink.Request)
+				return err
+			},
+		},
+		{
+			name: "ExecuteAll",
+			fn: func() error {
+				_, err := c.ExecuteAll([]genetlink.Message{req, req}, family.ID, netlink.Request)
+				return err
+			},
+		},
+		{
+			name: "Dump",
+			fn: func() error {
+				_, err := c.Dump(2, family, nil)
+				return err
+			},
+		},
+		{
+			name: "GetFamily",
+			fn: func() error {
+				_, err := c.GetFamily("nlctrl")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fn(); !errors.Is(err, genetlink.ErrWriteClosed) {
+				t.Fatalf("expected ErrWriteClosed, but got: %v", err)
+			}
+		})
+	}
+
+	if requests != 0 {
+		t.Fatalf("expected no requests, but got %d", requests)
+	}
+
+	msgs, _, err := c.Receive()
+	if err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+
+	want := []genetlink.Message{{Header: genetlink.Header{Command: 1}}}
+	if diff := cmp.Diff(want, msgs); diff != "" {
+		t.Fatalf("unexpected messages (-want +got):\n%s", diff)
+	}
+}
+
+func TestConnSetValidation(t *testing.T) {
+	// Reply with a message which is not correlated with the request, as
+	// happens when a multicast message is received by the same socket.
+	c := genltest.Dial(genltest.Respond(func(_ genetlink.Message, nreq netlink.Message) ([]genltest.Response, error) {
+		return []genltest.Response{{
+			Header: netlink.Header{
+				Type:     nreq.Header.Type,
+				Sequence: nreq.Header.Sequence + 1,
+				PID:      nreq.Header.PID,
+			},
+			Message: genetlink.Message{Header: genetlink.Header{Command: 2}},
+		}}, nil
+	}))
+	defer c.Close()
+
+	req := genetlink.Message{Header: genetlink.Header{Command: 1}}
+
+	if _, err := c.Execute(req, unix.GENL_ID_CTRL, netlink.Request); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+
+	c.SetValidation(false)
+
+	msgs, err := c.Execute(req, unix.GENL_ID_CTRL, netlink.Request)
+	if err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+
+	want := []genetlink.Message{{Header: genetlink.Header{Command: 2}}}
+	if diff := cmp.Diff(want, msgs); diff != "" {
+		t.Fatalf("unexpected replies (-want +got):\n%s", diff)
+	}
+}
+
+func TestConnResponseLimits(t *testing.T) {
+	// Each reply is a 16 byte netlink header, a 4 byte generic netlink
+	// header, and 4 bytes of data.
+	c := genltest.Dial(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		msgs := make([]genetlink.Message, 4)
+		for i := range msgs {
+			msgs[i] = genetlink.Message{
+				Header: genetlink.Header{Command: 1},
+				Data:   []byte{0xff, 0xff, 0xff, 0xff},
+			}
+		}
+
+		return msgs, nil
+	})
+	defer c.Close()
+
+	family := genetlink.Family{ID: 0x20, Version: 1}
+
+	tests := []struct {
+		name   string
+		limits genetlink.ResponseLimits
+		err    error
+	}{
+		{
+			name: "no limits",
+		},
+		{
+			name:   "within limits",
+			limits: genetlink.ResponseLimits{Messages: 4, Bytes: 96},
+		},
+		{
+			name:   "messages",
+			limits: genetlink.ResponseLimits{Messages: 3},
+			err: &genetlink.ResponseLimitError{
+				Messages: 4,
+				Bytes:    96,
+				Limits:   genetlink.ResponseLimits{Messages: 3},
+			},
+		},
+		{
+			name:   "bytes",
+			limits: genetlink.ResponseLimits{Bytes: 95},
+			err: &genetlink.ResponseLimitError{
+				Messages: 4,
+				Bytes:    96,
+				Limits:   genetlink.ResponseLimits{Bytes: 95},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.SetResponseLimits(tt.limits)
+
+			msgs, err := c.Dump(1, family, nil)
+			if diff := cmp.Diff(tt.err, err); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(4, len(msgs)); diff != "" {
+				t.Fatalf("unexpected number of messages (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConnResponseLimitsStream(t *testing.T) {
+	const n = 100
+
+	// The dump is far larger than the limits, and the simulated receive
+	// buffer holds a single 24 byte reply, so the replies arrive over many
+	// reads as they would from the kernel.
+	c := genltest.DialConfig(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		msgs := make([]genetlink.Message, n)
+		for i := range msgs {
+			msgs[i] = genetlink.Message{
+				Header: genetlink.Header{Command: 1},
+				Data:   []byte{0xff, 0xff, 0xff, 0xff},
+			}
+		}
+
+		return msgs, nil
+	}, &genltest.Config{BufferSize: 24})
+	defer c.Close()
+
+	family := genetlink.Family{ID: 0x20, Version: 1}
+
+	tests := []struct {
+		name   string
+		limits genetlink.ResponseLimits
+		err    error
+	}{
+		{
+			name:   "messages",
+			limits: genetlink.ResponseLimits{Messages: 3},
+			err: &genetlink.ResponseLimitError{
+				Messages: 4,
+				Bytes:    96,
+				Limits:   genetlink.ResponseLimits{Messages: 3},
+			},
+		},
+		{
+			name:   "bytes",
+			limits: genetlink.ResponseLimits{Bytes: 95},
+			err: &genetlink.ResponseLimitError{
+				Messages: 4,
+				Bytes:    96,
+				Limits:   genetlink.ResponseLimits{Bytes: 95},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.SetResponseLimits(tt.limits)
+
+			// The limit error reports only the replies received before the
+			// limit was exceeded, rather than the whole dump.
+			_, err := c.Dump(1, family, nil)
+			if diff := cmp.Diff(tt.err, err); diff != "" {
+				t.Fatalf("unexpected error (-want +got):\n%s", diff)
+			}
+
+			// The rest of the dump was discarded, so the next dump receives
+			// only its own replies.
+			c.SetResponseLimits(genetlink.ResponseLimits{})
+
+			msgs, err := c.Dump(1, family, nil)
+			if err != nil {
+				t.Fatalf("failed to dump: %v", err)
+			}
+
+			if diff := cmp.Diff(n, len(msgs)); diff != "" {
+				t.Fatalf("unexpected number of messages (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package genetlink
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// An ioLock coordinates the operations which use a Conn's socket. Most
+// operations share the socket, but a request/reply exchange which applies a
+// deadline of its own holds the socket exclusively, so that its deadline can
+// never interrupt an unrelated operation in another goroutine. Waiting
+// exclusive operations take precedence over new shared operations.
+//
+// The zero value is ready to use.
+type ioLock struct {
+	mu      sync.Mutex
+	shared  int
+	held    bool
+	waiting int
+
+	// wake is closed and replaced whenever the lock is released.
+	wake chan struct{}
+}
+
+// rlock acquires l for shared use, returning a function which releases it.
+func (l *ioLock) rlock() func() {
+	l.mu.Lock()
+	for l.held || l.waiting > 0 {
+		wake := l.wakeC()
+		l.mu.Unlock()
+		<-wake
+		l.mu.Lock()
+	}
+
+	l.shared++
+	l.mu.Unlock()
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.shared--
+		l.broadcast()
+	}
+}
+
+// lock acquires l for exclusive use, returning a function which releases it,
+// or ctx.Err() if ctx is done first.
+func (l *ioLock) lock(ctx context.Context) (func(), error) {
+	l.mu.Lock()
+	l.waiting++
+	for l.held || l.shared > 0 {
+		wake := l.wakeC()
+		l.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			l.waiting--
+			l.broadcast()
+			return nil, ctx.Err()
+		}
+
+		l.mu.Lock()
+	}
+
+	l.waiting--
+	l.held = true
+	l.mu.Unlock()
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.held = false
+		l.broadcast()
+	}, nil
+}
+
+// wakeC returns the channel which is closed when l next changes. l.mu must
+// be held.
+func (l *ioLock) wakeC() chan struct{} {
+	if l.wake == nil {
+		l.wake = make(chan struct{})
+	}
+
+	return l.wake
+}
+
+// broadcast wakes all waiters. l.mu must be held.
+func (l *ioLock) broadcast() {
+	if l.wake != nil {
+		close(l.wake)
+		l.wake = nil
+	}
+}
+
+// deadlines tracks the socket deadlines set by the caller, so that they can
+// be restored once an operation which applies its own deadline completes.
+type deadlines struct {
+	mu          sync.Mutex
+	read, write time.Time
+
+	// scoped is set while an operation's own deadline is applied, during
+	// which deadlines set by the caller are recorded but not applied.
+	scoped bool
+}
+
+// setDeadlines records the read and/or write deadlines set by the caller
+// and applies them to the socket unless an operation's own deadline is in
+// effect.
+func (c *Conn) setDeadlines(op string, read, write bool, t time.Time) error {
+	ds, ok := c.c.(deadlineSetter)
+	if !ok {
+		return notSupported(op)
+	}
+
+	d := &c.dl
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if read {
+		d.read = t
+	}
+	if write {
+		d.write = t
+	}
+
+	if d.scoped {
+		// Applied when the operation's own deadline is removed.
+		return nil
+	}
+
+	switch {
+	case read && write:
+		return ds.SetDeadline(t)
+	case read:
+		return ds.SetReadDeadline(t)
+	default:
+		return ds.SetWriteDeadline(t)
+	}
+}
+
+// exclusive invokes fn with exclusive use of the socket, applying the
+// deadline of ctx to the socket and interrupting fn when ctx is canceled.
+// The deadlines set by the caller are restored before exclusive returns.
+//
+// The error returned by fn is returned as-is; see contextError.
+func (c *Conn) exclusive(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	release, err := c.io.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	ds, ok := c.c.(deadlineSetter)
+	if !ok {
+		if _, ok := ctx.Deadline(); ok {
+			return notSupported("set-deadline")
+		}
+
+		// Cancelation cannot interrupt fn, but is still reported.
+		return fn()
+	}
+
+	d := &c.dl
+	d.mu.Lock()
+	d.scoped = true
+	err = applyDeadlines(ctx, ds, d.read, d.write)
+	d.mu.Unlock()
+
+	defer func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		d.scoped = false
+		_ = ds.SetReadDeadline(d.read)
+		_ = ds.SetWriteDeadline(d.write)
+	}()
+
+	if err != nil {
+		return err
+	}
+
+	if ctx.Done() == nil {
+		// The context can never be canceled, so there is nothing to watch.
+		return fn()
+	}
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+
+		select {
+		case <-ctx.Done():
+			// Set a deadline in the past to unblock receive operations.
+			d.mu.Lock()
+			_ = ds.SetReadDeadline(time.Unix(1, 0))
+			d.mu.Unlock()
+		case <-stop:
+		}
+	}()
+
+	err = fn()
+	close(stop)
+	<-done
+
+	return err
+}
+
+// applyDeadlines applies the deadline of ctx to ds, unless the deadlines
+// set by the caller are earlier.
+func applyDeadlines(ctx context.Context, ds deadlineSetter, read, write time.Time) error {
+	d, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+
+	earlier := func(t time.Time) time.Time {
+		if t.IsZero() || d.Before(t) {
+			return d
+		}
+
+		return t
+	}
+
+	if err := ds.SetReadDeadline(earlier(read)); err != nil {
+		return err
+	}
+
+	return ds.SetWriteDeadline(earlier(write))
+}
+
+// contextError returns the error to report for an operation governed by ctx
+// which failed with err, preferring the context's error when the failure
+// was caused by the context.
+func contextError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// The socket deadline may expire just before the context reports it.
+	if d, ok := ctx.Deadline(); ok && !time.Now().Before(d) {
+		return context.DeadlineExceeded
+	}
+
+	return err
+}
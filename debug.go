@@ -0,0 +1,158 @@
+package genetlink
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mdlayher/netlink"
+)
+
+// DebugEnv is the environment variable which enables debugging output for
+// generic netlink operations, in the spirit of the netlink package's NLDEBUG
+// variable. Its value is a comma-separated list of key=value arguments:
+//
+//   - level: the verbosity of the output. At level 1, the default, each
+//     operation's family, command, version, and flags are logged, along with
+//     each family resolved by GetFamily or ListFamilies. At level 2, the
+//     headers of each reply are also logged.
+//
+// For example, GENLDEBUG=level=2 enables verbose output. Output is written to
+// standard error, and applies to Conns created after the program starts.
+const DebugEnv = "GENLDEBUG"
+
+// Arguments used to create a debugger.
+var debugArgs []string
+
+func init() {
+	// Is generic netlink debugging enabled?
+	s := os.Getenv(DebugEnv)
+	if s == "" {
+		return
+	}
+
+	debugArgs = strings.Split(s, ",")
+}
+
+// A debugger logs debugging information about a Conn's operations.
+type debugger struct {
+	Log   *log.Logger
+	Level int
+
+	mu    sync.Mutex
+	names map[uint16]string
+}
+
+// newDebugger creates a debugger by parsing key=value arguments, or returns
+// nil if debugging is disabled.
+func newDebugger(args []string) *debugger {
+	if args == nil {
+		return nil
+	}
+
+	d := &debugger{
+		Log:   log.New(os.Stderr, "genl: ", 0),
+		Level: 1,
+		names: map[uint16]string{ctrlID: "nlctrl"},
+	}
+
+	for _, a := range args {
+		kv := strings.Split(a, "=")
+		if len(kv) != 2 {
+			// Ignore malformed pairs and assume callers want defaults.
+			continue
+		}
+
+		switch kv[0] {
+		// Select the log level for the debugger.
+		case "level":
+			level, err := strconv.Atoi(kv[1])
+			if err != nil {
+				panic(fmt.Sprintf("genetlink: invalid %s level: %q", DebugEnv, a))
+			}
+
+			d.Level = level
+		}
+	}
+
+	return d
+}
+
+// debug executes fn with the debugger if debugging is enabled.
+func (c *Conn) debug(fn func(d *debugger)) {
+	if c.d != nil {
+		fn(c.d)
+	}
+}
+
+// debugf prints debugging information at the specified level, if d.Level is
+// high enough to print the message.
+func (d *debugger) debugf(level int, format string, v ...interface{}) {
+	if d.Level >= level {
+		d.Log.Printf(format, v...)
+	}
+}
+
+// family records a resolved family so that its name can be logged with
+// later operations.
+func (d *debugger) family(f Family) {
+	d.mu.Lock()
+	d.names[f.ID] = f.Name
+	d.mu.Unlock()
+
+	d.debugf(1, "family %q: id %#x, version %d, %d ops, %d groups",
+		f.Name, f.ID, f.Version, len(f.Ops), len(f.Groups))
+}
+
+// request logs an operation on the request nm.
+func (d *debugger) request(op string, nm netlink.Message) {
+	id := uint16(nm.Header.Type)
+
+	d.mu.Lock()
+	name, ok := d.names[id]
+	d.mu.Unlock()
+	if !ok {
+		name = "unknown"
+	}
+
+	var h Header
+	if len(nm.Data) >= headerLen {
+		h = Header{Command: nm.Data[0], Version: nm.Data[1]}
+	}
+
+	d.debugf(1, "%s: family %q (%#x), command %d, version %d, flags %s, seq %d",
+		op, name, id, h.Command, h.Version, debugFlags(nm.Header.Flags), nm.Header.Sequence)
+}
+
+// replies logs the replies to a request, or the error which occurred.
+func (d *debugger) replies(msgs []netlink.Message, err error) {
+	if err != nil {
+		d.debugf(1, "replies: err: %v", err)
+		return
+	}
+
+	d.debugf(1, "replies: %d messages", len(msgs))
+	for _, m := range msgs {
+		d.debugf(2, "reply: type %#x, flags %s, seq %d, pid %d, %d bytes",
+			uint16(m.Header.Type), debugFlags(m.Header.Flags),
+			m.Header.Sequence, m.Header.PID, len(m.Data))
+	}
+}
+
+// debugFlags formats netlink header flags, naming the dump flags which
+// netlink.HeaderFlags.String reports as separate bits.
+func debugFlags(f netlink.HeaderFlags) string {
+	if f&netlink.Request == 0 || f&netlink.Dump != netlink.Dump {
+		return f.String()
+	}
+
+	s := "dump"
+	if rest := f &^ netlink.Dump; rest != 0 {
+		s = rest.String() + "|" + s
+	}
+
+	return s
+}
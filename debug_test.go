@@ -0,0 +1,95 @@
+package genetlink
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/netlink"
+)
+
+func TestDebugger(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name: "level 1",
+			args: []string{"level=1"},
+			want: `genl: execute: family "unknown" (0x20), command 1, version 2, flags request|dump, seq 1
+genl: replies: 1 messages
+`,
+		},
+		{
+			name: "level 2",
+			args: []string{"level=2", "foo"},
+			want: `genl: execute: family "unknown" (0x20), command 1, version 2, flags request|dump, seq 1
+genl: replies: 1 messages
+genl: reply: type 0x20, flags multi, seq 1, pid 0, 4 bytes
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			c := NewTransportConn(echoTransport{})
+			c.d = newDebugger(tt.args)
+			if c.d != nil {
+				c.d.Log = log.New(&buf, "genl: ", 0)
+			}
+
+			m := Message{Header: Header{Command: 1, Version: 2}}
+			if _, err := c.Execute(m, 0x20, netlink.Request|netlink.Dump); err != nil {
+				t.Fatalf("failed to execute: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+				t.Fatalf("unexpected debug output (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDebugFlags(t *testing.T) {
+	tests := []struct {
+		flags netlink.HeaderFlags
+		s     string
+	}{
+		{flags: netlink.Request, s: "request"},
+		{flags: netlink.Request | netlink.Dump, s: "request|dump"},
+		{flags: netlink.Request | netlink.Acknowledge | netlink.Dump, s: "request|acknowledge|dump"},
+		{flags: netlink.Multi, s: "multi"},
+	}
+
+	for _, tt := range tests {
+		if diff := cmp.Diff(tt.s, debugFlags(tt.flags)); diff != "" {
+			t.Fatalf("unexpected flags string (-want +got):\n%s", diff)
+		}
+	}
+}
+
+// echoTransport is a Transport which replies to each request with a copy of
+// the request.
+type echoTransport struct{}
+
+func (echoTransport) Close() error { return nil }
+
+func (echoTransport) Send(m netlink.Message) (netlink.Message, error) { return m, nil }
+
+func (echoTransport) SendMessages(msgs []netlink.Message) ([]netlink.Message, error) {
+	return msgs, nil
+}
+
+func (echoTransport) Receive() ([]netlink.Message, error) { return nil, nil }
+
+func (echoTransport) Execute(m netlink.Message) ([]netlink.Message, error) {
+	m.Header.Flags = netlink.Multi
+	return []netlink.Message{m}, nil
+}
@@ -0,0 +1,155 @@
+package genetlink
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/mdlayher/genetlink/internal/endian"
+)
+
+// Default values for the fields of DecodeLimits.
+const (
+	DefaultMaxDepth      = 32
+	DefaultMaxAttributes = 16384
+)
+
+// nlaFNested is the netlink attribute flag for nested attributes.
+const nlaFNested = 0x8000 // unix.NLA_F_NESTED
+
+// ErrDecodeLimit is wrapped by errors returned when attributes exceed the
+// limits of a DecodeLimits.
+var ErrDecodeLimit = errors.New("genetlink: attribute decoding limit exceeded")
+
+// An AttributeError reports an attribute which could not be decoded, and
+// locates it within its Message.
+type AttributeError struct {
+	// Path identifies the attribute. Attributes are identified by their
+	// name if known, or otherwise by their type, with nested attributes
+	// separated by slashes, such as "2/1". The elements of a nested array
+	// are identified by their type in brackets, such as "STATS[2]/BYTES". An
+	// empty Path refers to the Message's data as a whole.
+	Path string
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements error.
+func (e *AttributeError) Error() string {
+	if e.Path == "" {
+		return "genetlink: attributes: " + e.Err.Error()
+	}
+
+	return "genetlink: attribute " + e.Path + ": " + e.Err.Error()
+}
+
+// Unwrap implements errors unwrapping.
+func (e *AttributeError) Unwrap() error { return e.Err }
+
+// attributePath joins the elements of an attribute path.
+func attributePath(prefix, elem string) string {
+	if prefix == "" {
+		return elem
+	}
+
+	return prefix + "/" + elem
+}
+
+// DecodeLimits are limits on the structure of netlink attributes decoded by
+// the helpers in this package, so that malformed or hostile messages cannot
+// trigger excessive recursion or memory use.
+//
+// A zero value for a field means that its default is used.
+type DecodeLimits struct {
+	// MaxDepth is the maximum nesting depth of attributes. Top-level
+	// attributes have a depth of 1. If 0, DefaultMaxDepth is used.
+	MaxDepth int
+
+	// MaxAttributes is the maximum total number of attributes, including
+	// nested attributes. If 0, DefaultMaxAttributes is used.
+	MaxAttributes int
+}
+
+// Check verifies that the packed attributes in b do not exceed the limits.
+// Attributes with the netlink.Nested flag set are checked recursively.
+// Check can be used to vet untrusted messages before decoding them.
+//
+// If a limit is exceeded, the returned error wraps ErrDecodeLimit. If the
+// attributes are malformed, an *AttributeError which locates them and does
+// not wrap ErrDecodeLimit is returned.
+func (l DecodeLimits) Check(b []byte) error {
+	d := l.decoder()
+	return d.check(b, 1, "")
+}
+
+// decoder returns a limitDecoder which enforces l.
+func (l DecodeLimits) decoder() *limitDecoder {
+	d := &limitDecoder{
+		maxDepth: l.MaxDepth,
+		left:     l.MaxAttributes,
+	}
+
+	if d.maxDepth == 0 {
+		d.maxDepth = DefaultMaxDepth
+	}
+	if d.left == 0 {
+		d.left = DefaultMaxAttributes
+	}
+
+	return d
+}
+
+// A limitDecoder tracks the attributes decoded under a DecodeLimits.
+type limitDecoder struct {
+	maxDepth int
+	left     int
+}
+
+// enter accounts for n attributes at depth.
+func (d *limitDecoder) enter(depth, n int) error {
+	if depth > d.maxDepth {
+		return fmt.Errorf("%w: nesting depth exceeds %d", ErrDecodeLimit, d.maxDepth)
+	}
+
+	d.left -= n
+	if d.left < 0 {
+		return fmt.Errorf("%w: too many attributes", ErrDecodeLimit)
+	}
+
+	return nil
+}
+
+// check checks the packed attributes in b at depth, which are nested within
+// the attribute at path.
+func (d *limitDecoder) check(b []byte, depth int, path string) error {
+	for len(b) > 0 {
+		if len(b) < nlaHeaderLen {
+			return &AttributeError{Path: path, Err: errInvalidAttribute}
+		}
+
+		l := int(endian.Uint16(b[0:2]))
+		if l < nlaHeaderLen || l > len(b) {
+			return &AttributeError{Path: path, Err: errInvalidAttribute}
+		}
+
+		if err := d.enter(depth, 1); err != nil {
+			return err
+		}
+
+		if typ := endian.Uint16(b[2:4]); typ&nlaFNested != 0 {
+			elem := strconv.Itoa(int(typ & nlaTypeMask))
+			if err := d.check(b[nlaHeaderLen:l], depth+1, attributePath(path, elem)); err != nil {
+				return err
+			}
+		}
+
+		if n := nlaAlign(l); n < len(b) {
+			b = b[n:]
+		} else {
+			b = nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package genetlink
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/netlink"
+)
+
+func TestDecodeLimitsCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits DecodeLimits
+		b      []byte
+		ok     bool
+		limit  bool
+	}{
+		{
+			name: "empty",
+			ok:   true,
+		},
+		{
+			name: "default limits",
+			b:    nestAttributes(DefaultMaxDepth),
+			ok:   true,
+		},
+		{
+			name:  "default depth exceeded",
+			b:     nestAttributes(DefaultMaxDepth + 1),
+			limit: true,
+		},
+		{
+			name:   "depth",
+			limits: DecodeLimits{MaxDepth: 3},
+			b:      nestAttributes(3),
+			ok:     true,
+		},
+		{
+			name:   "depth exceeded",
+			limits: DecodeLimits{MaxDepth: 3},
+			b:      nestAttributes(4),
+			limit:  true,
+		},
+		{
+			name:   "attributes exceeded",
+			limits: DecodeLimits{MaxAttributes: 3},
+			b:      nestAttributes(4),
+			limit:  true,
+		},
+		{
+			name:   "flat attributes exceeded",
+			limits: DecodeLimits{MaxAttributes: 1},
+			b: mustMarshalAttributes([]netlink.Attribute{
+				{Type: 1, Data: []byte{0x01}},
+				{Type: 2, Data: []byte{0x02}},
+			}),
+			limit: true,
+		},
+		{
+			name: "malformed",
+			b:    []byte{0xff, 0xff, 0x01, 0x00},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.Check(tt.b)
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected an error, but none occurred")
+			}
+
+			if diff := cmp.Diff(tt.limit, errors.Is(err, ErrDecodeLimit)); diff != "" {
+				t.Fatalf("unexpected limit error (-want +got):\n%s\nerror: %v", diff, err)
+			}
+		})
+	}
+}
+
+func TestDecodeLimitsCheckPath(t *testing.T) {
+	b := mustMarshalAttributes([]netlink.Attribute{
+		{Type: 1, Data: []byte{0x01}},
+		{
+			Type: 2 | netlink.Nested,
+			Data: mustMarshalAttributes([]netlink.Attribute{{
+				Type: 3 | netlink.Nested,
+				// Truncated attribute header.
+				Data: []byte{0xff, 0xff, 0x01, 0x00},
+			}}),
+		},
+	})
+
+	err := DecodeLimits{}.Check(b)
+
+	var aerr *AttributeError
+	if !errors.As(err, &aerr) {
+		t.Fatalf("expected an attribute error, but got: %v", err)
+	}
+
+	if diff := cmp.Diff("2/3", aerr.Path); diff != "" {
+		t.Fatalf("unexpected attribute path (-want +got):\n%s", diff)
+	}
+}
+
+func TestDiffLimits(t *testing.T) {
+	a := Message{Data: nestAttributes(4)}
+	b := Message{Data: nestAttributes(5)}
+
+	// Within the limits, the innermost attributes are compared, and only
+	// the deeper one is nested.
+	want := []Difference{
+		{
+			Kind: DifferenceChanged,
+			Path: "1/1/1/1.flags",
+			A:    []byte{0x00, 0x00},
+			B:    []byte{0x80, 0x00},
+		},
+		{
+			Kind: DifferenceChanged,
+			Path: "1/1/1/1",
+			A:    []byte{},
+			B:    mustMarshalAttributes([]netlink.Attribute{{Type: 1}}),
+		},
+	}
+	if diff := cmp.Diff(want, DiffLimits(a, b, DecodeLimits{})); diff != "" {
+		t.Fatalf("unexpected differences (-want +got):\n%s", diff)
+	}
+
+	// Beyond the depth limit, nested attributes are compared by value.
+	want = []Difference{{
+		Kind: DifferenceChanged,
+		Path: "1/1",
+		A:    a.Data[8:],
+		B:    b.Data[8:],
+	}}
+	got := DiffLimits(a, b, DecodeLimits{MaxDepth: 2})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected differences (-want +got):\n%s", diff)
+	}
+}
+
+// nestAttributes returns attributes nested to depth, with an empty attribute
+// at the innermost level.
+func nestAttributes(depth int) []byte {
+	b := mustMarshalAttributes([]netlink.Attribute{{Type: 1}})
+	for i := 1; i < depth; i++ {
+		b = mustMarshalAttributes([]netlink.Attribute{{
+			Type: netlink.Nested | 1,
+			Data: b,
+		}})
+	}
+
+	return b
+}
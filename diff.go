@@ -0,0 +1,240 @@
+package genetlink
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+)
+
+// A DifferenceKind indicates how an element differs between two Messages.
+type DifferenceKind int
+
+// Possible DifferenceKind values.
+const (
+	DifferenceChanged DifferenceKind = iota
+	DifferenceAdded
+	DifferenceRemoved
+)
+
+// String returns the string representation of a DifferenceKind.
+func (k DifferenceKind) String() string {
+	switch k {
+	case DifferenceChanged:
+		return "changed"
+	case DifferenceAdded:
+		return "added"
+	case DifferenceRemoved:
+		return "removed"
+	default:
+		return fmt.Sprintf("DifferenceKind(%d)", int(k))
+	}
+}
+
+// A Difference is a single difference between two Messages reported by Diff.
+type Difference struct {
+	// Kind indicates whether the element was changed, added, or removed.
+	Kind DifferenceKind
+
+	// Path identifies the element which differs. Header fields are identified
+	// as "command" and "version". Attributes are identified by their type,
+	// with nested attribute types separated by slashes, such as "2/1". When
+	// a type occurs more than once at the same level, its zero-based
+	// occurrence index is appended in brackets, such as "3[1]". If Data could
+	// not be parsed as attributes, the path "data" is used. A difference in
+	// the netlink.Nested or netlink.NetByteOrder flags of an attribute is
+	// identified by the attribute's path followed by ".flags", such as
+	// "2.flags".
+	Path string
+
+	// A and B contain the raw values of the element in each Message. A is nil
+	// for added elements and B is nil for removed elements. The flags of an
+	// attribute are stored as a big-endian uint16.
+	A, B []byte
+}
+
+// String returns a human-readable representation of a Difference.
+func (d Difference) String() string {
+	switch d.Kind {
+	case DifferenceAdded:
+		return fmt.Sprintf("added %s: [%# x]", d.Path, d.B)
+	case DifferenceRemoved:
+		return fmt.Sprintf("removed %s: [%# x]", d.Path, d.A)
+	default:
+		return fmt.Sprintf("changed %s: [%# x] -> [%# x]", d.Path, d.A, d.B)
+	}
+}
+
+// Diff reports the differences between Messages a and b. Data is decoded as
+// netlink attributes and compared attribute by attribute, so the result is
+// not affected by padding or attribute ordering. Attributes with the
+// netlink.Nested flag set in both Messages are compared recursively; all other
+// attributes are compared by value.
+//
+// If the Data of either Message cannot be decoded as attributes, the Data
+// fields are compared as opaque bytes.
+//
+// Attributes are decoded using the default DecodeLimits. Nested attributes
+// which exceed the limits are compared by value.
+//
+// Diff returns nil if no differences are found.
+func Diff(a, b Message) []Difference {
+	return DiffLimits(a, b, DecodeLimits{})
+}
+
+// DiffLimits is like Diff, but decodes attributes using the specified
+// DecodeLimits.
+func DiffLimits(a, b Message, l DecodeLimits) []Difference {
+	var diffs []Difference
+	if a.Header.Command != b.Header.Command {
+		diffs = append(diffs, Difference{
+			Kind: DifferenceChanged,
+			Path: "command",
+			A:    []byte{a.Header.Command},
+			B:    []byte{b.Header.Command},
+		})
+	}
+
+	if a.Header.Version != b.Header.Version {
+		diffs = append(diffs, Difference{
+			Kind: DifferenceChanged,
+			Path: "version",
+			A:    []byte{a.Header.Version},
+			B:    []byte{b.Header.Version},
+		})
+	}
+
+	ad, err := diffAttributes(l.decoder(), 1, "", a.Data, b.Data)
+	if err != nil {
+		if !bytes.Equal(a.Data, b.Data) {
+			diffs = append(diffs, Difference{
+				Kind: DifferenceChanged,
+				Path: "data",
+				A:    a.Data,
+				B:    b.Data,
+			})
+		}
+
+		return diffs
+	}
+
+	return append(diffs, ad...)
+}
+
+// attrFlags are the flag bits of an attribute's type.
+const attrFlags = netlink.Nested | netlink.NetByteOrder
+
+// An attrKey identifies an attribute by its type and occurrence index.
+type attrKey struct {
+	Type uint16
+	N    int
+}
+
+// diffAttributes computes the differences between the attributes packed in
+// a and b at depth, prefixing each path with prefix.
+func diffAttributes(d *limitDecoder, depth int, prefix string, a, b []byte) ([]Difference, error) {
+	aattrs, err := netlink.UnmarshalAttributes(a)
+	if err != nil {
+		return nil, err
+	}
+
+	battrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.enter(depth, len(aattrs)+len(battrs)); err != nil {
+		return nil, err
+	}
+
+	akeys, amap, acount := indexAttributes(aattrs)
+	bkeys, bmap, bcount := indexAttributes(battrs)
+
+	path := func(k attrKey) string {
+		p := fmt.Sprintf("%s%d", prefix, k.Type)
+		if acount[k.Type] > 1 || bcount[k.Type] > 1 {
+			p += fmt.Sprintf("[%d]", k.N)
+		}
+
+		return p
+	}
+
+	var diffs []Difference
+	for _, k := range akeys {
+		aa := amap[k]
+
+		ba, ok := bmap[k]
+		if !ok {
+			diffs = append(diffs, Difference{
+				Kind: DifferenceRemoved,
+				Path: path(k),
+				A:    aa.Data,
+			})
+			continue
+		}
+
+		if af, bf := aa.Type&attrFlags, ba.Type&attrFlags; af != bf {
+			diffs = append(diffs, Difference{
+				Kind: DifferenceChanged,
+				Path: path(k) + ".flags",
+				A:    []byte{byte(af >> 8), byte(af)},
+				B:    []byte{byte(bf >> 8), byte(bf)},
+			})
+		}
+
+		if aa.Type&ba.Type&netlink.Nested != 0 {
+			nd, err := diffAttributes(d, depth+1, path(k)+"/", aa.Data, ba.Data)
+			if err == nil {
+				diffs = append(diffs, nd...)
+				continue
+			}
+
+			// Fall back to comparing the attributes by value.
+		}
+
+		if !bytes.Equal(aa.Data, ba.Data) {
+			diffs = append(diffs, Difference{
+				Kind: DifferenceChanged,
+				Path: path(k),
+				A:    aa.Data,
+				B:    ba.Data,
+			})
+		}
+	}
+
+	for _, k := range bkeys {
+		if _, ok := amap[k]; ok {
+			continue
+		}
+
+		diffs = append(diffs, Difference{
+			Kind: DifferenceAdded,
+			Path: path(k),
+			B:    bmap[k].Data,
+		})
+	}
+
+	return diffs, nil
+}
+
+// indexAttributes indexes attrs by their type and occurrence index, returning
+// the keys in their original order and the number of occurrences of each type.
+func indexAttributes(attrs []netlink.Attribute) ([]attrKey, map[attrKey]netlink.Attribute, map[uint16]int) {
+	var (
+		keys   = make([]attrKey, 0, len(attrs))
+		m      = make(map[attrKey]netlink.Attribute, len(attrs))
+		counts = make(map[uint16]int)
+	)
+
+	for _, a := range attrs {
+		typ := a.Type &^ attrFlags
+
+		k := attrKey{Type: typ, N: counts[typ]}
+		counts[typ]++
+
+		keys = append(keys, k)
+		m[k] = a
+	}
+
+	return keys, m, counts
+}
@@ -0,0 +1,176 @@
+package genetlink
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/netlink"
+)
+
+func TestDiff(t *testing.T) {
+	nested := func(attrs ...netlink.Attribute) []byte {
+		return mustMarshalAttributes(attrs)
+	}
+
+	tests := []struct {
+		name  string
+		a, b  Message
+		diffs []Difference
+	}{
+		{
+			name: "equal",
+			a: Message{
+				Header: Header{Command: 1, Version: 1},
+				Data:   nested(netlink.Attribute{Type: 1, Data: []byte{0x01}}),
+			},
+			b: Message{
+				Header: Header{Command: 1, Version: 1},
+				Data:   nested(netlink.Attribute{Type: 1, Data: []byte{0x01}}),
+			},
+		},
+		{
+			name: "reordered",
+			a: Message{
+				Data: nested(
+					netlink.Attribute{Type: 1, Data: []byte{0x01}},
+					netlink.Attribute{Type: 2, Data: []byte{0x02}},
+				),
+			},
+			b: Message{
+				Data: nested(
+					netlink.Attribute{Type: 2, Data: []byte{0x02}},
+					netlink.Attribute{Type: 1, Data: []byte{0x01}},
+				),
+			},
+		},
+		{
+			name: "header",
+			a:    Message{Header: Header{Command: 1, Version: 1}},
+			b:    Message{Header: Header{Command: 2, Version: 3}},
+			diffs: []Difference{
+				{
+					Kind: DifferenceChanged,
+					Path: "command",
+					A:    []byte{1},
+					B:    []byte{2},
+				},
+				{
+					Kind: DifferenceChanged,
+					Path: "version",
+					A:    []byte{1},
+					B:    []byte{3},
+				},
+			},
+		},
+		{
+			name: "attributes",
+			a: Message{
+				Data: nested(
+					netlink.Attribute{Type: 1, Data: []byte{0x01}},
+					netlink.Attribute{Type: 2, Data: []byte{0x02}},
+					netlink.Attribute{
+						Type: 3 | netlink.Nested,
+						Data: nested(
+							netlink.Attribute{Type: 1, Data: []byte{0x01}},
+							netlink.Attribute{Type: 1, Data: []byte{0x02}},
+						),
+					},
+				),
+			},
+			b: Message{
+				Data: nested(
+					netlink.Attribute{Type: 1, Data: []byte{0xff}},
+					netlink.Attribute{
+						Type: 3 | netlink.Nested,
+						Data: nested(
+							netlink.Attribute{Type: 1, Data: []byte{0x01}},
+							netlink.Attribute{Type: 1, Data: []byte{0x03}},
+						),
+					},
+					netlink.Attribute{Type: 4, Data: []byte{0x04}},
+				),
+			},
+			diffs: []Difference{
+				{
+					Kind: DifferenceChanged,
+					Path: "1",
+					A:    []byte{0x01},
+					B:    []byte{0xff},
+				},
+				{
+					Kind: DifferenceRemoved,
+					Path: "2",
+					A:    []byte{0x02},
+				},
+				{
+					Kind: DifferenceChanged,
+					Path: "3/1[1]",
+					A:    []byte{0x02},
+					B:    []byte{0x03},
+				},
+				{
+					Kind: DifferenceAdded,
+					Path: "4",
+					B:    []byte{0x04},
+				},
+			},
+		},
+		{
+			name: "flags",
+			a: Message{
+				Data: nested(
+					netlink.Attribute{Type: 1, Data: []byte{0x00, 0x01}},
+					netlink.Attribute{Type: 2 | netlink.Nested, Data: nested()},
+				),
+			},
+			b: Message{
+				Data: nested(
+					netlink.Attribute{Type: 1 | netlink.NetByteOrder, Data: []byte{0x00, 0x01}},
+					netlink.Attribute{Type: 2, Data: nested()},
+				),
+			},
+			diffs: []Difference{
+				{
+					Kind: DifferenceChanged,
+					Path: "1.flags",
+					A:    []byte{0x00, 0x00},
+					B:    []byte{0x40, 0x00},
+				},
+				{
+					Kind: DifferenceChanged,
+					Path: "2.flags",
+					A:    []byte{0x80, 0x00},
+					B:    []byte{0x00, 0x00},
+				},
+			},
+		},
+		{
+			name: "opaque data",
+			a:    Message{Data: []byte{0xff}},
+			b:    Message{Data: []byte{0xfe}},
+			diffs: []Difference{{
+				Kind: DifferenceChanged,
+				Path: "data",
+				A:    []byte{0xff},
+				B:    []byte{0xfe},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.diffs, Diff(tt.a, tt.b)); diff != "" {
+				t.Fatalf("unexpected differences (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func mustMarshalAttributes(attrs []netlink.Attribute) []byte {
+	b, err := netlink.MarshalAttributes(attrs)
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
@@ -0,0 +1,134 @@
+package genetlink
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mdlayher/netlink"
+)
+
+// DumpContext is like Dump, but returns ctx.Err() if ctx is canceled or its
+// deadline expires before all of the replies are received. The context is
+// applied as described for ExecuteContext.
+//
+// When dump coalescing is enabled, a caller which joins a dump in progress
+// waits for it only until its own ctx is done. The shared dump does not run
+// under any one caller's deadline, and is aborted only once every caller
+// waiting for it has given up.
+//
+// A dump which is canceled after its request was sent leaves its remaining
+// replies queued on the socket. The Conn records the abandoned request and
+// discards its remaining replies before the next request is sent, so that
+// the next caller never consumes the leftovers of a previous dump. Drain
+// can be used to discard them eagerly.
+func (c *Conn) DumpContext(ctx context.Context, cmd uint8, family Family,
+	attrs func(ae *netlink.AttributeEncoder) error) ([]Message, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	msgs, err := c.dumpContext(ctx, cmd, family, attrs)
+	if err != nil && !errors.Is(err, ErrDumpInterrupted) {
+		return nil, contextError(ctx, err)
+	}
+
+	return msgs, err
+}
+
+// Drain discards the remaining replies to a request which was abandoned when
+// its replies could not be received before a deadline, such as a request
+// made using DumpContext, ExecuteContext, or ExecuteTimeout. Drain returns
+// immediately if there is no abandoned request.
+//
+// Draining is performed automatically before the next request is sent using
+// Execute, Dump, or their variants, so Drain need only be called to
+// resynchronize the Conn eagerly, for example before returning it to a
+// pool, or before using Receive directly.
+//
+// Only abandoned dump requests and requests with the netlink.Acknowledge
+// flag are recorded, because the kernel always terminates their replies.
+// Late replies to other requests cannot be detected and must be handled by
+// the caller.
+func (c *Conn) Drain(ctx context.Context) error {
+	return contextError(ctx, c.exclusive(ctx, c.drain))
+}
+
+// An abandonedRequest tracks a request whose replies were not fully received.
+type abandonedRequest struct {
+	mu   sync.Mutex
+	ok   bool
+	seq  uint32
+	dump bool
+}
+
+// nextSequence returns a sequence number for a request whose replies may need
+// to be identified if the request is abandoned.
+func (c *Conn) nextSequence() uint32 {
+	return atomic.AddUint32(&c.seq, 1)
+}
+
+// abandon records that the replies to the request with header h were not
+// fully received, if their end can be detected.
+func (c *Conn) abandon(h netlink.Header) {
+	dump := h.Flags&netlink.Dump != 0
+	if !dump && h.Flags&netlink.Acknowledge == 0 {
+		return
+	}
+
+	a := &c.abandoned
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.ok, a.seq, a.dump = true, h.Sequence, dump
+}
+
+// drain discards the remaining replies to an abandoned request, if any.
+func (c *Conn) drain() error {
+	a := &c.abandoned
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for a.ok {
+		msgs, err := c.c.Receive()
+		switch {
+		case isReceiveTimeout(err):
+			return err
+		case isMessageError(err):
+			// An error message terminates the replies.
+			a.ok = false
+			continue
+		case err != nil:
+			return err
+		case len(msgs) == 0 && a.dump:
+			// Only the "multi-part done" message remained.
+			a.ok = false
+			continue
+		}
+
+		for _, m := range msgs {
+			if m.Header.Sequence != a.seq {
+				// Not a reply to the abandoned request, such as a multicast
+				// message; discard it.
+				continue
+			}
+
+			// The remainder of a dump is received through its "multi-part
+			// done" message, and an acknowledgement ends any other request.
+			if a.dump || m.Header.Type == netlink.Error {
+				a.ok = false
+			}
+		}
+	}
+
+	return nil
+}
+
+// isReceiveTimeout reports whether err was caused by a deadline expiring
+// while receiving replies.
+func isReceiveTimeout(err error) bool {
+	var oerr *netlink.OpError
+	return errors.As(err, &oerr) && oerr.Op == "receive" && errors.Is(err, os.ErrDeadlineExceeded)
+}
@@ -0,0 +1,104 @@
+//go:build linux
+// +build linux
+
+package genetlink_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestConnDumpContext(t *testing.T) {
+	family := genetlink.Family{ID: 0x20, Version: 1}
+
+	// Dump requests produce three replies, all other requests are echoed,
+	// and there are no multicast messages.
+	fn := func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		if nreq.Header.Type == 0 {
+			return nil, nil
+		}
+
+		if nreq.Header.Flags&netlink.Dump == 0 {
+			return []genetlink.Message{{Header: greq.Header}}, nil
+		}
+
+		return []genetlink.Message{
+			{Header: greq.Header},
+			{Header: greq.Header},
+			{Header: greq.Header},
+		}, nil
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		c := genltest.Dial(fn)
+		defer c.Close()
+
+		msgs, err := c.DumpContext(context.Background(), 1, family, nil)
+		if err != nil {
+			t.Fatalf("failed to dump: %v", err)
+		}
+
+		if diff := cmp.Diff(3, len(msgs)); diff != "" {
+			t.Fatalf("unexpected number of messages (-want +got):\n%s", diff)
+		}
+	})
+
+	// cancel starts a dump which is canceled before its replies arrive, and
+	// then releases the replies.
+	cancel := func(t *testing.T, c *genetlink.Conn, g *genltest.Gate) {
+		t.Helper()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-g.Waiting()
+			cancel()
+		}()
+
+		if _, err := c.DumpContext(ctx, 1, family, nil); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected canceled, but got: %v", err)
+		}
+
+		g.Release()
+	}
+
+	t.Run("drain", func(t *testing.T) {
+		g := genltest.NewGate()
+		c := genltest.Dial(g.Block(fn))
+		defer c.Close()
+
+		cancel(t, c, g)
+
+		if err := c.Drain(context.Background()); err != nil {
+			t.Fatalf("failed to drain: %v", err)
+		}
+
+		// The leftovers of the dump were discarded.
+		msgs, _, err := c.Receive()
+		if err != nil {
+			t.Fatalf("failed to receive: %v", err)
+		}
+
+		if diff := cmp.Diff(0, len(msgs)); diff != "" {
+			t.Fatalf("unexpected number of leftover messages (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("next request", func(t *testing.T) {
+		g := genltest.NewGate()
+		c := genltest.Dial(g.Block(fn))
+		defer c.Close()
+
+		cancel(t, c, g)
+
+		msgs, err := c.Execute(genetlink.Message{Header: genetlink.Header{Command: 2}}, family.ID, netlink.Request)
+		if err := checkCommand(msgs, err, 2); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
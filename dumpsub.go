@@ -0,0 +1,60 @@
+package genetlink
+
+import (
+	"errors"
+
+	"github.com/mdlayher/netlink"
+)
+
+// errMonitorConn is returned when a Monitor's own Conn is used to dump.
+var errMonitorConn = errors.New("genetlink: dumps cannot use the Monitor's Conn")
+
+// DumpSubscribe performs a dump and subscribes to the Events which follow
+// it without losing any Events to the classic race between the two, where
+// changes made while the dump is in progress are neither reflected in the
+// dump nor reported to a subscription created afterward.
+//
+// DumpSubscribe first joins the multicast group with the specified name of
+// the named family and creates a Subscription with cfg, as with
+// SubscribeGroup, so that Events are buffered from that point on. It then
+// dumps cmd of the family using dc, encoding the request's attributes with
+// attrs if it is not nil, and returns the dump along with the Subscription.
+//
+// Events which were received by Run before the dump began are reflected in
+// the dump, so they are discarded. Events which arrived while the dump was
+// in progress may or may not be reflected in it, so they are replayed by
+// the Subscription, followed by all later Events. Consumers apply the
+// replayed Events to the state built from the dump, which is correct as
+// long as applying an Event to state which already reflects it has no
+// effect, as is the case for the notifications of most families.
+//
+// dc must be a Conn other than the Monitor's, since the Monitor's Conn
+// receives multicast messages which would be interleaved with the replies
+// to the dump. Unless the Monitor has a Resolver other than its own Conn,
+// dc is also used to resolve the family. If the dump fails, the Subscription is closed and the error
+// is returned, along with the messages of an interrupted dump.
+func (m *Monitor) DumpSubscribe(dc *Conn, cmd uint8, family, group string,
+	attrs func(ae *netlink.AttributeEncoder) error, cfg *SubscriptionConfig) ([]Message, *Subscription, error) {
+	if dc == m.c {
+		return nil, nil, errMonitorConn
+	}
+
+	f, err := m.join(dc, family, group)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s := m.newSubscription(cfg, &f.ID)
+
+	// Events delivered so far were sent by the kernel before the dump
+	// request, so the dump reflects them.
+	s.discardBuffered()
+
+	msgs, err := dc.Dump(cmd, f, attrs)
+	if err != nil {
+		_ = s.Close()
+		return msgs, nil, err
+	}
+
+	return msgs, s, nil
+}
@@ -0,0 +1,212 @@
+package genetlink_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestMonitorDumpSubscribe(t *testing.T) {
+	family := genetlink.Family{
+		ID:      0x20,
+		Version: 1,
+		Name:    "foo",
+		Groups:  []genetlink.MulticastGroup{{ID: 5, Name: "events"}},
+	}
+
+	var k *genltest.Kernel
+	k = genltest.NewKernel(genltest.ServeFamily(family,
+		func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+			if nreq.Header.Flags&netlink.Dump == 0 {
+				return nil, genltest.ErrorNotExist()
+			}
+
+			// An object changes while the dump is in progress, so the change
+			// must be delivered after the dump.
+			event := genetlink.Message{Header: genetlink.Header{Command: 2}}
+			if _, err := k.Multicast(family.ID, 5, event); err != nil {
+				return nil, err
+			}
+
+			return []genetlink.Message{
+				{Header: genetlink.Header{Command: 1}, Data: []byte{1}},
+				{Header: genetlink.Header{Command: 1}, Data: []byte{2}},
+			}, nil
+		},
+	), nil)
+
+	ec, dc := k.Dial(), k.Dial()
+	defer dc.Close()
+
+	m := genetlink.NewMonitor(ec)
+
+	msgs, s, err := m.DumpSubscribe(dc, 1, "foo", "events", nil, &genetlink.SubscriptionConfig{Buffer: 8})
+	if err != nil {
+		t.Fatalf("failed to dump and subscribe: %v", err)
+	}
+
+	want := []genetlink.Message{
+		{Header: genetlink.Header{Command: 1}, Data: []byte{1}},
+		{Header: genetlink.Header{Command: 1}, Data: []byte{2}},
+	}
+
+	if diff := cmp.Diff(want, msgs); diff != "" {
+		t.Fatalf("unexpected dump (-want +got):\n%s", diff)
+	}
+
+	errStop := errors.New("stop")
+	go func() {
+		_ = m.Run(func(_ genetlink.Event) error { return errStop })
+		_ = ec.Close()
+	}()
+
+	e, ok := <-s.Events()
+	if !ok {
+		t.Fatal("subscription closed before the replayed event")
+	}
+
+	if diff := cmp.Diff(uint8(2), e.Command); diff != "" {
+		t.Fatalf("unexpected event (-want +got):\n%s", diff)
+	}
+}
+
+func TestMonitorDumpSubscribeError(t *testing.T) {
+	family := genetlink.Family{
+		ID:     0x20,
+		Name:   "foo",
+		Groups: []genetlink.MulticastGroup{{ID: 5, Name: "events"}},
+	}
+
+	k := genltest.NewKernel(genltest.ServeFamily(family,
+		func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			return nil, genltest.ErrorNotExist()
+		},
+	), nil)
+
+	ec, dc := k.Dial(), k.Dial()
+	defer ec.Close()
+	defer dc.Close()
+
+	m := genetlink.NewMonitor(ec)
+
+	if _, _, err := m.DumpSubscribe(dc, 1, "foo", "bar", nil, nil); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+
+	_, s, err := m.DumpSubscribe(dc, 1, "foo", "events", nil, nil)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not exist error, but got: %v", err)
+	}
+	if s != nil {
+		t.Fatal("expected no subscription after a failed dump")
+	}
+}
+
+func TestMonitorDumpSubscribeRunning(t *testing.T) {
+	family := genetlink.Family{
+		ID:      0x20,
+		Version: 1,
+		Name:    "foo",
+		Groups:  []genetlink.MulticastGroup{{ID: 5, Name: "events"}},
+	}
+
+	k := genltest.NewKernel(genltest.ServeFamily(family,
+		func(_ genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+			if nreq.Header.Flags&netlink.Dump == 0 {
+				return nil, genltest.ErrorNotExist()
+			}
+
+			return []genetlink.Message{{Header: genetlink.Header{Command: 1}}}, nil
+		},
+	), nil)
+
+	tests := []struct {
+		name string
+		fn   func(m *genetlink.Monitor, dc *genetlink.Conn) ([]genetlink.Message, *genetlink.Subscription, error)
+	}{
+		{
+			name: "DumpSubscribe",
+			fn: func(m *genetlink.Monitor, dc *genetlink.Conn) ([]genetlink.Message, *genetlink.Subscription, error) {
+				return m.DumpSubscribe(dc, 1, "foo", "events", nil, nil)
+			},
+		},
+		{
+			name: "Snapshot",
+			fn: func(m *genetlink.Monitor, dc *genetlink.Conn) ([]genetlink.Message, *genetlink.Subscription, error) {
+				s, err := m.Snapshot(context.Background(), dc, "foo", 1, "events")
+				if err != nil {
+					return nil, nil, err
+				}
+
+				return s.Messages, s.Subscription, nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec, dc := k.Dial(), k.Dial()
+			defer dc.Close()
+
+			m := genetlink.NewMonitor(ec)
+
+			runC := make(chan error, 1)
+			go func() { runC <- m.Run(nil) }()
+			defer func() {
+				_ = ec.Close()
+				<-runC
+			}()
+
+			// Give Run time to begin receiving using the Monitor's Conn,
+			// which must not be used to resolve the family.
+			time.Sleep(20 * time.Millisecond)
+
+			if _, _, err := tt.fn(m, ec); err == nil {
+				t.Fatal("expected an error using the Monitor's Conn, but none occurred")
+			}
+
+			type result struct {
+				msgs []genetlink.Message
+				s    *genetlink.Subscription
+				err  error
+			}
+
+			resC := make(chan result, 1)
+			go func() {
+				msgs, s, err := tt.fn(m, dc)
+				resC <- result{msgs, s, err}
+			}()
+
+			var r result
+			select {
+			case r = <-resC:
+				if r.err != nil {
+					t.Fatalf("failed to dump while Run is active: %v", r.err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out dumping while Run is active")
+			}
+
+			want := []genetlink.Message{{Header: genetlink.Header{Command: 1}}}
+			if diff := cmp.Diff(want, r.msgs); diff != "" {
+				t.Fatalf("unexpected dump (-want +got):\n%s", diff)
+			}
+
+			event := genetlink.Message{Header: genetlink.Header{Command: 2}}
+			if _, err := k.Multicast(family.ID, 5, event); err != nil {
+				t.Fatalf("failed to multicast: %v", err)
+			}
+
+			if e := <-r.s.Events(); e.Command != 2 {
+				t.Fatalf("unexpected event command: %d", e.Command)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package genetlink
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrUnavailable is matched by errors returned when generic netlink is
+// unavailable because of a limitation of the environment, such as a sandbox
+// which does not implement generic netlink sockets. Callers can check for it
+// using errors.Is and skip generic netlink features, since retrying will not
+// succeed.
+var ErrUnavailable = errors.New("genetlink: generic netlink unavailable in this environment")
+
+// Possible EnvironmentError.Environment values.
+const (
+	EnvironmentGVisor    = "gVisor"
+	EnvironmentContainer = "container"
+)
+
+// An EnvironmentError reports that a generic netlink socket could not be
+// created because the environment does not support or denies netlink
+// sockets. EnvironmentErrors match ErrUnavailable using errors.Is.
+type EnvironmentError struct {
+	// Environment describes the detected environment, such as
+	// EnvironmentGVisor or EnvironmentContainer, or is empty if the
+	// environment could not be identified.
+	Environment string
+
+	// Err is the underlying error. If the socket was denied by a security
+	// policy, Err is a *RestrictionError.
+	Err error
+}
+
+// Error implements error.
+func (e *EnvironmentError) Error() string {
+	env := "this environment"
+	if e.Environment != "" {
+		env = "a " + e.Environment + " environment"
+	}
+
+	return "genetlink: generic netlink sockets are unavailable in " + env + ": " + e.Err.Error()
+}
+
+// Unwrap implements errors unwrapping.
+func (e *EnvironmentError) Unwrap() error { return e.Err }
+
+// Is reports whether target is ErrUnavailable.
+func (e *EnvironmentError) Is(target error) bool { return target == ErrUnavailable }
+
+// environmentError returns an *EnvironmentError if err, returned while
+// dialing, indicates that the environment does not support or denies
+// generic netlink sockets. Otherwise, err is returned unchanged.
+func environmentError(err error) error {
+	var serr *os.SyscallError
+	if !errors.As(err, &serr) || serr.Syscall != "socket" || !isUnavailable(serr.Err) {
+		return err
+	}
+
+	if rerr := restriction(err); rerr != nil {
+		err = rerr
+	}
+
+	return &EnvironmentError{
+		Environment: detectEnvironment(),
+		Err:         err,
+	}
+}
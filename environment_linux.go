@@ -0,0 +1,48 @@
+//go:build linux
+// +build linux
+
+package genetlink
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// isUnavailable reports whether err, returned when creating a socket,
+// indicates that netlink or generic netlink sockets are not implemented or
+// are denied.
+func isUnavailable(err error) bool {
+	return errors.Is(err, unix.EAFNOSUPPORT) ||
+		errors.Is(err, unix.EPROTONOSUPPORT) ||
+		isRestricted(err)
+}
+
+// detectEnvironment identifies a sandbox or container environment using
+// well-known heuristics, or returns an empty string.
+func detectEnvironment() string {
+	// gVisor reports a fixed kernel version string.
+	if b, err := os.ReadFile("/proc/version"); err == nil &&
+		bytes.Contains(b, []byte("#1 SMP Sun Jan 10 15:06:54 PST 2016")) {
+		return EnvironmentGVisor
+	}
+
+	// Docker and Podman create marker files in the container's root.
+	for _, f := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(f); err == nil {
+			return EnvironmentContainer
+		}
+	}
+
+	if b, err := os.ReadFile("/proc/1/cgroup"); err == nil {
+		for _, s := range []string{"docker", "kubepods", "containerd", "lxc"} {
+			if bytes.Contains(b, []byte(s)) {
+				return EnvironmentContainer
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,82 @@
+//go:build linux
+// +build linux
+
+package genetlink
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestEnvironmentError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		unavailable bool
+		restricted  bool
+	}{
+		{
+			name: "bind EADDRINUSE",
+			err:  os.NewSyscallError("bind", unix.EADDRINUSE),
+		},
+		{
+			name: "setsockopt EPERM",
+			err:  os.NewSyscallError("setsockopt", unix.EPERM),
+		},
+		{
+			name: "socket EMFILE",
+			err:  os.NewSyscallError("socket", unix.EMFILE),
+		},
+		{
+			name:        "socket EPROTONOSUPPORT",
+			err:         os.NewSyscallError("socket", unix.EPROTONOSUPPORT),
+			unavailable: true,
+		},
+		{
+			name:        "socket EAFNOSUPPORT",
+			err:         os.NewSyscallError("socket", unix.EAFNOSUPPORT),
+			unavailable: true,
+		},
+		{
+			name:        "socket EPERM",
+			err:         os.NewSyscallError("socket", unix.EPERM),
+			unavailable: true,
+			restricted:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := environmentError(tt.err)
+
+			var eerr *EnvironmentError
+			if got := errors.As(err, &eerr); got != tt.unavailable {
+				t.Fatalf("unexpected EnvironmentError: %v", err)
+			}
+			if !tt.unavailable {
+				if err != tt.err {
+					t.Fatalf("error was modified: %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, ErrUnavailable) {
+				t.Fatalf("error does not match ErrUnavailable: %v", err)
+			}
+
+			var serr *os.SyscallError
+			if !errors.As(tt.err, &serr) || !errors.Is(err, serr.Err) {
+				t.Fatalf("error does not wrap its cause: %v", err)
+			}
+
+			var rerr *RestrictionError
+			if got := errors.As(err, &rerr); got != tt.restricted {
+				t.Fatalf("unexpected RestrictionError: %v", err)
+			}
+		})
+	}
+}
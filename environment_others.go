@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package genetlink
+
+// isUnavailable always reports false.
+func isUnavailable(_ error) bool {
+	return false
+}
+
+// detectEnvironment always returns an empty string.
+func detectEnvironment() string {
+	return ""
+}
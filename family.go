@@ -6,6 +6,25 @@ type Family struct {
 	Version uint8
 	Name    string
 	Groups  []MulticastGroup
+	Ops     []Op
+}
+
+// GetFamilyOptions specifies optional information retrieved along with a
+// Family by Conn.GetFamilyInfo.
+type GetFamilyOptions struct {
+	// Policy retrieves the family's attribute validation policies, as with
+	// Conn.GetPolicy. Retrieving policies requires Linux 5.10+.
+	Policy bool
+}
+
+// FamilyInfo is a Family along with optional information retrieved by
+// Conn.GetFamilyInfo.
+type FamilyInfo struct {
+	Family
+
+	// Policy is the family's attribute validation policies, or nil if they
+	// were not requested. A family with no policies has an empty Policy.
+	Policy *Policy
 }
 
 // A MulticastGroup is a generic netlink multicast group, which can be joined
@@ -15,3 +34,83 @@ type MulticastGroup struct {
 	ID   uint32
 	Name string
 }
+
+// An Op is a generic netlink operation supported by a family. The ID of an Op
+// corresponds to a generic netlink header command.
+type Op struct {
+	ID    uint32
+	Flags OpFlags
+}
+
+// OpFlags are flags which describe the capabilities and permission
+// requirements of an Op.
+type OpFlags uint32
+
+// Possible OpFlags values.
+const (
+	OpAdminPerm    OpFlags = 0x01 // unix.GENL_ADMIN_PERM
+	OpCapDo        OpFlags = 0x02 // unix.GENL_CMD_CAP_DO
+	OpCapDump      OpFlags = 0x04 // unix.GENL_CMD_CAP_DUMP
+	OpCapHasPolicy OpFlags = 0x08 // unix.GENL_CMD_CAP_HASPOL
+	OpUnsAdminPerm OpFlags = 0x10 // unix.GENL_UNS_ADMIN_PERM
+)
+
+// Equal reports whether f and x are equal. The order of Groups and Ops is not
+// significant, so Families retrieved from different kernels or at different
+// times compare equal if they describe the same family.
+func (f Family) Equal(x Family) bool {
+	if f.ID != x.ID || f.Version != x.Version || f.Name != x.Name {
+		return false
+	}
+
+	if len(f.Groups) != len(x.Groups) || len(f.Ops) != len(x.Ops) {
+		return false
+	}
+
+	groups := make(map[MulticastGroup]int, len(f.Groups))
+	for _, g := range f.Groups {
+		groups[g]++
+	}
+	for _, g := range x.Groups {
+		if groups[g] == 0 {
+			return false
+		}
+		groups[g]--
+	}
+
+	ops := make(map[Op]int, len(f.Ops))
+	for _, o := range f.Ops {
+		ops[o]++
+	}
+	for _, o := range x.Ops {
+		if ops[o] == 0 {
+			return false
+		}
+		ops[o]--
+	}
+
+	return true
+}
+
+// op returns the Op for the specified command, if the Family supports it.
+func (f Family) op(command uint8) (Op, bool) {
+	for _, o := range f.Ops {
+		if o.ID == uint32(command) {
+			return o, true
+		}
+	}
+
+	return Op{}, false
+}
+
+// group returns the MulticastGroup with the specified name, if the Family
+// has one.
+func (f Family) group(name string) (MulticastGroup, bool) {
+	for _, g := range f.Groups {
+		if g.Name == name {
+			return g, true
+		}
+	}
+
+	return MulticastGroup{}, false
+}
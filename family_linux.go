@@ -4,6 +4,7 @@
 package genetlink
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -19,6 +20,11 @@ var errInvalidFamilyVersion = errors.New("invalid family version attribute")
 
 // getFamily retrieves a generic netlink family with the specified name.
 func (c *Conn) getFamily(name string) (Family, error) {
+	return c.getFamilyContext(context.Background(), name)
+}
+
+// getFamilyContext is like getFamily, but applies ctx to the request.
+func (c *Conn) getFamilyContext(ctx context.Context, name string) (Family, error) {
 	b, err := netlink.MarshalAttributes([]netlink.Attribute{{
 		Type: unix.CTRL_ATTR_FAMILY_NAME,
 		Data: nlenc.Bytes(name),
@@ -36,7 +42,13 @@ func (c *Conn) getFamily(name string) (Family, error) {
 		Data: b,
 	}
 
-	msgs, err := c.Execute(req, unix.GENL_ID_CTRL, netlink.Request)
+	release, err := c.sched.acquire(ctx, PriorityNormal)
+	if err != nil {
+		return Family{}, err
+	}
+	defer release()
+
+	msgs, err := c.execute(ctx, req, unix.GENL_ID_CTRL, netlink.Request)
 	if err != nil {
 		return Family{}, err
 	}
@@ -113,6 +125,8 @@ func parseFamily(b []byte) (Family, error) {
 			f.Version = uint8(v)
 		case unix.CTRL_ATTR_MCAST_GROUPS:
 			ad.Nested(parseMulticastGroups(&f.Groups))
+		case unix.CTRL_ATTR_OPS:
+			ad.Nested(parseOps(&f.Ops))
 		}
 	}
 
@@ -148,3 +162,28 @@ func parseMulticastGroups(groups *[]MulticastGroup) func(*netlink.AttributeDecod
 		return nil
 	}
 }
+
+// parseOps parses an array of operation nested attributes into a slice of Ops.
+func parseOps(ops *[]Op) func(*netlink.AttributeDecoder) error {
+	return func(ad *netlink.AttributeDecoder) error {
+		*ops = make([]Op, 0, ad.Len())
+		for ad.Next() {
+			ad.Nested(func(nad *netlink.AttributeDecoder) error {
+				var o Op
+				for nad.Next() {
+					switch nad.Type() {
+					case unix.CTRL_ATTR_OP_ID:
+						o.ID = nad.Uint32()
+					case unix.CTRL_ATTR_OP_FLAGS:
+						o.Flags = OpFlags(nad.Uint32())
+					}
+				}
+
+				*ops = append(*ops, o)
+				return nil
+			})
+		}
+
+		return nil
+	}
+}
@@ -4,7 +4,10 @@
 package genetlink_test
 
 import (
+	"errors"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/mdlayher/genetlink"
@@ -80,6 +83,253 @@ func TestConnGetFamily(t *testing.T) {
 	}
 }
 
+func TestConnGetFamilyInfo(t *testing.T) {
+	var (
+		foo = genetlink.Family{
+			ID:      0x20,
+			Version: 1,
+			Name:    "foo",
+			Ops: []genetlink.Op{{
+				ID:    1,
+				Flags: genetlink.OpCapDo | genetlink.OpCapHasPolicy,
+			}},
+		}
+
+		bar = genetlink.Family{
+			ID:      0x21,
+			Version: 1,
+			Name:    "bar",
+			Ops:     []genetlink.Op{{ID: 1, Flags: genetlink.OpCapDo}},
+		}
+	)
+
+	var policies int
+	c := genltest.Dial(genltest.ServeFamilies([]genetlink.Family{foo, bar},
+		genltest.CheckRequest(unix.GENL_ID_CTRL, unix.CTRL_CMD_GETPOLICY, netlink.Request|netlink.Dump,
+			func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+				policies++
+				return []genetlink.Message{
+					policyMessage(t, func(ae *netlink.AttributeEncoder) {
+						ae.Nested(unix.CTRL_ATTR_OP_POLICY, func(ae *netlink.AttributeEncoder) error {
+							ae.Nested(1, func(ae *netlink.AttributeEncoder) error {
+								ae.Uint32(unix.CTRL_ATTR_POLICY_DO, 0)
+								return nil
+							})
+							return nil
+						})
+					}),
+				}, nil
+			},
+		),
+	))
+	defer c.Close()
+
+	tests := []struct {
+		name     string
+		family   string
+		opts     *genetlink.GetFamilyOptions
+		info     genetlink.FamilyInfo
+		policies int
+	}{
+		{
+			name:   "no options",
+			family: "foo",
+			info:   genetlink.FamilyInfo{Family: foo},
+		},
+		{
+			name:   "policy",
+			family: "foo",
+			opts:   &genetlink.GetFamilyOptions{Policy: true},
+			info: genetlink.FamilyInfo{
+				Family: foo,
+				Policy: &genetlink.Policy{
+					Sets: []genetlink.PolicySet{},
+					Ops: []genetlink.OpPolicy{{
+						Command: 1,
+						Do:      0,
+						Dump:    -1,
+					}},
+				},
+			},
+			policies: 1,
+		},
+		{
+			name:   "no policy",
+			family: "bar",
+			opts:   &genetlink.GetFamilyOptions{Policy: true},
+			info: genetlink.FamilyInfo{
+				Family: bar,
+				Policy: &genetlink.Policy{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policies = 0
+
+			info, err := c.GetFamilyInfo(tt.family, tt.opts)
+			if err != nil {
+				t.Fatalf("failed to get family info: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.info, info); diff != "" {
+				t.Fatalf("unexpected family info (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.policies, policies); diff != "" {
+				t.Fatalf("unexpected number of policy requests (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := c.GetFamilyInfo("baz", nil); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not exist error, but got: %v", err)
+	}
+}
+
+func TestConnFamilyCache(t *testing.T) {
+	noop := func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, nil
+	}
+
+	var (
+		requests int
+		serve    = genltest.ServeFamilies(genltest.Families(), noop)
+	)
+
+	c := genltest.Dial(func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		requests++
+		return serve(greq, nreq)
+	})
+	defer c.Close()
+
+	c.SetFamilyCache(true)
+
+	tests := []struct {
+		name     string
+		fn       func()
+		family   string
+		requests int
+	}{
+		{
+			name:     "miss",
+			family:   "nl80211",
+			requests: 1,
+		},
+		{
+			name:   "hit",
+			family: "nl80211",
+		},
+		{
+			name:     "other family",
+			family:   "ethtool",
+			requests: 1,
+		},
+		{
+			name:     "invalidate family",
+			fn:       func() { c.InvalidateFamily("nl80211") },
+			family:   "nl80211",
+			requests: 1,
+		},
+		{
+			name:   "invalidate other family",
+			fn:     func() { c.InvalidateFamily("ethtool") },
+			family: "nl80211",
+		},
+		{
+			name:     "invalidate all",
+			fn:       c.InvalidateFamilies,
+			family:   "nl80211",
+			requests: 1,
+		},
+		{
+			name:     "disabled",
+			fn:       func() { c.SetFamilyCache(false) },
+			family:   "nl80211",
+			requests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.fn != nil {
+				tt.fn()
+			}
+
+			requests = 0
+			f, err := c.GetFamily(tt.family)
+			if err != nil {
+				t.Fatalf("failed to get family: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.family, f.Name); diff != "" {
+				t.Fatalf("unexpected family (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.requests, requests); diff != "" {
+				t.Fatalf("unexpected number of requests (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConnFamilyCacheClock(t *testing.T) {
+	noop := func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, nil
+	}
+
+	var (
+		requests int
+		serve    = genltest.ServeFamilies(genltest.Families(), noop)
+	)
+
+	c := genltest.Dial(func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		requests++
+		return serve(greq, nreq)
+	})
+	defer c.Close()
+
+	clk := genltest.NewClock(time.Unix(0, 0))
+	c.SetClock(clk)
+	c.SetFamilyCacheTTL(time.Minute)
+
+	tests := []struct {
+		name     string
+		advance  time.Duration
+		requests int
+	}{
+		{
+			name:     "miss",
+			requests: 1,
+		},
+		{
+			name:    "not expired",
+			advance: 59 * time.Second,
+		},
+		{
+			name:     "expired",
+			advance:  time.Second,
+			requests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk.Advance(tt.advance)
+
+			requests = 0
+			if _, err := c.GetFamily("nl80211"); err != nil {
+				t.Fatalf("failed to get family: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.requests, requests); diff != "" {
+				t.Fatalf("unexpected number of requests (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestConnFamilyList(t *testing.T) {
 	const (
 		version = 1
@@ -233,6 +483,37 @@ func TestFamily_parseAttributes(t *testing.T) {
 						},
 					}),
 				},
+				{
+					Type: unix.CTRL_ATTR_OPS,
+					Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+						{
+							Type: 1,
+							Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+								{
+									Type: unix.CTRL_ATTR_OP_ID,
+									Data: nlenc.Uint32Bytes(unix.CTRL_CMD_GETFAMILY),
+								},
+								{
+									Type: unix.CTRL_ATTR_OP_FLAGS,
+									Data: nlenc.Uint32Bytes(unix.GENL_CMD_CAP_DO | unix.GENL_CMD_CAP_DUMP),
+								},
+							}),
+						},
+						{
+							Type: 2,
+							Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+								{
+									Type: unix.CTRL_ATTR_OP_ID,
+									Data: nlenc.Uint32Bytes(unix.CTRL_CMD_GETPOLICY),
+								},
+								{
+									Type: unix.CTRL_ATTR_OP_FLAGS,
+									Data: nlenc.Uint32Bytes(unix.GENL_ADMIN_PERM | unix.GENL_CMD_CAP_DUMP),
+								},
+							}),
+						},
+					}),
+				},
 			},
 			f: genetlink.Family{
 				ID:      16,
@@ -248,6 +529,16 @@ func TestFamily_parseAttributes(t *testing.T) {
 						Name: "foobar",
 					},
 				},
+				Ops: []genetlink.Op{
+					{
+						ID:    unix.CTRL_CMD_GETFAMILY,
+						Flags: genetlink.OpCapDo | genetlink.OpCapDump,
+					},
+					{
+						ID:    unix.CTRL_CMD_GETPOLICY,
+						Flags: genetlink.OpAdminPerm | genetlink.OpCapDump,
+					},
+				},
 			},
 			ok: true,
 		},
@@ -4,6 +4,7 @@
 package genetlink
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 )
@@ -18,6 +19,11 @@ func (c *Conn) getFamily(name string) (Family, error) {
 	return Family{}, errUnimplemented
 }
 
+// getFamilyContext always returns an error.
+func (c *Conn) getFamilyContext(_ context.Context, name string) (Family, error) {
+	return Family{}, errUnimplemented
+}
+
 // listFamilies always returns an error.
 func (c *Conn) listFamilies() ([]Family, error) {
 	return nil, errUnimplemented
@@ -0,0 +1,92 @@
+package genetlink
+
+import "testing"
+
+func TestFamilyEqual(t *testing.T) {
+	f := Family{
+		ID:      16,
+		Version: 2,
+		Name:    "nlctrl",
+		Groups: []MulticastGroup{
+			{ID: 16, Name: "notify"},
+			{ID: 17, Name: "foo"},
+		},
+		Ops: []Op{
+			{ID: 3, Flags: OpCapDo},
+			{ID: 10, Flags: OpCapDump},
+		},
+	}
+
+	tests := []struct {
+		name string
+		x    Family
+		ok   bool
+	}{
+		{
+			name: "equal",
+			x:    f,
+			ok:   true,
+		},
+		{
+			name: "reordered",
+			x: Family{
+				ID:      16,
+				Version: 2,
+				Name:    "nlctrl",
+				Groups: []MulticastGroup{
+					{ID: 17, Name: "foo"},
+					{ID: 16, Name: "notify"},
+				},
+				Ops: []Op{
+					{ID: 10, Flags: OpCapDump},
+					{ID: 3, Flags: OpCapDo},
+				},
+			},
+			ok: true,
+		},
+		{
+			name: "name",
+			x: Family{
+				ID:      16,
+				Version: 2,
+				Name:    "foo",
+				Groups:  f.Groups,
+				Ops:     f.Ops,
+			},
+		},
+		{
+			name: "groups",
+			x: Family{
+				ID:      16,
+				Version: 2,
+				Name:    "nlctrl",
+				Groups: []MulticastGroup{
+					{ID: 16, Name: "notify"},
+					{ID: 16, Name: "notify"},
+				},
+				Ops: f.Ops,
+			},
+		},
+		{
+			name: "ops",
+			x: Family{
+				ID:      16,
+				Version: 2,
+				Name:    "nlctrl",
+				Groups:  f.Groups,
+				Ops: []Op{
+					{ID: 3, Flags: OpCapDo | OpAdminPerm},
+					{ID: 10, Flags: OpCapDump},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.Equal(tt.x); tt.ok != got {
+				t.Fatalf("unexpected equality: %v, want: %v", got, tt.ok)
+			}
+		})
+	}
+}
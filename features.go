@@ -0,0 +1,67 @@
+package genetlink
+
+import "strings"
+
+// Features describes the netlink socket options in effect for a Conn, as
+// reported by the kernel. Options may be enabled using the Strict field of
+// netlink.Config when dialing, or afterward using Conn.SetOption.
+type Features struct {
+	// ExtendedAcknowledge reports whether the kernel may include extended
+	// error information, such as error messages, in acknowledgements.
+	ExtendedAcknowledge bool
+
+	// StrictCheck reports whether the kernel strictly validates requests.
+	StrictCheck bool
+
+	// NoENOBUFS reports whether ENOBUFS errors are suppressed when the
+	// socket's receive buffer overflows.
+	NoENOBUFS bool
+
+	// CapAcknowledge reports whether acknowledgements omit the payload of
+	// the request which they acknowledge.
+	CapAcknowledge bool
+
+	// BroadcastError reports whether errors which occur while delivering
+	// multicast messages to the socket are reported to senders.
+	BroadcastError bool
+
+	// PacketInfo reports whether the multicast group of each received
+	// message is reported in control messages.
+	PacketInfo bool
+}
+
+// String returns a space-separated list of the enabled features, using the
+// names of their netlink socket options, or "none" if no features are
+// enabled.
+func (f Features) String() string {
+	var names []string
+	for _, o := range []struct {
+		on   bool
+		name string
+	}{
+		{f.ExtendedAcknowledge, "ext-ack"},
+		{f.StrictCheck, "strict-check"},
+		{f.NoENOBUFS, "no-enobufs"},
+		{f.CapAcknowledge, "cap-ack"},
+		{f.BroadcastError, "broadcast-error"},
+		{f.PacketInfo, "pktinfo"},
+	} {
+		if o.on {
+			names = append(names, o.name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "none"
+	}
+
+	return strings.Join(names, " ")
+}
+
+// Features retrieves the netlink socket options in effect for the Conn, so
+// that callers can log their effective configuration and check for optional
+// capabilities. Options which the kernel does not report, such as on kernels
+// which predate them, are reported as disabled.
+func (c *Conn) Features() (Features, error) {
+	return c.features()
+}
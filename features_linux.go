@@ -0,0 +1,64 @@
+//go:build linux
+// +build linux
+
+package genetlink
+
+import (
+	"errors"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// features retrieves the socket's boolean netlink options using getsockopt.
+func (c *Conn) features() (Features, error) {
+	rc, err := c.SyscallConn()
+	if err != nil {
+		return Features{}, err
+	}
+
+	var (
+		f    Features
+		serr error
+	)
+
+	if err := rc.Control(func(fd uintptr) {
+		for _, o := range []struct {
+			opt int
+			on  *bool
+		}{
+			{unix.NETLINK_EXT_ACK, &f.ExtendedAcknowledge},
+			{unix.NETLINK_GET_STRICT_CHK, &f.StrictCheck},
+			{unix.NETLINK_NO_ENOBUFS, &f.NoENOBUFS},
+			{unix.NETLINK_CAP_ACK, &f.CapAcknowledge},
+			{unix.NETLINK_BROADCAST_ERROR, &f.BroadcastError},
+			{unix.NETLINK_PKTINFO, &f.PacketInfo},
+		} {
+			var (
+				v int32
+				n = uint32(unsafe.Sizeof(v))
+			)
+
+			_, _, errno := unix.Syscall6(unix.SYS_GETSOCKOPT, fd,
+				unix.SOL_NETLINK, uintptr(o.opt),
+				uintptr(unsafe.Pointer(&v)), uintptr(unsafe.Pointer(&n)), 0)
+			switch {
+			case errno == 0:
+				*o.on = v != 0
+			case errors.Is(errno, unix.ENOPROTOOPT):
+				// The kernel predates the option, so it cannot be enabled.
+			default:
+				serr = errno
+				return
+			}
+		}
+	}); err != nil {
+		return Features{}, err
+	}
+	if serr != nil {
+		return Features{}, os.NewSyscallError("getsockopt", serr)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package genetlink
+
+// features always returns an error.
+func (c *Conn) features() (Features, error) {
+	return Features{}, errUnimplemented
+}
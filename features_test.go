@@ -0,0 +1,49 @@
+package genetlink_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+)
+
+func TestFeaturesString(t *testing.T) {
+	tests := []struct {
+		name string
+		f    genetlink.Features
+		s    string
+	}{
+		{
+			name: "none",
+			s:    "none",
+		},
+		{
+			name: "strict",
+			f: genetlink.Features{
+				ExtendedAcknowledge: true,
+				StrictCheck:         true,
+			},
+			s: "ext-ack strict-check",
+		},
+		{
+			name: "all",
+			f: genetlink.Features{
+				ExtendedAcknowledge: true,
+				StrictCheck:         true,
+				NoENOBUFS:           true,
+				CapAcknowledge:      true,
+				BroadcastError:      true,
+				PacketInfo:          true,
+			},
+			s: "ext-ack strict-check no-enobufs cap-ack broadcast-error pktinfo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.s, tt.f.String()); diff != "" {
+				t.Fatalf("unexpected string (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
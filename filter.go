@@ -0,0 +1,142 @@
+package genetlink
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/mdlayher/netlink/nlenc"
+	"golang.org/x/net/bpf"
+)
+
+// Offsets of fields in a generic netlink message, as seen by a classic BPF
+// program attached to a netlink socket.
+const (
+	filterTypeOffset    = 4  // nlmsghdr.nlmsg_type
+	filterCommandOffset = 16 // genlmsghdr.cmd
+
+	// nlmsgMinType is the lowest netlink message type which is not a
+	// control message, such as an error or "multi-part done".
+	nlmsgMinType = 0x10 // unix.NLMSG_MIN_TYPE
+)
+
+// A Filter builds a classic BPF program which accepts only generic netlink
+// messages matching a set of family IDs and commands. Use Assemble to build
+// the program, and Conn.SetBPF to attach it to a Conn:
+//
+//	prog, err := new(genetlink.Filter).
+//		Match(nl80211.ID, cmdNewScanResults, cmdScanAborted).
+//		Match(nlctrl.ID).
+//		Assemble()
+//	if err != nil {
+//		// ...
+//	}
+//
+//	if err := c.SetBPF(prog); err != nil {
+//		// ...
+//	}
+//
+// Netlink control messages, such as errors and acknowledgements, are always
+// accepted. Replies to requests are filtered like any other message, so a
+// Filter must also match the families of any requests sent using the Conn.
+//
+// The kernel runs a socket filter once per datagram, and the program only
+// inspects the first message in each datagram. The kernel sends multicast
+// messages in separate datagrams, so Filter is best suited to sockets which
+// receive multicast messages.
+//
+// The zero value of a Filter is ready to use, and rejects all messages other
+// than control messages.
+type Filter struct {
+	rules []filterRule
+}
+
+// A filterRule matches a family ID and, optionally, a set of commands.
+type filterRule struct {
+	family   uint16
+	commands []uint8
+}
+
+// Match accepts messages of the specified family ID which have any of the
+// specified commands, or any command if none are specified.
+func (f *Filter) Match(family uint16, commands ...uint8) *Filter {
+	f.rules = append(f.rules, filterRule{
+		family:   family,
+		commands: commands,
+	})
+
+	return f
+}
+
+// Instructions returns the BPF instructions of the program.
+func (f *Filter) Instructions() ([]bpf.Instruction, error) {
+	// BPF loads fields in network byte order, but netlink headers use native
+	// byte order.
+	hi, lo := uint32(filterTypeOffset), uint32(filterTypeOffset+1)
+	if nlenc.NativeEndian() == binary.LittleEndian {
+		hi, lo = lo, hi
+	}
+
+	const accept = 0xffffffff
+
+	prog := []bpf.Instruction{
+		// Accept control messages.
+		bpf.LoadAbsolute{Off: hi, Size: 1},
+		bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: 0, SkipTrue: 3},
+		bpf.LoadAbsolute{Off: lo, Size: 1},
+		bpf.JumpIf{Cond: bpf.JumpGreaterOrEqual, Val: nlmsgMinType, SkipTrue: 1},
+		bpf.RetConstant{Val: accept},
+	}
+
+	for _, r := range f.rules {
+		// The maximum jump within a rule must fit in a uint8.
+		if len(r.commands) > 250 {
+			return nil, fmt.Errorf("genetlink: too many commands for family %d filter: %d",
+				r.family, len(r.commands))
+		}
+
+		var rule []bpf.Instruction
+		if len(r.commands) == 0 {
+			rule = []bpf.Instruction{bpf.RetConstant{Val: accept}}
+		} else {
+			n := len(r.commands)
+
+			rule = append(rule, bpf.LoadAbsolute{Off: filterCommandOffset, Size: 1})
+			for i, c := range r.commands {
+				// Skip the remaining comparisons and the jump past the
+				// accept instruction.
+				rule = append(rule, bpf.JumpIf{
+					Cond:     bpf.JumpEqual,
+					Val:      uint32(c),
+					SkipTrue: uint8(n - i),
+				})
+			}
+			rule = append(rule,
+				bpf.Jump{Skip: 1},
+				bpf.RetConstant{Val: accept},
+			)
+		}
+
+		prog = append(prog,
+			bpf.LoadAbsolute{Off: filterTypeOffset, Size: 2},
+			bpf.JumpIf{
+				Cond:     bpf.JumpNotEqual,
+				Val:      uint32(binary.BigEndian.Uint16(nlenc.Uint16Bytes(r.family))),
+				SkipTrue: uint8(len(rule)),
+			},
+		)
+		prog = append(prog, rule...)
+	}
+
+	// Reject all other messages.
+	return append(prog, bpf.RetConstant{Val: 0}), nil
+}
+
+// Assemble builds the program for use with Conn.SetBPF.
+func (f *Filter) Assemble() ([]bpf.RawInstruction, error) {
+	prog, err := f.Instructions()
+	if err != nil {
+		return nil, err
+	}
+
+	return bpf.Assemble(prog)
+}
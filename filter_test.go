@@ -0,0 +1,112 @@
+package genetlink_test
+
+import (
+	"testing"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"golang.org/x/net/bpf"
+)
+
+func TestFilter(t *testing.T) {
+	packet := func(typ netlink.HeaderType, command uint8) []byte {
+		gb, err := (genetlink.Message{Header: genetlink.Header{Command: command}}).MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to marshal generic netlink message: %v", err)
+		}
+
+		b, err := (netlink.Message{
+			Header: netlink.Header{Length: uint32(16 + len(gb)), Type: typ},
+			Data:   gb,
+		}).MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to marshal netlink message: %v", err)
+		}
+
+		return b
+	}
+
+	tests := []struct {
+		name   string
+		f      *genetlink.Filter
+		accept [][]byte
+		reject [][]byte
+	}{
+		{
+			name: "empty",
+			f:    &genetlink.Filter{},
+			accept: [][]byte{
+				packet(netlink.Error, 0),
+				packet(netlink.Done, 0),
+			},
+			reject: [][]byte{
+				packet(0x10, 1),
+				packet(0x20, 1),
+			},
+		},
+		{
+			name: "family",
+			f:    new(genetlink.Filter).Match(0x20),
+			accept: [][]byte{
+				packet(netlink.Error, 0),
+				packet(0x20, 1),
+				packet(0x20, 2),
+			},
+			reject: [][]byte{
+				packet(0x10, 1),
+				packet(0x1020, 1),
+				packet(0x2000, 1),
+			},
+		},
+		{
+			name: "commands",
+			f: new(genetlink.Filter).
+				Match(0x20, 1, 3).
+				Match(0x10),
+			accept: [][]byte{
+				packet(0x20, 1),
+				packet(0x20, 3),
+				packet(0x10, 1),
+				packet(0x10, 2),
+			},
+			reject: [][]byte{
+				packet(0x20, 0),
+				packet(0x20, 2),
+				packet(0x21, 1),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog, err := tt.f.Instructions()
+			if err != nil {
+				t.Fatalf("failed to build instructions: %v", err)
+			}
+
+			vm, err := bpf.NewVM(prog)
+			if err != nil {
+				t.Fatalf("failed to create VM: %v", err)
+			}
+
+			for _, p := range tt.accept {
+				if n, err := vm.Run(p); err != nil || n == 0 {
+					t.Fatalf("expected packet %x to be accepted, got: %d, %v", p, n, err)
+				}
+			}
+
+			for _, p := range tt.reject {
+				if n, err := vm.Run(p); err != nil || n != 0 {
+					t.Fatalf("expected packet %x to be rejected, got: %d, %v", p, n, err)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterTooManyCommands(t *testing.T) {
+	_, err := new(genetlink.Filter).Match(0x20, make([]uint8, 251)...).Assemble()
+	if err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+}
@@ -0,0 +1,63 @@
+//go:build linux
+// +build linux
+
+package genetlink_test
+
+import (
+	"testing"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func FuzzConnGetFamily(f *testing.F) {
+	genltest.AddFuzzSeeds(f, 1, 16)
+
+	// Seed with the realistic replies of a genltest controller.
+	var corpus genltest.Corpus
+	c := genltest.DialConfig(
+		genltest.ServeFamilies(genltest.Families(), nil),
+		&genltest.Config{Corpus: &corpus},
+	)
+	if _, err := c.ListFamilies(); err != nil {
+		f.Fatalf("failed to list families: %v", err)
+	}
+	_ = c.Close()
+	corpus.AddTo(f)
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var m genetlink.Message
+		if err := m.UnmarshalBinary(b); err != nil {
+			return
+		}
+
+		c := genltest.Dial(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			return []genetlink.Message{m}, nil
+		})
+		defer c.Close()
+
+		// Any input must be rejected or parsed without panicking.
+		_, _ = c.GetFamily("foo")
+	})
+}
+
+func FuzzConnGetPolicy(f *testing.F) {
+	genltest.AddFuzzSeeds(f, 2, 16)
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var m genetlink.Message
+		if err := m.UnmarshalBinary(b); err != nil {
+			return
+		}
+		m.Header.Command = unix.CTRL_CMD_GETPOLICY
+
+		c := genltest.Dial(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			return []genetlink.Message{m}, nil
+		})
+		defer c.Close()
+
+		_, _ = c.GetPolicy("foo")
+	})
+}
@@ -0,0 +1,32 @@
+package genetlink
+
+import (
+	"testing"
+)
+
+func FuzzMessage(f *testing.F) {
+	f.Add([]byte{0x01, 0x02, 0x00, 0x00})
+	f.Add([]byte{0x01, 0x02, 0x00, 0x00, 0x05, 0x00, 0x01, 0x00, 0xff, 0x00, 0x00, 0x00})
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var m Message
+		if err := m.UnmarshalBinary(b); err != nil {
+			return
+		}
+
+		mb, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+
+		var m2 Message
+		if err := m2.UnmarshalBinary(mb); err != nil {
+			t.Fatalf("failed to unmarshal marshaled message: %v", err)
+		}
+
+		// Any message must produce no differences when compared to itself.
+		if diffs := Diff(m, m2); len(diffs) > 0 {
+			t.Fatalf("unexpected differences after round trip: %v", diffs)
+		}
+	})
+}
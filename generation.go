@@ -0,0 +1,203 @@
+package genetlink
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// A Generation identifies the version of an object in an ObjectCache.
+type Generation struct {
+	// Dump is the generation of the dump which last loaded the cache,
+	// starting at 1 for the initial dump.
+	Dump uint64
+
+	// Seq counts the updates applied to the object from events since it
+	// was loaded by the dump, or since it was first created by an event.
+	Seq uint64
+}
+
+// An ObjectCache holds objects of type V identified by keys of type K, such
+// as interfaces keyed by their index, built from an initial dump plus
+// subsequent events. Each object is tagged with its Generation, and the
+// cache tracks whether it may have become inconsistent with the kernel, so
+// that the application can detect stale state and dump again.
+//
+// The cache becomes stale when Invalidate is called, such as when a
+// Monitor delivers an Event with a Gap or a dump reports
+// ErrDumpInterrupted, and when an event removes an object which the cache
+// does not hold, which indicates that earlier events were missed.
+//
+// An ObjectCache is safe for concurrent use.
+type ObjectCache[K comparable, V any] struct {
+	mu    sync.Mutex
+	dump  uint64
+	objs  map[K]cachedObject[V]
+	stale error
+
+	// invalidations counts calls to invalidate, so that Refresh can detect
+	// invalidations which occur while it is dumping.
+	invalidations uint64
+}
+
+// A cachedObject is an object and its Generation.
+type cachedObject[V any] struct {
+	v   V
+	gen Generation
+}
+
+// NewObjectCache creates an empty ObjectCache. The cache is stale until it
+// is loaded with the results of a dump using Load or Refresh.
+func NewObjectCache[K comparable, V any]() *ObjectCache[K, V] {
+	return &ObjectCache[K, V]{
+		objs:  make(map[K]cachedObject[V]),
+		stale: errors.New("genetlink: object cache has not been loaded"),
+	}
+}
+
+// Load replaces the contents of the cache with objs, the results of a
+// dump, and starts a new dump generation. The cache is no longer stale.
+func (c *ObjectCache[K, V]) Load(objs map[K]V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.load(objs)
+	c.stale = nil
+}
+
+// load implements Load without clearing the stale state. c.mu must be held.
+func (c *ObjectCache[K, V]) load(objs map[K]V) {
+	c.dump++
+
+	c.objs = make(map[K]cachedObject[V], len(objs))
+	for k, v := range objs {
+		c.objs[k] = cachedObject[V]{v: v, gen: Generation{Dump: c.dump}}
+	}
+}
+
+// Refresh invokes dump to reload the cache if it is stale, and reports
+// whether it did so. If dump returns an error, the cache remains stale and
+// the error is returned. If the cache is invalidated again while dump is in
+// progress, the cache is loaded but remains stale.
+func (c *ObjectCache[K, V]) Refresh(dump func() (map[K]V, error)) (bool, error) {
+	c.mu.Lock()
+	stale, n := c.stale, c.invalidations
+	c.mu.Unlock()
+	if stale == nil {
+		return false, nil
+	}
+
+	objs, err := dump()
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.load(objs)
+	if c.invalidations == n {
+		c.stale = nil
+	}
+
+	return true, nil
+}
+
+// Update stores v for key k from an event, and returns the object's new
+// Generation.
+func (c *ObjectCache[K, V]) Update(k K, v V) Generation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	gen := Generation{Dump: c.dump}
+	if o, ok := c.objs[k]; ok {
+		gen.Seq = o.gen.Seq + 1
+	}
+
+	c.objs[k] = cachedObject[V]{v: v, gen: gen}
+	return gen
+}
+
+// Remove removes the object with key k from an event. If the cache does not
+// hold the object, events were likely missed, so the cache becomes stale
+// and Remove returns false.
+func (c *ObjectCache[K, V]) Remove(k K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.objs[k]; !ok {
+		c.invalidate(fmt.Errorf("genetlink: removed object %v is not cached", k))
+		return false
+	}
+
+	delete(c.objs, k)
+	return true
+}
+
+// Get returns the object with key k and its Generation, if the cache holds
+// it.
+func (c *ObjectCache[K, V]) Get(k K) (V, Generation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	o, ok := c.objs[k]
+	return o.v, o.gen, ok
+}
+
+// Len returns the number of objects in the cache.
+func (c *ObjectCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.objs)
+}
+
+// Range invokes fn for each object in the cache until fn returns false. The
+// cache must not be modified by fn.
+func (c *ObjectCache[K, V]) Range(fn func(k K, v V, gen Generation) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, o := range c.objs {
+		if !fn(k, o.v, o.gen) {
+			return
+		}
+	}
+}
+
+// Generation returns the current dump generation of the cache, which is 0
+// until the cache is first loaded.
+func (c *ObjectCache[K, V]) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dump
+}
+
+// Invalidate marks the cache as stale because of err, such as after an
+// Event with a Gap or an inconsistency observed by the application. The
+// first error since the cache was loaded is retained.
+func (c *ObjectCache[K, V]) Invalidate(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.invalidate(err)
+}
+
+// invalidate implements Invalidate. c.mu must be held.
+func (c *ObjectCache[K, V]) invalidate(err error) {
+	if err == nil {
+		err = errors.New("genetlink: object cache invalidated")
+	}
+
+	c.invalidations++
+	if c.stale == nil {
+		c.stale = err
+	}
+}
+
+// Stale returns a non-nil error describing why the cache may be
+// inconsistent with the kernel and should be reloaded, or nil if it is
+// believed to be consistent.
+func (c *ObjectCache[K, V]) Stale() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stale
+}
@@ -0,0 +1,150 @@
+package genetlink_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+)
+
+func TestObjectCache(t *testing.T) {
+	c := genetlink.NewObjectCache[uint32, string]()
+	if c.Stale() == nil {
+		t.Fatal("expected an empty cache to be stale")
+	}
+
+	var dumps int
+	dump := func() (map[uint32]string, error) {
+		dumps++
+		return map[uint32]string{1: "lo", 2: "eth0"}, nil
+	}
+
+	refresh := func(want bool) {
+		t.Helper()
+
+		ok, err := c.Refresh(dump)
+		if err != nil {
+			t.Fatalf("failed to refresh: %v", err)
+		}
+		if diff := cmp.Diff(want, ok); diff != "" {
+			t.Fatalf("unexpected refresh (-want +got):\n%s", diff)
+		}
+	}
+
+	refresh(true)
+	if err := c.Stale(); err != nil {
+		t.Fatalf("cache is stale after loading: %v", err)
+	}
+
+	// A consistent cache is not dumped again.
+	refresh(false)
+
+	// Events update and remove objects.
+	if diff := cmp.Diff(genetlink.Generation{Dump: 1, Seq: 1}, c.Update(2, "eth1")); diff != "" {
+		t.Fatalf("unexpected update generation (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(genetlink.Generation{Dump: 1}, c.Update(3, "wlan0")); diff != "" {
+		t.Fatalf("unexpected create generation (-want +got):\n%s", diff)
+	}
+	if !c.Remove(1) {
+		t.Fatal("failed to remove cached object")
+	}
+
+	v, gen, ok := c.Get(2)
+	if !ok {
+		t.Fatal("object 2 is not cached")
+	}
+	if diff := cmp.Diff("eth1", v); diff != "" {
+		t.Fatalf("unexpected object (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(genetlink.Generation{Dump: 1, Seq: 1}, gen); diff != "" {
+		t.Fatalf("unexpected generation (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(2, c.Len()); diff != "" {
+		t.Fatalf("unexpected number of objects (-want +got):\n%s", diff)
+	}
+
+	// Removing an unknown object indicates missed events.
+	if c.Remove(1) {
+		t.Fatal("removed an object which is not cached")
+	}
+	if c.Stale() == nil {
+		t.Fatal("expected the cache to be stale")
+	}
+
+	refresh(true)
+
+	got := make(map[uint32]genetlink.Generation)
+	c.Range(func(k uint32, _ string, gen genetlink.Generation) bool {
+		got[k] = gen
+		return true
+	})
+
+	want := map[uint32]genetlink.Generation{
+		1: {Dump: 2},
+		2: {Dump: 2},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected generations (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(2, dumps); diff != "" {
+		t.Fatalf("unexpected number of dumps (-want +got):\n%s", diff)
+	}
+}
+
+func TestObjectCacheInvalidate(t *testing.T) {
+	c := genetlink.NewObjectCache[uint32, string]()
+	c.Load(nil)
+
+	errGap := errors.New("gap")
+	c.Invalidate(errGap)
+	c.Invalidate(errors.New("other"))
+
+	// The first reason is retained.
+	if err := c.Stale(); !errors.Is(err, errGap) {
+		t.Fatalf("expected gap error, but got: %v", err)
+	}
+
+	// A failed dump leaves the cache stale.
+	errDump := errors.New("dump")
+	ok, err := c.Refresh(func() (map[uint32]string, error) {
+		return nil, errDump
+	})
+	if ok || !errors.Is(err, errDump) {
+		t.Fatalf("unexpected refresh: %v, %v", ok, err)
+	}
+
+	if c.Stale() == nil {
+		t.Fatal("expected the cache to be stale")
+	}
+
+	if diff := cmp.Diff(uint64(1), c.Generation()); diff != "" {
+		t.Fatalf("unexpected generation (-want +got):\n%s", diff)
+	}
+}
+
+func TestObjectCacheRefreshInvalidated(t *testing.T) {
+	c := genetlink.NewObjectCache[uint32, string]()
+
+	// The cache is invalidated while the dump is in progress, so it must
+	// remain stale once the dump completes.
+	ok, err := c.Refresh(func() (map[uint32]string, error) {
+		c.Invalidate(errors.New("gap"))
+		return map[uint32]string{1: "lo"}, nil
+	})
+	if !ok || err != nil {
+		t.Fatalf("unexpected refresh: %v, %v", ok, err)
+	}
+
+	if c.Stale() == nil {
+		t.Fatal("expected the cache to be stale")
+	}
+
+	if diff := cmp.Diff(1, c.Len()); diff != "" {
+		t.Fatalf("unexpected number of objects (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,274 @@
+// Package genlchaos injects faults into generic netlink operations, so that
+// applications can validate their error handling in staging environments
+// before intermittent failures occur in production.
+//
+// Faults are only injected when Config.Enabled is set, which is intended to
+// be controlled by an explicit flag or configuration value:
+//
+//	nc, err := netlink.Dial(genetlink.Protocol, nil)
+//	if err != nil {
+//		// ...
+//	}
+//
+//	c := genetlink.NewTransportConn(genlchaos.NewTransport(nc, genlchaos.Config{
+//		Enabled:          *chaos,
+//		DelayProbability: 0.05,
+//		MaxDelay:         500 * time.Millisecond,
+//		ErrorProbability: 0.01,
+//	}))
+package genlchaos
+
+import (
+	"math/rand"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"golang.org/x/net/bpf"
+)
+
+// DefaultErrors are the transient errors injected when Config.Errors is
+// empty.
+var DefaultErrors = []error{
+	syscall.EBUSY,
+	syscall.EAGAIN,
+	syscall.ENOBUFS,
+}
+
+// A Config configures the faults injected by a Transport.
+type Config struct {
+	// Enabled must be set for any faults to be injected. If Enabled is
+	// false, NewTransport returns its input Transport unmodified.
+	Enabled bool
+
+	// DelayProbability is the probability, from 0 to 1, that an operation
+	// is delayed by a random duration of up to MaxDelay.
+	DelayProbability float64
+	MaxDelay         time.Duration
+
+	// ErrorProbability is the probability, from 0 to 1, that an operation
+	// fails with one of Errors, chosen at random. If Errors is empty,
+	// DefaultErrors are used. Errors are returned within a
+	// *netlink.OpError, as if they were reported by the kernel.
+	ErrorProbability float64
+	Errors           []error
+
+	// Seed seeds the source of randomness, so that a sequence of faults can
+	// be reproduced. If Seed is 0, a seed based on the current time is used.
+	Seed int64
+}
+
+// NewTransport returns a genetlink.Transport which injects faults described
+// by cfg into the Execute and Receive operations of t. Faults are injected
+// before an operation is passed to t, so an injected error leaves any
+// pending replies to be received by a later operation.
+//
+// If cfg.Enabled is false, t is returned unmodified.
+func NewTransport(t genetlink.Transport, cfg Config) genetlink.Transport {
+	if !cfg.Enabled {
+		return t
+	}
+
+	if len(cfg.Errors) == 0 {
+		cfg.Errors = DefaultErrors
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return &transport{
+		t:   t,
+		cfg: cfg,
+		r:   rand.New(rand.NewSource(seed)),
+	}
+}
+
+var _ genetlink.Transport = &transport{}
+
+// A transport is a genetlink.Transport which injects faults.
+type transport struct {
+	t   genetlink.Transport
+	cfg Config
+
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// fault determines the faults to inject into an operation.
+func (t *transport) fault() (time.Duration, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var d time.Duration
+	if t.cfg.MaxDelay > 0 && t.r.Float64() < t.cfg.DelayProbability {
+		d = time.Duration(t.r.Int63n(int64(t.cfg.MaxDelay)))
+	}
+
+	var err error
+	if t.r.Float64() < t.cfg.ErrorProbability {
+		err = t.cfg.Errors[t.r.Intn(len(t.cfg.Errors))]
+	}
+
+	return d, err
+}
+
+// inject injects faults into the operation op, returning an error if the
+// operation should fail.
+func (t *transport) inject(op string) error {
+	d, err := t.fault()
+	if d > 0 {
+		time.Sleep(d)
+	}
+	if err != nil {
+		return &netlink.OpError{Op: op, Err: err}
+	}
+
+	return nil
+}
+
+func (t *transport) Execute(m netlink.Message) ([]netlink.Message, error) {
+	if err := t.inject("receive"); err != nil {
+		return nil, err
+	}
+
+	return t.t.Execute(m)
+}
+
+func (t *transport) Receive() ([]netlink.Message, error) {
+	if err := t.inject("receive"); err != nil {
+		return nil, err
+	}
+
+	return t.t.Receive()
+}
+
+func (t *transport) Close() error { return t.t.Close() }
+
+func (t *transport) Send(m netlink.Message) (netlink.Message, error) {
+	return t.t.Send(m)
+}
+
+func (t *transport) SendMessages(msgs []netlink.Message) ([]netlink.Message, error) {
+	return t.t.SendMessages(msgs)
+}
+
+// The optional methods of genetlink.Transport are forwarded to the
+// underlying Transport when it implements them.
+
+func (t *transport) JoinGroup(group uint32) error {
+	gjl, ok := t.t.(interface{ JoinGroup(group uint32) error })
+	if !ok {
+		return notSupported("join-group")
+	}
+
+	return gjl.JoinGroup(group)
+}
+
+func (t *transport) LeaveGroup(group uint32) error {
+	gjl, ok := t.t.(interface{ LeaveGroup(group uint32) error })
+	if !ok {
+		return notSupported("leave-group")
+	}
+
+	return gjl.LeaveGroup(group)
+}
+
+func (t *transport) SetBPF(filter []bpf.RawInstruction) error {
+	bs, ok := t.t.(interface {
+		SetBPF(filter []bpf.RawInstruction) error
+	})
+	if !ok {
+		return notSupported("set-bpf")
+	}
+
+	return bs.SetBPF(filter)
+}
+
+func (t *transport) RemoveBPF() error {
+	bs, ok := t.t.(interface{ RemoveBPF() error })
+	if !ok {
+		return notSupported("remove-bpf")
+	}
+
+	return bs.RemoveBPF()
+}
+
+func (t *transport) SetOption(option netlink.ConnOption, enable bool) error {
+	s, ok := t.t.(interface {
+		SetOption(option netlink.ConnOption, enable bool) error
+	})
+	if !ok {
+		return notSupported("set-option")
+	}
+
+	return s.SetOption(option, enable)
+}
+
+func (t *transport) SetReadBuffer(bytes int) error {
+	bs, ok := t.t.(interface{ SetReadBuffer(bytes int) error })
+	if !ok {
+		return notSupported("set-read-buffer")
+	}
+
+	return bs.SetReadBuffer(bytes)
+}
+
+func (t *transport) SetWriteBuffer(bytes int) error {
+	bs, ok := t.t.(interface{ SetWriteBuffer(bytes int) error })
+	if !ok {
+		return notSupported("set-write-buffer")
+	}
+
+	return bs.SetWriteBuffer(bytes)
+}
+
+func (t *transport) SyscallConn() (syscall.RawConn, error) {
+	sc, ok := t.t.(interface {
+		SyscallConn() (syscall.RawConn, error)
+	})
+	if !ok {
+		return nil, notSupported("syscall-conn")
+	}
+
+	return sc.SyscallConn()
+}
+
+func (t *transport) SetDeadline(d time.Time) error {
+	ds, ok := t.t.(interface{ SetDeadline(t time.Time) error })
+	if !ok {
+		return notSupported("set-deadline")
+	}
+
+	return ds.SetDeadline(d)
+}
+
+func (t *transport) SetReadDeadline(d time.Time) error {
+	ds, ok := t.t.(interface{ SetReadDeadline(t time.Time) error })
+	if !ok {
+		return notSupported("set-read-deadline")
+	}
+
+	return ds.SetReadDeadline(d)
+}
+
+func (t *transport) SetWriteDeadline(d time.Time) error {
+	ds, ok := t.t.(interface{ SetWriteDeadline(t time.Time) error })
+	if !ok {
+		return notSupported("set-write-deadline")
+	}
+
+	return ds.SetWriteDeadline(d)
+}
+
+// notSupported returns an error for an operation which the underlying
+// Transport does not support.
+func notSupported(op string) error {
+	return &netlink.OpError{
+		Op:  op,
+		Err: genetlink.ErrNotSupported,
+	}
+}
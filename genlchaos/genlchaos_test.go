@@ -0,0 +1,144 @@
+package genlchaos_test
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genlchaos"
+	"github.com/mdlayher/netlink"
+)
+
+func TestNewTransportDisabled(t *testing.T) {
+	var et echoTransport
+	if got := genlchaos.NewTransport(et, genlchaos.Config{ErrorProbability: 1}); got != et {
+		t.Fatalf("disabled transport was wrapped: %#v", got)
+	}
+}
+
+func TestTransportErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  genlchaos.Config
+		errs int
+	}{
+		{
+			name: "never",
+			cfg:  genlchaos.Config{Enabled: true},
+		},
+		{
+			name: "always",
+			cfg: genlchaos.Config{
+				Enabled:          true,
+				ErrorProbability: 1,
+				Errors:           []error{syscall.EBUSY},
+			},
+			errs: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := genetlink.NewTransportConn(genlchaos.NewTransport(echoTransport{}, tt.cfg))
+			defer c.Close()
+
+			var errs int
+			for i := 0; i < 10; i++ {
+				_, err := c.Execute(genetlink.Message{}, 0x20, netlink.Request)
+				if err == nil {
+					continue
+				}
+
+				var oerr *netlink.OpError
+				if !errors.As(err, &oerr) || !errors.Is(err, syscall.EBUSY) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				errs++
+			}
+
+			if diff := cmp.Diff(tt.errs, errs); diff != "" {
+				t.Fatalf("unexpected number of errors (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTransportSeed(t *testing.T) {
+	cfg := genlchaos.Config{
+		Enabled:          true,
+		ErrorProbability: 0.5,
+		Seed:             1,
+	}
+
+	// The same seed produces the same sequence of faults.
+	run := func() []string {
+		c := genetlink.NewTransportConn(genlchaos.NewTransport(echoTransport{}, cfg))
+		defer c.Close()
+
+		var out []string
+		for i := 0; i < 20; i++ {
+			_, _, err := c.Receive()
+			if err != nil {
+				out = append(out, err.Error())
+			} else {
+				out = append(out, "ok")
+			}
+		}
+
+		return out
+	}
+
+	if diff := cmp.Diff(run(), run()); diff != "" {
+		t.Fatalf("unexpected faults (-want +got):\n%s", diff)
+	}
+}
+
+func TestTransportDelay(t *testing.T) {
+	c := genetlink.NewTransportConn(genlchaos.NewTransport(echoTransport{}, genlchaos.Config{
+		Enabled:          true,
+		DelayProbability: 1,
+		MaxDelay:         10 * time.Millisecond,
+	}))
+	defer c.Close()
+
+	for i := 0; i < 5; i++ {
+		if _, err := c.Execute(genetlink.Message{}, 0x20, netlink.Request); err != nil {
+			t.Fatalf("failed to execute: %v", err)
+		}
+	}
+}
+
+func TestTransportNotSupported(t *testing.T) {
+	c := genetlink.NewTransportConn(genlchaos.NewTransport(echoTransport{}, genlchaos.Config{
+		Enabled: true,
+	}))
+	defer c.Close()
+
+	if err := c.JoinGroup(1); !errors.Is(err, genetlink.ErrNotSupported) {
+		t.Fatalf("expected ErrNotSupported, but got: %v", err)
+	}
+}
+
+var _ genetlink.Transport = echoTransport{}
+
+// echoTransport is a Transport which replies to each request with a copy of
+// the request, and to each receive with no messages.
+type echoTransport struct{}
+
+func (echoTransport) Close() error { return nil }
+
+func (echoTransport) Send(m netlink.Message) (netlink.Message, error) { return m, nil }
+
+func (echoTransport) SendMessages(msgs []netlink.Message) ([]netlink.Message, error) {
+	return msgs, nil
+}
+
+func (echoTransport) Receive() ([]netlink.Message, error) { return nil, nil }
+
+func (echoTransport) Execute(m netlink.Message) ([]netlink.Message, error) {
+	return []netlink.Message{m}, nil
+}
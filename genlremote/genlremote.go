@@ -0,0 +1,334 @@
+// Package genlremote forwards generic netlink operations to an agent running
+// on a remote host, so that fleet tooling can use the genetlink.Conn API to
+// query families such as nl80211, ethtool, and devlink on many machines.
+//
+// The agent, provided by the Serve function and the genlagent command, speaks
+// a small framed protocol over any byte stream. The most common transport is
+// SSH, using the agent's standard input and output:
+//
+//	c, err := genlremote.DialCommand(exec.Command("ssh", "host", "genlagent"))
+//
+// Netlink messages are forwarded as-is, and are encoded using the byte order
+// of the remote host. The local and remote hosts must share the same byte
+// order.
+package genlremote
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/internal/nlmsg"
+	"github.com/mdlayher/netlink"
+)
+
+// Protocol operations. Requests are sent by the client and replies are sent
+// by the agent with the same frame ID.
+const (
+	opHello   = 0x01
+	opSend    = 0x02
+	opReceive = 0x03
+	opJoin    = 0x04
+	opLeave   = 0x05
+
+	opOK    = 0x80
+	opError = 0x81
+)
+
+const (
+	// frameHeaderLen is the length of a frame's ID and operation fields.
+	frameHeaderLen = 5
+
+	// maxFrameLen bounds the size of a single frame.
+	maxFrameLen = 16 * 1024 * 1024
+)
+
+var (
+	// errClosed is returned when operations are attempted on a closed client.
+	errClosed = errors.New("genlremote: connection closed")
+
+	// errFrameTooLarge is returned when a peer sends an oversized frame.
+	errFrameTooLarge = errors.New("genlremote: frame exceeds maximum length")
+)
+
+// A frame is a single protocol message.
+type frame struct {
+	ID      uint32
+	Op      uint8
+	Payload []byte
+}
+
+// readFrame reads a single frame from r.
+func readFrame(r io.Reader) (frame, error) {
+	var lb [4]byte
+	if _, err := io.ReadFull(r, lb[:]); err != nil {
+		return frame{}, err
+	}
+
+	n := binary.BigEndian.Uint32(lb[:])
+	if n < frameHeaderLen {
+		return frame{}, fmt.Errorf("genlremote: frame too short: %d bytes", n)
+	}
+	if n > maxFrameLen {
+		return frame{}, errFrameTooLarge
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return frame{}, err
+	}
+
+	return frame{
+		ID:      binary.BigEndian.Uint32(b[0:4]),
+		Op:      b[4],
+		Payload: b[frameHeaderLen:],
+	}, nil
+}
+
+// writeFrame writes a single frame to w.
+func writeFrame(w io.Writer, f frame) error {
+	b := make([]byte, 4+frameHeaderLen+len(f.Payload))
+	binary.BigEndian.PutUint32(b[0:4], uint32(frameHeaderLen+len(f.Payload)))
+	binary.BigEndian.PutUint32(b[4:8], f.ID)
+	b[8] = f.Op
+	copy(b[9:], f.Payload)
+
+	_, err := w.Write(b)
+	return err
+}
+
+// errorPayload encodes err as an error reply payload. System call errors
+// retain their error number so they can be inspected by the client.
+func errorPayload(err error) []byte {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		errno = 0
+	}
+
+	b := make([]byte, 4, 4+len(err.Error()))
+	binary.BigEndian.PutUint32(b, uint32(errno))
+	return append(b, err.Error()...)
+}
+
+// payloadError decodes an error reply payload.
+func payloadError(b []byte) error {
+	if len(b) < 4 {
+		return errors.New("genlremote: malformed error reply")
+	}
+
+	if errno := syscall.Errno(binary.BigEndian.Uint32(b[0:4])); errno != 0 {
+		return errno
+	}
+
+	return fmt.Errorf("genlremote: remote error: %s", b[4:])
+}
+
+// DialCommand starts cmd, which must run an agent that communicates using its
+// standard input and output, and returns a Conn which forwards operations to
+// the agent. The command is stopped when the Conn is closed.
+func DialCommand(cmd *exec.Cmd) (*genetlink.Conn, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	rwc := &cmdPipe{
+		WriteCloser: stdin,
+		Reader:      stdout,
+		cmd:         cmd,
+	}
+
+	c, err := NewConn(rwc)
+	if err != nil {
+		_ = rwc.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// A cmdPipe is an io.ReadWriteCloser using a command's standard input and
+// output.
+type cmdPipe struct {
+	io.WriteCloser
+	io.Reader
+	cmd *exec.Cmd
+}
+
+// Close closes the command's standard input and waits for it to exit.
+func (p *cmdPipe) Close() error {
+	err := p.WriteCloser.Close()
+	_ = p.cmd.Process.Kill()
+	_ = p.cmd.Wait()
+	return err
+}
+
+// NewConn returns a Conn which forwards operations to an agent using rwc.
+// rwc is closed when the Conn is closed.
+func NewConn(rwc io.ReadWriteCloser) (*genetlink.Conn, error) {
+	c := &client{
+		rwc:     rwc,
+		pending: make(map[uint32]chan frame),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop()
+
+	// The agent replies to hello with the port ID of its netlink socket.
+	b, err := c.do(opHello, nil)
+	if err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	if len(b) != 4 {
+		_ = c.Close()
+		return nil, errors.New("genlremote: malformed hello reply")
+	}
+
+	return genetlink.NewConn(netlink.NewConn(c, binary.BigEndian.Uint32(b))), nil
+}
+
+var _ netlink.Socket = &client{}
+
+// A client is a netlink.Socket which forwards operations to an agent.
+type client struct {
+	rwc io.ReadWriteCloser
+
+	// wmu serializes frame writes.
+	wmu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  uint32
+	pending map[uint32]chan frame
+	err     error
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// readLoop delivers reply frames to their waiting callers until the
+// connection is closed.
+func (c *client) readLoop() {
+	for {
+		f, err := readFrame(c.rwc)
+		if err != nil {
+			c.mu.Lock()
+			c.err = err
+			c.mu.Unlock()
+
+			c.closeOnce.Do(func() { close(c.done) })
+			return
+		}
+
+		c.mu.Lock()
+		ch, ok := c.pending[f.ID]
+		delete(c.pending, f.ID)
+		c.mu.Unlock()
+
+		if ok {
+			ch <- f
+		}
+	}
+}
+
+// do performs a single request/reply exchange with the agent.
+func (c *client) do(op uint8, payload []byte) ([]byte, error) {
+	ch := make(chan frame, 1)
+
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	c.wmu.Lock()
+	err := writeFrame(c.rwc, frame{ID: id, Op: op, Payload: payload})
+	c.wmu.Unlock()
+	if err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return nil, err
+	}
+
+	select {
+	case f := <-ch:
+		if f.Op == opError {
+			return nil, payloadError(f.Payload)
+		}
+
+		return f.Payload, nil
+	case <-c.done:
+		return nil, errClosed
+	}
+}
+
+// Close implements netlink.Socket.
+func (c *client) Close() error {
+	err := c.rwc.Close()
+	c.closeOnce.Do(func() { close(c.done) })
+	return err
+}
+
+// Send implements netlink.Socket.
+func (c *client) Send(m netlink.Message) error {
+	return c.SendMessages([]netlink.Message{m})
+}
+
+// SendMessages implements netlink.Socket.
+func (c *client) SendMessages(msgs []netlink.Message) error {
+	var b []byte
+	for _, m := range msgs {
+		mb, err := m.MarshalBinary()
+		if err != nil {
+			return err
+		}
+
+		b = append(b, mb...)
+	}
+
+	_, err := c.do(opSend, b)
+	return err
+}
+
+// Receive implements netlink.Socket.
+func (c *client) Receive() ([]netlink.Message, error) {
+	b, err := c.do(opReceive, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return nlmsg.Parse(b)
+}
+
+// JoinGroup joins a multicast group on the agent's socket.
+func (c *client) JoinGroup(group uint32) error {
+	_, err := c.do(opJoin, uint32Bytes(group))
+	return err
+}
+
+// LeaveGroup leaves a multicast group on the agent's socket.
+func (c *client) LeaveGroup(group uint32) error {
+	_, err := c.do(opLeave, uint32Bytes(group))
+	return err
+}
+
+// uint32Bytes encodes v as big endian bytes.
+func uint32Bytes(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
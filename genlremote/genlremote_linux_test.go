@@ -0,0 +1,49 @@
+//go:build linux
+// +build linux
+
+package genlremote_test
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/mdlayher/genetlink/genlremote"
+)
+
+func TestIntegrationConnGetFamily(t *testing.T) {
+	client, server := net.Pipe()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- genlremote.Serve(server)
+	}()
+
+	c, err := genlremote.NewConn(client)
+	if err != nil {
+		t.Fatalf("failed to create remote connection: %v", err)
+	}
+
+	f, err := c.GetFamily("nlctrl")
+	if err != nil {
+		t.Fatalf("failed to get family: %v", err)
+	}
+
+	if f.Name != "nlctrl" || f.ID != 0x10 {
+		t.Fatalf("unexpected family: %+v", f)
+	}
+
+	// Errors from the remote kernel must be inspectable locally.
+	if _, err := c.GetFamily("genlremote-nope"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not exist error, but got: %v", err)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("failed to close connection: %v", err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("failed to serve: %v", err)
+	}
+}
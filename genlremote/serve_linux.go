@@ -0,0 +1,123 @@
+//go:build linux
+// +build linux
+
+package genlremote
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/socket"
+	"golang.org/x/sys/unix"
+)
+
+// Serve runs an agent which performs generic netlink operations on behalf of
+// a client connected via rw. Serve opens its own generic netlink socket and
+// returns when rw reaches EOF or an error occurs.
+func Serve(rw io.ReadWriter) error {
+	c, err := socket.Socket(unix.AF_NETLINK, unix.SOCK_RAW, genetlink.Protocol, "netlink", nil)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(&unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		_ = c.Close()
+		return err
+	}
+
+	sa, err := c.Getsockname()
+	if err != nil {
+		_ = c.Close()
+		return err
+	}
+	pid := sa.(*unix.SockaddrNetlink).Pid
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var (
+		wmu sync.Mutex
+		wg  sync.WaitGroup
+	)
+	defer func() {
+		// Unblock any handlers waiting on the socket, such as a pending
+		// receive, before waiting for them to finish.
+		cancel()
+		_ = c.Close()
+		wg.Wait()
+	}()
+
+	reply := func(f frame) {
+		wmu.Lock()
+		defer wmu.Unlock()
+		_ = writeFrame(rw, f)
+	}
+
+	for {
+		f, err := readFrame(rw)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		// Handle each request concurrently so that a blocking receive does
+		// not prevent sends or multicast group changes.
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			b, err := handle(ctx, c, pid, f)
+			if err != nil {
+				reply(frame{ID: f.ID, Op: opError, Payload: errorPayload(err)})
+				return
+			}
+
+			reply(frame{ID: f.ID, Op: opOK, Payload: b})
+		}()
+	}
+}
+
+// handle performs the operation requested by f using socket c.
+func handle(ctx context.Context, c *socket.Conn, pid uint32, f frame) ([]byte, error) {
+	switch f.Op {
+	case opHello:
+		return uint32Bytes(pid), nil
+	case opSend:
+		return nil, c.Sendto(ctx, f.Payload, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK})
+	case opReceive:
+		// Peek at the size of the next datagram so the whole message can be
+		// read at once.
+		n, _, err := c.Recvfrom(ctx, nil, unix.MSG_PEEK|unix.MSG_TRUNC)
+		if err != nil {
+			return nil, err
+		}
+
+		b := make([]byte, n)
+		n, _, err = c.Recvfrom(ctx, b, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return b[:n], nil
+	case opJoin, opLeave:
+		if len(f.Payload) != 4 {
+			return nil, errors.New("genlremote: malformed multicast group request")
+		}
+
+		opt := unix.NETLINK_ADD_MEMBERSHIP
+		if f.Op == opLeave {
+			opt = unix.NETLINK_DROP_MEMBERSHIP
+		}
+
+		return nil, c.SetsockoptInt(unix.SOL_NETLINK, opt, int(binary.BigEndian.Uint32(f.Payload)))
+	default:
+		return nil, os.ErrInvalid
+	}
+}
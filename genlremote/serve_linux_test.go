@@ -0,0 +1,38 @@
+//go:build linux
+// +build linux
+
+package genlremote
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestServeClosePendingReceive(t *testing.T) {
+	client, server := net.Pipe()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(server)
+	}()
+
+	// Nothing is sent to the agent's socket, so this receive blocks until
+	// Serve shuts down.
+	if err := writeFrame(client, frame{ID: 1, Op: opReceive}); err != nil {
+		t.Fatalf("failed to write receive frame: %v", err)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("failed to close client: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("failed to serve: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return with a pending receive")
+	}
+}
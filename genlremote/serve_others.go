@@ -0,0 +1,20 @@
+//go:build !linux
+// +build !linux
+
+package genlremote
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// errUnimplemented is returned by Serve on platforms that cannot make use of
+// generic netlink.
+var errUnimplemented = fmt.Errorf("genlremote agent not implemented on %s/%s",
+	runtime.GOOS, runtime.GOARCH)
+
+// Serve always returns an error.
+func Serve(_ io.ReadWriter) error {
+	return errUnimplemented
+}
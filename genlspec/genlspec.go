@@ -0,0 +1,260 @@
+// Package genlspec generates netlink specification documents from live
+// generic netlink family information.
+//
+// The generated documents follow the YAML format used by the Linux kernel's
+// Documentation/netlink/specs directory. Because the kernel does not report
+// symbolic names for operations and attributes, placeholder names derived from
+// their numeric values are used, and can be edited by hand afterward.
+//
+// Package genlspec also generates Go constants with String methods for the
+// commands and attributes named by a genetlink.Schema.
+package genlspec
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mdlayher/genetlink"
+)
+
+// Marshal produces a YAML netlink specification document for Family f using
+// the attribute policies in p. p may be the zero value if policy information
+// is unavailable, in which case no attribute sets are generated.
+func Marshal(f genetlink.Family, p genetlink.Policy) ([]byte, error) {
+	var b bytes.Buffer
+	if err := Encode(&b, f, p); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// Encode writes a YAML netlink specification document for Family f using
+// the attribute policies in p to w. See Marshal for details.
+func Encode(w io.Writer, f genetlink.Family, p genetlink.Policy) error {
+	e := &encoder{w: w}
+
+	e.line(0, "name: %s", f.Name)
+	e.line(0, "protocol: genetlink")
+	e.line(0, "doc: Generated from live kernel family %q (ID %d, version %d).", f.Name, f.ID, f.Version)
+
+	if len(p.Sets) > 0 {
+		e.line(0, "")
+		e.line(0, "attribute-sets:")
+		for _, s := range p.Sets {
+			e.line(1, "- name: %s", setName(s.Index))
+			e.line(2, "attributes:")
+			for _, a := range s.Attributes {
+				e.attribute(a)
+			}
+		}
+	}
+
+	if len(f.Ops) > 0 {
+		e.line(0, "")
+		e.line(0, "operations:")
+		e.line(1, "list:")
+		for _, o := range f.Ops {
+			e.operation(o, p)
+		}
+	}
+
+	if len(f.Groups) > 0 {
+		e.line(0, "")
+		e.line(0, "mcast-groups:")
+		e.line(1, "list:")
+		for _, g := range f.Groups {
+			e.line(2, "- name: %s", g.Name)
+		}
+	}
+
+	return e.err
+}
+
+// An encoder writes indented YAML lines to an io.Writer, retaining the first
+// error which occurs.
+type encoder struct {
+	w   io.Writer
+	err error
+}
+
+// line writes a single line with the specified indentation level.
+func (e *encoder) line(indent int, format string, v ...interface{}) {
+	if e.err != nil {
+		return
+	}
+
+	s := fmt.Sprintf(format, v...)
+	if s != "" {
+		s = strings.Repeat("  ", indent) + s
+	}
+
+	_, e.err = io.WriteString(e.w, s+"\n")
+}
+
+// attribute writes the specification for a single attribute policy.
+func (e *encoder) attribute(a genetlink.AttributePolicy) {
+	e.line(3, "- name: %s", attrName(a.Type))
+	e.line(4, "value: %d", a.Type)
+	e.line(4, "type: %s", kindName(a.Kind))
+
+	switch a.Kind {
+	case genetlink.AttributeNested:
+		e.line(4, "nested-attributes: %s", setName(a.NestedIndex))
+	case genetlink.AttributeNestedArray:
+		e.line(4, "sub-type: nest")
+		e.line(4, "nested-attributes: %s", setName(a.NestedIndex))
+	}
+
+	var checks []string
+	switch a.Kind {
+	case genetlink.AttributeU8, genetlink.AttributeU16, genetlink.AttributeU32, genetlink.AttributeU64:
+		if a.MinUnsigned != 0 || a.MaxUnsigned != 0 {
+			checks = append(checks,
+				fmt.Sprintf("min: %d", a.MinUnsigned),
+				fmt.Sprintf("max: %d", a.MaxUnsigned),
+			)
+		}
+	case genetlink.AttributeS8, genetlink.AttributeS16, genetlink.AttributeS32, genetlink.AttributeS64:
+		if a.MinSigned != 0 || a.MaxSigned != 0 {
+			checks = append(checks,
+				fmt.Sprintf("min: %d", a.MinSigned),
+				fmt.Sprintf("max: %d", a.MaxSigned),
+			)
+		}
+	case genetlink.AttributeBinary, genetlink.AttributeString, genetlink.AttributeNulString:
+		if a.MinLength != 0 {
+			checks = append(checks, fmt.Sprintf("min-len: %d", a.MinLength))
+		}
+		if a.MaxLength != 0 {
+			checks = append(checks, fmt.Sprintf("max-len: %d", a.MaxLength))
+		}
+	}
+
+	if len(checks) > 0 {
+		e.line(4, "checks:")
+		for _, c := range checks {
+			e.line(5, "%s", c)
+		}
+	}
+}
+
+// operation writes the specification for a single operation.
+func (e *encoder) operation(o genetlink.Op, p genetlink.Policy) {
+	e.line(2, "- name: %s", opName(o.ID))
+	e.line(3, "value: %d", o.ID)
+
+	var flags []string
+	if o.Flags&genetlink.OpAdminPerm != 0 {
+		flags = append(flags, "admin-perm")
+	}
+	if o.Flags&genetlink.OpUnsAdminPerm != 0 {
+		flags = append(flags, "uns-admin-perm")
+	}
+	if len(flags) > 0 {
+		e.line(3, "flags: [ %s ]", strings.Join(flags, ", "))
+	}
+
+	op := opPolicy(o.ID, p)
+
+	// Prefer the "do" policy as the operation's attribute set, and fall back
+	// to the "dump" policy.
+	set := op.Do
+	if set < 0 {
+		set = op.Dump
+	}
+	if set >= 0 {
+		e.line(3, "attribute-set: %s", setName(uint32(set)))
+	}
+
+	if o.Flags&genetlink.OpCapDo != 0 {
+		e.request("do", op.Do, p)
+	}
+	if o.Flags&genetlink.OpCapDump != 0 {
+		e.request("dump", op.Dump, p)
+	}
+}
+
+// request writes the request attributes for an operation of the specified
+// kind, using the PolicySet with the specified index.
+func (e *encoder) request(kind string, index int, p genetlink.Policy) {
+	e.line(3, "%s:", kind)
+
+	var s genetlink.PolicySet
+	if index >= 0 {
+		s, _ = p.Set(uint32(index))
+	}
+
+	if len(s.Attributes) == 0 {
+		e.line(4, "request: {}")
+		return
+	}
+
+	e.line(4, "request:")
+	e.line(5, "attributes:")
+	for _, a := range s.Attributes {
+		e.line(6, "- %s", attrName(a.Type))
+	}
+}
+
+// opPolicy returns the OpPolicy for the operation with the specified ID, or
+// an OpPolicy with no policy sets if none exists.
+func opPolicy(id uint32, p genetlink.Policy) genetlink.OpPolicy {
+	for _, op := range p.Ops {
+		if op.Command == id {
+			return op
+		}
+	}
+
+	return genetlink.OpPolicy{
+		Command: id,
+		Do:      -1,
+		Dump:    -1,
+	}
+}
+
+// setName returns the placeholder name for a policy set.
+func setName(index uint32) string { return fmt.Sprintf("policy-%d", index) }
+
+// attrName returns the placeholder name for an attribute.
+func attrName(typ uint16) string { return fmt.Sprintf("attr-%d", typ) }
+
+// opName returns the placeholder name for an operation.
+func opName(id uint32) string { return fmt.Sprintf("op-%d", id) }
+
+// kindName returns the netlink specification type name for an AttributeKind.
+func kindName(k genetlink.AttributeKind) string {
+	switch k {
+	case genetlink.AttributeFlag:
+		return "flag"
+	case genetlink.AttributeU8:
+		return "u8"
+	case genetlink.AttributeU16:
+		return "u16"
+	case genetlink.AttributeU32:
+		return "u32"
+	case genetlink.AttributeU64:
+		return "u64"
+	case genetlink.AttributeS8:
+		return "s8"
+	case genetlink.AttributeS16:
+		return "s16"
+	case genetlink.AttributeS32:
+		return "s32"
+	case genetlink.AttributeS64:
+		return "s64"
+	case genetlink.AttributeString, genetlink.AttributeNulString:
+		return "string"
+	case genetlink.AttributeNested:
+		return "nest"
+	case genetlink.AttributeNestedArray:
+		return "indexed-array"
+	case genetlink.AttributeBitfield32:
+		return "bitfield32"
+	default:
+		// Invalid and binary attributes are both treated as opaque bytes.
+		return "binary"
+	}
+}
@@ -0,0 +1,144 @@
+package genlspec_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genlspec"
+)
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		f    genetlink.Family
+		p    genetlink.Policy
+		out  string
+	}{
+		{
+			name: "no policy",
+			f: genetlink.Family{
+				ID:      0x20,
+				Version: 1,
+				Name:    "foo",
+			},
+			out: `name: foo
+protocol: genetlink
+doc: Generated from live kernel family "foo" (ID 32, version 1).
+`,
+		},
+		{
+			name: "full",
+			f: genetlink.Family{
+				ID:      0x20,
+				Version: 2,
+				Name:    "foo",
+				Groups: []genetlink.MulticastGroup{{
+					ID:   0x21,
+					Name: "events",
+				}},
+				Ops: []genetlink.Op{
+					{
+						ID:    1,
+						Flags: genetlink.OpCapDo | genetlink.OpAdminPerm,
+					},
+					{
+						ID:    2,
+						Flags: genetlink.OpCapDump,
+					},
+				},
+			},
+			p: genetlink.Policy{
+				Sets: []genetlink.PolicySet{
+					{
+						Index: 0,
+						Attributes: []genetlink.AttributePolicy{
+							{
+								Type:        1,
+								Kind:        genetlink.AttributeU32,
+								MinUnsigned: 1,
+								MaxUnsigned: 10,
+							},
+							{
+								Type:        2,
+								Kind:        genetlink.AttributeNested,
+								NestedIndex: 1,
+							},
+						},
+					},
+					{
+						Index: 1,
+						Attributes: []genetlink.AttributePolicy{{
+							Type:      1,
+							Kind:      genetlink.AttributeString,
+							MaxLength: 15,
+						}},
+					},
+				},
+				Ops: []genetlink.OpPolicy{{
+					Command: 1,
+					Do:      0,
+					Dump:    -1,
+				}},
+			},
+			out: `name: foo
+protocol: genetlink
+doc: Generated from live kernel family "foo" (ID 32, version 2).
+
+attribute-sets:
+  - name: policy-0
+    attributes:
+      - name: attr-1
+        value: 1
+        type: u32
+        checks:
+          min: 1
+          max: 10
+      - name: attr-2
+        value: 2
+        type: nest
+        nested-attributes: policy-1
+  - name: policy-1
+    attributes:
+      - name: attr-1
+        value: 1
+        type: string
+        checks:
+          max-len: 15
+
+operations:
+  list:
+    - name: op-1
+      value: 1
+      flags: [ admin-perm ]
+      attribute-set: policy-0
+      do:
+        request:
+          attributes:
+            - attr-1
+            - attr-2
+    - name: op-2
+      value: 2
+      dump:
+        request: {}
+
+mcast-groups:
+  list:
+    - name: events
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := genlspec.Marshal(tt.f, tt.p)
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.out, string(b)); diff != "" {
+				t.Fatalf("unexpected specification (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
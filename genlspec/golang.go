@@ -0,0 +1,183 @@
+package genlspec
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mdlayher/genetlink"
+)
+
+// MarshalGo produces Go source code for package pkg which declares the
+// commands and attributes named by Schema s as constants of the types Command
+// and Attribute, with String methods which return their names, so that logs
+// and trace output show "NL80211_CMD_GET_INTERFACE" rather than "5".
+//
+// The Go identifier of each constant is its name with any characters which
+// are not valid in identifiers replaced by underscores, such as
+// NL80211_CMD_GET_INTERFACE. Names in lower case, as used by netlink
+// specifications, are converted to exported camel case identifiers, such as
+// GetInterface for "get-interface".
+//
+// Only the Schema's shared attributes are generated; the attribute sets of
+// individual commands and nested attributes are not.
+func MarshalGo(pkg string, s genetlink.Schema) ([]byte, error) {
+	var b bytes.Buffer
+	g := &generator{b: &b, seen: make(map[string]bool)}
+
+	g.printf("// Code generated by genlspec. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", pkg)
+	g.printf("import \"strconv\"\n")
+
+	commands := make(map[uint64]string, len(s.Commands))
+	for v, c := range s.Commands {
+		commands[uint64(v)] = c.Name
+	}
+
+	attrs := make(map[uint64]string, len(s.Attributes))
+	for v, a := range s.Attributes {
+		attrs[uint64(v)] = a.Name
+	}
+
+	g.constants("Command", "uint8", "a generic netlink command", commands)
+	g.constants("Attribute", "uint16", "a generic netlink attribute type", attrs)
+
+	if g.err != nil {
+		return nil, g.err
+	}
+
+	out, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("genlspec: failed to format generated code: %v", err)
+	}
+
+	return out, nil
+}
+
+// EncodeGo writes Go source code generated from Schema s for package pkg to
+// w. See MarshalGo for details.
+func EncodeGo(w io.Writer, pkg string, s genetlink.Schema) error {
+	b, err := MarshalGo(pkg, s)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// A generator writes Go source code, retaining the first error which occurs.
+type generator struct {
+	b    *bytes.Buffer
+	seen map[string]bool
+	err  error
+}
+
+// printf writes formatted Go source code.
+func (g *generator) printf(format string, v ...interface{}) {
+	fmt.Fprintf(g.b, format, v...)
+}
+
+// constants writes a named integer type with the specified underlying type,
+// its constants, and its String method.
+func (g *generator) constants(typ, underlying, doc string, names map[uint64]string) {
+	if len(names) == 0 {
+		return
+	}
+
+	values := make([]uint64, 0, len(names))
+	for v := range names {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	g.printf("\n// %s %s is %s.\n", article(typ), typ, doc)
+	g.printf("type %s %s\n\n", typ, underlying)
+
+	g.printf("// Possible %s values.\n", typ)
+	g.printf("const (\n")
+	for _, v := range values {
+		id := identifier(names[v])
+		if id == "" {
+			g.fail(fmt.Errorf("genlspec: %s %d has no name", strings.ToLower(typ), v))
+			continue
+		}
+		if g.seen[id] {
+			g.fail(fmt.Errorf("genlspec: duplicate identifier %q", id))
+			continue
+		}
+		g.seen[id] = true
+
+		g.printf("\t%s %s = %d\n", id, typ, v)
+	}
+	g.printf(")\n\n")
+
+	g.printf("// String returns the name of %s %s.\n", strings.ToLower(article(typ)), typ)
+	g.printf("func (v %s) String() string {\n", typ)
+	g.printf("\tswitch v {\n")
+	for _, v := range values {
+		g.printf("\tcase %d:\n", v)
+		g.printf("\t\treturn %q\n", names[v])
+	}
+	g.printf("\tdefault:\n")
+	g.printf("\t\treturn \"%s(\" + strconv.Itoa(int(v)) + \")\"\n", typ)
+	g.printf("\t}\n")
+	g.printf("}\n")
+}
+
+// fail records err if no error has occurred yet.
+func (g *generator) fail(err error) {
+	if g.err == nil {
+		g.err = err
+	}
+}
+
+// identifier returns an exported Go identifier for name. Names in lower case
+// are converted to camel case, and otherwise, characters which are not valid
+// in identifiers are replaced with underscores.
+func identifier(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	if strings.ToLower(name) == name {
+		var b strings.Builder
+		for _, w := range strings.FieldsFunc(name, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+
+		name = b.String()
+		if name == "" {
+			return ""
+		}
+	}
+
+	id := []rune(name)
+	for i, r := range id {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			id[i] = '_'
+		}
+	}
+
+	if !unicode.IsUpper(id[0]) {
+		// Digits and underscores cannot begin an exported identifier.
+		return "X" + string(id)
+	}
+
+	return string(id)
+}
+
+// article returns the indefinite article for word.
+func article(word string) string {
+	if strings.ContainsRune("AEIOU", rune(word[0])) {
+		return "An"
+	}
+
+	return "A"
+}
@@ -0,0 +1,94 @@
+package genlspec_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genlspec"
+)
+
+func TestMarshalGo(t *testing.T) {
+	s := genetlink.Schema{
+		Commands: map[uint8]genetlink.CommandSchema{
+			5: {Name: "NL80211_CMD_GET_INTERFACE"},
+			1: {Name: "NL80211_CMD_GET_WIPHY"},
+		},
+		Attributes: genetlink.AttributeSet{
+			3: {Name: "NL80211_ATTR_IFINDEX", Kind: genetlink.AttributeU32},
+			4: {Name: "if-name"},
+		},
+	}
+
+	b, err := genlspec.MarshalGo("nl80211", s)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	want := `// Code generated by genlspec. DO NOT EDIT.
+
+package nl80211
+
+import "strconv"
+
+// A Command is a generic netlink command.
+type Command uint8
+
+// Possible Command values.
+const (
+	NL80211_CMD_GET_WIPHY     Command = 1
+	NL80211_CMD_GET_INTERFACE Command = 5
+)
+
+// String returns the name of a Command.
+func (v Command) String() string {
+	switch v {
+	case 1:
+		return "NL80211_CMD_GET_WIPHY"
+	case 5:
+		return "NL80211_CMD_GET_INTERFACE"
+	default:
+		return "Command(" + strconv.Itoa(int(v)) + ")"
+	}
+}
+
+// An Attribute is a generic netlink attribute type.
+type Attribute uint16
+
+// Possible Attribute values.
+const (
+	NL80211_ATTR_IFINDEX Attribute = 3
+	IfName               Attribute = 4
+)
+
+// String returns the name of an Attribute.
+func (v Attribute) String() string {
+	switch v {
+	case 3:
+		return "NL80211_ATTR_IFINDEX"
+	case 4:
+		return "if-name"
+	default:
+		return "Attribute(" + strconv.Itoa(int(v)) + ")"
+	}
+}
+`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Fatalf("unexpected Go source (-want +got):\n%s", diff)
+	}
+}
+
+func TestMarshalGoDuplicate(t *testing.T) {
+	s := genetlink.Schema{
+		Commands: map[uint8]genetlink.CommandSchema{
+			1: {Name: "get"},
+		},
+		Attributes: genetlink.AttributeSet{
+			1: {Name: "get"},
+		},
+	}
+
+	if _, err := genlspec.MarshalGo("foo", s); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+}
@@ -0,0 +1,91 @@
+package genltest
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/internal/endian"
+	"github.com/mdlayher/netlink"
+)
+
+// CheckAlignment returns a Func that verifies that an incoming request is
+// strictly encoded, and then passes the request through to fn. A request is
+// strictly encoded when:
+//   - its netlink header length matches the length of the message
+//   - its body is padded to a multiple of 4 bytes (NLMSG_ALIGNTO)
+//   - each attribute, including nested attributes, is within the bounds of
+//     its enclosing data and is followed by zeroed padding to a multiple of
+//     4 bytes (NLA_ALIGNTO)
+//
+// Requests which are not strictly encoded fail with an error describing the
+// problem. CheckAlignment catches encodings which happen to work with a
+// lenient decoder, but which a stricter kernel or a different architecture
+// would reject.
+func CheckAlignment(fn Func) Func {
+	return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		// Multicast interactions have no request to check.
+		if nreq.Header == (netlink.Header{}) && len(nreq.Data) == 0 {
+			return fn(greq, nreq)
+		}
+
+		if want, got := nlmsgHeaderLen+len(nreq.Data), int(nreq.Header.Length); want != got {
+			return nil, fmt.Errorf("genltest: unexpected netlink header length: %d, want: %d", got, want)
+		}
+
+		if l := len(nreq.Data); l != nlmsgAlign(l) {
+			return nil, fmt.Errorf("genltest: netlink message body length %d is not aligned", l)
+		}
+
+		// Skip the generic netlink header to check the attributes.
+		const genlHeaderLen = 4
+		if len(nreq.Data) > genlHeaderLen {
+			if err := checkAttributeAlignment(nreq.Data[genlHeaderLen:], genlHeaderLen); err != nil {
+				return nil, err
+			}
+		}
+
+		return fn(greq, nreq)
+	}
+}
+
+// checkAttributeAlignment verifies the encoding of the packed attributes in
+// b, which begin at offset bytes into the netlink message body.
+func checkAttributeAlignment(b []byte, offset int) error {
+	const attrHeaderLen = 4
+
+	for i := 0; i < len(b); {
+		if len(b)-i < attrHeaderLen {
+			return fmt.Errorf("genltest: short attribute header at offset %d", offset+i)
+		}
+
+		l := int(endian.Uint16(b[i : i+2]))
+		typ := endian.Uint16(b[i+2 : i+4])
+		if l < attrHeaderLen || i+l > len(b) {
+			return fmt.Errorf("genltest: attribute type %d at offset %d has invalid length: %d",
+				typ&^(netlink.Nested|netlink.NetByteOrder), offset+i, l)
+		}
+
+		end := i + nlmsgAlign(l)
+		if end > len(b) {
+			return fmt.Errorf("genltest: attribute type %d at offset %d is missing padding",
+				typ&^(netlink.Nested|netlink.NetByteOrder), offset+i)
+		}
+
+		for _, p := range b[i+l : end] {
+			if p != 0x00 {
+				return fmt.Errorf("genltest: attribute type %d at offset %d has non-zero padding",
+					typ&^(netlink.Nested|netlink.NetByteOrder), offset+i)
+			}
+		}
+
+		if typ&netlink.Nested != 0 {
+			if err := checkAttributeAlignment(b[i+attrHeaderLen:i+l], offset+i+attrHeaderLen); err != nil {
+				return err
+			}
+		}
+
+		i = end
+	}
+
+	return nil
+}
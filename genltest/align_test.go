@@ -0,0 +1,94 @@
+package genltest_test
+
+import (
+	"testing"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nltest"
+)
+
+func TestCheckAlignment(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		ok   bool
+	}{
+		{
+			name: "OK",
+			data: nltest.MustMarshalAttributes([]netlink.Attribute{
+				{Type: 1, Data: []byte{0xff}},
+				{
+					Type: 2 | netlink.Nested,
+					Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+						{Type: 1, Data: []byte{0xff, 0xff}},
+					}),
+				},
+			}),
+			ok: true,
+		},
+		{
+			name: "no attributes",
+			ok:   true,
+		},
+		{
+			name: "missing padding",
+			data: []byte{
+				0x05, 0x00, 0x01, 0x00,
+				0xff,
+				0x04, 0x00, 0x02, 0x00,
+			},
+		},
+		{
+			name: "unaligned body",
+			data: []byte{
+				0x05, 0x00, 0x01, 0x00,
+				0xff,
+			},
+		},
+		{
+			name: "non-zero padding",
+			data: []byte{
+				0x05, 0x00, 0x01, 0x00,
+				0xff, 0xff, 0x00, 0x00,
+			},
+		},
+		{
+			name: "too long",
+			data: []byte{
+				0x0c, 0x00, 0x01, 0x00,
+				0xff, 0xff, 0xff, 0xff,
+			},
+		},
+		{
+			name: "bad nested",
+			data: []byte{
+				0x09, 0x00, 0x01, 0x80,
+				// Nested attribute has no padding within its parent.
+				0x05, 0x00, 0x01, 0x00,
+				0xff, 0x00, 0x00, 0x00,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := genltest.Dial(genltest.CheckAlignment(noop))
+			defer c.Close()
+
+			_, err := c.Send(genetlink.Message{Data: tt.data}, 1, netlink.Request)
+			if err != nil {
+				t.Fatalf("failed to send: %v", err)
+			}
+
+			_, _, err = c.Receive()
+			if err != nil && tt.ok {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err == nil && !tt.ok {
+				t.Fatal("expected an error, but none occurred")
+			}
+		})
+	}
+}
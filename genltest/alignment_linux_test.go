@@ -0,0 +1,47 @@
+//go:build linux
+// +build linux
+
+package genltest
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/netlink/nlenc"
+)
+
+func TestPolicyIntegersUnaligned(t *testing.T) {
+	tests := []struct {
+		name string
+		b    []byte
+		want uint64
+	}{
+		{
+			name: "u16",
+			b:    nlenc.Uint16Bytes(0xfffe),
+			want: 0xfffe,
+		},
+		{
+			name: "u32",
+			b:    nlenc.Uint32Bytes(0xfffffffe),
+			want: 0xfffffffe,
+		},
+		{
+			name: "u64",
+			b:    nlenc.Uint64Bytes(0xfffffffffffffffe),
+			want: 0xfffffffffffffffe,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, unsigned(misalign(tt.b))); diff != "" {
+				t.Fatalf("unexpected unsigned value (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(int64(-2), signed(misalign(tt.b))); diff != "" {
+				t.Fatalf("unexpected signed value (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package genltest
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+)
+
+// The decoding tests in this file feed buffers which begin at odd addresses
+// to each decode path. Decoding which dereferences unaligned pointers, as
+// the integer functions of nlenc do, faults on strict-alignment
+// architectures such as mips, where CI runs these tests.
+
+// misalign returns a copy of b which begins at an odd address.
+func misalign(b []byte) []byte {
+	buf := make([]byte, len(b)+1)
+	copy(buf[1:], b)
+	return buf[1:]
+}
+
+// unalignedAttributes returns packed attributes with values of each integer
+// size.
+func unalignedAttributes(t *testing.T) []byte {
+	t.Helper()
+
+	b, err := netlink.MarshalAttributes([]netlink.Attribute{
+		{Type: 1, Data: []byte{0xff}},
+		{Type: 2, Data: nlenc.Uint16Bytes(0x0102)},
+		{Type: 3, Data: nlenc.Uint32Bytes(0x01020304)},
+		{Type: 4, Data: nlenc.Uint64Bytes(0x0102030405060708)},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal attributes: %v", err)
+	}
+
+	return b
+}
+
+func TestCheckAttributeAlignmentUnaligned(t *testing.T) {
+	if err := checkAttributeAlignment(misalign(unalignedAttributes(t)), 4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestUnpadUnaligned(t *testing.T) {
+	attrs := unalignedAttributes(t)
+	b := append([]byte{0x01, 0x01, 0x00, 0x00}, attrs...)
+
+	// Only the padding following the 1 and 2 byte values is removed.
+	want := append([]byte{0x01, 0x01, 0x00, 0x00}, attrs[:5]...)
+	want = append(want, attrs[8:14]...)
+	want = append(want, attrs[16:]...)
+
+	if diff := cmp.Diff(want, unpad(misalign(b))); diff != "" {
+		t.Fatalf("unexpected data (-want +got):\n%s", diff)
+	}
+}
+
+func TestRecorderErrorUnaligned(t *testing.T) {
+	var r Recorder
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e := r.event("reply", netlink.Message{
+		Header: netlink.Header{Type: netlink.Error},
+		Data:   misalign(nlenc.Int32Bytes(-2)),
+	})
+
+	if diff := cmp.Diff(int32(2), e.Errno); diff != "" {
+		t.Fatalf("unexpected errno (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,99 @@
+package genltest
+
+import "github.com/mdlayher/genetlink"
+
+// A FamilyBuilder assembles complete replies of the generic netlink
+// controller for a single family, including the header size, maximum
+// attribute type, and attribute policies which ServeFamily does not report.
+// Methods which configure a FamilyBuilder return it, so that calls can be
+// chained:
+//
+//	b := genltest.NewFamilyBuilder(genetlink.Family{ID: 0x20, Version: 1, Name: "foo"}).
+//		MaxAttr(8).
+//		Op(1, genetlink.OpCapDo|genetlink.OpCapHasPolicy).
+//		Group(0x10, "events").
+//		Policy(p)
+//
+//	c := genltest.Dial(b.Serve(fn))
+type FamilyBuilder struct {
+	family  genetlink.Family
+	hdrSize uint32
+	maxAttr uint32
+	policy  *genetlink.Policy
+}
+
+// NewFamilyBuilder creates a FamilyBuilder for the family f. The operations
+// and multicast groups of f are copied, so later changes to f do not affect
+// the FamilyBuilder.
+func NewFamilyBuilder(f genetlink.Family) *FamilyBuilder {
+	f.Ops = append([]genetlink.Op(nil), f.Ops...)
+	f.Groups = append([]genetlink.MulticastGroup(nil), f.Groups...)
+
+	return &FamilyBuilder{family: f}
+}
+
+// Family returns the family information configured by b.
+func (b *FamilyBuilder) Family() genetlink.Family {
+	f := b.family
+	f.Ops = append([]genetlink.Op(nil), f.Ops...)
+	f.Groups = append([]genetlink.MulticastGroup(nil), f.Groups...)
+
+	return f
+}
+
+// HeaderSize sets the size in bytes of the family's user header, which
+// follows the generic netlink header in the family's messages.
+func (b *FamilyBuilder) HeaderSize(n uint32) *FamilyBuilder {
+	b.hdrSize = n
+	return b
+}
+
+// MaxAttr sets the maximum attribute type supported by the family.
+func (b *FamilyBuilder) MaxAttr(n uint32) *FamilyBuilder {
+	b.maxAttr = n
+	return b
+}
+
+// Op adds an operation with the specified command ID and flags.
+func (b *FamilyBuilder) Op(id uint32, flags genetlink.OpFlags) *FamilyBuilder {
+	b.family.Ops = append(b.family.Ops, genetlink.Op{ID: id, Flags: flags})
+	return b
+}
+
+// Group adds a multicast group with the specified ID and name.
+func (b *FamilyBuilder) Group(id uint32, name string) *FamilyBuilder {
+	b.family.Groups = append(b.family.Groups, genetlink.MulticastGroup{ID: id, Name: name})
+	return b
+}
+
+// Policy sets the family's attribute validation policies, which are returned
+// in reply to "get policy" requests, such as those made by
+// genetlink.Conn.GetPolicy.
+func (b *FamilyBuilder) Policy(p genetlink.Policy) *FamilyBuilder {
+	b.policy = &p
+	return b
+}
+
+// Build builds the controller's "new family" reply to a "get family"
+// request for the family. Attributes are encoded in the same order as the
+// kernel.
+func (b *FamilyBuilder) Build() ([]genetlink.Message, error) {
+	return b.build()
+}
+
+// BuildPolicy builds the controller's replies to a "get policy" dump request
+// for the family: one message for each attribute policy, followed by one
+// message for each operation policy, as the kernel does. If no policy was
+// set using Policy, the family has no policies and no messages are returned.
+func (b *FamilyBuilder) BuildPolicy() ([]genetlink.Message, error) {
+	return b.buildPolicy()
+}
+
+// Serve returns a Func that intercepts "get family" and "get policy"
+// commands to the generic netlink controller for the family, and replies
+// with the messages created by Build and BuildPolicy, framed as described
+// for ServeFamilies. Requests for other families fail with ENOENT, and
+// requests which are not related to the controller are passed through to fn.
+func (b *FamilyBuilder) Serve(fn Func) Func {
+	return b.serve(fn)
+}
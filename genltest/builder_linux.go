@@ -0,0 +1,238 @@
+//go:build linux
+// +build linux
+
+package genltest
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// build is the Linux implementation of FamilyBuilder.Build.
+func (b *FamilyBuilder) build() ([]genetlink.Message, error) {
+	f := b.family
+	ae := netlink.NewAttributeEncoder()
+	ae.String(unix.CTRL_ATTR_FAMILY_NAME, f.Name)
+	ae.Uint16(unix.CTRL_ATTR_FAMILY_ID, f.ID)
+	ae.Uint32(unix.CTRL_ATTR_VERSION, uint32(f.Version))
+	ae.Uint32(unix.CTRL_ATTR_HDRSIZE, b.hdrSize)
+	ae.Uint32(unix.CTRL_ATTR_MAXATTR, b.maxAttr)
+
+	// Encode operation attributes if applicable.
+	if len(f.Ops) > 0 {
+		ae.Nested(unix.CTRL_ATTR_OPS, encodeOps(f.Ops))
+	}
+
+	// Encode multicast group attributes if applicable.
+	if len(f.Groups) > 0 {
+		ae.Nested(unix.CTRL_ATTR_MCAST_GROUPS, encodeGroups(f.Groups))
+	}
+
+	attrb, err := ae.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	return []genetlink.Message{{
+		Header: genetlink.Header{
+			Command: unix.CTRL_CMD_NEWFAMILY,
+			Version: ctrlVersion,
+		},
+		Data: attrb,
+	}}, nil
+}
+
+// buildPolicy is the Linux implementation of FamilyBuilder.BuildPolicy.
+func (b *FamilyBuilder) buildPolicy() ([]genetlink.Message, error) {
+	if b.policy == nil {
+		return nil, nil
+	}
+
+	var msgs []genetlink.Message
+	add := func(fn func(ae *netlink.AttributeEncoder)) error {
+		ae := netlink.NewAttributeEncoder()
+		ae.Uint16(unix.CTRL_ATTR_FAMILY_ID, b.family.ID)
+		fn(ae)
+
+		attrb, err := ae.Encode()
+		if err != nil {
+			return err
+		}
+
+		msgs = append(msgs, genetlink.Message{
+			Header: genetlink.Header{
+				Command: unix.CTRL_CMD_GETPOLICY,
+				Version: ctrlVersion,
+			},
+			Data: attrb,
+		})
+
+		return nil
+	}
+
+	for _, s := range b.policy.Sets {
+		for _, ap := range s.Attributes {
+			s, ap := s, ap
+			err := add(func(ae *netlink.AttributeEncoder) {
+				ae.Nested(unix.CTRL_ATTR_POLICY, func(ae *netlink.AttributeEncoder) error {
+					ae.Nested(uint16(s.Index), func(ae *netlink.AttributeEncoder) error {
+						ae.Nested(ap.Type, encodeAttributePolicy(ap))
+						return nil
+					})
+					return nil
+				})
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, op := range b.policy.Ops {
+		op := op
+		err := add(func(ae *netlink.AttributeEncoder) {
+			ae.Nested(unix.CTRL_ATTR_OP_POLICY, func(ae *netlink.AttributeEncoder) error {
+				ae.Nested(uint16(op.Command), func(ae *netlink.AttributeEncoder) error {
+					if op.Do >= 0 {
+						ae.Uint32(unix.CTRL_ATTR_POLICY_DO, uint32(op.Do))
+					}
+					if op.Dump >= 0 {
+						ae.Uint32(unix.CTRL_ATTR_POLICY_DUMP, uint32(op.Dump))
+					}
+					return nil
+				})
+				return nil
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return msgs, nil
+}
+
+// encodeAttributePolicy encodes the fields of ap which apply to its kind, as
+// the kernel does.
+func encodeAttributePolicy(ap genetlink.AttributePolicy) func(ae *netlink.AttributeEncoder) error {
+	return func(ae *netlink.AttributeEncoder) error {
+		ae.Uint32(unix.NL_POLICY_TYPE_ATTR_TYPE, uint32(ap.Kind))
+
+		switch ap.Kind {
+		case genetlink.AttributeU8, genetlink.AttributeU16,
+			genetlink.AttributeU32, genetlink.AttributeU64:
+			ae.Uint64(unix.NL_POLICY_TYPE_ATTR_MIN_VALUE_U, ap.MinUnsigned)
+			ae.Uint64(unix.NL_POLICY_TYPE_ATTR_MAX_VALUE_U, ap.MaxUnsigned)
+			if ap.Mask != 0 {
+				ae.Uint64(unix.NL_POLICY_TYPE_ATTR_MASK, ap.Mask)
+			}
+		case genetlink.AttributeS8, genetlink.AttributeS16,
+			genetlink.AttributeS32, genetlink.AttributeS64:
+			ae.Int64(unix.NL_POLICY_TYPE_ATTR_MIN_VALUE_S, ap.MinSigned)
+			ae.Int64(unix.NL_POLICY_TYPE_ATTR_MAX_VALUE_S, ap.MaxSigned)
+		case genetlink.AttributeBinary, genetlink.AttributeString, genetlink.AttributeNulString:
+			if ap.MinLength != 0 {
+				ae.Uint32(unix.NL_POLICY_TYPE_ATTR_MIN_LENGTH, ap.MinLength)
+			}
+			if ap.MaxLength != 0 {
+				ae.Uint32(unix.NL_POLICY_TYPE_ATTR_MAX_LENGTH, ap.MaxLength)
+			}
+		case genetlink.AttributeNested, genetlink.AttributeNestedArray:
+			if ap.NestedMaxType != 0 {
+				ae.Uint32(unix.NL_POLICY_TYPE_ATTR_POLICY_IDX, ap.NestedIndex)
+				ae.Uint32(unix.NL_POLICY_TYPE_ATTR_POLICY_MAXTYPE, ap.NestedMaxType)
+			}
+		case genetlink.AttributeBitfield32:
+			ae.Uint32(unix.NL_POLICY_TYPE_ATTR_BITFIELD32_MASK, ap.BitfieldMask)
+		}
+
+		return nil
+	}
+}
+
+// serve is the Linux implementation of FamilyBuilder.Serve.
+func (b *FamilyBuilder) serve(fn Func) Func {
+	// Snapshot the configuration so later changes to b do not race with
+	// requests.
+	sb := &FamilyBuilder{
+		family:  b.Family(),
+		hdrSize: b.hdrSize,
+		maxAttr: b.maxAttr,
+		policy:  b.policy,
+	}
+
+	return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		if nreq.Header.Type != unix.GENL_ID_CTRL {
+			return fn(greq, nreq)
+		}
+
+		var build func() ([]genetlink.Message, error)
+		switch greq.Header.Command {
+		case unix.CTRL_CMD_GETFAMILY:
+			build = sb.build
+		case unix.CTRL_CMD_GETPOLICY:
+			build = sb.buildPolicy
+		default:
+			return fn(greq, nreq)
+		}
+
+		name, id, err := parseFamilyRequest(greq)
+		if err != nil {
+			return nil, err
+		}
+		if len(name) >= unix.GENL_NAMSIZ {
+			return nil, Error(int(unix.EINVAL))
+		}
+
+		dump := nreq.Header.Flags&netlink.Dump != 0
+		switch {
+		case name == "" && id == 0 && (greq.Header.Command != unix.CTRL_CMD_GETFAMILY || !dump):
+			// Only a dump of all families need not identify a family.
+			return nil, Error(int(unix.EINVAL))
+		case name != "" && name != sb.family.Name, id != 0 && id != sb.family.ID:
+			return nil, ErrorNotExist()
+		case greq.Header.Command == unix.CTRL_CMD_GETPOLICY && sb.policy == nil:
+			// The kernel fails policy dumps for families without policies.
+			return nil, Error(int(unix.ENODATA))
+		}
+
+		msgs, err := build()
+		if err != nil {
+			return nil, err
+		}
+
+		return ctrlReply(nreq, msgs)
+	}
+}
+
+// parseFamilyRequest parses the family name and ID from a request to the
+// generic netlink controller.
+func parseFamilyRequest(greq genetlink.Message) (string, uint16, error) {
+	ad, err := netlink.NewAttributeDecoder(greq.Data)
+	if err != nil {
+		return "", 0, fmt.Errorf("genltest: failed to parse controller request attributes: %v", err)
+	}
+
+	var (
+		name string
+		id   uint16
+	)
+
+	for ad.Next() {
+		switch ad.Type() {
+		case unix.CTRL_ATTR_FAMILY_NAME:
+			name = ad.String()
+		case unix.CTRL_ATTR_FAMILY_ID:
+			id = ad.Uint16()
+		}
+	}
+
+	if err := ad.Err(); err != nil {
+		return "", 0, fmt.Errorf("genltest: unexpected error decoding controller request: %v", err)
+	}
+
+	return name, id, nil
+}
@@ -0,0 +1,101 @@
+//go:build linux
+// +build linux
+
+package genltest_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func TestFamilyBuilder(t *testing.T) {
+	policy := genetlink.Policy{
+		Sets: []genetlink.PolicySet{
+			{
+				Index: 0,
+				Attributes: []genetlink.AttributePolicy{
+					{Type: 1, Kind: genetlink.AttributeU32, MaxUnsigned: 10},
+					{Type: 2, Kind: genetlink.AttributeString, MaxLength: 15},
+					{Type: 3, Kind: genetlink.AttributeNested, NestedIndex: 1, NestedMaxType: 2},
+				},
+			},
+			{
+				Index: 1,
+				Attributes: []genetlink.AttributePolicy{
+					{Type: 1, Kind: genetlink.AttributeS16, MinSigned: -5, MaxSigned: 5},
+					{Type: 2, Kind: genetlink.AttributeFlag},
+				},
+			},
+		},
+		Ops: []genetlink.OpPolicy{
+			{Command: 1, Do: 0, Dump: -1},
+			{Command: 2, Do: 0, Dump: 0},
+		},
+	}
+
+	b := genltest.NewFamilyBuilder(genetlink.Family{ID: 0x20, Version: 1, Name: "foo"}).
+		HeaderSize(4).
+		MaxAttr(3).
+		Op(1, genetlink.OpCapDo|genetlink.OpCapHasPolicy).
+		Op(2, genetlink.OpCapDo|genetlink.OpCapDump|genetlink.OpCapHasPolicy).
+		Group(0x10, "events").
+		Policy(policy)
+
+	msgs, err := b.Build()
+	if err != nil {
+		t.Fatalf("failed to build: %v", err)
+	}
+
+	attrs := msgs[0].Attributes()
+	hdrSize, _ := attrs.Uint32(unix.CTRL_ATTR_HDRSIZE)
+	maxAttr, _ := attrs.Uint32(unix.CTRL_ATTR_MAXATTR)
+	if diff := cmp.Diff([2]uint32{4, 3}, [2]uint32{hdrSize, maxAttr}); diff != "" {
+		t.Fatalf("unexpected header size and maximum attribute (-want +got):\n%s", diff)
+	}
+
+	c := genltest.Dial(b.Serve(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, nil
+	}))
+	defer c.Close()
+
+	got, err := c.GetFamilyInfo("foo", &genetlink.GetFamilyOptions{Policy: true})
+	if err != nil {
+		t.Fatalf("failed to get family info: %v", err)
+	}
+
+	want := genetlink.FamilyInfo{
+		Family: b.Family(),
+		Policy: &policy,
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected family info (-want +got):\n%s", diff)
+	}
+
+	if _, err := c.GetFamily("bar"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not exist error, but got: %v", err)
+	}
+}
+
+func TestFamilyBuilderNoPolicy(t *testing.T) {
+	b := genltest.NewFamilyBuilder(genetlink.Family{ID: 0x20, Version: 1, Name: "foo"})
+
+	c := genltest.Dial(b.Serve(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, nil
+	}))
+	defer c.Close()
+
+	_, err := c.GetPolicy("foo")
+
+	var oerr *netlink.OpError
+	if !errors.As(err, &oerr) || !errors.Is(oerr.Err, unix.ENODATA) {
+		t.Fatalf("expected ENODATA, but got: %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package genltest
+
+import (
+	"sync"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+)
+
+// A call holds the delivery state of a single invocation of a Func by a test
+// connection. Funcs such as Truncate and Gate.Block record how their replies
+// must be delivered in the call, rather than in their return values, so that
+// they compose with middleware which wraps, replaces, or inspects errors.
+type call struct {
+	// rewrite, if set, is applied to the netlink form of each reply, where
+	// i is the index of the reply.
+	rewrite func(i int, m netlink.Message) netlink.Message
+
+	// blocked, if set, holds back the replies until they are ready.
+	blocked *blocked
+}
+
+// A blocked holds the replies of a Func, such as one created by Gate.Block,
+// until the channel returned by ready is closed. ready is called each time a
+// caller waits for the replies, and fn produces the replies once they are
+// ready.
+type blocked struct {
+	ready func() <-chan struct{}
+	fn    func() ([]genetlink.Message, error)
+}
+
+// addRewrite composes rw with any rewrites already recorded in c, so that rw
+// is applied last.
+func (c *call) addRewrite(rw func(i int, m netlink.Message) netlink.Message) {
+	inner := c.rewrite
+	if inner == nil {
+		c.rewrite = rw
+		return
+	}
+
+	c.rewrite = func(i int, m netlink.Message) netlink.Message {
+		return rw(i, inner(i, m))
+	}
+}
+
+// calls tracks the calls in progress, keyed by the backing array of the
+// request passed to the Func, which is unique to each call.
+var calls = struct {
+	mu sync.Mutex
+	m  map[*byte]*call
+}{m: make(map[*byte]*call)}
+
+// startCall prepares nreq to be passed to a Func as a new call, returning the
+// request to pass and a function which ends the call.
+func startCall(nreq netlink.Message) (netlink.Message, *call, func()) {
+	// Copy the request data so its backing array identifies this call alone,
+	// even when the request carries no data.
+	nreq.Data = append(make([]byte, 0, len(nreq.Data)+1), nreq.Data...)
+
+	c := &call{}
+	return nreq, c, resumeCall(nreq, c)
+}
+
+// resumeCall registers c as the call in progress for nreq, which must have
+// been returned by startCall, returning a function which ends the call.
+func resumeCall(nreq netlink.Message, c *call) func() {
+	key := callKey(nreq)
+
+	calls.mu.Lock()
+	calls.m[key] = c
+	calls.mu.Unlock()
+
+	return func() {
+		calls.mu.Lock()
+		delete(calls.m, key)
+		calls.mu.Unlock()
+	}
+}
+
+// callFor returns the call in progress for nreq, or nil if nreq was not
+// passed to the Func by a test connection.
+func callFor(nreq netlink.Message) *call {
+	key := callKey(nreq)
+	if key == nil {
+		return nil
+	}
+
+	calls.mu.Lock()
+	defer calls.mu.Unlock()
+
+	return calls.m[key]
+}
+
+// callKey returns the key which identifies the call for nreq.
+func callKey(nreq netlink.Message) *byte {
+	if cap(nreq.Data) == 0 {
+		return nil
+	}
+
+	return &nreq.Data[:1][0]
+}
+
+// block records in the call for nreq that its replies are held back as
+// described by b. Outside of a call, the replies cannot be held back and are
+// produced immediately.
+func block(nreq netlink.Message, b *blocked) ([]genetlink.Message, error) {
+	c := callFor(nreq)
+	if c == nil {
+		return b.fn()
+	}
+
+	c.blocked = b
+	return nil, nil
+}
@@ -0,0 +1,103 @@
+package genltest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+)
+
+// A RequestSummary contains the values of a request which are verified by
+// CheckRequest.
+type RequestSummary struct {
+	Family  uint16
+	Command uint8
+	Flags   netlink.HeaderFlags
+}
+
+// A RequestError is returned by a Func created by CheckRequest when a request
+// does not match the expected values. Zero values in Want were not checked.
+//
+// The error message lists each mismatched value in the same "(-want +got)"
+// form as a cmp.Diff, with netlink header flags decoded symbolically and the
+// names of the families returned by Families noted alongside their IDs,
+// followed by a summary of the request's attributes.
+type RequestError struct {
+	Want, Got RequestSummary
+
+	// Request is the generic netlink message which failed the check.
+	Request genetlink.Message
+}
+
+// Error implements error.
+func (e *RequestError) Error() string {
+	var b strings.Builder
+	b.WriteString("genltest: unexpected request (-want +got):\n")
+
+	if e.Want.Family != 0 && e.Want.Family != e.Got.Family {
+		fmt.Fprintf(&b, "\tfamily:\n\t\t- %s\n\t\t+ %s\n",
+			familyString(e.Want.Family), familyString(e.Got.Family))
+	}
+	if e.Want.Command != 0 && e.Want.Command != e.Got.Command {
+		fmt.Fprintf(&b, "\tcommand:\n\t\t- %d\n\t\t+ %d\n", e.Want.Command, e.Got.Command)
+	}
+	if e.Want.Flags != 0 && e.Want.Flags != e.Got.Flags {
+		fmt.Fprintf(&b, "\tflags:\n\t\t- %s\n\t\t+ %s\n", flagsString(e.Want.Flags), flagsString(e.Got.Flags))
+	}
+
+	fmt.Fprintf(&b, "\tattributes: %s", attributeSummary(e.Request.Data))
+	return b.String()
+}
+
+// familyString formats a family ID, noting the name of the family if it is
+// one of those returned by Families.
+func familyString(id uint16) string {
+	for _, f := range Families() {
+		if f.ID == id {
+			return fmt.Sprintf("%#x (%s)", id, f.Name)
+		}
+	}
+
+	return fmt.Sprintf("%#x", id)
+}
+
+// flagsString formats netlink header flags symbolically. The flags for a dump
+// request are formatted as "dump" rather than as their constituent flags.
+func flagsString(f netlink.HeaderFlags) string {
+	if f&netlink.Dump != netlink.Dump {
+		return f.String()
+	}
+
+	if f == netlink.Dump {
+		return "dump"
+	}
+
+	return (f &^ netlink.Dump).String() + "|dump"
+}
+
+// attributeSummary summarizes the top-level attributes in b by type and
+// length.
+func attributeSummary(b []byte) string {
+	if len(b) == 0 {
+		return "none"
+	}
+
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil {
+		return fmt.Sprintf("malformed (%d bytes): %v", len(b), err)
+	}
+
+	ss := make([]string, 0, len(attrs))
+	for _, a := range attrs {
+		typ := a.Type &^ (netlink.Nested | netlink.NetByteOrder)
+		if a.Type&netlink.Nested != 0 {
+			ss = append(ss, fmt.Sprintf("%d: nested, %d bytes", typ, len(a.Data)))
+			continue
+		}
+
+		ss = append(ss, fmt.Sprintf("%d: %d bytes", typ, len(a.Data)))
+	}
+
+	return "[" + strings.Join(ss, ", ") + "]"
+}
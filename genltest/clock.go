@@ -0,0 +1,106 @@
+package genltest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+)
+
+var _ genetlink.Clock = &Clock{}
+
+// A Clock is a genetlink.Clock whose time only changes when it is advanced
+// by a test, so that cache expiry, deduplication windows, and periodic
+// checks can be tested deterministically using genetlink.Conn.SetClock and
+// similar methods. A Clock is safe for concurrent use.
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*ticker
+}
+
+// NewClock creates a Clock whose current time is start.
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now implements genetlink.Clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// NewTicker implements genetlink.Clock. The Ticker ticks only when the Clock
+// is advanced past each of its periods. As with time.Ticker, ticks are
+// dropped if the receiver has not consumed the previous tick.
+func (c *Clock) NewTicker(d time.Duration) genetlink.Ticker {
+	if d <= 0 {
+		panicf("genltest: non-positive interval for NewTicker: %v", d)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &ticker{
+		c:      c,
+		period: d,
+		next:   c.now.Add(d),
+		ch:     make(chan time.Time, 1),
+	}
+	c.tickers = append(c.tickers, t)
+
+	return t
+}
+
+// Advance moves the Clock's current time forward by d, delivering any ticks
+// which became due.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for _, t := range c.tickers {
+		for !t.next.After(c.now) {
+			select {
+			case t.ch <- t.next:
+			default:
+			}
+
+			t.next = t.next.Add(t.period)
+		}
+	}
+}
+
+// Tickers reports the number of active Tickers created by the Clock. Tests
+// can use it to wait until the code under test has started a Ticker before
+// advancing the Clock.
+func (c *Clock) Tickers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.tickers)
+}
+
+// A ticker is a genetlink.Ticker created by a Clock.
+type ticker struct {
+	c      *Clock
+	period time.Duration
+	next   time.Time
+	ch     chan time.Time
+}
+
+func (t *ticker) Chan() <-chan time.Time { return t.ch }
+
+func (t *ticker) Stop() {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+
+	for i, tt := range t.c.tickers {
+		if tt == t {
+			t.c.tickers = append(t.c.tickers[:i], t.c.tickers[i+1:]...)
+			return
+		}
+	}
+}
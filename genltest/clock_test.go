@@ -0,0 +1,59 @@
+package genltest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink/genltest"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Unix(0, 0)
+	clk := genltest.NewClock(start)
+
+	if diff := cmp.Diff(start, clk.Now()); diff != "" {
+		t.Fatalf("unexpected start time (-want +got):\n%s", diff)
+	}
+
+	tk := clk.NewTicker(time.Second)
+	if diff := cmp.Diff(1, clk.Tickers()); diff != "" {
+		t.Fatalf("unexpected number of tickers (-want +got):\n%s", diff)
+	}
+
+	// No tick is due until a full period has elapsed.
+	clk.Advance(999 * time.Millisecond)
+	select {
+	case <-tk.Chan():
+		t.Fatal("unexpected early tick")
+	default:
+	}
+
+	clk.Advance(time.Millisecond)
+	if diff := cmp.Diff(start.Add(time.Second), <-tk.Chan()); diff != "" {
+		t.Fatalf("unexpected tick (-want +got):\n%s", diff)
+	}
+
+	// Unconsumed ticks are dropped.
+	clk.Advance(3 * time.Second)
+	if diff := cmp.Diff(start.Add(2*time.Second), <-tk.Chan()); diff != "" {
+		t.Fatalf("unexpected tick (-want +got):\n%s", diff)
+	}
+	select {
+	case <-tk.Chan():
+		t.Fatal("unexpected dropped tick")
+	default:
+	}
+
+	tk.Stop()
+	if diff := cmp.Diff(0, clk.Tickers()); diff != "" {
+		t.Fatalf("unexpected number of tickers (-want +got):\n%s", diff)
+	}
+
+	clk.Advance(time.Minute)
+	select {
+	case <-tk.Chan():
+		t.Fatal("unexpected tick after stop")
+	default:
+	}
+}
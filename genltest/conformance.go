@@ -0,0 +1,42 @@
+package genltest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mdlayher/genetlink"
+)
+
+// ConformanceEnv is the environment variable which, when set to a non-empty
+// value, allows LiveConformance to run against the host's kernel.
+const ConformanceEnv = "GENLTEST_LIVE"
+
+// Conformance runs a suite of subtests against the generic netlink
+// controller of the connections returned by dial, verifying the behaviors
+// which genltest emulates: family lookups and listings, the errors returned
+// for unknown and invalid family names, and the framing of the controller's
+// replies.
+//
+// Running Conformance against both a Conn created by Dial with ServeFamilies
+// and a Conn to the kernel, as LiveConformance does, ensures that tests
+// written against genltest remain faithful to the kernel. Conformance fails
+// its test on platforms other than Linux.
+func Conformance(t *testing.T, dial func() (*genetlink.Conn, error)) {
+	t.Helper()
+	conformance(t, dial)
+}
+
+// LiveConformance runs Conformance against the host's kernel if the
+// environment variable named by ConformanceEnv is set, and otherwise skips
+// the test.
+func LiveConformance(t *testing.T) {
+	t.Helper()
+
+	if os.Getenv(ConformanceEnv) == "" {
+		t.Skipf("skipping, set %s=1 to run conformance tests against the kernel", ConformanceEnv)
+	}
+
+	Conformance(t, func() (*genetlink.Conn, error) {
+		return genetlink.Dial(nil)
+	})
+}
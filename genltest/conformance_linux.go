@@ -0,0 +1,169 @@
+//go:build linux
+// +build linux
+
+package genltest
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// conformance is the Linux implementation of Conformance.
+func conformance(t *testing.T, dial func() (*genetlink.Conn, error)) {
+	t.Helper()
+
+	conn := func(t *testing.T) *genetlink.Conn {
+		t.Helper()
+
+		c, err := dial()
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+		t.Cleanup(func() { _ = c.Close() })
+
+		return c
+	}
+
+	t.Run("get family", func(t *testing.T) {
+		f, err := conn(t).GetFamily("nlctrl")
+		if err != nil {
+			t.Fatalf("failed to get nlctrl family: %v", err)
+		}
+
+		checkController(t, f)
+	})
+
+	t.Run("get family not exist", func(t *testing.T) {
+		_, err := conn(t).GetFamily("genltest_none")
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected not exist error, but got: %v", err)
+		}
+	})
+
+	t.Run("get family name too long", func(t *testing.T) {
+		// Family names must fit in GENL_NAMSIZ (16) bytes with a trailing NUL.
+		_, err := conn(t).GetFamily("genltest_too_long")
+
+		var oerr *netlink.OpError
+		if !errors.As(err, &oerr) || !errors.Is(oerr.Err, unix.EINVAL) {
+			t.Fatalf("expected EINVAL, but got: %v", err)
+		}
+	})
+
+	t.Run("list families", func(t *testing.T) {
+		fs, err := conn(t).ListFamilies()
+		if err != nil {
+			t.Fatalf("failed to list families: %v", err)
+		}
+
+		for _, f := range fs {
+			if f.Name == "nlctrl" {
+				checkController(t, f)
+				return
+			}
+		}
+
+		t.Fatal("nlctrl family was not listed")
+	})
+
+	t.Run("get family framing", func(t *testing.T) {
+		ae := netlink.NewAttributeEncoder()
+		ae.String(unix.CTRL_ATTR_FAMILY_NAME, "nlctrl")
+
+		checkFraming(t, conn(t), ae, netlink.Request)
+	})
+
+	t.Run("dump framing", func(t *testing.T) {
+		checkFraming(t, conn(t), nil, netlink.Request|netlink.Dump)
+	})
+}
+
+// checkController verifies the well-known properties of the nlctrl family.
+func checkController(t *testing.T, f genetlink.Family) {
+	t.Helper()
+
+	if f.ID != unix.GENL_ID_CTRL || f.Name != "nlctrl" || f.Version != 2 {
+		t.Fatalf("unexpected nlctrl family ID, name, or version: %#x, %q, %d",
+			f.ID, f.Name, f.Version)
+	}
+
+	var notify bool
+	for _, g := range f.Groups {
+		if g.Name == "notify" && g.ID != 0 {
+			notify = true
+		}
+	}
+	if !notify {
+		t.Fatalf("nlctrl family has no notify multicast group: %+v", f.Groups)
+	}
+
+	var get bool
+	for _, o := range f.Ops {
+		if o.ID == unix.CTRL_CMD_GETFAMILY && o.Flags&genetlink.OpCapDo != 0 {
+			get = true
+		}
+	}
+	if !get {
+		t.Fatalf("nlctrl family does not report its get family operation: %+v", f.Ops)
+	}
+}
+
+// checkFraming sends a "get family" request with the attributes encoded by
+// ae and flags, and verifies the netlink and generic netlink headers of the
+// replies.
+func checkFraming(t *testing.T, c *genetlink.Conn, ae *netlink.AttributeEncoder, flags netlink.HeaderFlags) {
+	t.Helper()
+
+	req := genetlink.Message{
+		Header: genetlink.Header{
+			Command: unix.CTRL_CMD_GETFAMILY,
+			Version: 1,
+		},
+	}
+
+	if ae != nil {
+		b, err := ae.Encode()
+		if err != nil {
+			t.Fatalf("failed to encode attributes: %v", err)
+		}
+		req.Data = b
+	}
+
+	nreq, err := c.Send(req, unix.GENL_ID_CTRL, flags)
+	if err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+
+	msgs, nmsgs, err := c.Receive()
+	if err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+	if len(msgs) == 0 {
+		t.Fatal("expected at least one reply")
+	}
+
+	var multi netlink.HeaderFlags
+	if flags&netlink.Dump != 0 {
+		multi = netlink.Multi
+	}
+
+	for i, nm := range nmsgs {
+		h := nm.Header
+		if h.Type != unix.GENL_ID_CTRL || h.Flags != multi {
+			t.Fatalf("unexpected type or flags for reply %d: %+v", i, h)
+		}
+		if h.Sequence != nreq.Header.Sequence || h.PID != nreq.Header.PID {
+			t.Fatalf("reply %d does not echo request sequence and PID: %+v, request: %+v",
+				i, h, nreq.Header)
+		}
+
+		if gh := msgs[i].Header; gh.Command != unix.CTRL_CMD_NEWFAMILY || gh.Version != 2 {
+			t.Fatalf("unexpected generic netlink header for reply %d: %+v", i, gh)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+//go:build linux
+// +build linux
+
+package genltest_test
+
+import (
+	"testing"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+)
+
+func TestConformanceFake(t *testing.T) {
+	genltest.Conformance(t, func() (*genetlink.Conn, error) {
+		return genltest.Dial(genltest.ServeFamilies(genltest.Families(), noop)), nil
+	})
+}
+
+func TestConformanceLive(t *testing.T) {
+	genltest.LiveConformance(t)
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+package genltest
+
+import (
+	"testing"
+
+	"github.com/mdlayher/genetlink"
+)
+
+// conformance always fails the test.
+func conformance(t *testing.T, _ func() (*genetlink.Conn, error)) {
+	t.Helper()
+	t.Fatalf("genltest: %v", errUnimplemented)
+}
@@ -0,0 +1,106 @@
+package genltest
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/mdlayher/netlink"
+)
+
+// A Corpus collects the generic netlink messages exchanged by a
+// genetlink.Conn created by DialConfig, in their binary wire form, for use
+// as a seed corpus when fuzzing message and attribute parsers. Seeding
+// fuzzing with the messages observed in realistic tests lets the fuzzer
+// begin from well formed inputs rather than random bytes. The zero value is
+// ready to use.
+//
+// Each input is the body of a netlink message: a generic netlink header
+// followed by packed netlink attributes, as accepted by
+// genetlink.Message.UnmarshalBinary. Netlink control messages, such as
+// errors and "multi-part done" messages, are not collected. Duplicate
+// inputs are collected only once.
+type Corpus struct {
+	mu     sync.Mutex
+	inputs [][]byte
+	seen   map[string]struct{}
+}
+
+// Inputs returns a copy of the inputs collected so far, in the order in
+// which they were first observed.
+func (c *Corpus) Inputs() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	inputs := make([][]byte, 0, len(c.inputs))
+	for _, b := range c.inputs {
+		inputs = append(inputs, append([]byte(nil), b...))
+	}
+
+	return inputs
+}
+
+// AddTo adds the inputs collected so far to the seed corpus of f. Fuzz
+// targets which use these seeds receive a single []byte argument.
+func (c *Corpus) AddTo(f *testing.F) {
+	f.Helper()
+
+	for _, b := range c.Inputs() {
+		f.Add(b)
+	}
+}
+
+// WriteDir writes the inputs collected so far to dir in the format used by
+// "go test" for seed corpus files, creating dir if necessary. dir is
+// typically "testdata/fuzz/FuzzName" for a fuzz target FuzzName which
+// accepts a single []byte argument. Each file is named by the hash of its
+// contents, so writing the same inputs again does not create duplicates.
+func (c *Corpus) WriteDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("genltest: failed to create corpus directory: %v", err)
+	}
+
+	for _, b := range c.Inputs() {
+		data := []byte("go test fuzz v1\n[]byte(" + strconv.Quote(string(b)) + ")\n")
+		name := fmt.Sprintf("%x", sha256.Sum256(data))[:16]
+
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return fmt.Errorf("genltest: failed to write corpus file: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// record collects the generic netlink messages within msgs.
+func (c *Corpus) record(msgs []netlink.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, m := range msgs {
+		switch m.Header.Type {
+		case netlink.Noop, netlink.Error, netlink.Done, netlink.Overrun:
+			continue
+		}
+
+		// A generic netlink message always has a 4 byte header.
+		if len(m.Data) < 4 {
+			continue
+		}
+
+		if c.seen == nil {
+			c.seen = make(map[string]struct{})
+		}
+
+		if _, ok := c.seen[string(m.Data)]; ok {
+			continue
+		}
+
+		c.seen[string(m.Data)] = struct{}{}
+		c.inputs = append(c.inputs, append([]byte(nil), m.Data...))
+	}
+}
@@ -0,0 +1,85 @@
+package genltest_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestCorpus(t *testing.T) {
+	reply := genetlink.Message{
+		Header: genetlink.Header{Command: 2, Version: 1},
+		Data:   []byte{0x05, 0x00, 0x01, 0x00, 0xff, 0x00, 0x00, 0x00},
+	}
+
+	var corpus genltest.Corpus
+	c := genltest.DialConfig(func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		if greq.Header.Command == 0xff {
+			return nil, genltest.Error(2)
+		}
+
+		return []genetlink.Message{reply}, nil
+	}, &genltest.Config{Corpus: &corpus})
+	defer c.Close()
+
+	req := genetlink.Message{Header: genetlink.Header{Command: 1, Version: 1}}
+
+	// Duplicate messages are only collected once, and errors are not
+	// collected at all.
+	for i := 0; i < 2; i++ {
+		if _, err := c.Execute(req, 0x20, netlink.Request); err != nil {
+			t.Fatalf("failed to execute: %v", err)
+		}
+	}
+
+	bad := genetlink.Message{Header: genetlink.Header{Command: 0xff}}
+	if _, err := c.Execute(bad, 0x20, netlink.Request); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+
+	var want [][]byte
+	for _, m := range []genetlink.Message{req, reply, bad} {
+		b, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+
+		want = append(want, b)
+	}
+
+	if diff := cmp.Diff(want, corpus.Inputs()); diff != "" {
+		t.Fatalf("unexpected corpus inputs (-want +got):\n%s", diff)
+	}
+
+	dir := filepath.Join(t.TempDir(), "FuzzMessage")
+	for i := 0; i < 2; i++ {
+		if err := corpus.WriteDir(dir); err != nil {
+			t.Fatalf("failed to write corpus: %v", err)
+		}
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read corpus directory: %v", err)
+	}
+	if diff := cmp.Diff(len(want), len(files)); diff != "" {
+		t.Fatalf("unexpected number of corpus files (-want +got):\n%s", diff)
+	}
+
+	for _, f := range files {
+		b, err := os.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			t.Fatalf("failed to read corpus file: %v", err)
+		}
+
+		if !strings.HasPrefix(string(b), "go test fuzz v1\n[]byte(") {
+			t.Fatalf("unexpected corpus file contents: %q", b)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build plan9 || windows
+// +build plan9 windows
+
+package genltest
+
+func isSyscallError(_ error) bool {
+	return false
+}
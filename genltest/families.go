@@ -0,0 +1,108 @@
+package genltest
+
+import "github.com/mdlayher/genetlink"
+
+// Operation flags commonly reported by the kernel for "get" and "set"
+// operations.
+const (
+	opGet = genetlink.OpCapDo | genetlink.OpCapDump | genetlink.OpCapHasPolicy
+	opSet = genetlink.OpCapDo | genetlink.OpCapHasPolicy | genetlink.OpUnsAdminPerm
+)
+
+// Families returns fixtures for several common generic netlink families,
+// using IDs, versions, operations, and multicast groups as reported by a
+// typical Linux machine:
+//   - nlctrl
+//   - acpi_event
+//   - ethtool
+//   - nl80211
+//
+// Each call returns new values which may be modified by the caller. Families
+// is intended for use with ServeFamilies, so that tests can run against a
+// believable controller even when these families do not exist on the host.
+func Families() []genetlink.Family {
+	return []genetlink.Family{
+		{
+			ID:      0x10,
+			Version: 2,
+			Name:    "nlctrl",
+			Groups: []genetlink.MulticastGroup{
+				{ID: 0x10, Name: "notify"},
+			},
+			Ops: []genetlink.Op{
+				{ID: 3, Flags: opGet},                       // CTRL_CMD_GETFAMILY
+				{ID: 10, Flags: opGet &^ genetlink.OpCapDo}, // CTRL_CMD_GETPOLICY
+			},
+		},
+		{
+			ID:      0x1a,
+			Version: 1,
+			Name:    "acpi_event",
+			Groups: []genetlink.MulticastGroup{
+				{ID: 0x07, Name: "acpi_mc_group"},
+			},
+		},
+		{
+			ID:      0x15,
+			Version: 1,
+			Name:    "ethtool",
+			Groups: []genetlink.MulticastGroup{
+				{ID: 0x06, Name: "monitor"},
+			},
+			Ops: []genetlink.Op{
+				{ID: 1, Flags: opGet}, // ETHTOOL_MSG_STRSET_GET
+				{ID: 2, Flags: opGet}, // ETHTOOL_MSG_LINKINFO_GET
+				{ID: 3, Flags: opSet}, // ETHTOOL_MSG_LINKINFO_SET
+				{ID: 4, Flags: opGet}, // ETHTOOL_MSG_LINKMODES_GET
+				{ID: 5, Flags: opSet}, // ETHTOOL_MSG_LINKMODES_SET
+				{ID: 6, Flags: opGet}, // ETHTOOL_MSG_LINKSTATE_GET
+				{ID: 7, Flags: opGet}, // ETHTOOL_MSG_DEBUG_GET
+				{ID: 8, Flags: opSet}, // ETHTOOL_MSG_DEBUG_SET
+			},
+		},
+		{
+			ID:      0x20,
+			Version: 1,
+			Name:    "nl80211",
+			Groups: []genetlink.MulticastGroup{
+				{ID: 0x08, Name: "config"},
+				{ID: 0x09, Name: "scan"},
+				{ID: 0x0a, Name: "regulatory"},
+				{ID: 0x0b, Name: "mlme"},
+				{ID: 0x0c, Name: "vendor"},
+				{ID: 0x0d, Name: "nan"},
+				{ID: 0x0e, Name: "testmode"},
+			},
+			Ops: []genetlink.Op{
+				{ID: 1, Flags: opGet},                       // NL80211_CMD_GET_WIPHY
+				{ID: 2, Flags: opSet},                       // NL80211_CMD_SET_WIPHY
+				{ID: 5, Flags: opGet},                       // NL80211_CMD_GET_INTERFACE
+				{ID: 6, Flags: opSet},                       // NL80211_CMD_SET_INTERFACE
+				{ID: 7, Flags: opSet},                       // NL80211_CMD_NEW_INTERFACE
+				{ID: 8, Flags: opSet},                       // NL80211_CMD_DEL_INTERFACE
+				{ID: 17, Flags: opGet},                      // NL80211_CMD_GET_STATION
+				{ID: 32, Flags: opGet &^ genetlink.OpCapDo}, // NL80211_CMD_GET_SCAN
+				{ID: 33, Flags: opSet},                      // NL80211_CMD_TRIGGER_SCAN
+			},
+		},
+	}
+}
+
+// ServeFamilies returns a Func that intercepts "get family" commands to the
+// generic netlink controller and returns family information for the
+// requested family from fs. If the requested family is not present in fs,
+// an ENOENT error is returned, as the kernel does. Dump requests, such as
+// those made by genetlink.Conn.ListFamilies, return information for all of
+// the families in fs.
+//
+// Replies are framed as they are by the kernel's controller: they carry the
+// controller's family ID and version and the request's sequence number and
+// PID, and the replies to dump requests are multi-part messages terminated
+// by a "multi-part done" message.
+//
+// Requests which are not related to requesting a family are passed through to fn.
+//
+// ServeFamilies is typically used with the fixtures returned by Families.
+func ServeFamilies(fs []genetlink.Family, fn Func) Func {
+	return serveFamilies(fs, fn)
+}
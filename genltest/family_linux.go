@@ -15,65 +15,196 @@ import (
 func serveFamily(f genetlink.Family, fn Func) Func {
 	return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
 		// Only intercept "get family" commands to the generic netlink controller.
-		if nreq.Header.Type != unix.GENL_ID_CTRL || greq.Header.Command != unix.CTRL_CMD_GETFAMILY {
+		if !isGetFamily(greq, nreq) {
 			return fn(greq, nreq)
 		}
 
-		ad, err := netlink.NewAttributeDecoder(greq.Data)
+		name, ok, err := parseGetFamily(greq)
 		if err != nil {
-			return nil, fmt.Errorf("genltest: failed to parse get family request attributes: %v", err)
+			return nil, err
 		}
 
 		// Ensure this request is for the family provided by f.
-		for ad.Next() {
-			if want, got := unix.CTRL_ATTR_FAMILY_NAME, int(ad.Type()); want != got {
-				return nil, fmt.Errorf("genltest: unexpected get family request attribute: %d, want: %d", got, want)
-			}
+		if want, got := f.Name, name; ok && want != got {
+			return nil, fmt.Errorf("genltest: unexpected get family request value: %q, want: %q", got, want)
+		}
 
-			if want, got := f.Name, ad.String(); want != got {
-				return nil, fmt.Errorf("genltest: unexpected get family request value: %q, want: %q", got, want)
+		msgs, err := encodeFamily(f)
+		if err != nil {
+			return nil, err
+		}
+
+		return ctrlReply(nreq, msgs)
+	}
+}
+
+// serveFamilies is the Linux implementation of ServeFamilies.
+func serveFamilies(fs []genetlink.Family, fn Func) Func {
+	return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		// Only intercept "get family" commands to the generic netlink controller.
+		if !isGetFamily(greq, nreq) {
+			return fn(greq, nreq)
+		}
+
+		// A dump lists all families, as performed by genetlink.Conn.ListFamilies.
+		if nreq.Header.Flags&netlink.Dump != 0 {
+			msgs, err := encodeFamilies(fs)
+			if err != nil {
+				return nil, err
 			}
+
+			return ctrlReply(nreq, msgs)
 		}
 
-		if err := ad.Err(); err != nil {
-			return nil, fmt.Errorf("genltest: unexpected error decoding get family request: %v", err)
+		name, _, err := parseGetFamily(greq)
+		if err != nil {
+			return nil, err
+		}
+
+		// The kernel's policy rejects names which do not fit in GENL_NAMSIZ
+		// bytes with a trailing NUL.
+		if len(name) >= unix.GENL_NAMSIZ {
+			return nil, Error(int(unix.EINVAL))
+		}
+
+		for _, f := range fs {
+			if f.Name != name {
+				continue
+			}
+
+			msgs, err := encodeFamily(f)
+			if err != nil {
+				return nil, err
+			}
+
+			return ctrlReply(nreq, msgs)
 		}
 
-		// Return the family information for f.
-		ae := netlink.NewAttributeEncoder()
-		ae.Uint16(unix.CTRL_ATTR_FAMILY_ID, f.ID)
-		ae.String(unix.CTRL_ATTR_FAMILY_NAME, f.Name)
-		ae.Uint32(unix.CTRL_ATTR_VERSION, uint32(f.Version))
+		return nil, ErrorNotExist()
+	}
+}
+
+// isGetFamily reports whether a request is a "get family" command to the
+// generic netlink controller.
+func isGetFamily(greq genetlink.Message, nreq netlink.Message) bool {
+	return nreq.Header.Type == unix.GENL_ID_CTRL && greq.Header.Command == unix.CTRL_CMD_GETFAMILY
+}
+
+// parseGetFamily parses the family name from a "get family" request, and
+// reports whether a name was present.
+func parseGetFamily(greq genetlink.Message) (string, bool, error) {
+	ad, err := netlink.NewAttributeDecoder(greq.Data)
+	if err != nil {
+		return "", false, fmt.Errorf("genltest: failed to parse get family request attributes: %v", err)
+	}
+
+	var (
+		name string
+		ok   bool
+	)
 
-		// Encode multicast group attributes if applicable.
-		if len(f.Groups) > 0 {
-			ae.Nested(unix.CTRL_ATTR_MCAST_GROUPS, encodeGroups(f.Groups))
+	for ad.Next() {
+		if want, got := unix.CTRL_ATTR_FAMILY_NAME, int(ad.Type()); want != got {
+			return "", false, fmt.Errorf("genltest: unexpected get family request attribute: %d, want: %d", got, want)
 		}
 
-		attrb, err := ae.Encode()
+		name, ok = ad.String(), true
+	}
+
+	if err := ad.Err(); err != nil {
+		return "", false, fmt.Errorf("genltest: unexpected error decoding get family request: %v", err)
+	}
+
+	return name, ok, nil
+}
+
+// ctrlVersion is the version of the generic netlink controller.
+const ctrlVersion = 2
+
+// ctrlReply frames the replies of the generic netlink controller to nreq as
+// the kernel does: each reply carries the controller's family ID and the
+// request's sequence number and PID, and the replies to a dump request are
+// multi-part messages terminated by a "multi-part done" message.
+func ctrlReply(nreq netlink.Message, msgs []genetlink.Message) ([]genetlink.Message, error) {
+	dump := nreq.Header.Flags&netlink.Dump != 0
+
+	var flags netlink.HeaderFlags
+	if dump {
+		flags = netlink.Multi
+
+		// The "multi-part done" message carries an error number of 0, which
+		// is encoded identically to an empty generic netlink header.
+		msgs = append(msgs, genetlink.Message{})
+	}
+
+	if c := callFor(nreq); c != nil {
+		c.addRewrite(func(i int, m netlink.Message) netlink.Message {
+			typ := netlink.HeaderType(unix.GENL_ID_CTRL)
+			if dump && i == len(msgs)-1 {
+				typ = netlink.Done
+			}
+
+			m.Header = netlink.Header{
+				Length:   uint32(nlmsgHeaderLen + len(m.Data)),
+				Type:     typ,
+				Flags:    flags,
+				Sequence: nreq.Header.Sequence,
+				PID:      nreq.Header.PID,
+			}
+
+			return m
+		})
+	}
+
+	return msgs, nil
+}
+
+// encodeFamily encodes the family information for f as a "new family" reply,
+// with attributes in the same order as the kernel.
+func encodeFamily(f genetlink.Family) ([]genetlink.Message, error) {
+	return (&FamilyBuilder{family: f}).build()
+}
+
+// encodeFamilies encodes the family information for each of fs as a series
+// of "new family" replies to a dump request.
+func encodeFamilies(fs []genetlink.Family) ([]genetlink.Message, error) {
+	msgs := make([]genetlink.Message, 0, len(fs))
+	for _, f := range fs {
+		fmsgs, err := encodeFamily(f)
 		if err != nil {
 			return nil, err
 		}
 
-		return []genetlink.Message{{
-			Header: genetlink.Header{
-				Command: unix.CTRL_CMD_NEWFAMILY,
-				// TODO(mdlayher): constant nlctrl version number?
-				Version: 2,
-			},
-			Data: attrb,
-		}}, nil
+		msgs = append(msgs, fmsgs...)
 	}
+
+	return msgs, nil
 }
 
 // encodeGroups encodes multicast groups as packed netlink attributes.
 func encodeGroups(groups []genetlink.MulticastGroup) func(ae *netlink.AttributeEncoder) error {
 	return func(ae *netlink.AttributeEncoder) error {
-		// Groups are a netlink "array" of nested attributes.
+		// Groups are a netlink "array" of nested attributes, indexed from 1.
 		for i, g := range groups {
-			ae.Nested(uint16(i), func(nae *netlink.AttributeEncoder) error {
-				nae.String(unix.CTRL_ATTR_MCAST_GRP_NAME, g.Name)
+			ae.Nested(uint16(i+1), func(nae *netlink.AttributeEncoder) error {
 				nae.Uint32(unix.CTRL_ATTR_MCAST_GRP_ID, g.ID)
+				nae.String(unix.CTRL_ATTR_MCAST_GRP_NAME, g.Name)
+				return nil
+			})
+		}
+
+		return nil
+	}
+}
+
+// encodeOps encodes operations as packed netlink attributes.
+func encodeOps(ops []genetlink.Op) func(ae *netlink.AttributeEncoder) error {
+	return func(ae *netlink.AttributeEncoder) error {
+		// Operations are a netlink "array" of nested attributes, indexed from 1.
+		for i, o := range ops {
+			ae.Nested(uint16(i+1), func(nae *netlink.AttributeEncoder) error {
+				nae.Uint32(unix.CTRL_ATTR_OP_ID, o.ID)
+				nae.Uint32(unix.CTRL_ATTR_OP_FLAGS, uint32(o.Flags))
 				return nil
 			})
 		}
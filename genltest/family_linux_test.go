@@ -4,7 +4,9 @@
 package genltest_test
 
 import (
+	"errors"
 	"io"
+	"os"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -74,6 +76,16 @@ func TestServeFamily(t *testing.T) {
 						Name: "baz",
 					},
 				},
+				Ops: []genetlink.Op{
+					{
+						ID:    1,
+						Flags: genetlink.OpCapDo,
+					},
+					{
+						ID:    2,
+						Flags: genetlink.OpCapDump | genetlink.OpAdminPerm,
+					},
+				},
 			},
 			fn: func(c *genetlink.Conn) (*genetlink.Family, error) {
 				f, err := c.GetFamily("foo")
@@ -143,3 +155,131 @@ func TestServeFamily(t *testing.T) {
 		})
 	}
 }
+
+func TestServeFamilies(t *testing.T) {
+	c := genltest.Dial(genltest.ServeFamilies(genltest.Families(), noop))
+	defer c.Close()
+
+	for _, want := range genltest.Families() {
+		t.Run(want.Name, func(t *testing.T) {
+			got, err := c.GetFamily(want.Name)
+			if err != nil {
+				t.Fatalf("failed to get family: %v", err)
+			}
+
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatalf("unexpected generic netlink family (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("not exist", func(t *testing.T) {
+		if _, err := c.GetFamily("foo"); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected not exist error, but got: %v", err)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		got, err := c.ListFamilies()
+		if err != nil {
+			t.Fatalf("failed to list families: %v", err)
+		}
+
+		if diff := cmp.Diff(genltest.Families(), got); diff != "" {
+			t.Fatalf("unexpected generic netlink families (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestServeFamiliesListEmpty(t *testing.T) {
+	c := genltest.Dial(genltest.ServeFamilies(nil, noop))
+	defer c.Close()
+
+	fs, err := c.ListFamilies()
+	if err != nil {
+		t.Fatalf("failed to list families: %v", err)
+	}
+
+	if l := len(fs); l > 0 {
+		t.Fatalf("expected no families, but got: %d", l)
+	}
+}
+
+func TestServeFamiliesFraming(t *testing.T) {
+	c := genltest.Dial(genltest.ServeFamilies(genltest.Families(), noop))
+	defer c.Close()
+
+	tests := []struct {
+		name  string
+		flags netlink.HeaderFlags
+		attrs []netlink.Attribute
+		n     int
+		multi netlink.HeaderFlags
+	}{
+		{
+			name:  "get",
+			flags: netlink.Request,
+			attrs: []netlink.Attribute{{
+				Type: unix.CTRL_ATTR_FAMILY_NAME,
+				Data: nlenc.Bytes("nlctrl"),
+			}},
+			n: 1,
+		},
+		{
+			name:  "dump",
+			flags: netlink.Request | netlink.Dump,
+			n:     len(genltest.Families()),
+			multi: netlink.Multi,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := genetlink.Message{
+				Header: genetlink.Header{
+					Command: unix.CTRL_CMD_GETFAMILY,
+					Version: 1,
+				},
+				Data: nltest.MustMarshalAttributes(tt.attrs),
+			}
+
+			nreq, err := c.Send(req, unix.GENL_ID_CTRL, tt.flags)
+			if err != nil {
+				t.Fatalf("failed to send: %v", err)
+			}
+
+			// The "multi-part done" message is consumed by netlink.Conn.
+			msgs, nmsgs, err := c.Receive()
+			if err != nil {
+				t.Fatalf("failed to receive: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.n, len(msgs)); diff != "" {
+				t.Fatalf("unexpected number of messages (-want +got):\n%s", diff)
+			}
+
+			for i := range msgs {
+				want := netlink.Header{
+					Length:   uint32(16 + 4 + len(msgs[i].Data)),
+					Type:     unix.GENL_ID_CTRL,
+					Flags:    tt.multi,
+					Sequence: nreq.Header.Sequence,
+					PID:      nreq.Header.PID,
+				}
+
+				if diff := cmp.Diff(want, nmsgs[i].Header); diff != "" {
+					t.Fatalf("unexpected netlink header (-want +got):\n%s", diff)
+				}
+
+				wantg := genetlink.Header{
+					Command: unix.CTRL_CMD_NEWFAMILY,
+					Version: 2,
+				}
+
+				if diff := cmp.Diff(wantg, msgs[i].Header); diff != "" {
+					t.Fatalf("unexpected generic netlink header (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
@@ -22,3 +22,27 @@ func serveFamily(f genetlink.Family, fn Func) Func {
 		return nil, errUnimplemented
 	}
 }
+
+// serveFamilies returns a Func which always returns an error.
+func serveFamilies(fs []genetlink.Family, fn Func) Func {
+	return func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, errUnimplemented
+	}
+}
+
+// build always returns an error.
+func (b *FamilyBuilder) build() ([]genetlink.Message, error) {
+	return nil, errUnimplemented
+}
+
+// buildPolicy always returns an error.
+func (b *FamilyBuilder) buildPolicy() ([]genetlink.Message, error) {
+	return nil, errUnimplemented
+}
+
+// serve returns a Func which always returns an error.
+func (b *FamilyBuilder) serve(fn Func) Func {
+	return func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, errUnimplemented
+	}
+}
@@ -0,0 +1,67 @@
+package genltest
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+)
+
+// Delay returns a Func which delays the replies of fn by d, measured from the
+// time the request is sent. A caller receiving the replies blocks until they
+// are ready, the connection's read deadline expires, or the connection is
+// closed, so Delay can be used to test the timeout behavior of clients.
+func Delay(d time.Duration, fn Func) Func {
+	return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		ready := make(chan struct{})
+		time.AfterFunc(d, func() { close(ready) })
+
+		return block(nreq, &blocked{
+			ready: func() <-chan struct{} { return ready },
+			fn: func() ([]genetlink.Message, error) {
+				return fn(greq, nreq)
+			},
+		})
+	}
+}
+
+// Drop returns a Func which passes requests to fn but discards its replies
+// with probability p, using r as a source of randomness. A caller waiting on
+// dropped replies blocks until the connection's read deadline expires or the
+// connection is closed, so Drop can be used to test the retry behavior of
+// clients.
+//
+// r must not be used concurrently elsewhere while the Func is in use.
+func Drop(p float64, r *rand.Rand, fn Func) Func {
+	return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		msgs, err := fn(greq, nreq)
+		if r.Float64() >= p {
+			return msgs, err
+		}
+
+		return block(nreq, &blocked{
+			// A nil channel is never ready.
+			ready: func() <-chan struct{} { return nil },
+			fn: func() ([]genetlink.Message, error) {
+				return nil, nil
+			},
+		})
+	}
+}
+
+// InjectError returns a Func which returns a netlink error with the specified
+// error number with probability p, using r as a source of randomness, and
+// otherwise passes requests through to fn. InjectError can be used to test
+// the handling of intermittent errors such as EBUSY or EAGAIN.
+//
+// r must not be used concurrently elsewhere while the Func is in use.
+func InjectError(p float64, r *rand.Rand, number int, fn Func) Func {
+	return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		if r.Float64() < p {
+			return nil, Error(number)
+		}
+
+		return fn(greq, nreq)
+	}
+}
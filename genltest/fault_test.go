@@ -0,0 +1,109 @@
+package genltest_test
+
+import (
+	"errors"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		delay   time.Duration
+		timeout time.Duration
+		ok      bool
+	}{
+		{
+			name:    "timeout",
+			delay:   time.Hour,
+			timeout: 10 * time.Millisecond,
+		},
+		{
+			name:    "OK",
+			delay:   10 * time.Millisecond,
+			timeout: time.Hour,
+			ok:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := genltest.Dial(genltest.Delay(tt.delay, echo))
+			defer c.Close()
+
+			if err := c.SetReadDeadline(time.Now().Add(tt.timeout)); err != nil {
+				t.Fatalf("failed to set read deadline: %v", err)
+			}
+
+			_, err := c.Execute(genetlink.Message{}, 1, netlink.Request)
+			if err != nil && tt.ok {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, os.ErrDeadlineExceeded) {
+				t.Fatalf("expected deadline exceeded, but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestDrop(t *testing.T) {
+	var calls int
+	c := genltest.Dial(genltest.Drop(1, rand.New(rand.NewSource(1)), func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		calls++
+		return echo(greq, nreq)
+	}))
+	defer c.Close()
+
+	if err := c.SetReadDeadline(time.Now().Add(10 * time.Millisecond)); err != nil {
+		t.Fatalf("failed to set read deadline: %v", err)
+	}
+
+	if _, err := c.Execute(genetlink.Message{}, 1, netlink.Request); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, but got: %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected request to be processed once, but got: %d", calls)
+	}
+}
+
+func TestInjectError(t *testing.T) {
+	const ebusy = 16
+
+	tests := []struct {
+		name string
+		p    float64
+		ok   bool
+	}{
+		{
+			name: "always",
+			p:    1,
+		},
+		{
+			name: "never",
+			p:    0,
+			ok:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := genltest.Dial(genltest.InjectError(tt.p, rand.New(rand.NewSource(1)), ebusy, echo))
+			defer c.Close()
+
+			_, err := c.Execute(genetlink.Message{}, 1, netlink.Request)
+			if err != nil && tt.ok {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err == nil && !tt.ok {
+				t.Fatal("expected an error, but none occurred")
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package genltest
+
+import (
+	"sync"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+)
+
+// A Gate holds back the replies of a Func until the Gate is released, so
+// that timeout and cancellation code paths can be exercised deterministically.
+//
+// While a Gate is closed, a caller receiving replies from a Func wrapped by
+// Block blocks until the Gate is released, the connection's read deadline
+// expires, or the connection is closed. A deadline expiry or close does not
+// discard the request: if the Gate is released later, its replies are
+// delivered on the next receive, as they would be by the kernel.
+type Gate struct {
+	releaseOnce sync.Once
+	release     chan struct{}
+
+	waitingOnce sync.Once
+	waiting     chan struct{}
+}
+
+// NewGate creates a closed Gate.
+func NewGate() *Gate {
+	return &Gate{
+		release: make(chan struct{}),
+		waiting: make(chan struct{}),
+	}
+}
+
+// Block returns a Func which defers calling fn until g is released.
+func (g *Gate) Block(fn Func) Func {
+	return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		return block(nreq, &blocked{
+			ready: g.wait,
+			fn: func() ([]genetlink.Message, error) {
+				return fn(greq, nreq)
+			},
+		})
+	}
+}
+
+// Release opens g, allowing all blocked and future replies to proceed.
+// Release may be called more than once.
+func (g *Gate) Release() {
+	g.releaseOnce.Do(func() { close(g.release) })
+}
+
+// Waiting returns a channel which is closed once a caller first blocks on g.
+// Tests can use it to cancel or time out the caller only after it is known
+// to be waiting for a reply.
+func (g *Gate) Waiting() <-chan struct{} {
+	return g.waiting
+}
+
+// wait marks g as having a blocked caller and returns the channel which is
+// closed when g is released.
+func (g *Gate) wait() <-chan struct{} {
+	g.waitingOnce.Do(func() { close(g.waiting) })
+	return g.release
+}
+
+// A pendingError is the netlink-level form of a blocked call, carried by a
+// socket until its replies are ready.
+type pendingError struct {
+	ready func() <-chan struct{}
+	fn    func() ([]netlink.Message, error)
+}
+
+func (err *pendingError) Error() string {
+	return "genltest: reply pending"
+}
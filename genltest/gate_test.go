@@ -0,0 +1,108 @@
+package genltest_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestGateRelease(t *testing.T) {
+	g := genltest.NewGate()
+	c := genltest.Dial(g.Block(echo))
+	defer c.Close()
+
+	req := genetlink.Message{Data: []byte{0xff}}
+
+	type result struct {
+		msgs []genetlink.Message
+		err  error
+	}
+
+	resC := make(chan result, 1)
+	go func() {
+		msgs, err := c.Execute(req, 1, 1)
+		resC <- result{msgs: msgs, err: err}
+	}()
+
+	<-g.Waiting()
+	g.Release()
+
+	res := <-resC
+	if res.err != nil {
+		t.Fatalf("failed to execute: %v", res.err)
+	}
+
+	if diff := cmp.Diff([]genetlink.Message{req}, res.msgs); diff != "" {
+		t.Fatalf("unexpected replies (-want +got):\n%s", diff)
+	}
+}
+
+func TestGateDeadline(t *testing.T) {
+	g := genltest.NewGate()
+	c := genltest.Dial(g.Block(echo))
+	defer c.Close()
+
+	if err := c.SetReadDeadline(time.Now().Add(10 * time.Millisecond)); err != nil {
+		t.Fatalf("failed to set read deadline: %v", err)
+	}
+
+	req := genetlink.Message{Data: []byte{0xff}}
+
+	_, err := c.Execute(req, 1, 1)
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, but got: %v", err)
+	}
+
+	var nerr *netlink.OpError
+	if !errors.As(err, &nerr) || !nerr.Timeout() {
+		t.Fatalf("expected a timeout error, but got: %v", err)
+	}
+
+	// The reply was not discarded by the timeout and arrives late once the
+	// gate is released.
+	g.Release()
+
+	if err := c.SetReadDeadline(time.Time{}); err != nil {
+		t.Fatalf("failed to clear read deadline: %v", err)
+	}
+
+	msgs, _, err := c.Receive()
+	if err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+
+	if diff := cmp.Diff([]genetlink.Message{req}, msgs); diff != "" {
+		t.Fatalf("unexpected replies (-want +got):\n%s", diff)
+	}
+}
+
+func TestGateClose(t *testing.T) {
+	g := genltest.NewGate()
+	c := genltest.Dial(g.Block(echo))
+
+	errC := make(chan error, 1)
+	go func() {
+		_, err := c.Execute(genetlink.Message{}, 1, 1)
+		errC <- err
+	}()
+
+	<-g.Waiting()
+	if err := c.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	if err := <-errC; !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("expected closed error, but got: %v", err)
+	}
+}
+
+// echo is a Func which turns the request back around to the client.
+func echo(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+	return []genetlink.Message{greq}, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/mdlayher/genetlink"
 	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
 	"github.com/mdlayher/netlink/nltest"
 )
 
@@ -15,8 +16,49 @@ func Error(number int) error {
 	return &errnoError{number: number}
 }
 
+// Linux error numbers used by the friendly error constructors. These values
+// are the same on all Linux architectures.
+const (
+	eperm  = 0x1 // unix.EPERM
+	enoent = 0x2 // unix.ENOENT
+)
+
+// ErrorPermission returns a netlink EPERM error to the caller, as the kernel
+// does when an unprivileged caller invokes an operation which requires
+// CAP_NET_ADMIN. The error satisfies errors.Is(err, os.ErrPermission) on
+// Linux.
+func ErrorPermission() error {
+	return Error(eperm)
+}
+
+// ErrorNotExist returns a netlink ENOENT error to the caller, as the kernel
+// does when a requested family or object does not exist. The error satisfies
+// errors.Is(err, os.ErrNotExist) on Linux.
+func ErrorNotExist() error {
+	return Error(enoent)
+}
+
+// ErrorWithExtAck returns a netlink error to the caller with the specified
+// error number and an extended acknowledgement carrying message and offset,
+// which are reported in the Message and Offset fields of the netlink.OpError
+// returned to the caller. offset is the byte offset of the offending
+// attribute from the start of the request's netlink header, or 0 if unknown.
+func ErrorWithExtAck(number int, message string, offset uint32) error {
+	return &errnoError{
+		number:  number,
+		extAck:  true,
+		message: message,
+		offset:  offset,
+	}
+}
+
 type errnoError struct {
 	number int
+
+	// Optional extended acknowledgement information.
+	extAck  bool
+	message string
+	offset  uint32
 }
 
 func (err *errnoError) Error() string {
@@ -43,12 +85,114 @@ type Func func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Messag
 // sent from the connection will be passed to the Func.  The connection should be
 // closed as usual when it is no longer needed.
 func Dial(fn Func) *genetlink.Conn {
-	return genetlink.NewConn(nltest.Dial(adapt(fn)))
+	return DialConfig(fn, nil)
+}
+
+// Config specifies optional configuration for a genetlink.Conn created by
+// DialConfig. The zero value is equivalent to calling Dial.
+type Config struct {
+	// Groups specifies the multicast group IDs which may be joined using
+	// genetlink.Conn.JoinGroup. If nil, any group may be joined. Attempting
+	// to join any other group returns an EINVAL system call error.
+	Groups []uint32
+
+	// Membership, if set, is invoked whenever the connection joins (join is
+	// true) or leaves a multicast group, so tests can observe and assert
+	// membership changes made by the code under test. If Membership returns
+	// an error, the operation fails and the error is returned to the caller.
+	Membership func(group uint32, join bool) error
+
+	// BufferSize, if greater than zero, specifies the size in bytes of the
+	// simulated receive buffer. Replies which do not fit in a single buffer
+	// are delivered across multiple reads as a multi-part message, mimicking
+	// how the kernel pages dump output. A single message larger than the
+	// buffer is still delivered whole.
+	BufferSize int
+
+	// PID specifies the port ID assigned to the connection, which is used
+	// in the netlink header of requests and mimicked by replies. If zero,
+	// nltest.PID is used.
+	PID uint32
+
+	// KernelPID specifies the port ID reported in the netlink header of
+	// multicast messages, which is 0 for messages sent by the kernel. A
+	// non-zero value simulates messages sent by another netlink socket.
+	// Messages which already specify a PID, such as those returned by a
+	// ResponseFunc, are left unchanged.
+	KernelPID uint32
+
+	// Recorder, if set, records all messages sent and received by the
+	// connection.
+	Recorder *Recorder
+
+	// Corpus, if set, collects the generic netlink messages sent and
+	// received by the connection for use as a fuzzing seed corpus.
+	Corpus *Corpus
+
+	// Verify, if set, is invoked when the connection is first closed, and
+	// any error it returns is returned by Close. Verify is typically set to
+	// Mock.Verify, so that closing the connection reports unexpected or
+	// missing requests.
+	Verify func() error
+}
+
+// DialConfig is like Dial, but also applies the configuration specified by
+// cfg. If cfg is nil, a default configuration is used.
+func DialConfig(fn Func, cfg *Config) *genetlink.Conn {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	pid := cfg.PID
+	if pid == 0 {
+		pid = nltest.PID
+	}
+
+	return genetlink.NewTransportConn(newTransport(adapt(fn), cfg, pid))
+}
+
+// newTransport creates a transport for a new socket which passes requests
+// to fn.
+func newTransport(fn nltest.Func, cfg *Config, pid uint32) *transport {
+	s := newSocket(fn, cfg)
+	return &transport{
+		Conn: netlink.NewConn(s, pid),
+		s:    s,
+		dial: func() genetlink.Transport {
+			// Only the original connection verifies the requests made to it
+			// when it is closed.
+			dcfg := *cfg
+			dcfg.Verify = nil
+
+			return newTransport(fn, &dcfg, pid)
+		},
+	}
+}
+
+// A transport is the genetlink.Transport of a test connection. It exposes
+// the reads of its socket so that genetlink.Conn can check replies against
+// its response limits as they arrive, as it does for a real socket, and can
+// dial further connections to the same Func.
+type transport struct {
+	*netlink.Conn
+	s    *socket
+	dial func() genetlink.Transport
+}
+
+// Dial implements the optional genetlink.Transport method.
+func (t *transport) Dial() (genetlink.Transport, error) {
+	return t.dial(), nil
+}
+
+// ReceivePart implements the optional genetlink.Transport method.
+func (t *transport) ReceivePart() ([]netlink.Message, error) {
+	return t.s.Receive()
 }
 
 // ServeFamily returns a Func that intercepts "get family" commands to the
 // generic netlink controller, verifies that the requested family name matches
 // the provided one, and then returns family information specified by f.
+// Replies are framed as described for ServeFamilies.
 //
 // Requests which are not related to requesting a family are passed through to fn.
 //
@@ -64,26 +208,32 @@ func ServeFamily(f genetlink.Family, fn Func) Func {
 //
 // If family, command, or flags are set to the zero value, the specific check
 // for that value will be skipped for request message.
+//
+// A request which does not match fails with a *RequestError, which describes
+// each mismatched value and summarizes the request.
 func CheckRequest(family uint16, command uint8, flags netlink.HeaderFlags, fn Func) Func {
-	base := nltest.CheckRequest(
-		// Expect genetlink family in header type.
-		[]netlink.HeaderType{netlink.HeaderType(family)},
-		// Expect specified netlink flags.
-		[]netlink.HeaderFlags{flags},
-		// Make the next nltest function a noop.
-		// TODO(mdlayher): modify nltest to eliminate the need for this?
-		nltest.Func(func(_ []netlink.Message) ([]netlink.Message, error) {
-			return nil, nil
-		}),
-	)
+	want := RequestSummary{Family: family, Command: command, Flags: flags}
 
 	return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
-		if _, err := base([]netlink.Message{nreq}); err != nil {
-			return nil, fmt.Errorf("genltest: netlink header validation failed: %v", err)
+		got := RequestSummary{
+			Family:  uint16(nreq.Header.Type),
+			Command: greq.Header.Command,
+			Flags:   nreq.Header.Flags,
+		}
+
+		var mismatch bool
+		if family != 0 && want.Family != got.Family {
+			mismatch = true
+		}
+		if command != 0 && want.Command != got.Command {
+			mismatch = true
+		}
+		if flags != 0 && want.Flags != got.Flags {
+			mismatch = true
 		}
 
-		if want, got := command, greq.Header.Command; command != 0 && want != got {
-			return nil, fmt.Errorf("genltest: unexpected generic netlink header command: %d, want: %d", got, want)
+		if mismatch {
+			return nil, &RequestError{Want: want, Got: got, Request: greq}
 		}
 
 		return fn(greq, nreq)
@@ -118,35 +268,137 @@ func adapt(fn Func) nltest.Func {
 			}
 		}
 
+		req, c, end := startCall(req)
 		gmsgs, err := fn(gm, req)
+		end()
+
+		return deliver(reqs, req, c, gmsgs, err)
+	}
+}
+
+// deliver converts the results of the call c to a Func into netlink replies
+// to the request req, deferring them if the call's replies are blocked.
+func deliver(reqs []netlink.Message, req netlink.Message, c *call, gmsgs []genetlink.Message, err error) ([]netlink.Message, error) {
+	b := c.blocked
+	if err != nil || b == nil {
+		return reply(reqs, req, gmsgs, err, c.rewrite)
+	}
+
+	// Defer the Func's replies until they are ready.
+	return nil, &pendingError{
+		ready: b.ready,
+		fn: func() ([]netlink.Message, error) {
+			c := &call{}
+			end := resumeCall(req, c)
+			gmsgs, err := b.fn()
+			end()
+
+			return deliver(reqs, req, c, gmsgs, err)
+		},
+	}
+}
+
+// reply converts the generic netlink messages and error returned by a Func
+// into netlink replies to the request req, applying rewrite, if set, to each
+// encoded reply.
+func reply(reqs []netlink.Message, req netlink.Message, gmsgs []genetlink.Message, err error, rewrite func(i int, m netlink.Message) netlink.Message) ([]netlink.Message, error) {
+	if rewrite == nil {
+		rewrite = func(_ int, m netlink.Message) netlink.Message { return m }
+	}
+
+	if err != nil {
+		// An error was returned with an error number by the Func.
+		// Pass this to the caller as a netlink message error.
+		nerr, ok := err.(*errnoError)
+		if !ok {
+			return nil, err
+		}
+
+		if nerr.extAck {
+			return extAckError(nerr, req)
+		}
+
+		msgs, err := nltest.Error(nerr.number, reqs)
 		if err != nil {
-			// An error was returned with an error number by the Func.
-			// Pass this to the caller as a netlink message error.
-			nerr, ok := err.(*errnoError)
-			if !ok {
-				return nil, err
-			}
+			return nil, err
+		}
 
-			return nltest.Error(nerr.number, reqs)
+		// Like the kernel, do not echo the request's flags: the flags of a
+		// dump request would otherwise indicate extended acknowledgement
+		// attributes which are not present.
+		for i := range msgs {
+			msgs[i].Header.Flags = 0
 		}
 
-		nmsgs := make([]netlink.Message, 0, len(gmsgs))
-		for _, msg := range gmsgs {
-			b, err := msg.MarshalBinary()
-			if err != nil {
-				return nil, err
-			}
+		return msgs, nil
+	}
 
-			nmsgs = append(nmsgs, netlink.Message{
-				// Mimic the sequence and PID of the request for validation.
-				Header: netlink.Header{
-					Sequence: req.Header.Sequence,
-					PID:      req.Header.PID,
-				},
-				Data: b,
-			})
+	nmsgs := make([]netlink.Message, 0, len(gmsgs))
+	for i, msg := range gmsgs {
+		b, err := msg.MarshalBinary()
+		if err != nil {
+			return nil, err
 		}
 
-		return nmsgs, nil
+		nmsgs = append(nmsgs, rewrite(i, netlink.Message{
+			// Mimic the sequence and PID of the request for validation.
+			Header: netlink.Header{
+				Sequence: req.Header.Sequence,
+				PID:      req.Header.PID,
+			},
+			Data: b,
+		}))
 	}
+
+	return nmsgs, nil
+}
+
+// extAckError builds a netlink error message in response to req which carries
+// the extended acknowledgement attributes from nerr, in the same format used
+// by the kernel: the error number, the original request, and the attributes.
+func extAckError(nerr *errnoError, req netlink.Message) ([]netlink.Message, error) {
+	const (
+		attrMsg  = 0x1 // unix.NLMSGERR_ATTR_MSG
+		attrOffs = 0x2 // unix.NLMSGERR_ATTR_OFFS
+	)
+
+	ae := netlink.NewAttributeEncoder()
+	if nerr.message != "" {
+		ae.String(attrMsg, nerr.message)
+	}
+	if nerr.offset != 0 {
+		ae.Uint32(attrOffs, nerr.offset)
+	}
+
+	tlvs, err := ae.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	// Echo the request with its length updated to account for padding, so
+	// the attributes are aligned and found at the offset the length implies.
+	data := make([]byte, nlmsgAlign(len(req.Data)))
+	copy(data, req.Data)
+	hdr := req.Header
+	hdr.Length = uint32(nlmsgHeaderLen + len(data))
+
+	b := make([]byte, 0, 4+int(hdr.Length)+len(tlvs))
+	b = append(b, nlenc.Int32Bytes(-1*int32(nerr.number))...)
+	b = append(b, nlenc.Uint32Bytes(hdr.Length)...)
+	b = append(b, nlenc.Uint16Bytes(uint16(hdr.Type))...)
+	b = append(b, nlenc.Uint16Bytes(uint16(hdr.Flags))...)
+	b = append(b, nlenc.Uint32Bytes(hdr.Sequence)...)
+	b = append(b, nlenc.Uint32Bytes(hdr.PID)...)
+	b = append(b, data...)
+	b = append(b, tlvs...)
+
+	return []netlink.Message{{
+		Header: netlink.Header{
+			Type:     netlink.Error,
+			Flags:    netlink.AcknowledgeTLVs,
+			Sequence: req.Header.Sequence,
+			PID:      req.Header.PID,
+		},
+		Data: b,
+	}}, nil
 }
@@ -4,6 +4,7 @@
 package genltest_test
 
 import (
+	"errors"
 	"os"
 	"syscall"
 	"testing"
@@ -35,3 +36,64 @@ func TestConnLinuxReceiveError(t *testing.T) {
 		t.Fatalf("expected permission denied error, but got: %v", err)
 	}
 }
+
+func TestErrorConstructors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "permission",
+			err:  genltest.ErrorPermission(),
+			want: os.ErrPermission,
+		},
+		{
+			name: "not exist",
+			err:  genltest.ErrorNotExist(),
+			want: os.ErrNotExist,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := genltest.Dial(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+				return nil, tt.err
+			})
+			defer c.Close()
+
+			if _, err := c.Execute(genetlink.Message{}, 1, netlink.Request); !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, but got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestErrorWithExtAck(t *testing.T) {
+	c := genltest.Dial(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, genltest.ErrorWithExtAck(int(syscall.EINVAL), "bad attribute", 24)
+	})
+	defer c.Close()
+
+	// An odd-length request exercises padding of the echoed request.
+	_, err := c.Execute(genetlink.Message{Data: []byte{0xff}}, 1, netlink.Request)
+
+	var oerr *netlink.OpError
+	if !errors.As(err, &oerr) {
+		t.Fatalf("expected netlink.OpError, but got: %v", err)
+	}
+
+	if !errors.Is(err, syscall.EINVAL) {
+		t.Fatalf("expected EINVAL, but got: %v", err)
+	}
+	if want, got := "bad attribute", oerr.Message; want != got {
+		t.Fatalf("unexpected message: %q, want: %q", got, want)
+	}
+	if want, got := 24, oerr.Offset; want != got {
+		t.Fatalf("unexpected offset: %d, want: %d", got, want)
+	}
+}
+
+func TestCheckFamilyRoundTrip(t *testing.T) {
+	genltest.CheckFamilyRoundTrip(t, 1, 100)
+}
@@ -5,11 +5,14 @@ import (
 	"errors"
 	"io"
 	"reflect"
+	"syscall"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/mdlayher/genetlink"
 	"github.com/mdlayher/genetlink/genltest"
 	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nltest"
 )
 
 func TestConnSend(t *testing.T) {
@@ -181,6 +184,208 @@ func TestCheckRequest(t *testing.T) {
 	}
 }
 
+func TestCheckRequestError(t *testing.T) {
+	fn := genltest.CheckRequest(0x10, 3, netlink.Request|netlink.Dump, noop)
+
+	greq := genetlink.Message{
+		Header: genetlink.Header{Command: 1},
+		Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+			{Type: 2, Data: []byte("nl80211\x00")},
+			{Type: 6 | netlink.Nested, Data: nltest.MustMarshalAttributes([]netlink.Attribute{{Type: 1}})},
+		}),
+	}
+
+	nreq := netlink.Message{
+		Header: netlink.Header{
+			Type:  0x15,
+			Flags: netlink.Request | netlink.Acknowledge,
+		},
+	}
+
+	_, err := fn(greq, nreq)
+
+	var rerr *genltest.RequestError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected RequestError, but got: %v", err)
+	}
+
+	want := `genltest: unexpected request (-want +got):
+	family:
+		- 0x10 (nlctrl)
+		+ 0x15 (ethtool)
+	command:
+		- 3
+		+ 1
+	flags:
+		- request|dump
+		+ request|acknowledge
+	attributes: [2: 8 bytes, 6: nested, 4 bytes]`
+
+	if diff := cmp.Diff(want, err.Error()); diff != "" {
+		t.Fatalf("unexpected error message (-want +got):\n%s", diff)
+	}
+}
+
 var noop = func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
 	return nil, nil
 }
+
+func TestDialConfigMembership(t *testing.T) {
+	type change struct {
+		Group uint32
+		Join  bool
+	}
+
+	var changes []change
+	c := genltest.DialConfig(noop, &genltest.Config{
+		Groups: []uint32{1, 2},
+		Membership: func(group uint32, join bool) error {
+			changes = append(changes, change{Group: group, Join: join})
+			return nil
+		},
+	})
+	defer c.Close()
+
+	for _, g := range []uint32{1, 2} {
+		if err := c.JoinGroup(g); err != nil {
+			t.Fatalf("failed to join group %d: %v", g, err)
+		}
+	}
+
+	if err := c.LeaveGroup(1); err != nil {
+		t.Fatalf("failed to leave group: %v", err)
+	}
+
+	if err := c.JoinGroup(3); !errors.Is(err, syscall.EINVAL) {
+		t.Fatalf("expected EINVAL joining unknown group, but got: %v", err)
+	}
+
+	want := []change{
+		{Group: 1, Join: true},
+		{Group: 2, Join: true},
+		{Group: 1, Join: false},
+	}
+
+	if !reflect.DeepEqual(want, changes) {
+		t.Fatalf("unexpected membership changes:\n- want: %v\n-  got: %v",
+			want, changes)
+	}
+}
+
+func TestDialConfigMembershipError(t *testing.T) {
+	errFoo := errors.New("foo")
+
+	c := genltest.DialConfig(noop, &genltest.Config{
+		Membership: func(_ uint32, _ bool) error {
+			return errFoo
+		},
+	})
+	defer c.Close()
+
+	if err := c.JoinGroup(1); !errors.Is(err, errFoo) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDialConfigBufferSize(t *testing.T) {
+	want := make([]genetlink.Message, 5)
+	for i := range want {
+		want[i] = genetlink.Message{
+			Header: genetlink.Header{Command: uint8(i)},
+			Data:   make([]byte, 16),
+		}
+	}
+
+	c := genltest.DialConfig(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return want, nil
+	}, &genltest.Config{
+		// Room for two messages per read.
+		BufferSize: 80,
+	})
+	defer c.Close()
+
+	if _, err := c.Send(genetlink.Message{}, 1, netlink.Request|netlink.Dump); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+
+	got, nmsgs, err := c.Receive()
+	if err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected messages:\n- want: %v\n-  got: %v", want, got)
+	}
+
+	for i, m := range nmsgs {
+		if m.Header.Flags&netlink.Multi == 0 {
+			t.Fatalf("message %d is not multi-part: %+v", i, m.Header)
+		}
+	}
+}
+
+func TestDialConfigBufferSizeMulticast(t *testing.T) {
+	var calls int
+	c := genltest.DialConfig(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		calls++
+		return []genetlink.Message{
+			{Header: genetlink.Header{Command: 1}},
+			{Header: genetlink.Header{Command: 2}},
+		}, nil
+	}, &genltest.Config{
+		// Room for one message per read.
+		BufferSize: 20,
+	})
+	defer c.Close()
+
+	for _, cmd := range []uint8{1, 2, 1} {
+		msgs, _, err := c.Receive()
+		if err != nil {
+			t.Fatalf("failed to receive: %v", err)
+		}
+
+		if len(msgs) != 1 || msgs[0].Header.Command != cmd {
+			t.Fatalf("unexpected messages for command %d: %v", cmd, msgs)
+		}
+	}
+
+	if calls != 2 {
+		t.Fatalf("unexpected number of Func calls: %d", calls)
+	}
+}
+
+func TestDialConfigPID(t *testing.T) {
+	const (
+		pid       = 100
+		kernelPID = 200
+	)
+
+	c := genltest.DialConfig(func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return []genetlink.Message{greq}, nil
+	}, &genltest.Config{
+		PID:       pid,
+		KernelPID: kernelPID,
+	})
+	defer c.Close()
+
+	nreq, err := c.Send(genetlink.Message{}, 1, netlink.Request)
+	if err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+	if nreq.Header.PID != pid {
+		t.Fatalf("unexpected request PID: %d", nreq.Header.PID)
+	}
+
+	// The reply mimics the client PID, and the following multicast message
+	// carries the kernel PID.
+	for _, want := range []uint32{pid, kernelPID} {
+		_, nmsgs, err := c.Receive()
+		if err != nil {
+			t.Fatalf("failed to receive: %v", err)
+		}
+
+		if got := nmsgs[0].Header.PID; want != got {
+			t.Fatalf("unexpected reply PID: %d, want: %d", got, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package genltest
+
+import (
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+)
+
+// Interleave returns a Func which inserts unsolicited messages between fn's
+// replies to each dump request, as the kernel may do when a socket which has
+// joined multicast groups also performs a dump. Interleave can be used to
+// verify that consumers separate the replies to their requests from
+// asynchronous events, rather than assuming every received message is a
+// reply.
+//
+// One of events is inserted after each reply to a dump request other than
+// the final "multi-part done" message, cycling through events in order. The
+// inserted messages are framed as multicast messages from the generic
+// netlink family with the specified ID: they carry no sequence number, port
+// ID, or flags. Replies returned directly by fn are framed as a multi-part
+// message terminated by a "multi-part done" message, as the kernel does.
+//
+// Replies to requests which are not dump requests are passed through
+// unchanged, as are errors returned by fn.
+func Interleave(family uint16, events []genetlink.Message, fn Func) Func {
+	return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		if len(events) == 0 || nreq.Header.Flags&netlink.Dump == 0 {
+			return fn(greq, nreq)
+		}
+
+		msgs, err := fn(greq, nreq)
+		if err != nil {
+			return nil, err
+		}
+
+		c := callFor(nreq)
+		switch {
+		case c == nil:
+			// Events can only be framed once encoded by a connection.
+			return msgs, nil
+		case c.blocked != nil:
+			// Interleave the replies once they are unblocked.
+			inner := c.blocked.fn
+			c.blocked.fn = func() ([]genetlink.Message, error) {
+				return Interleave(family, events, func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+					return inner()
+				})(greq, nreq)
+			}
+
+			return msgs, nil
+		case c.rewrite == nil:
+			// Replies returned directly by fn are not yet framed.
+			msgs, c.rewrite = multipart(msgs)
+		}
+
+		return interleave(family, events, msgs, c), nil
+	}
+}
+
+// multipart frames the replies msgs to a dump request as a multi-part
+// message terminated by a "multi-part done" message, returning the messages
+// to deliver and the rewrite which frames them.
+func multipart(msgs []genetlink.Message) ([]genetlink.Message, func(i int, m netlink.Message) netlink.Message) {
+	// The "multi-part done" message carries an error number of 0, which is
+	// encoded identically to an empty generic netlink header.
+	msgs = append(msgs, genetlink.Message{})
+
+	return msgs, func(i int, m netlink.Message) netlink.Message {
+		m.Header.Flags |= netlink.Multi
+		if i == len(msgs)-1 {
+			m.Header.Type = netlink.Done
+		}
+
+		return m
+	}
+}
+
+// interleave inserts events between the replies msgs of the call c, updating
+// the call's rewrite to match, and returns the messages to deliver.
+func interleave(family uint16, events, msgs []genetlink.Message, c *call) []genetlink.Message {
+	var (
+		out []genetlink.Message
+		// index maps each message to the index of its reply in msgs, or -1
+		// for inserted events.
+		index []int
+	)
+
+	for i, m := range msgs {
+		out = append(out, m)
+		index = append(index, i)
+
+		if i == len(msgs)-1 {
+			break
+		}
+
+		out = append(out, events[i%len(events)])
+		index = append(index, -1)
+	}
+
+	rewrite := c.rewrite
+	c.rewrite = func(i int, m netlink.Message) netlink.Message {
+		if j := index[i]; j != -1 {
+			return rewrite(j, m)
+		}
+
+		m.Header = netlink.Header{
+			Length: uint32(nlmsgHeaderLen + len(m.Data)),
+			Type:   netlink.HeaderType(family),
+		}
+
+		return m
+	}
+
+	return out
+}
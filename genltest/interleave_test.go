@@ -0,0 +1,101 @@
+package genltest_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestInterleave(t *testing.T) {
+	const family = 0x30
+
+	events := []genetlink.Message{
+		{Header: genetlink.Header{Command: 0xa}},
+		{Header: genetlink.Header{Command: 0xb}},
+	}
+
+	replies := []genetlink.Message{
+		{Header: genetlink.Header{Command: 1}},
+		{Header: genetlink.Header{Command: 2}},
+		{Header: genetlink.Header{Command: 3}},
+	}
+
+	c := genltest.Dial(genltest.Interleave(family, events, func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return replies, nil
+	}))
+	defer c.Close()
+
+	// A consumer which assumes every message is a reply fails validation.
+	req := genetlink.Message{Header: genetlink.Header{Command: 1}}
+	if _, err := c.Execute(req, 0x20, netlink.Request|netlink.Dump); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+
+	// A consumer which separates replies from events by sequence number
+	// observes both.
+	nreq, err := c.Send(req, 0x20, netlink.Request|netlink.Dump)
+	if err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+
+	msgs, nmsgs, err := c.Receive()
+	if err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+
+	var (
+		gotReplies, gotEvents []genetlink.Message
+		commands              []uint8
+	)
+
+	for i, nm := range nmsgs {
+		commands = append(commands, msgs[i].Header.Command)
+
+		switch nm.Header.Sequence {
+		case nreq.Header.Sequence:
+			if nm.Header.Flags&netlink.Multi == 0 {
+				t.Fatalf("reply %d is not a multi-part message: %+v", i, nm.Header)
+			}
+
+			gotReplies = append(gotReplies, msgs[i])
+		case 0:
+			if want := (netlink.Header{Length: 20, Type: family}); nm.Header != want {
+				t.Fatalf("unexpected event header (-want +got):\n%s", cmp.Diff(want, nm.Header))
+			}
+
+			gotEvents = append(gotEvents, msgs[i])
+		default:
+			t.Fatalf("unexpected sequence number for message %d: %d", i, nm.Header.Sequence)
+		}
+	}
+
+	if diff := cmp.Diff([]uint8{1, 0xa, 2, 0xb, 3, 0xa}, commands); diff != "" {
+		t.Fatalf("unexpected message order (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(replies, gotReplies); diff != "" {
+		t.Fatalf("unexpected replies (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(append(events, events[0]), gotEvents); diff != "" {
+		t.Fatalf("unexpected events (-want +got):\n%s", diff)
+	}
+}
+
+func TestInterleaveServeFamilies(t *testing.T) {
+	events := []genetlink.Message{{Header: genetlink.Header{Command: 0xa}}}
+
+	c := genltest.Dial(genltest.Interleave(0x30, events,
+		genltest.ServeFamilies(genltest.Families(), noop)))
+	defer c.Close()
+
+	// Requests which are not dumps are unaffected.
+	if _, err := c.GetFamily("nl80211"); err != nil {
+		t.Fatalf("failed to get family: %v", err)
+	}
+
+	if _, err := c.ListFamilies(); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+}
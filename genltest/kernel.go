@@ -0,0 +1,126 @@
+package genltest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nltest"
+)
+
+// A Kernel is a fake generic netlink kernel which can back several
+// genetlink.Conn values at once, so that tests can cover applications which
+// use multiple connections, such as one for dumps and another for events,
+// and interactions between connections.
+//
+// Requests from every connection are passed to a single Func, which must be
+// safe for concurrent use. Each connection is assigned a distinct port ID,
+// so the Func can identify the connection which sent a request using the
+// PID field of the request's netlink header.
+//
+// Unlike a connection created by Dial, a Func used by a Kernel is not
+// invoked for multicast interactions. Instead, multicast messages are sent
+// using Multicast, and are delivered only to the connections which have
+// joined the message's group. A Func may call Multicast to notify other
+// connections of the effects of a request, as the kernel does. Receive on a
+// connection with no pending replies blocks until a multicast message
+// arrives, its deadline expires, or it is closed.
+type Kernel struct {
+	fn  Func
+	cfg Config
+
+	mu    sync.Mutex
+	next  uint32
+	socks map[*socket]struct{}
+}
+
+// NewKernel creates a Kernel which passes requests to fn. The configuration
+// cfg applies to every connection. If cfg is nil, a default configuration is
+// used. cfg.PID specifies the port ID of the first connection, and each
+// subsequent connection is assigned the next port ID.
+func NewKernel(fn Func, cfg *Config) *Kernel {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	next := cfg.PID
+	if next == 0 {
+		next = nltest.PID
+	}
+
+	return &Kernel{
+		fn:    fn,
+		cfg:   *cfg,
+		next:  next,
+		socks: make(map[*socket]struct{}),
+	}
+}
+
+// Dial creates a genetlink.Conn backed by k. The connection should be closed
+// as usual when it is no longer needed.
+func (k *Kernel) Dial() *genetlink.Conn {
+	return genetlink.NewTransportConn(k.transport())
+}
+
+// transport creates the transport of a new connection backed by k.
+func (k *Kernel) transport() *transport {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	pid := k.next
+	k.next++
+
+	s := newSocket(adapt(k.fn), &k.cfg)
+	s.kernel = k
+	k.socks[s] = struct{}{}
+
+	return &transport{
+		Conn: netlink.NewConn(s, pid),
+		s:    s,
+		dial: func() genetlink.Transport { return k.transport() },
+	}
+}
+
+// Multicast sends msgs to the connections which have joined the multicast
+// group with the specified ID, as messages from the generic netlink family
+// with the specified ID. It returns the number of connections which received
+// the messages.
+func (k *Kernel) Multicast(family uint16, group uint32, msgs ...genetlink.Message) (int, error) {
+	nmsgs := make([]netlink.Message, 0, len(msgs))
+	for _, m := range msgs {
+		b, err := m.MarshalBinary()
+		if err != nil {
+			return 0, fmt.Errorf("genltest: failed to marshal multicast message: %v", err)
+		}
+
+		nmsgs = append(nmsgs, netlink.Message{
+			Header: netlink.Header{
+				Length: uint32(nlmsgHeaderLen + len(b)),
+				Type:   netlink.HeaderType(family),
+				PID:    k.cfg.KernelPID,
+			},
+			Data: b,
+		})
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	var n int
+	for s := range k.socks {
+		if s.deliver(group, nmsgs) {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// remove removes a closed socket from k.
+func (k *Kernel) remove(s *socket) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	delete(k.socks, s)
+}
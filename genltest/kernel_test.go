@@ -0,0 +1,117 @@
+package genltest_test
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestKernelMultipleConns(t *testing.T) {
+	genltest.VerifyNoLeaks(t)
+
+	const (
+		family = 0x20
+		group  = 0x5
+	)
+
+	var (
+		k *genltest.Kernel
+
+		mu   sync.Mutex
+		pids []uint32
+	)
+
+	k = genltest.NewKernel(func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		mu.Lock()
+		pids = append(pids, nreq.Header.PID)
+		mu.Unlock()
+
+		// Notify event listeners of the change, as the kernel would.
+		event := genetlink.Message{
+			Header: genetlink.Header{Command: 2},
+			Data:   greq.Data,
+		}
+		if _, err := k.Multicast(family, group, event); err != nil {
+			return nil, err
+		}
+
+		return []genetlink.Message{greq}, nil
+	}, nil)
+
+	events, requests := k.Dial(), k.Dial()
+	defer events.Close()
+	defer requests.Close()
+
+	if err := events.JoinGroup(group); err != nil {
+		t.Fatalf("failed to join group: %v", err)
+	}
+
+	// Only the connection which joined the group receives the event.
+	req := genetlink.Message{
+		Header: genetlink.Header{Command: 1},
+		Data:   []byte{0x05, 0x00, 0x01, 0x00, 0xff, 0x00, 0x00, 0x00},
+	}
+
+	if _, err := requests.Execute(req, family, netlink.Request); err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+
+	msgs, nmsgs, err := events.Receive()
+	if err != nil {
+		t.Fatalf("failed to receive event: %v", err)
+	}
+
+	want := []genetlink.Message{{
+		Header: genetlink.Header{Command: 2},
+		Data:   req.Data,
+	}}
+
+	if diff := cmp.Diff(want, msgs); diff != "" {
+		t.Fatalf("unexpected events (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(netlink.HeaderType(family), nmsgs[0].Header.Type); diff != "" {
+		t.Fatalf("unexpected event type (-want +got):\n%s", diff)
+	}
+
+	// Each connection has its own port ID.
+	if _, err := events.Execute(req, family, netlink.Request); err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+
+	if len(pids) != 2 || pids[0] == pids[1] {
+		t.Fatalf("expected distinct port IDs, but got: %v", pids)
+	}
+
+	// The event for the second request is still pending.
+	if _, _, err := events.Receive(); err != nil {
+		t.Fatalf("failed to receive event: %v", err)
+	}
+
+	// With no events pending, Receive blocks until the deadline.
+	if err := events.SetReadDeadline(time.Now().Add(10 * time.Millisecond)); err != nil {
+		t.Fatalf("failed to set deadline: %v", err)
+	}
+	if _, _, err := events.Receive(); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, but got: %v", err)
+	}
+
+	// Closed and unsubscribed connections no longer receive events.
+	if err := events.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	n, err := k.Multicast(family, group, want...)
+	if err != nil {
+		t.Fatalf("failed to multicast: %v", err)
+	}
+	if diff := cmp.Diff(0, n); diff != "" {
+		t.Fatalf("unexpected number of receivers (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,136 @@
+package genltest
+
+import (
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Timing of the checks performed by VerifyNoLeaks.
+const (
+	// leakGrace is how long receive calls on closed connections may take to
+	// return before they are reported as leaks.
+	leakGrace = time.Second
+
+	// leakSettle is how long VerifyNoLeaks observes closed connections for
+	// receive calls made by loops which did not stop.
+	leakSettle = 10 * time.Millisecond
+)
+
+// sockets tracks the sockets created by genltest while any calls to
+// VerifyNoLeaks are active, so that sockets are not retained otherwise.
+var sockets struct {
+	mu     sync.Mutex
+	active int
+	next   uint64
+	all    map[*socket]struct{}
+}
+
+// track registers s for leak detection if VerifyNoLeaks is active, assigning
+// it an ID and recording the stack which created it.
+func track(s *socket) {
+	sockets.mu.Lock()
+	defer sockets.mu.Unlock()
+
+	if sockets.active == 0 {
+		return
+	}
+
+	sockets.next++
+	s.id, s.stack = sockets.next, debug.Stack()
+	sockets.all[s] = struct{}{}
+}
+
+// watch begins tracking sockets, returning the ID of the newest socket.
+func watch() uint64 {
+	sockets.mu.Lock()
+	defer sockets.mu.Unlock()
+
+	if sockets.active == 0 {
+		sockets.all = make(map[*socket]struct{})
+	}
+	sockets.active++
+
+	return sockets.next
+}
+
+// unwatch returns the sockets created after the socket with the specified
+// ID, in the order in which they were created, and stops tracking sockets
+// if no other calls to VerifyNoLeaks are active.
+func unwatch(id uint64) []*socket {
+	sockets.mu.Lock()
+	defer sockets.mu.Unlock()
+
+	var ss []*socket
+	for s := range sockets.all {
+		if s.id > id {
+			ss = append(ss, s)
+		}
+	}
+
+	sockets.active--
+	if sockets.active == 0 {
+		sockets.all = nil
+	}
+
+	sort.Slice(ss, func(i, j int) bool { return ss[i].id < ss[j].id })
+	return ss
+}
+
+// VerifyNoLeaks fails t if connections created by Dial, DialConfig, or a
+// Kernel during the test are not closed by the time the test and its
+// deferred functions complete, or if receive loops keep calling Receive on
+// them after they are closed. Each failure reports the stack which created
+// the leaked connection. VerifyNoLeaks catches resource leaks in the
+// teardown paths of code under test.
+//
+// VerifyNoLeaks should be called at the start of a test. Connections created
+// by parallel tests cannot be distinguished from those of t, so
+// VerifyNoLeaks should not be used in tests which call t.Parallel or which
+// run alongside them.
+func VerifyNoLeaks(t testing.TB) {
+	t.Helper()
+
+	start := watch()
+	t.Cleanup(func() {
+		ss := unwatch(start)
+
+		var closed []*socket
+		for _, s := range ss {
+			if !s.closed() {
+				t.Errorf("genltest: connection was not closed, created at:\n%s", s.stack)
+				continue
+			}
+
+			closed = append(closed, s)
+		}
+
+		// Give receive calls on closed connections a chance to return.
+		deadline := time.Now().Add(leakGrace)
+		for _, s := range closed {
+			for atomic.LoadInt32(&s.receiving) > 0 && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+		}
+
+		if len(closed) == 0 {
+			return
+		}
+
+		calls := make([]uint64, len(closed))
+		for i, s := range closed {
+			calls[i] = atomic.LoadUint64(&s.receives)
+		}
+
+		time.Sleep(leakSettle)
+
+		for i, s := range closed {
+			if atomic.LoadInt32(&s.receiving) > 0 || atomic.LoadUint64(&s.receives) != calls[i] {
+				t.Errorf("genltest: receive loop still running on closed connection, created at:\n%s", s.stack)
+			}
+		}
+	})
+}
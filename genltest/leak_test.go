@@ -0,0 +1,103 @@
+package genltest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink/genltest"
+)
+
+func TestVerifyNoLeaks(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func() (stop func())
+		errs []string
+	}{
+		{
+			name: "closed",
+			fn: func() func() {
+				c := genltest.Dial(noop)
+				_ = c.Close()
+				return func() {}
+			},
+		},
+		{
+			name: "not closed",
+			fn: func() func() {
+				c := genltest.Dial(noop)
+				return func() { _ = c.Close() }
+			},
+			errs: []string{"genltest: connection was not closed"},
+		},
+		{
+			name: "receive loop",
+			fn: func() func() {
+				c := genltest.Dial(noop)
+				_ = c.Close()
+
+				// A loop which ignores errors keeps running after the
+				// connection is closed.
+				done := make(chan struct{})
+				stopped := make(chan struct{})
+				go func() {
+					defer close(stopped)
+					for {
+						select {
+						case <-done:
+							return
+						default:
+							_, _, _ = c.Receive()
+						}
+					}
+				}()
+
+				return func() {
+					close(done)
+					<-stopped
+				}
+			},
+			errs: []string{"genltest: receive loop still running on closed connection"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &fakeTB{TB: t}
+			genltest.VerifyNoLeaks(tb)
+
+			stop := tt.fn()
+			tb.cleanup()
+			stop()
+
+			if diff := cmp.Diff(tt.errs, tb.errs); diff != "" {
+				t.Fatalf("unexpected errors (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// A fakeTB is a testing.TB which records errors and cleanup functions.
+type fakeTB struct {
+	testing.TB
+	errs     []string
+	cleanups []func()
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Cleanup(fn func()) { tb.cleanups = append(tb.cleanups, fn) }
+
+func (tb *fakeTB) Errorf(format string, args ...interface{}) {
+	// Omit the stack trace which follows the description.
+	s := fmt.Sprintf(format, args...)
+	tb.errs = append(tb.errs, strings.SplitN(s, ",", 2)[0])
+}
+
+// cleanup runs the cleanup functions in reverse order.
+func (tb *fakeTB) cleanup() {
+	for i := len(tb.cleanups) - 1; i >= 0; i-- {
+		tb.cleanups[i]()
+	}
+}
@@ -0,0 +1,154 @@
+package genltest
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+)
+
+// A Mock dispatches requests to Funcs registered as expectations, and
+// records requests which match no expectation, so that tests can verify that
+// the code under test sends exactly the requests they expect.
+//
+// Use Func to obtain a Func for Dial or DialConfig, and Verify (or DialMock)
+// to check the expectations once the test is complete.
+type Mock struct {
+	mu         sync.Mutex
+	exps       []*Expectation
+	unexpected []string
+}
+
+// An Expectation is a request expected by a Mock.
+type Expectation struct {
+	family  uint16
+	command uint8
+	fn      Func
+
+	times, calls int
+}
+
+// NewMock creates a Mock with no expectations.
+func NewMock() *Mock {
+	return &Mock{}
+}
+
+// Expect registers an expectation for a request with the specified generic
+// netlink family and command, which is answered by fn. If family or command
+// are set to the zero value, any value matches. Expectations are matched in
+// the order they are registered, and by default are satisfied by a single
+// request.
+func (m *Mock) Expect(family uint16, command uint8, fn Func) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := &Expectation{
+		family:  family,
+		command: command,
+		fn:      fn,
+		times:   1,
+	}
+	m.exps = append(m.exps, e)
+
+	return e
+}
+
+// Times sets the number of requests which satisfy e.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Func returns a Func which passes each request to the first matching
+// expectation which has not yet been satisfied. Requests which match no
+// expectation are recorded and fail with an error. Multicast interactions
+// have no request, and return no messages.
+func (m *Mock) Func() Func {
+	return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		if nreq.Header == (netlink.Header{}) && len(nreq.Data) == 0 {
+			return nil, io.EOF
+		}
+
+		fn, err := m.match(greq, nreq)
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(greq, nreq)
+	}
+}
+
+// match finds the Func for the expectation which matches a request.
+func (m *Mock) match(greq genetlink.Message, nreq netlink.Message) (Func, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	family := uint16(nreq.Header.Type)
+	for _, e := range m.exps {
+		if e.calls >= e.times {
+			continue
+		}
+		if e.family != 0 && e.family != family {
+			continue
+		}
+		if e.command != 0 && e.command != greq.Header.Command {
+			continue
+		}
+
+		e.calls++
+		return e.fn, nil
+	}
+
+	req := fmt.Sprintf("family: %d, command: %d, flags: %s",
+		family, greq.Header.Command, nreq.Header.Flags)
+	m.unexpected = append(m.unexpected, req)
+
+	return nil, fmt.Errorf("genltest: unexpected request: %s", req)
+}
+
+// Verify returns an error if any requests matched no expectation, or if any
+// expectations were not satisfied.
+func (m *Mock) Verify() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var problems []string
+	for _, req := range m.unexpected {
+		problems = append(problems, "unexpected request: "+req)
+	}
+
+	for _, e := range m.exps {
+		if e.calls < e.times {
+			problems = append(problems, fmt.Sprintf(
+				"unsatisfied expectation: family: %d, command: %d, got %d of %d requests",
+				e.family, e.command, e.calls, e.times))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("genltest: mock verification failed:\n\t%s", strings.Join(problems, "\n\t"))
+}
+
+// DialMock sets up a genetlink.Conn for testing which passes requests to m.
+// The connection is closed and m is verified when tb and all of its subtests
+// complete, failing tb if verification fails.
+func DialMock(tb testing.TB, m *Mock) *genetlink.Conn {
+	tb.Helper()
+
+	c := Dial(m.Func())
+	tb.Cleanup(func() {
+		_ = c.Close()
+		if err := m.Verify(); err != nil {
+			tb.Error(err)
+		}
+	})
+
+	return c
+}
@@ -0,0 +1,66 @@
+package genltest_test
+
+import (
+	"testing"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestMock(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []uint8
+		ok       bool
+	}{
+		{
+			name:     "OK",
+			commands: []uint8{1, 2, 2},
+			ok:       true,
+		},
+		{
+			name:     "unexpected",
+			commands: []uint8{1, 2, 2, 3},
+		},
+		{
+			name:     "unsatisfied",
+			commands: []uint8{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := genltest.NewMock()
+			m.Expect(1, 1, echo)
+			m.Expect(1, 2, echo).Times(2)
+
+			c := genltest.DialConfig(m.Func(), &genltest.Config{Verify: m.Verify})
+
+			for _, cmd := range tt.commands {
+				_, err := c.Execute(genetlink.Message{Header: genetlink.Header{Command: cmd}}, 1, netlink.Request)
+				if cmd == 3 && err == nil {
+					t.Fatal("expected an error for unexpected request, but none occurred")
+				}
+			}
+
+			err := c.Close()
+			if err != nil && tt.ok {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err == nil && !tt.ok {
+				t.Fatal("expected an error, but none occurred")
+			}
+		})
+	}
+}
+
+func TestDialMock(t *testing.T) {
+	m := genltest.NewMock()
+	m.Expect(0, 1, echo)
+
+	c := genltest.DialMock(t, m)
+	if _, err := c.Execute(genetlink.Message{Header: genetlink.Header{Command: 1}}, 1, netlink.Request); err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package genltest
+
+import "github.com/mdlayher/genetlink"
+
+// CheckPolicy returns a Func that validates the attributes of requests to the
+// generic netlink family with the specified ID against the policy p, in the
+// same way as the kernel, and then passes valid requests through to fn.
+//
+// The policy used for a request is selected by its command and whether or
+// not it is a dump request. Requests for other families, and commands with
+// no policy in p, are passed through to fn without validation.
+//
+// Requests with attributes of the wrong length or with values out of range
+// fail with an EINVAL or ERANGE error. If strict is true, requests with
+// attributes which do not appear in the policy also fail with EINVAL, as is
+// the case for most modern generic netlink families.
+// Like the kernel, CheckPolicy also fails requests with EINVAL when their
+// attributes are nested more than 10 levels deep.
+//
+// CheckPolicy is useful to catch attribute encoding bugs which a more
+// permissive Func would hide. Policies can be retrieved from a real kernel
+// using genetlink.Conn.GetPolicy.
+func CheckPolicy(family uint16, p genetlink.Policy, strict bool, fn Func) Func {
+	return checkPolicy(family, p, strict, fn)
+}
@@ -0,0 +1,276 @@
+//go:build linux
+// +build linux
+
+package genltest
+
+import (
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/internal/endian"
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// checkPolicy is the Linux implementation of CheckPolicy.
+func checkPolicy(family uint16, p genetlink.Policy, strict bool, fn Func) Func {
+	return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		if nreq.Header.Type != netlink.HeaderType(family) {
+			return fn(greq, nreq)
+		}
+
+		set, ok := opPolicySet(p, greq.Header.Command, nreq.Header.Flags&netlink.Dump != 0)
+		if !ok {
+			return fn(greq, nreq)
+		}
+
+		v := &validator{
+			p:      p,
+			strict: strict,
+		}
+
+		if err := v.validate(set, greq.Data); err != nil {
+			return nil, err
+		}
+
+		return fn(greq, nreq)
+	}
+}
+
+// opPolicySet returns the PolicySet used to validate a do or dump request
+// for command, if one exists.
+func opPolicySet(p genetlink.Policy, command uint8, dump bool) (genetlink.PolicySet, bool) {
+	for _, op := range p.Ops {
+		if op.Command != uint32(command) {
+			continue
+		}
+
+		index := op.Do
+		if dump {
+			index = op.Dump
+		}
+		if index < 0 {
+			return genetlink.PolicySet{}, false
+		}
+
+		return p.Set(uint32(index))
+	}
+
+	return genetlink.PolicySet{}, false
+}
+
+// A validator validates attributes against the policy sets of a Policy.
+type validator struct {
+	p      genetlink.Policy
+	strict bool
+	depth  int
+}
+
+// maxPolicyDepth is the maximum nesting depth of validated attributes,
+// matching the kernel's MAX_POLICY_RECURSION_DEPTH.
+const maxPolicyDepth = 10
+
+// Errors returned for attributes which fail validation, matching those
+// returned by the kernel.
+var (
+	errInvalid = Error(int(unix.EINVAL))
+	errRange   = Error(int(unix.ERANGE))
+)
+
+// validate validates the packed attributes in b against set.
+func (v *validator) validate(set genetlink.PolicySet, b []byte) error {
+	if v.depth >= maxPolicyDepth {
+		return errInvalid
+	}
+
+	v.depth++
+	defer func() { v.depth-- }()
+
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil {
+		return errInvalid
+	}
+
+	for _, a := range attrs {
+		ap, ok := attributePolicy(set, a.Type&^(unix.NLA_F_NESTED|unix.NLA_F_NET_BYTEORDER))
+		if !ok {
+			if v.strict {
+				return errInvalid
+			}
+
+			continue
+		}
+
+		if err := v.validateAttribute(ap, a.Data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateAttribute validates the data b of a single attribute against ap.
+func (v *validator) validateAttribute(ap genetlink.AttributePolicy, b []byte) error {
+	switch ap.Kind {
+	case genetlink.AttributeFlag:
+		if len(b) != 0 {
+			return errInvalid
+		}
+	case genetlink.AttributeU8, genetlink.AttributeU16, genetlink.AttributeU32, genetlink.AttributeU64:
+		size := intSize(ap.Kind)
+		if err := v.checkSize(size, b); err != nil {
+			return err
+		}
+
+		n := unsigned(b[:size])
+		if (ap.MinUnsigned != 0 || ap.MaxUnsigned != 0) && (n < ap.MinUnsigned || n > ap.MaxUnsigned) {
+			return errRange
+		}
+		if ap.Mask != 0 && n&^ap.Mask != 0 {
+			return errInvalid
+		}
+	case genetlink.AttributeS8, genetlink.AttributeS16, genetlink.AttributeS32, genetlink.AttributeS64:
+		size := intSize(ap.Kind)
+		if err := v.checkSize(size, b); err != nil {
+			return err
+		}
+
+		n := signed(b[:size])
+		if (ap.MinSigned != 0 || ap.MaxSigned != 0) && (n < ap.MinSigned || n > ap.MaxSigned) {
+			return errRange
+		}
+	case genetlink.AttributeBinary:
+		return checkLength(ap, len(b))
+	case genetlink.AttributeString:
+		// A trailing NUL is permitted but not counted.
+		if len(b) > 0 && b[len(b)-1] == 0x00 {
+			b = b[:len(b)-1]
+		}
+
+		return checkLength(ap, len(b))
+	case genetlink.AttributeNulString:
+		if len(b) == 0 || b[len(b)-1] != 0x00 {
+			return errInvalid
+		}
+
+		return checkLength(ap, len(b)-1)
+	case genetlink.AttributeNested:
+		set, ok := v.nested(ap)
+		if !ok {
+			return nil
+		}
+
+		return v.validate(set, b)
+	case genetlink.AttributeNestedArray:
+		set, ok := v.nested(ap)
+		if !ok {
+			return nil
+		}
+
+		attrs, err := netlink.UnmarshalAttributes(b)
+		if err != nil {
+			return errInvalid
+		}
+
+		for _, a := range attrs {
+			if err := v.validate(set, a.Data); err != nil {
+				return err
+			}
+		}
+	case genetlink.AttributeBitfield32:
+		if len(b) != 8 {
+			return errInvalid
+		}
+
+		value, selector := endian.Uint32(b[0:4]), endian.Uint32(b[4:8])
+		if (value|selector)&^ap.BitfieldMask != 0 {
+			return errInvalid
+		}
+	}
+
+	return nil
+}
+
+// intSize returns the size in bytes of an integer attribute of kind k.
+func intSize(k genetlink.AttributeKind) int {
+	switch k {
+	case genetlink.AttributeU8, genetlink.AttributeS8:
+		return 1
+	case genetlink.AttributeU16, genetlink.AttributeS16:
+		return 2
+	case genetlink.AttributeU32, genetlink.AttributeS32:
+		return 4
+	default:
+		return 8
+	}
+}
+
+// checkSize verifies that b is large enough for an integer of size bytes.
+// Short attributes are always rejected, and in strict mode, so are long ones.
+func (v *validator) checkSize(size int, b []byte) error {
+	switch {
+	case len(b) < size:
+		return errRange
+	case len(b) > size && v.strict:
+		return errInvalid
+	}
+
+	return nil
+}
+
+// nested returns the PolicySet which applies to the nested attributes of an
+// attribute with policy ap, if one exists.
+func (v *validator) nested(ap genetlink.AttributePolicy) (genetlink.PolicySet, bool) {
+	if ap.NestedMaxType == 0 {
+		return genetlink.PolicySet{}, false
+	}
+
+	return v.p.Set(ap.NestedIndex)
+}
+
+// checkLength verifies that an attribute's length n is within the bounds of
+// ap. A MaxLength of zero indicates no upper bound.
+func checkLength(ap genetlink.AttributePolicy, n int) error {
+	if uint32(n) < ap.MinLength || (ap.MaxLength != 0 && uint32(n) > ap.MaxLength) {
+		return errRange
+	}
+
+	return nil
+}
+
+// attributePolicy returns the AttributePolicy for typ from set, if one exists.
+func attributePolicy(set genetlink.PolicySet, typ uint16) (genetlink.AttributePolicy, bool) {
+	for _, ap := range set.Attributes {
+		if ap.Type == typ {
+			return ap, true
+		}
+	}
+
+	return genetlink.AttributePolicy{}, false
+}
+
+// unsigned decodes b as a native endian unsigned integer.
+func unsigned(b []byte) uint64 {
+	switch len(b) {
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(endian.Uint16(b))
+	case 4:
+		return uint64(endian.Uint32(b))
+	default:
+		return endian.Uint64(b)
+	}
+}
+
+// signed decodes b as a native endian signed integer.
+func signed(b []byte) int64 {
+	switch len(b) {
+	case 1:
+		return int64(int8(b[0]))
+	case 2:
+		return int64(int16(endian.Uint16(b)))
+	case 4:
+		return int64(int32(endian.Uint32(b)))
+	default:
+		return int64(endian.Uint64(b))
+	}
+}
@@ -0,0 +1,227 @@
+//go:build linux
+// +build linux
+
+package genltest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"github.com/mdlayher/netlink/nltest"
+	"golang.org/x/sys/unix"
+)
+
+func TestCheckPolicy(t *testing.T) {
+	const family = 0x20
+
+	p := genetlink.Policy{
+		Sets: []genetlink.PolicySet{
+			{
+				Index: 0,
+				Attributes: []genetlink.AttributePolicy{
+					{
+						Type:        1,
+						Kind:        genetlink.AttributeU8,
+						MinUnsigned: 1,
+						MaxUnsigned: 10,
+					},
+					{
+						Type:      2,
+						Kind:      genetlink.AttributeString,
+						MaxLength: 4,
+					},
+					{
+						Type:          3,
+						Kind:          genetlink.AttributeNested,
+						NestedIndex:   1,
+						NestedMaxType: 1,
+					},
+				},
+			},
+			{
+				Index: 1,
+				Attributes: []genetlink.AttributePolicy{{
+					Type: 1,
+					Kind: genetlink.AttributeFlag,
+				}},
+			},
+		},
+		Ops: []genetlink.OpPolicy{{
+			Command: 1,
+			Do:      0,
+			Dump:    -1,
+		}},
+	}
+
+	tests := []struct {
+		name   string
+		strict bool
+		family uint16
+		flags  netlink.HeaderFlags
+		attrs  []netlink.Attribute
+		err    error
+	}{
+		{
+			name: "OK",
+			attrs: []netlink.Attribute{
+				{Type: 1, Data: []byte{5}},
+				{Type: 2, Data: nlenc.Bytes("foo")},
+				{
+					Type: 3 | netlink.Nested,
+					Data: nltest.MustMarshalAttributes([]netlink.Attribute{{Type: 1}}),
+				},
+			},
+		},
+		{
+			name:  "out of range",
+			attrs: []netlink.Attribute{{Type: 1, Data: []byte{11}}},
+			err:   unix.ERANGE,
+		},
+		{
+			name:  "short integer",
+			attrs: []netlink.Attribute{{Type: 1}},
+			err:   unix.ERANGE,
+		},
+		{
+			name:  "long integer",
+			attrs: []netlink.Attribute{{Type: 1, Data: []byte{1, 0}}},
+		},
+		{
+			name:   "long integer strict",
+			strict: true,
+			attrs:  []netlink.Attribute{{Type: 1, Data: []byte{1, 0}}},
+			err:    unix.EINVAL,
+		},
+		{
+			name:  "long string",
+			attrs: []netlink.Attribute{{Type: 2, Data: nlenc.Bytes("foobar")}},
+			err:   unix.ERANGE,
+		},
+		{
+			name: "bad nested",
+			attrs: []netlink.Attribute{{
+				Type: 3,
+				Data: nltest.MustMarshalAttributes([]netlink.Attribute{{Type: 1, Data: []byte{1}}}),
+			}},
+			err: unix.EINVAL,
+		},
+		{
+			name:  "unknown",
+			attrs: []netlink.Attribute{{Type: 4}},
+		},
+		{
+			name:   "unknown strict",
+			strict: true,
+			attrs:  []netlink.Attribute{{Type: 4}},
+			err:    unix.EINVAL,
+		},
+		{
+			name:  "no dump policy",
+			flags: netlink.Dump,
+			attrs: []netlink.Attribute{{Type: 1, Data: []byte{11}}},
+		},
+		{
+			name:   "other family",
+			family: family + 1,
+			attrs:  []netlink.Attribute{{Type: 1, Data: []byte{11}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := genltest.Dial(genltest.CheckPolicy(family, p, tt.strict, noop))
+			defer c.Close()
+
+			if tt.family == 0 {
+				tt.family = family
+			}
+
+			req := genetlink.Message{
+				Header: genetlink.Header{Command: 1},
+				Data:   nltest.MustMarshalAttributes(tt.attrs),
+			}
+
+			_, err := c.Execute(req, tt.family, netlink.Request|netlink.Acknowledge|tt.flags)
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, but got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestCheckPolicyDepth(t *testing.T) {
+	const family = 0x20
+
+	// Attribute 1 is recursively nested within itself.
+	p := genetlink.Policy{
+		Sets: []genetlink.PolicySet{{
+			Index: 0,
+			Attributes: []genetlink.AttributePolicy{{
+				Type:          1,
+				Kind:          genetlink.AttributeNested,
+				NestedIndex:   0,
+				NestedMaxType: 1,
+			}},
+		}},
+		Ops: []genetlink.OpPolicy{{
+			Command: 1,
+			Do:      0,
+			Dump:    -1,
+		}},
+	}
+
+	nest := func(depth int) []byte {
+		var b []byte
+		for i := 0; i < depth; i++ {
+			b = nltest.MustMarshalAttributes([]netlink.Attribute{{
+				Type: 1 | netlink.Nested,
+				Data: b,
+			}})
+		}
+
+		return b
+	}
+
+	tests := []struct {
+		name  string
+		depth int
+		err   error
+	}{
+		{
+			name:  "OK",
+			depth: 9,
+		},
+		{
+			name:  "too deep",
+			depth: 10,
+			err:   unix.EINVAL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := genltest.Dial(genltest.CheckPolicy(family, p, true, noop))
+			defer c.Close()
+
+			req := genetlink.Message{
+				Header: genetlink.Header{Command: 1},
+				Data:   nest(tt.depth),
+			}
+
+			_, err := c.Execute(req, family, netlink.Request|netlink.Acknowledge)
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, but got: %v", tt.err, err)
+			}
+		})
+	}
+}
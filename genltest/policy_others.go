@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+package genltest
+
+import (
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+)
+
+// checkPolicy returns a Func which always returns an error.
+func checkPolicy(family uint16, p genetlink.Policy, strict bool, fn Func) Func {
+	return func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, errUnimplemented
+	}
+}
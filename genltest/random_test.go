@@ -0,0 +1,65 @@
+package genltest_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestRandomMessage(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100; i++ {
+		m := genltest.RandomMessage(r)
+
+		b, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+
+		var got genetlink.Message
+		if err := got.UnmarshalBinary(b); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+
+		if err := walk(got.Data); err != nil {
+			t.Fatalf("failed to decode attributes: %v", err)
+		}
+	}
+}
+
+// walk decodes all attributes in b, recursing into nested attributes.
+func walk(b []byte) error {
+	ad, err := netlink.NewAttributeDecoder(b)
+	if err != nil {
+		return err
+	}
+
+	for ad.Next() {
+		if ad.TypeFlags()&netlink.Nested == 0 {
+			continue
+		}
+
+		if err := walk(ad.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return ad.Err()
+}
+
+func FuzzRandomSeeds(f *testing.F) {
+	genltest.AddFuzzSeeds(f, 1, 8)
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var m genetlink.Message
+		_ = m.UnmarshalBinary(b)
+	})
+}
+
+func TestCheckMessageRoundTrip(t *testing.T) {
+	genltest.CheckMessageRoundTrip(t, 1, 100)
+}
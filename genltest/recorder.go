@@ -0,0 +1,194 @@
+package genltest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/internal/endian"
+	"github.com/mdlayher/netlink"
+)
+
+// A Recorder records the netlink messages exchanged by a genetlink.Conn
+// created by DialConfig, in a stable form suitable for snapshot testing and
+// for attaching to bug reports. The zero value is ready to use.
+//
+// Sequence numbers are normalized in order of first appearance, starting at
+// 1, so that recordings do not depend on the random initial sequence number
+// chosen by the connection.
+type Recorder struct {
+	mu     sync.Mutex
+	events []Event
+	seqs   map[uint32]uint32
+}
+
+// An Event is a single netlink message or error observed by a Recorder.
+type Event struct {
+	// Direction is "request" for messages sent by the connection, or "reply"
+	// for messages and errors received by it.
+	Direction string `json:"direction"`
+
+	// Error is set when receiving failed, and no other fields are set.
+	Error string `json:"error,omitempty"`
+
+	// Netlink header fields. Flags uses the netlink.HeaderFlags string form.
+	Type     uint16 `json:"type,omitempty"`
+	Flags    string `json:"flags,omitempty"`
+	Sequence uint32 `json:"sequence,omitempty"`
+	PID      uint32 `json:"pid,omitempty"`
+
+	// Errno is the error number carried by a netlink error message. An
+	// acknowledgement carries no error number.
+	Errno int32 `json:"errno,omitempty"`
+
+	// Generic netlink header fields, set for generic netlink messages.
+	Command *uint8 `json:"command,omitempty"`
+	Version *uint8 `json:"version,omitempty"`
+
+	// Attributes contains the message's attributes, if they could be
+	// decoded. Otherwise, Data contains the hex encoded message body.
+	Attributes []EventAttribute `json:"attributes,omitempty"`
+	Data       string           `json:"data,omitempty"`
+}
+
+// An EventAttribute is a netlink attribute within an Event. Attributes with
+// the netlink.Nested flag set are decoded into Attributes, and all others
+// are hex encoded in Data.
+type EventAttribute struct {
+	Type       uint16           `json:"type"`
+	Attributes []EventAttribute `json:"attributes,omitempty"`
+	Data       string           `json:"data,omitempty"`
+}
+
+// Events returns a copy of the Events recorded so far.
+func (r *Recorder) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Event(nil), r.events...)
+}
+
+// WriteJSON writes the Events recorded so far to w as indented JSON.
+func (r *Recorder) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+
+	events := r.Events()
+	if events == nil {
+		events = []Event{}
+	}
+
+	return enc.Encode(events)
+}
+
+// record records messages sent or received in the specified direction.
+func (r *Recorder) record(direction string, msgs []netlink.Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, m := range msgs {
+		r.events = append(r.events, r.event(direction, m))
+	}
+}
+
+// recordError records an error returned to the connection.
+func (r *Recorder) recordError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, Event{
+		Direction: "reply",
+		Error:     err.Error(),
+	})
+}
+
+// event creates an Event for m. r.mu must be held.
+func (r *Recorder) event(direction string, m netlink.Message) Event {
+	e := Event{
+		Direction: direction,
+		Type:      uint16(m.Header.Type),
+		Sequence:  r.sequence(m.Header.Sequence),
+		PID:       m.Header.PID,
+	}
+	if m.Header.Flags != 0 {
+		e.Flags = m.Header.Flags.String()
+	}
+
+	switch m.Header.Type {
+	case netlink.Error:
+		// The remainder of the body echoes the request, including its
+		// sequence number, so only the error number is recorded.
+		if len(m.Data) >= 4 {
+			e.Errno = -int32(endian.Uint32(m.Data[:4]))
+		}
+
+		return e
+	case netlink.Noop, netlink.Done, netlink.Overrun:
+		e.Data = hex.EncodeToString(m.Data)
+		return e
+	}
+
+	var gm genetlink.Message
+	if err := gm.UnmarshalBinary(m.Data); err != nil {
+		e.Data = hex.EncodeToString(m.Data)
+		return e
+	}
+
+	e.Command, e.Version = &gm.Header.Command, &gm.Header.Version
+
+	attrs, ok := eventAttributes(gm.Data)
+	if !ok {
+		e.Data = hex.EncodeToString(gm.Data)
+		return e
+	}
+
+	e.Attributes = attrs
+	return e
+}
+
+// sequence normalizes the sequence number seq. r.mu must be held.
+func (r *Recorder) sequence(seq uint32) uint32 {
+	if seq == 0 {
+		return 0
+	}
+
+	if r.seqs == nil {
+		r.seqs = make(map[uint32]uint32)
+	}
+
+	n, ok := r.seqs[seq]
+	if !ok {
+		n = uint32(len(r.seqs) + 1)
+		r.seqs[seq] = n
+	}
+
+	return n
+}
+
+// eventAttributes decodes b into EventAttributes, reporting whether b could
+// be decoded.
+func eventAttributes(b []byte) ([]EventAttribute, bool) {
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil {
+		return nil, false
+	}
+
+	eas := make([]EventAttribute, 0, len(attrs))
+	for _, a := range attrs {
+		ea := EventAttribute{Type: a.Type}
+		if a.Type&netlink.Nested != 0 {
+			if nested, ok := eventAttributes(a.Data); ok {
+				ea.Attributes = nested
+				eas = append(eas, ea)
+				continue
+			}
+		}
+
+		ea.Data = hex.EncodeToString(a.Data)
+		eas = append(eas, ea)
+	}
+
+	return eas, true
+}
@@ -0,0 +1,141 @@
+package genltest_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nltest"
+)
+
+func TestRecorder(t *testing.T) {
+	var r genltest.Recorder
+	c := genltest.DialConfig(genltest.ServeFamily(
+		genetlink.Family{ID: 0x20, Version: 1, Name: "foo"},
+		func(_ genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+			if nreq.Header.Type == 0x20 {
+				return nil, genltest.Error(2)
+			}
+
+			return []genetlink.Message{{
+				Header: genetlink.Header{Command: 2, Version: 1},
+				Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+					{Type: 1, Data: []byte{0xff}},
+					{
+						Type: 2 | netlink.Nested,
+						Data: nltest.MustMarshalAttributes([]netlink.Attribute{{Type: 1}}),
+					},
+				}),
+			}}, nil
+		},
+	), &genltest.Config{Recorder: &r})
+	defer c.Close()
+
+	if _, err := c.GetFamily("foo"); err != nil {
+		t.Fatalf("failed to get family: %v", err)
+	}
+
+	if _, err := c.Execute(genetlink.Message{Header: genetlink.Header{Command: 1}}, 0x20, netlink.Request); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+
+	if _, _, err := c.Receive(); err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatalf("failed to write JSON: %v", err)
+	}
+
+	want := `[
+	{
+		"direction": "request",
+		"type": 16,
+		"flags": "request",
+		"sequence": 1,
+		"pid": 1,
+		"command": 3,
+		"version": 1,
+		"attributes": [
+			{
+				"type": 2,
+				"data": "666f6f00"
+			}
+		]
+	},
+	{
+		"direction": "reply",
+		"type": 16,
+		"sequence": 1,
+		"pid": 1,
+		"command": 1,
+		"version": 2,
+		"attributes": [
+			{
+				"type": 2,
+				"data": "666f6f00"
+			},
+			{
+				"type": 1,
+				"data": "2000"
+			},
+			{
+				"type": 3,
+				"data": "01000000"
+			},
+			{
+				"type": 4,
+				"data": "00000000"
+			},
+			{
+				"type": 5,
+				"data": "00000000"
+			}
+		]
+	},
+	{
+		"direction": "request",
+		"type": 32,
+		"flags": "request",
+		"sequence": 2,
+		"pid": 1,
+		"command": 1,
+		"version": 0
+	},
+	{
+		"direction": "reply",
+		"type": 2,
+		"sequence": 2,
+		"pid": 1,
+		"errno": 2
+	},
+	{
+		"direction": "reply",
+		"command": 2,
+		"version": 1,
+		"attributes": [
+			{
+				"type": 1,
+				"data": "ff"
+			},
+			{
+				"type": 32770,
+				"attributes": [
+					{
+						"type": 1
+					}
+				]
+			}
+		]
+	}
+]
+`
+
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Fatalf("unexpected JSON (-want +got):\n%s", diff)
+	}
+}
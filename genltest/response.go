@@ -0,0 +1,47 @@
+package genltest
+
+import (
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+)
+
+// A Response is a generic netlink message returned by a ResponseFunc along
+// with the netlink header used to deliver it.
+type Response struct {
+	Header  netlink.Header
+	Message genetlink.Message
+}
+
+// A ResponseFunc is like a Func, but returns Responses whose netlink headers
+// are controlled entirely by the function.
+//
+// Unlike a Func, no netlink header fields are synthesized for the responses:
+// to mimic a normal reply, copy the sequence number and PID from nreq. This
+// makes it possible to test validation of mismatched sequence numbers and
+// PIDs, unexpected flags, and other edge-case kernel behaviors.
+type ResponseFunc func(greq genetlink.Message, nreq netlink.Message) ([]Response, error)
+
+// Respond returns a Func which delivers the responses returned by fn using
+// their explicit netlink headers.
+func Respond(fn ResponseFunc) Func {
+	return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		res, err := fn(greq, nreq)
+		if err != nil {
+			return nil, err
+		}
+
+		msgs := make([]genetlink.Message, 0, len(res))
+		for _, r := range res {
+			msgs = append(msgs, r.Message)
+		}
+
+		if c := callFor(nreq); c != nil {
+			c.addRewrite(func(i int, m netlink.Message) netlink.Message {
+				m.Header = res[i].Header
+				return m
+			})
+		}
+
+		return msgs, nil
+	}
+}
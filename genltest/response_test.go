@@ -0,0 +1,106 @@
+package genltest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestRespond(t *testing.T) {
+	tests := []struct {
+		name   string
+		header func(nreq netlink.Message) netlink.Header
+		ok     bool
+	}{
+		{
+			name: "OK",
+			header: func(nreq netlink.Message) netlink.Header {
+				return netlink.Header{
+					Type:     1,
+					Flags:    netlink.Replace,
+					Sequence: nreq.Header.Sequence,
+					PID:      nreq.Header.PID,
+				}
+			},
+			ok: true,
+		},
+		{
+			name: "kernel PID",
+			header: func(nreq netlink.Message) netlink.Header {
+				return netlink.Header{Sequence: nreq.Header.Sequence}
+			},
+			ok: true,
+		},
+		{
+			name: "mismatched sequence",
+			header: func(nreq netlink.Message) netlink.Header {
+				return netlink.Header{
+					Sequence: nreq.Header.Sequence + 1,
+					PID:      nreq.Header.PID,
+				}
+			},
+		},
+		{
+			name: "mismatched PID",
+			header: func(nreq netlink.Message) netlink.Header {
+				return netlink.Header{
+					Sequence: nreq.Header.Sequence,
+					PID:      nreq.Header.PID + 1,
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want netlink.Header
+			c := genltest.Dial(genltest.Respond(func(greq genetlink.Message, nreq netlink.Message) ([]genltest.Response, error) {
+				want = tt.header(nreq)
+				return []genltest.Response{{
+					Header:  want,
+					Message: greq,
+				}}, nil
+			}))
+			defer c.Close()
+
+			nreq, err := c.Send(genetlink.Message{}, 1, netlink.Request)
+			if err != nil {
+				t.Fatalf("failed to send: %v", err)
+			}
+
+			_, nmsgs, err := c.Receive()
+			if err != nil {
+				t.Fatalf("failed to receive: %v", err)
+			}
+
+			if diff := cmp.Diff(want, nmsgs[0].Header); diff != "" {
+				t.Fatalf("unexpected netlink header (-want +got):\n%s", diff)
+			}
+
+			err = netlink.Validate(nreq, nmsgs)
+			if err != nil && tt.ok {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err == nil && !tt.ok {
+				t.Fatal("expected an error, but none occurred")
+			}
+		})
+	}
+}
+
+func TestRespondError(t *testing.T) {
+	errFoo := errors.New("foo")
+
+	c := genltest.Dial(genltest.Respond(func(_ genetlink.Message, _ netlink.Message) ([]genltest.Response, error) {
+		return nil, errFoo
+	}))
+	defer c.Close()
+
+	if _, err := c.Execute(genetlink.Message{}, 1, netlink.Request); !errors.Is(err, errFoo) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
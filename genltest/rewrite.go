@@ -0,0 +1,107 @@
+package genltest
+
+import (
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/internal/endian"
+	"github.com/mdlayher/netlink"
+)
+
+// Truncate returns a Func which truncates the encoded form of each message
+// returned by fn, including its generic netlink header, to at most n bytes.
+// Truncate can be used to verify that consumers handle short or malformed
+// replies, such as those produced by a MSG_TRUNC condition.
+func Truncate(n int, fn Func) Func {
+	return rewrite(fn, func(_ int, m netlink.Message) netlink.Message {
+		if len(m.Data) > n {
+			m.Data = m.Data[:n]
+		}
+
+		return m
+	})
+}
+
+// Unpad returns a Func which removes the alignment padding between the
+// top-level attributes of each message returned by fn, producing an
+// attribute stream which a strict decoder will misparse or reject.
+func Unpad(fn Func) Func {
+	return rewrite(fn, func(_ int, m netlink.Message) netlink.Message {
+		m.Data = unpad(m.Data)
+		return m
+	})
+}
+
+// InterruptDump returns a Func which sets the netlink.DumpInterrupted flag on
+// each message returned by fn, starting with the message at index n, as the
+// kernel does when the data being dumped changes partway through a dump.
+// InterruptDump can be used to test the resume and retry logic of consumers
+// without racing real kernel state changes.
+func InterruptDump(n int, fn Func) Func {
+	return rewrite(fn, func(i int, m netlink.Message) netlink.Message {
+		if i >= n {
+			m.Header.Flags |= netlink.DumpInterrupted
+		}
+
+		return m
+	})
+}
+
+// rewrite returns a Func which applies rw to the netlink form of each message
+// returned by fn, where i is the index of the message.
+func rewrite(fn Func, rw func(i int, m netlink.Message) netlink.Message) Func {
+	return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		msgs, err := fn(greq, nreq)
+		if err != nil {
+			return nil, err
+		}
+
+		c := callFor(nreq)
+		switch {
+		case c == nil:
+			// Messages can only be rewritten once encoded by a connection.
+		case c.blocked != nil:
+			// Rewrite the replies once they are unblocked.
+			inner := c.blocked.fn
+			c.blocked.fn = func() ([]genetlink.Message, error) {
+				return rewrite(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+					return inner()
+				}, rw)(greq, nreq)
+			}
+		default:
+			c.addRewrite(rw)
+		}
+
+		return msgs, nil
+	}
+}
+
+// unpad removes the padding following each top-level attribute in the
+// encoded generic netlink message b.
+func unpad(b []byte) []byte {
+	const (
+		genlHeaderLen = 4
+		attrHeaderLen = 4
+	)
+
+	if len(b) < genlHeaderLen {
+		return b
+	}
+
+	out := append([]byte(nil), b[:genlHeaderLen]...)
+	i := genlHeaderLen
+	for i+attrHeaderLen <= len(b) {
+		l := int(endian.Uint16(b[i : i+2]))
+		if l < attrHeaderLen || i+l > len(b) {
+			// Malformed attribute; copy the remainder as-is.
+			return append(out, b[i:]...)
+		}
+
+		out = append(out, b[i:i+l]...)
+		i += nlmsgAlign(l)
+	}
+
+	if i < len(b) {
+		out = append(out, b[i:]...)
+	}
+
+	return out
+}
@@ -0,0 +1,146 @@
+package genltest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"github.com/mdlayher/netlink/nltest"
+)
+
+func TestTruncate(t *testing.T) {
+	reply := genetlink.Message{
+		Header: genetlink.Header{Command: 1},
+		Data: nltest.MustMarshalAttributes([]netlink.Attribute{{
+			Type: 1,
+			Data: nlenc.Uint32Bytes(1),
+		}}),
+	}
+
+	fn := func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return []genetlink.Message{reply}, nil
+	}
+
+	t.Run("header", func(t *testing.T) {
+		c := genltest.Dial(genltest.Truncate(2, fn))
+		defer c.Close()
+
+		if _, err := c.Execute(genetlink.Message{}, 1, netlink.Request); err == nil {
+			t.Fatal("expected an error, but none occurred")
+		}
+	})
+
+	t.Run("attributes", func(t *testing.T) {
+		c := genltest.Dial(genltest.Truncate(10, fn))
+		defer c.Close()
+
+		msgs, err := c.Execute(genetlink.Message{}, 1, netlink.Request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if diff := cmp.Diff(reply.Data[:6], msgs[0].Data); diff != "" {
+			t.Fatalf("unexpected data (-want +got):\n%s", diff)
+		}
+
+		if _, err := netlink.UnmarshalAttributes(msgs[0].Data); err == nil {
+			t.Fatal("expected an error, but none occurred")
+		}
+	})
+}
+
+func TestUnpad(t *testing.T) {
+	c := genltest.Dial(genltest.Unpad(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return []genetlink.Message{{
+			Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+				{Type: 1, Data: []byte{0xff}},
+				{Type: 2, Data: []byte{0xaa, 0xbb}},
+			}),
+		}}, nil
+	}))
+	defer c.Close()
+
+	msgs, err := c.Execute(genetlink.Message{}, 1, netlink.Request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []byte{
+		// Type 1, no padding.
+		0x05, 0x00, 0x01, 0x00,
+		0xff,
+		// Type 2, no padding.
+		0x06, 0x00, 0x02, 0x00,
+		0xaa, 0xbb,
+	}
+
+	if diff := cmp.Diff(want, msgs[0].Data); diff != "" {
+		t.Fatalf("unexpected data (-want +got):\n%s", diff)
+	}
+}
+
+func TestInterruptDump(t *testing.T) {
+	c := genltest.DialConfig(genltest.InterruptDump(1, func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return make([]genetlink.Message, 3), nil
+	}), &genltest.Config{
+		// Force a multi-part reply.
+		BufferSize: 20,
+	})
+	defer c.Close()
+
+	if _, err := c.Send(genetlink.Message{}, 1, netlink.Request|netlink.Dump); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+
+	_, nmsgs, err := c.Receive()
+	if err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+
+	var got []bool
+	for _, m := range nmsgs {
+		got = append(got, m.Header.Flags&netlink.DumpInterrupted != 0)
+	}
+
+	if diff := cmp.Diff([]bool{false, true, true}, got); diff != "" {
+		t.Fatalf("unexpected interrupted flags (-want +got):\n%s", diff)
+	}
+}
+
+func TestRewriteWrappingMiddleware(t *testing.T) {
+	// A middleware which wraps every error must not interfere with replies
+	// which are rewritten or held back.
+	wrap := func(fn genltest.Func) genltest.Func {
+		return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+			msgs, err := fn(greq, nreq)
+			if err != nil {
+				return nil, fmt.Errorf("middleware: %w", err)
+			}
+
+			return msgs, nil
+		}
+	}
+
+	g := genltest.NewGate()
+	g.Release()
+
+	fn := func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return []genetlink.Message{{Data: []byte{0xff, 0xff, 0xff, 0xff}}}, nil
+	}
+
+	c := genltest.Dial(wrap(genltest.Truncate(6, wrap(g.Block(wrap(fn))))))
+	defer c.Close()
+
+	msgs, err := c.Execute(genetlink.Message{}, 1, netlink.Request)
+	if err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+
+	if diff := cmp.Diff([]byte{0xff, 0xff}, msgs[0].Data); diff != "" {
+		t.Fatalf("unexpected data (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,132 @@
+package genltest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+)
+
+// CheckRoundTrip generates n values of type T using gen and a random source
+// seeded with seed, and verifies that each value is unchanged after being
+// passed through marshal and then unmarshal. Nil and empty slices and maps are
+// considered equal.
+//
+// CheckRoundTrip is a building block for property tests of any binary codec,
+// such as decoders for a specific generic netlink family.
+func CheckRoundTrip[T any](
+	tb testing.TB,
+	seed int64,
+	n int,
+	gen func(r *rand.Rand) T,
+	marshal func(v T) ([]byte, error),
+	unmarshal func(b []byte) (T, error),
+) {
+	tb.Helper()
+
+	r := rand.New(rand.NewSource(seed))
+	for i := 0; i < n; i++ {
+		want := gen(r)
+
+		b, err := marshal(want)
+		if err != nil {
+			tb.Fatalf("genltest: round trip %d: failed to marshal: %v", i, err)
+		}
+
+		got, err := unmarshal(b)
+		if err != nil {
+			tb.Fatalf("genltest: round trip %d: failed to unmarshal [%# x]: %v", i, b, err)
+		}
+
+		if diff := cmp.Diff(want, got, cmpopts.EquateEmpty()); diff != "" {
+			tb.Fatalf("genltest: round trip %d: value changed (-want +got):\n%s", i, diff)
+		}
+	}
+}
+
+// CheckMessageRoundTrip verifies that n Messages generated by RandomMessage
+// round trip through genetlink.Message.MarshalBinary and UnmarshalBinary.
+func CheckMessageRoundTrip(tb testing.TB, seed int64, n int) {
+	tb.Helper()
+
+	CheckRoundTrip(tb, seed, n, RandomMessage,
+		func(m genetlink.Message) ([]byte, error) {
+			return m.MarshalBinary()
+		},
+		func(b []byte) (genetlink.Message, error) {
+			var m genetlink.Message
+			err := m.UnmarshalBinary(b)
+			return m, err
+		},
+	)
+}
+
+// CheckFamilyRoundTrip verifies that n Families generated by RandomFamily are
+// unchanged after being served by ServeFamily and retrieved using
+// genetlink.Conn.GetFamily.
+func CheckFamilyRoundTrip(tb testing.TB, seed int64, n int) {
+	tb.Helper()
+
+	r := rand.New(rand.NewSource(seed))
+	for i := 0; i < n; i++ {
+		want := RandomFamily(r)
+
+		c := Dial(ServeFamily(want, func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			return nil, Error(0x2) // ENOENT
+		}))
+
+		got, err := c.GetFamily(want.Name)
+		_ = c.Close()
+		if err != nil {
+			tb.Fatalf("genltest: round trip %d: failed to get family: %v", i, err)
+		}
+
+		if diff := cmp.Diff(want, got, cmpopts.EquateEmpty()); diff != "" {
+			tb.Fatalf("genltest: round trip %d: family changed (-want +got):\n%s", i, diff)
+		}
+	}
+}
+
+// RandomFamily generates a generic netlink Family with random operations and
+// multicast groups using r.
+func RandomFamily(r *rand.Rand) genetlink.Family {
+	f := genetlink.Family{
+		ID:      uint16(r.Intn(0x3ff-0x10) + 0x10),
+		Version: uint8(r.Intn(256)),
+		Name:    randomName(r),
+	}
+
+	ng := r.Intn(4)
+	for i := 0; i < ng; i++ {
+		f.Groups = append(f.Groups, genetlink.MulticastGroup{
+			ID:   r.Uint32(),
+			Name: randomName(r),
+		})
+	}
+
+	no := r.Intn(8)
+	for i := 0; i < no; i++ {
+		f.Ops = append(f.Ops, genetlink.Op{
+			ID:    uint32(i + 1),
+			Flags: genetlink.OpFlags(r.Intn(0x20)),
+		})
+	}
+
+	return f
+}
+
+// randomName generates a random printable name which fits within the kernel's
+// GENL_NAMSIZ limit.
+func randomName(r *rand.Rand) string {
+	const chars = "abcdefghijklmnopqrstuvwxyz0123456789_"
+
+	b := make([]byte, r.Intn(14)+1)
+	for i := range b {
+		b[i] = chars[r.Intn(len(chars))]
+	}
+
+	return string(b)
+}
@@ -0,0 +1,551 @@
+package genltest
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nltest"
+)
+
+var _ netlink.Socket = &socket{}
+
+// A socket is a netlink.Socket used for testing. Its request and response
+// semantics mirror those of package nltest, and it additionally supports
+// the optional behaviors configured by Config.
+type socket struct {
+	// receives counts calls to Receive, and receiving counts calls which
+	// are in progress, for VerifyNoLeaks. receives is accessed atomically
+	// and must remain 64-bit aligned.
+	receives  uint64
+	receiving int32
+
+	// id and stack identify the socket for VerifyNoLeaks, if it is tracked.
+	id    uint64
+	stack []byte
+
+	fn  nltest.Func
+	cfg Config
+
+	closeOnce sync.Once
+	done      chan struct{}
+
+	mu   sync.Mutex
+	msgs []netlink.Message
+	err  error
+
+	// batch holds the replies to each request of a batch sent by
+	// SendMessages, which are delivered by separate calls to Receive as
+	// they would be by the kernel.
+	batch []batchReply
+
+	// readDeadline is the deadline for Receive calls waiting on replies
+	// held back by a Func, such as one created by Gate.Block.
+	// deadlineC is closed and replaced whenever readDeadline changes so
+	// that blocked calls observe the new deadline.
+	readDeadline time.Time
+	deadlineC    chan struct{}
+
+	// kernel is the Kernel backing the socket, if any. For such sockets,
+	// groups holds the multicast groups joined by the socket, and mcast
+	// holds multicast messages delivered by the Kernel which have not yet
+	// been received. mcastC is closed and replaced whenever messages are
+	// delivered so that blocked calls observe them.
+	kernel *Kernel
+	groups map[uint32]struct{}
+	mcast  []netlink.Message
+	mcastC chan struct{}
+}
+
+// newSocket creates a socket which passes requests to fn.
+func newSocket(fn nltest.Func, cfg *Config) *socket {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	s := &socket{
+		fn:        fn,
+		cfg:       *cfg,
+		done:      make(chan struct{}),
+		deadlineC: make(chan struct{}),
+		groups:    make(map[uint32]struct{}),
+		mcastC:    make(chan struct{}),
+	}
+
+	track(s)
+	return s
+}
+
+// closed reports whether the socket has been closed.
+func (s *socket) closed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close closes the socket, unblocking any Receive calls waiting on replies.
+func (s *socket) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		if s.kernel != nil {
+			s.kernel.remove(s)
+		}
+		if s.cfg.Verify != nil {
+			err = s.cfg.Verify()
+		}
+	})
+
+	return err
+}
+
+// SetDeadline sets the read and write deadlines of the socket.
+func (s *socket) SetDeadline(t time.Time) error {
+	return s.SetReadDeadline(t)
+}
+
+// SetReadDeadline sets the deadline for Receive calls waiting on replies.
+func (s *socket) SetReadDeadline(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.readDeadline = t
+	close(s.deadlineC)
+	s.deadlineC = make(chan struct{})
+	return nil
+}
+
+// SetWriteDeadline is a no-op: sending to the socket never blocks.
+func (s *socket) SetWriteDeadline(_ time.Time) error { return nil }
+
+func (s *socket) SendMessages(messages []netlink.Message) error {
+	if s.cfg.Recorder != nil {
+		s.cfg.Recorder.record("request", messages)
+	}
+	if s.cfg.Corpus != nil {
+		s.cfg.Corpus.record(messages)
+	}
+
+	if len(messages) > 1 {
+		s.sendBatch(messages)
+		return nil
+	}
+
+	msgs, err := s.fn(messages)
+	msgs = s.multipart(msgs, err)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.msgs = append(s.msgs, msgs...)
+	s.err = err
+	return nil
+}
+
+// A batchReply is a single reply to a request in a batch.
+type batchReply struct {
+	msgs []netlink.Message
+	err  error
+}
+
+// sendBatch passes each of messages to the socket's Func in turn, queueing
+// their replies separately. As with the kernel, requests with the
+// netlink.Acknowledge flag which do not fail are followed by an
+// acknowledgement.
+func (s *socket) sendBatch(messages []netlink.Message) {
+	var replies []batchReply
+	for _, m := range messages {
+		msgs, err := s.fn([]netlink.Message{m})
+		if err == io.EOF {
+			msgs, err = nil, nil
+		}
+
+		if len(msgs) > 0 || err != nil {
+			replies = append(replies, batchReply{msgs: msgs, err: err})
+		}
+
+		if err != nil || m.Header.Flags&netlink.Acknowledge == 0 || isError(msgs) {
+			continue
+		}
+
+		ack, err := nltest.Error(0, []netlink.Message{m})
+		replies = append(replies, batchReply{msgs: ack, err: err})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batch = append(s.batch, replies...)
+}
+
+// isError reports whether msgs contains a netlink error message.
+func isError(msgs []netlink.Message) bool {
+	for _, m := range msgs {
+		if m.Header.Type == netlink.Error {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *socket) Send(m netlink.Message) error {
+	if s.cfg.Recorder != nil {
+		s.cfg.Recorder.record("request", []netlink.Message{m})
+	}
+	if s.cfg.Corpus != nil {
+		s.cfg.Corpus.record([]netlink.Message{m})
+	}
+
+	msgs, err := s.fn([]netlink.Message{m})
+	msgs = s.multipart(msgs, err)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.msgs, s.err = msgs, err
+	return nil
+}
+
+func (s *socket) Receive() ([]netlink.Message, error) {
+	atomic.AddUint64(&s.receives, 1)
+	atomic.AddInt32(&s.receiving, 1)
+	defer atomic.AddInt32(&s.receiving, -1)
+
+	msgs, err := s.receive()
+	if r := s.cfg.Recorder; r != nil {
+		if err != nil {
+			r.recordError(err)
+		} else {
+			r.record("reply", msgs)
+		}
+	}
+	if s.cfg.Corpus != nil && err == nil {
+		s.cfg.Corpus.record(msgs)
+	}
+
+	return msgs, err
+}
+
+// receive implements Receive.
+func (s *socket) receive() ([]netlink.Message, error) {
+	// Replies held back by a Func must be resolved before any others can be
+	// returned to the caller.
+	s.mu.Lock()
+	perr, ok := s.err.(*pendingError)
+	s.mu.Unlock()
+	if ok {
+		if err := s.wait(perr.ready()); err != nil {
+			return nil, err
+		}
+
+		msgs, err := perr.fn()
+		msgs = s.multipart(msgs, err)
+
+		s.mu.Lock()
+		s.msgs, s.err = append(s.msgs, msgs...), err
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+
+	// Deliver the next reply to a batch once any earlier replies are drained.
+	if len(s.msgs) == 0 && len(s.batch) > 0 {
+		r := s.batch[0]
+		s.batch = s.batch[1:]
+		s.mu.Unlock()
+
+		return r.msgs, r.err
+	}
+
+	// No messages set by Send means that we are emulating a
+	// multicast response or an error occurred.
+	if len(s.msgs) == 0 {
+		err := s.err
+		s.mu.Unlock()
+
+		switch {
+		case err == nil && s.kernel != nil:
+			// Multicast messages are delivered by the Kernel.
+			return s.receiveMulticast()
+		case err == nil:
+			// No error, simulate multicast, but also return EOF to simulate
+			// no replies if needed.
+			msgs, err := s.fn(nil)
+			if perr, ok := err.(*pendingError); ok {
+				if err := s.wait(perr.ready()); err != nil {
+					return nil, err
+				}
+
+				msgs, err = perr.fn()
+			}
+			if err == io.EOF {
+				err = nil
+			}
+
+			for i := range msgs {
+				if msgs[i].Header.PID == 0 {
+					msgs[i].Header.PID = s.cfg.KernelPID
+				}
+			}
+
+			// Deliver multicast messages which do not fit in the buffer
+			// on subsequent calls.
+			if n := s.page(msgs); n < len(msgs) {
+				s.mu.Lock()
+				s.msgs = append(s.msgs, msgs[n:]...)
+				s.mu.Unlock()
+
+				msgs = msgs[:n]
+			}
+
+			return msgs, err
+		case err == io.EOF:
+			// EOF, simulate no replies in multi-part message.
+			return nil, nil
+		}
+
+		// If the error is a system call error, wrap it in os.NewSyscallError
+		// to simulate what the Linux netlink.Conn does.
+		if isSyscallError(err) {
+			return nil, os.NewSyscallError("recvmsg", err)
+		}
+
+		// Some generic error occurred and should be passed to the caller.
+		return nil, err
+	}
+	defer s.mu.Unlock()
+
+	// Deliver only as many messages as fit in the buffer, leaving the rest
+	// for subsequent calls.
+	if n := s.page(s.msgs); n < len(s.msgs) {
+		ret := s.msgs[:n]
+		s.msgs = s.msgs[n:]
+
+		return ret, nil
+	}
+
+	// Detect multi-part messages.
+	var multi bool
+	for _, m := range s.msgs {
+		if m.Header.Flags&netlink.Multi != 0 && m.Header.Type != netlink.Done {
+			multi = true
+		}
+	}
+
+	// When a multi-part message is detected, return all messages except for the
+	// final "multi-part done", so that a second call to Receive from netlink.Conn
+	// will drain that message.
+	if multi {
+		last := s.msgs[len(s.msgs)-1]
+		ret := s.msgs[:len(s.msgs)-1]
+		s.msgs = []netlink.Message{last}
+
+		return ret, s.err
+	}
+
+	msgs, err := s.msgs, s.err
+	s.msgs, s.err = nil, nil
+
+	return msgs, err
+}
+
+// multipart converts msgs into a multi-part message terminated by a
+// "multi-part done" message if they must be split across several calls to
+// Receive due to Config.BufferSize.
+func (s *socket) multipart(msgs []netlink.Message, err error) []netlink.Message {
+	if err != nil || s.page(msgs) == len(msgs) {
+		return msgs
+	}
+
+	for _, m := range msgs {
+		if m.Header.Flags&netlink.Multi != 0 {
+			// Already a multi-part message.
+			return msgs
+		}
+	}
+
+	last := msgs[len(msgs)-1].Header
+	for i := range msgs {
+		msgs[i].Header.Flags |= netlink.Multi
+	}
+
+	return append(msgs, netlink.Message{
+		Header: netlink.Header{
+			Type: netlink.Done,
+			// The kernel also reports an interrupted dump on the final
+			// message.
+			Flags:    netlink.Multi | last.Flags&netlink.DumpInterrupted,
+			Sequence: last.Sequence,
+			PID:      last.PID,
+		},
+		Data: make([]byte, 4),
+	})
+}
+
+// page returns the number of leading messages in msgs which fit in a single
+// read buffer of Config.BufferSize bytes. At least one message is always
+// returned so that progress is made.
+func (s *socket) page(msgs []netlink.Message) int {
+	if s.cfg.BufferSize <= 0 {
+		return len(msgs)
+	}
+
+	var size int
+	for i, m := range msgs {
+		size += nlmsgAlign(nlmsgHeaderLen + len(m.Data))
+		if size > s.cfg.BufferSize && i > 0 {
+			return i
+		}
+	}
+
+	return len(msgs)
+}
+
+// Netlink message header length and alignment, as used by the kernel when
+// packing messages into a read buffer.
+const (
+	nlmsgHeaderLen = 16
+	nlmsgAlignTo   = 4
+)
+
+func nlmsgAlign(n int) int {
+	return (n + nlmsgAlignTo - 1) & ^(nlmsgAlignTo - 1)
+}
+
+// wait blocks until ready is closed, returning an error if the socket's read
+// deadline expires or the socket is closed first.
+func (s *socket) wait(ready <-chan struct{}) error {
+	for {
+		s.mu.Lock()
+		deadline, changed := s.readDeadline, s.deadlineC
+		s.mu.Unlock()
+
+		var (
+			t       *time.Timer
+			timeout <-chan time.Time
+		)
+		if !deadline.IsZero() {
+			d := time.Until(deadline)
+			if d <= 0 {
+				return os.NewSyscallError("recvmsg", os.ErrDeadlineExceeded)
+			}
+
+			t = time.NewTimer(d)
+			timeout = t.C
+		}
+
+		var (
+			err   error
+			retry bool
+		)
+		select {
+		case <-ready:
+		case <-s.done:
+			err = os.ErrClosed
+		case <-timeout:
+			err = os.NewSyscallError("recvmsg", os.ErrDeadlineExceeded)
+		case <-changed:
+			// Deadline changed, recompute the timeout.
+			retry = true
+		}
+
+		if t != nil {
+			t.Stop()
+		}
+		if !retry {
+			return err
+		}
+	}
+}
+
+// JoinGroup implements multicast group membership for the socket.
+func (s *socket) JoinGroup(group uint32) error {
+	return s.membership(group, true)
+}
+
+// LeaveGroup implements multicast group membership for the socket.
+func (s *socket) LeaveGroup(group uint32) error {
+	return s.membership(group, false)
+}
+
+// membership validates a multicast group membership change.
+func (s *socket) membership(group uint32, join bool) error {
+	if join && s.cfg.Groups != nil && !containsGroup(s.cfg.Groups, group) {
+		return os.NewSyscallError("setsockopt", syscall.EINVAL)
+	}
+
+	if s.cfg.Membership != nil {
+		if err := s.cfg.Membership(group, join); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if join {
+		s.groups[group] = struct{}{}
+	} else {
+		delete(s.groups, group)
+	}
+
+	return nil
+}
+
+// deliver queues multicast messages for the socket if it has joined group,
+// reporting whether they were queued.
+func (s *socket) deliver(group uint32, msgs []netlink.Message) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.groups[group]; !ok {
+		return false
+	}
+
+	s.mcast = append(s.mcast, msgs...)
+	close(s.mcastC)
+	s.mcastC = make(chan struct{})
+	return true
+}
+
+// receiveMulticast blocks until multicast messages are delivered to a socket
+// backed by a Kernel, and then receives as many as fit in the buffer.
+func (s *socket) receiveMulticast() ([]netlink.Message, error) {
+	for {
+		s.mu.Lock()
+		if len(s.mcast) > 0 {
+			n := s.page(s.mcast)
+			msgs := s.mcast[:n:n]
+			s.mcast = s.mcast[n:]
+			s.mu.Unlock()
+
+			return msgs, nil
+		}
+
+		ready := s.mcastC
+		s.mu.Unlock()
+
+		if err := s.wait(ready); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// containsGroup reports whether groups contains group.
+func containsGroup(groups []uint32, group uint32) bool {
+	for _, g := range groups {
+		if g == group {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,172 @@
+package genltest
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+)
+
+// Default values for the fields of StressConfig.
+const (
+	DefaultStressGoroutines = 16
+	DefaultStressIterations = 100
+)
+
+// StressConfig configures Stress.
+type StressConfig struct {
+	// Goroutines is the number of goroutines which run the workload
+	// concurrently. If 0, DefaultStressGoroutines is used.
+	Goroutines int
+
+	// Iterations is the number of times each goroutine runs the workload.
+	// If 0, DefaultStressIterations is used.
+	Iterations int
+
+	// MaxDelay is the maximum random delay inserted before each iteration
+	// of the workload and before each reply is delivered. If 0, no delays
+	// are inserted, but goroutines still yield at random to vary the
+	// interleaving of their operations.
+	MaxDelay time.Duration
+
+	// Seed seeds the source of randomness. Failures report the seed so that
+	// the delays of a failing run can be reproduced. If 0, a seed is chosen
+	// using the current time.
+	Seed int64
+
+	// Config is passed to DialConfig to create the Conn shared by the
+	// goroutines.
+	Config *Config
+}
+
+// Stress runs workload from many goroutines against a single Conn which
+// serves requests using fn, with randomized delays and interleaving, in the
+// same manner as this package's own concurrency tests. Stress is intended
+// to shake out locking bugs in libraries built on genetlink, especially
+// when combined with the race detector:
+//
+//	err := genltest.Stress(fn, nil, func(c *genetlink.Conn, g, i int) error {
+//		_, err := client.New(c).Stats()
+//		return err
+//	})
+//
+// workload is invoked with the shared Conn, the index of its goroutine, and
+// the iteration number. Stress returns an error wrapping the first error
+// returned by workload, after which no further iterations are started.
+func Stress(fn Func, cfg *StressConfig, workload func(c *genetlink.Conn, g, i int) error) error {
+	if cfg == nil {
+		cfg = &StressConfig{}
+	}
+
+	var (
+		goroutines = cfg.Goroutines
+		iterations = cfg.Iterations
+		seed       = cfg.Seed
+	)
+
+	if goroutines == 0 {
+		goroutines = DefaultStressGoroutines
+	}
+	if iterations == 0 {
+		iterations = DefaultStressIterations
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	s := &stress{
+		r:        rand.New(rand.NewSource(seed)),
+		maxDelay: cfg.MaxDelay,
+	}
+
+	c := DialConfig(s.delay(fn), cfg.Config)
+	defer c.Close()
+
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		serr error
+		stop = make(chan struct{})
+	)
+
+	wg.Add(goroutines)
+	for g := 0; g < goroutines; g++ {
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < iterations; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				s.pause()
+
+				if err := workload(c, g, i); err != nil {
+					once.Do(func() {
+						serr = fmt.Errorf("genltest: stress workload failed in goroutine %d, iteration %d (seed %d): %w",
+							g, i, seed, err)
+						close(stop)
+					})
+					return
+				}
+			}
+		}(g)
+	}
+
+	wg.Wait()
+	return serr
+}
+
+// A stress inserts random delays for Stress.
+type stress struct {
+	mu       sync.Mutex
+	r        *rand.Rand
+	maxDelay time.Duration
+}
+
+// duration returns a random duration up to s.maxDelay, or 0 if delays are
+// disabled.
+func (s *stress) duration() time.Duration {
+	if s.maxDelay <= 0 {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return time.Duration(s.r.Int63n(int64(s.maxDelay) + 1))
+}
+
+// pause sleeps for a random duration, or yields at random if delays are
+// disabled.
+func (s *stress) pause() {
+	if d := s.duration(); d > 0 {
+		time.Sleep(d)
+		return
+	}
+
+	s.mu.Lock()
+	yield := s.r.Intn(2) == 0
+	s.mu.Unlock()
+
+	if yield {
+		runtime.Gosched()
+	}
+}
+
+// delay returns a Func which delays the replies of fn by a random duration.
+func (s *stress) delay(fn Func) Func {
+	if s.maxDelay <= 0 {
+		return fn
+	}
+
+	return func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		return Delay(s.duration(), fn)(greq, nreq)
+	}
+}
@@ -0,0 +1,67 @@
+package genltest_test
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestStress(t *testing.T) {
+	echo := func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return []genetlink.Message{{Header: greq.Header}}, nil
+	}
+
+	cfg := &genltest.StressConfig{
+		Goroutines: 8,
+		Iterations: 20,
+		MaxDelay:   100 * time.Microsecond,
+		Seed:       1,
+	}
+
+	var n int64
+	err := genltest.Stress(echo, cfg, func(c *genetlink.Conn, g, i int) error {
+		atomic.AddInt64(&n, 1)
+
+		req := genetlink.Message{Header: genetlink.Header{Command: uint8(g)}}
+		msgs, err := c.Execute(req, 0x20, netlink.Request)
+		if err != nil {
+			return err
+		}
+
+		// Replies must never be delivered to the wrong goroutine.
+		if len(msgs) != 1 || msgs[0].Header.Command != uint8(g) {
+			return fmt.Errorf("unexpected replies: %v", msgs)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to stress: %v", err)
+	}
+
+	if diff := cmp.Diff(int64(8*20), n); diff != "" {
+		t.Fatalf("unexpected number of iterations (-want +got):\n%s", diff)
+	}
+}
+
+func TestStressError(t *testing.T) {
+	errFoo := errors.New("foo")
+
+	err := genltest.Stress(noop, nil, func(_ *genetlink.Conn, g, i int) error {
+		if g == 1 && i == 3 {
+			return errFoo
+		}
+
+		return nil
+	})
+	if !errors.Is(err, errFoo) {
+		t.Fatalf("expected workload error, but got: %v", err)
+	}
+}
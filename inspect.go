@@ -0,0 +1,536 @@
+package genetlink
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+)
+
+// An Inspection is a structured, human-readable description of a Message,
+// as produced by an Inspector. Its String method renders the Message as an
+// indented tree.
+type Inspection struct {
+	// Family is the family which sent or received the Message. If the family
+	// could not be resolved, only its ID is set.
+	Family Family
+
+	// Header is the Message's generic netlink header.
+	Header Header
+
+	// Command is the symbolic name of the Message's command, if known from
+	// a registered Schema.
+	Command string
+
+	// Attributes describes the Message's attributes. If they are malformed,
+	// Attributes is nil and Data holds the Message's data instead.
+	Attributes []InspectedAttribute
+	Data       []byte
+
+	// Err reports the first attribute which could not be decoded, such as
+	// one whose length does not match its kind, as an *AttributeError which
+	// locates it. Such attributes are described with their raw data.
+	Err error
+}
+
+// An InspectedAttribute describes a single netlink attribute within an
+// Inspection.
+type InspectedAttribute struct {
+	// Type is the attribute's type, without the nested and network byte
+	// order flags.
+	Type uint16
+
+	// Name is the symbolic name of the attribute, if known from a
+	// registered Schema.
+	Name string
+
+	// Kind is the kind of data carried by the attribute, as reported by the
+	// family's policy or a registered Schema. If neither describes the
+	// attribute, Kind is AttributeInvalid.
+	Kind AttributeKind
+
+	// Data is the attribute's raw data.
+	Data []byte
+
+	// Value is the attribute's data formatted according to Kind, or as
+	// hexadecimal bytes if Kind is unknown. Value is empty for nested
+	// attributes.
+	Value string
+
+	// Attributes describes the attributes nested within the attribute, if
+	// any.
+	Attributes []InspectedAttribute
+}
+
+// String renders an Inspection as an indented tree.
+func (i Inspection) String() string {
+	var b strings.Builder
+
+	name := i.Family.Name
+	if name == "" {
+		name = "unknown"
+	}
+
+	command := strconv.Itoa(int(i.Header.Command))
+	if i.Command != "" {
+		command += " " + i.Command
+	}
+
+	fmt.Fprintf(&b, "%s (%#x): command %s, version %d\n",
+		name, i.Family.ID, command, i.Header.Version)
+
+	if i.Attributes == nil && len(i.Data) > 0 {
+		fmt.Fprintf(&b, "  data: %s\n", hexBytes(i.Data))
+	}
+
+	writeAttributes(&b, i.Attributes, 1)
+	return b.String()
+}
+
+// writeAttributes writes the tree of attributes to b at the specified depth.
+func writeAttributes(b *strings.Builder, attrs []InspectedAttribute, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, a := range attrs {
+		kind := "unknown"
+		if a.Kind != AttributeInvalid {
+			kind = kindString(a.Kind)
+		}
+
+		typ := strconv.Itoa(int(a.Type))
+		if a.Name != "" {
+			typ += " " + a.Name
+		}
+
+		if a.Attributes != nil {
+			fmt.Fprintf(b, "%s%s (%s):\n", indent, typ, kind)
+			writeAttributes(b, a.Attributes, depth+1)
+			continue
+		}
+
+		fmt.Fprintf(b, "%s%s (%s): %s\n", indent, typ, kind, a.Value)
+	}
+}
+
+// An Inspector produces Inspections of Messages using the family and policy
+// information reported by the kernel over a Conn. Family and policy
+// information is retrieved once and then cached, so an Inspector suits
+// debuggers and tools which inspect many messages. Use a new Inspector to
+// observe families registered or changed afterward.
+//
+// An Inspector is safe for concurrent use.
+type Inspector struct {
+	c *Conn
+
+	mu       sync.Mutex
+	schemas  *SchemaRegistry
+	families map[uint16]Family
+	policies map[uint16]*Policy
+}
+
+// NewInspector creates an Inspector which retrieves information using c.
+func NewInspector(c *Conn) *Inspector {
+	return &Inspector{
+		c:        c,
+		schemas:  DefaultSchemas,
+		policies: make(map[uint16]*Policy),
+	}
+}
+
+// SetSchemas sets the SchemaRegistry used to name commands and attributes.
+// By default, DefaultSchemas is used. If r is nil, no Schemas are used.
+func (in *Inspector) SetSchemas(r *SchemaRegistry) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.schemas = r
+}
+
+// Inspect inspects m using a new Inspector for c. See Inspector.Inspect.
+func Inspect(c *Conn, family uint16, m Message) (Inspection, error) {
+	return NewInspector(c).Inspect(family, m)
+}
+
+// Inspect describes m, a Message sent to or received from the family with
+// the specified ID. The family's policy for the Message's command, if any,
+// determines the kind of each attribute and how nested attributes are
+// decoded. If a Schema is registered for the family, it names the command
+// and attributes, and describes attributes which the policy does not.
+// Attributes which are described by neither are decoded as nested
+// attributes if they carry the netlink.Nested flag.
+//
+// Inspect returns an error only if the families cannot be listed. Unknown
+// families and families whose policies cannot be retrieved, such as on
+// kernels older than Linux 5.10, are inspected without that information.
+func (in *Inspector) Inspect(family uint16, m Message) (Inspection, error) {
+	f, p, err := in.lookup(family)
+	if err != nil {
+		return Inspection{}, err
+	}
+
+	in.mu.Lock()
+	r := in.schemas
+	in.mu.Unlock()
+
+	var s *Schema
+	if r != nil && f.Name != "" {
+		if rs, ok := r.Lookup(f.Name); ok {
+			s = &rs
+		}
+	}
+
+	return inspect(f, p, s, m), nil
+}
+
+// inspect describes m, a Message of family f, using the policy p and the
+// Schema s, either of which may be nil.
+func inspect(f Family, p *Policy, s *Schema, m Message) Inspection {
+	i := Inspection{
+		Family: f,
+		Header: m.Header,
+	}
+
+	var set *PolicySet
+	if p != nil {
+		set = commandSet(*p, uint32(m.Header.Command))
+	}
+
+	var names AttributeSet
+	if s != nil {
+		i.Command = s.Commands[m.Header.Command].Name
+		names = s.attributes(m.Header.Command)
+	}
+
+	d := &inspectDecoder{p: p}
+	attrs, ok := d.attributes(set, names, m.Data, 0, "")
+	i.Err = d.err
+	if !ok {
+		i.Data = m.Data
+		return i
+	}
+
+	i.Attributes = attrs
+	return i
+}
+
+// lookup returns the family with the specified ID and its policy, if known.
+func (in *Inspector) lookup(id uint16) (Family, *Policy, error) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if in.families == nil {
+		fs, err := in.c.ListFamilies()
+		if err != nil {
+			return Family{}, nil, err
+		}
+
+		in.families = make(map[uint16]Family, len(fs))
+		for _, f := range fs {
+			in.families[f.ID] = f
+		}
+	}
+
+	f, ok := in.families[id]
+	if !ok {
+		return Family{ID: id}, nil, nil
+	}
+
+	p, ok := in.policies[id]
+	if ok {
+		return f, p, nil
+	}
+
+	for _, o := range f.Ops {
+		if o.Flags&OpCapHasPolicy == 0 {
+			continue
+		}
+
+		// Policies are optional, so errors are not fatal.
+		if fp, err := in.c.GetPolicy(f.Name); err == nil {
+			p = &fp
+		}
+
+		break
+	}
+
+	in.policies[id] = p
+	return f, p, nil
+}
+
+// commandSet returns the PolicySet used to validate the specified command,
+// preferring its "do" policy over its "dump" policy, or nil if none exists.
+func commandSet(p Policy, command uint32) *PolicySet {
+	for _, op := range p.Ops {
+		if op.Command != command {
+			continue
+		}
+
+		idx := op.Do
+		if idx < 0 {
+			idx = op.Dump
+		}
+		if idx < 0 {
+			return nil
+		}
+
+		s, ok := p.Set(uint32(idx))
+		if !ok {
+			return nil
+		}
+
+		return &s
+	}
+
+	return nil
+}
+
+// maxInspectDepth bounds the nesting depth of inspected attributes.
+const maxInspectDepth = DefaultMaxDepth
+
+// An inspectDecoder describes attributes using a policy and schema, and
+// records the first attribute which could not be decoded.
+type inspectDecoder struct {
+	p   *Policy
+	err error
+}
+
+// fail records an error for the attribute at path, unless an earlier error
+// was recorded.
+func (d *inspectDecoder) fail(path string, err error) {
+	if d.err == nil {
+		d.err = &AttributeError{Path: path, Err: err}
+	}
+}
+
+// attributes describes the attributes in b, which are nested within the
+// attribute at path, using the policy set and the schema attribute set
+// names, if any, reporting whether b could be decoded.
+func (d *inspectDecoder) attributes(set *PolicySet, names AttributeSet, b []byte, depth int, path string) ([]InspectedAttribute, bool) {
+	if depth >= maxInspectDepth {
+		d.fail(path, fmt.Errorf("nesting depth exceeds %d", maxInspectDepth))
+		return nil, false
+	}
+
+	attrs, err := netlink.UnmarshalAttributes(b)
+	if err != nil {
+		d.fail(path, err)
+		return nil, false
+	}
+
+	out := make([]InspectedAttribute, 0, len(attrs))
+	for _, a := range attrs {
+		ia := InspectedAttribute{
+			Type: a.Type & nlaTypeMask,
+			Data: a.Data,
+		}
+
+		var ap *AttributePolicy
+		if set != nil {
+			for i := range set.Attributes {
+				if set.Attributes[i].Type == ia.Type {
+					ap = &set.Attributes[i]
+					break
+				}
+			}
+		}
+
+		as := names[ia.Type]
+		ia.Name = as.Name
+
+		elem := ia.Name
+		if elem == "" {
+			elem = strconv.Itoa(int(ia.Type))
+		}
+		apath := attributePath(path, elem)
+
+		// The kernel's policy is authoritative, and a schema fills in
+		// attributes which it does not describe.
+		var nested *PolicySet
+		switch {
+		case ap != nil:
+			ia.Kind = ap.Kind
+			nested = nestedSet(d.p, ap)
+		case as.Kind != AttributeInvalid:
+			ia.Kind = as.Kind
+		case a.Type&netlink.Nested != 0:
+			ia.Kind = AttributeNested
+		}
+
+		switch ia.Kind {
+		case AttributeNested:
+			ia.Attributes, _ = d.attributes(nested, as.Nested, a.Data, depth+1, apath)
+		case AttributeNestedArray:
+			ia.Attributes, _ = d.array(nested, as.Nested, a.Data, depth+1, apath)
+		default:
+			if err := checkLength(ia.Kind, ap, len(a.Data)); err != nil {
+				d.fail(apath, err)
+			}
+		}
+
+		if ap == nil && as.Kind == AttributeInvalid {
+			// Neither the policy nor the schema describes the attribute.
+			ia.Kind = AttributeInvalid
+		}
+
+		if ia.Attributes == nil {
+			ia.Value = formatAttribute(ia.Kind, a.Type&netlink.NetByteOrder != 0, a.Data)
+		}
+
+		out = append(out, ia)
+	}
+
+	return out, true
+}
+
+// array describes a netlink "array" of nested attributes in b, each element
+// of which contains attributes described by set and names. Elements are
+// identified by their type in brackets following path.
+func (d *inspectDecoder) array(set *PolicySet, names AttributeSet, b []byte, depth int, path string) ([]InspectedAttribute, bool) {
+	elems, ok := (&inspectDecoder{p: d.p}).attributes(nil, nil, b, depth, path)
+	if !ok {
+		d.fail(path, errInvalidAttribute)
+		return nil, false
+	}
+
+	for i := range elems {
+		epath := fmt.Sprintf("%s[%d]", path, elems[i].Type)
+		attrs, ok := d.attributes(set, names, elems[i].Data, depth+1, epath)
+		if !ok {
+			continue
+		}
+
+		elems[i].Kind = AttributeNested
+		elems[i].Value = ""
+		elems[i].Attributes = attrs
+	}
+
+	return elems, true
+}
+
+// checkLength verifies that an attribute of the specified kind and policy ap,
+// if any, has a valid data length n.
+func checkLength(kind AttributeKind, ap *AttributePolicy, n int) error {
+	want := -1
+	switch kind {
+	case AttributeFlag:
+		want = 0
+	case AttributeU8, AttributeS8:
+		want = 1
+	case AttributeU16, AttributeS16:
+		want = 2
+	case AttributeU32, AttributeS32:
+		want = 4
+	case AttributeU64, AttributeS64, AttributeBitfield32:
+		want = 8
+	}
+
+	switch {
+	case want >= 0 && n != want:
+		return fmt.Errorf("length %d, want %d", n, want)
+	case ap == nil:
+		return nil
+	case ap.MinLength > 0 && n < int(ap.MinLength):
+		return fmt.Errorf("length %d, want at least %d", n, ap.MinLength)
+	case ap.MaxLength > 0 && n > int(ap.MaxLength):
+		return fmt.Errorf("length %d, want at most %d", n, ap.MaxLength)
+	}
+
+	return nil
+}
+
+// nestedSet returns the PolicySet which applies to the attributes nested
+// within an attribute with policy ap, or nil if none exists.
+func nestedSet(p *Policy, ap *AttributePolicy) *PolicySet {
+	if p == nil || ap.NestedMaxType == 0 {
+		return nil
+	}
+
+	s, ok := p.Set(ap.NestedIndex)
+	if !ok {
+		return nil
+	}
+
+	return &s
+}
+
+// formatAttribute formats attribute data b of the specified kind. If
+// bigEndian is true, integers are in network byte order.
+func formatAttribute(kind AttributeKind, bigEndian bool, b []byte) string {
+	var order binary.ByteOrder = nlenc.NativeEndian()
+	if bigEndian {
+		order = binary.BigEndian
+	}
+
+	switch {
+	case kind == AttributeFlag && len(b) == 0:
+		return "true"
+	case kind == AttributeU8 && len(b) == 1:
+		return strconv.FormatUint(uint64(b[0]), 10)
+	case kind == AttributeU16 && len(b) == 2:
+		return strconv.FormatUint(uint64(order.Uint16(b)), 10)
+	case kind == AttributeU32 && len(b) == 4:
+		return strconv.FormatUint(uint64(order.Uint32(b)), 10)
+	case kind == AttributeU64 && len(b) == 8:
+		return strconv.FormatUint(order.Uint64(b), 10)
+	case kind == AttributeS8 && len(b) == 1:
+		return strconv.FormatInt(int64(int8(b[0])), 10)
+	case kind == AttributeS16 && len(b) == 2:
+		return strconv.FormatInt(int64(int16(order.Uint16(b))), 10)
+	case kind == AttributeS32 && len(b) == 4:
+		return strconv.FormatInt(int64(int32(order.Uint32(b))), 10)
+	case kind == AttributeS64 && len(b) == 8:
+		return strconv.FormatInt(int64(order.Uint64(b)), 10)
+	case kind == AttributeString, kind == AttributeNulString:
+		return strconv.Quote(nlenc.String(b))
+	case kind == AttributeBitfield32 && len(b) == 8:
+		return fmt.Sprintf("value %#x, selector %#x", order.Uint32(b[0:4]), order.Uint32(b[4:8]))
+	default:
+		return hexBytes(b)
+	}
+}
+
+// kindString returns a short name for an AttributeKind.
+func kindString(k AttributeKind) string {
+	switch k {
+	case AttributeFlag:
+		return "flag"
+	case AttributeU8:
+		return "u8"
+	case AttributeU16:
+		return "u16"
+	case AttributeU32:
+		return "u32"
+	case AttributeU64:
+		return "u64"
+	case AttributeS8:
+		return "s8"
+	case AttributeS16:
+		return "s16"
+	case AttributeS32:
+		return "s32"
+	case AttributeS64:
+		return "s64"
+	case AttributeBinary:
+		return "binary"
+	case AttributeString:
+		return "string"
+	case AttributeNulString:
+		return "nul-string"
+	case AttributeNested:
+		return "nested"
+	case AttributeNestedArray:
+		return "nested-array"
+	case AttributeBitfield32:
+		return "bitfield32"
+	default:
+		return fmt.Sprintf("kind(%d)", uint32(k))
+	}
+}
+
+// hexBytes formats b as bracketed hexadecimal bytes.
+func hexBytes(b []byte) string {
+	return fmt.Sprintf("[% x]", b)
+}
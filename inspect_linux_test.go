@@ -0,0 +1,124 @@
+//go:build linux
+// +build linux
+
+package genetlink_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"github.com/mdlayher/netlink/nltest"
+)
+
+func TestInspect(t *testing.T) {
+	policy := genetlink.Policy{
+		Sets: []genetlink.PolicySet{
+			{
+				Index: 0,
+				Attributes: []genetlink.AttributePolicy{
+					{Type: 1, Kind: genetlink.AttributeU32},
+					{Type: 2, Kind: genetlink.AttributeString},
+					{Type: 3, Kind: genetlink.AttributeNested, NestedIndex: 1, NestedMaxType: 2},
+				},
+			},
+			{
+				Index: 1,
+				Attributes: []genetlink.AttributePolicy{
+					{Type: 1, Kind: genetlink.AttributeS16},
+					{Type: 2, Kind: genetlink.AttributeFlag},
+				},
+			},
+		},
+		Ops: []genetlink.OpPolicy{{Command: 1, Do: 0, Dump: -1}},
+	}
+
+	b := genltest.NewFamilyBuilder(genetlink.Family{ID: 0x20, Version: 1, Name: "foo"}).
+		Op(1, genetlink.OpCapDo|genetlink.OpCapHasPolicy).
+		Policy(policy)
+
+	c := genltest.Dial(b.Serve(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, nil
+	}))
+	defer c.Close()
+
+	m := genetlink.Message{
+		Header: genetlink.Header{Command: 1, Version: 1},
+		Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+			{Type: 1, Data: nlenc.Uint32Bytes(10)},
+			{Type: 2, Data: nlenc.Bytes("eth0")},
+			{
+				Type: 3 | netlink.Nested,
+				Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+					{Type: 1, Data: nlenc.Uint16Bytes(0xffff)},
+					{Type: 2},
+				}),
+			},
+			// Not described by the policy.
+			{Type: 4, Data: []byte{0xde, 0xad}},
+		}),
+	}
+
+	in := genetlink.NewInspector(c)
+
+	tests := []struct {
+		name   string
+		family uint16
+		m      genetlink.Message
+		want   string
+	}{
+		{
+			name:   "policy",
+			family: 0x20,
+			m:      m,
+			want: `foo (0x20): command 1, version 1
+  1 (u32): 10
+  2 (string): "eth0"
+  3 (nested):
+    1 (s16): -1
+    2 (flag): true
+  4 (unknown): [de ad]
+`,
+		},
+		{
+			name:   "unknown family",
+			family: 0x30,
+			m:      m,
+			want: `unknown (0x30): command 1, version 1
+  1 (unknown): [0a 00 00 00]
+  2 (unknown): [65 74 68 30 00]
+  3 (unknown):
+    1 (unknown): [ff ff]
+    2 (unknown): []
+  4 (unknown): [de ad]
+`,
+		},
+		{
+			name:   "malformed",
+			family: 0x20,
+			m: genetlink.Message{
+				Header: genetlink.Header{Command: 2},
+				Data:   []byte{0xff},
+			},
+			want: `foo (0x20): command 2, version 0
+  data: [ff]
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i, err := in.Inspect(tt.family, tt.m)
+			if err != nil {
+				t.Fatalf("failed to inspect: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, i.String()); diff != "" {
+				t.Fatalf("unexpected inspection (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+// Package endian provides alignment-safe reads of integers in native byte
+// order from netlink message and attribute data.
+//
+// The integer functions of the netlink/nlenc package dereference unsafe
+// pointers into their input, which faults on architectures such as arm and
+// mips when the data is not aligned, as netlink attribute data often is: a
+// u64 attribute value is only guaranteed 4 byte alignment. The functions of
+// this package read individual bytes, and are safe at any alignment.
+package endian
+
+import (
+	"encoding/binary"
+
+	"github.com/mdlayher/netlink/nlenc"
+)
+
+// Native is the system's native byte order.
+var Native binary.ByteOrder = nlenc.NativeEndian()
+
+// Uint16 decodes a uint16 from the first 2 bytes of b in native byte order.
+func Uint16(b []byte) uint16 { return Native.Uint16(b) }
+
+// Uint32 decodes a uint32 from the first 4 bytes of b in native byte order.
+func Uint32(b []byte) uint32 { return Native.Uint32(b) }
+
+// Uint64 decodes a uint64 from the first 8 bytes of b in native byte order.
+func Uint64(b []byte) uint64 { return Native.Uint64(b) }
@@ -0,0 +1,33 @@
+package endian_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink/internal/endian"
+	"github.com/mdlayher/netlink/nlenc"
+)
+
+func TestUnaligned(t *testing.T) {
+	// Read values at every offset within a buffer, so that on architectures
+	// which fault on unaligned access, such as arm and mips, unsafe reads
+	// would crash the test.
+	for i := 0; i < 8; i++ {
+		b := make([]byte, i+8)
+		copy(b[i:], nlenc.Uint64Bytes(0x0102030405060708))
+
+		if diff := cmp.Diff(uint64(0x0102030405060708), endian.Uint64(b[i:])); diff != "" {
+			t.Fatalf("unexpected uint64 at offset %d (-want +got):\n%s", i, diff)
+		}
+
+		copy(b[i:], nlenc.Uint32Bytes(0x01020304))
+		if diff := cmp.Diff(uint32(0x01020304), endian.Uint32(b[i:])); diff != "" {
+			t.Fatalf("unexpected uint32 at offset %d (-want +got):\n%s", i, diff)
+		}
+
+		copy(b[i:], nlenc.Uint16Bytes(0x0102))
+		if diff := cmp.Diff(uint16(0x0102), endian.Uint16(b[i:])); diff != "" {
+			t.Fatalf("unexpected uint16 at offset %d (-want +got):\n%s", i, diff)
+		}
+	}
+}
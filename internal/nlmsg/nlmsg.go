@@ -0,0 +1,52 @@
+// Package nlmsg parses raw netlink message streams, such as those read
+// directly from a netlink socket or captured by an nlmon interface.
+package nlmsg
+
+import (
+	"errors"
+
+	"github.com/mdlayher/genetlink/internal/endian"
+	"github.com/mdlayher/netlink"
+)
+
+// HeaderLen is the size of a netlink message header.
+const HeaderLen = 16
+
+// errInvalidLength is returned when a message header contains a length which
+// does not fit in the input buffer.
+var errInvalidLength = errors.New("netlink message header has invalid length")
+
+// Parse parses one or more netlink messages packed in b. Unlike
+// netlink.Message.UnmarshalBinary, Parse accepts messages whose header length
+// is not a multiple of the netlink alignment, as the kernel produces. The
+// Data field of each message refers to the memory of b.
+func Parse(b []byte) ([]netlink.Message, error) {
+	var msgs []netlink.Message
+	for len(b) >= HeaderLen {
+		h := netlink.Header{
+			Length:   endian.Uint32(b[0:4]),
+			Type:     netlink.HeaderType(endian.Uint16(b[4:6])),
+			Flags:    netlink.HeaderFlags(endian.Uint16(b[6:8])),
+			Sequence: endian.Uint32(b[8:12]),
+			PID:      endian.Uint32(b[12:16]),
+		}
+
+		if h.Length < HeaderLen || int(h.Length) > len(b) {
+			return nil, errInvalidLength
+		}
+
+		msgs = append(msgs, netlink.Message{
+			Header: h,
+			Data:   b[HeaderLen:h.Length],
+		})
+
+		// Advance to the next 4-byte aligned message.
+		l := int(h.Length+3) &^ 3
+		if l > len(b) {
+			l = len(b)
+		}
+		b = b[l:]
+	}
+
+	return msgs, nil
+}
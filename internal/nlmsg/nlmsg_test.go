@@ -0,0 +1,125 @@
+package nlmsg_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink/internal/nlmsg"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+)
+
+func TestParse(t *testing.T) {
+	header := func(length uint32, typ uint16) []byte {
+		b := nlenc.Uint32Bytes(length)
+		b = append(b, nlenc.Uint16Bytes(typ)...)
+		b = append(b, nlenc.Uint16Bytes(0)...)
+		b = append(b, nlenc.Uint32Bytes(1)...)
+		return append(b, nlenc.Uint32Bytes(2)...)
+	}
+
+	tests := []struct {
+		name string
+		b    []byte
+		msgs []netlink.Message
+		ok   bool
+	}{
+		{
+			name: "empty",
+			ok:   true,
+		},
+		{
+			name: "short length",
+			b:    header(8, 0x10),
+		},
+		{
+			name: "long length",
+			b:    header(32, 0x10),
+		},
+		{
+			name: "OK",
+			b: append(
+				// Unaligned length followed by padding.
+				append(header(17, 0x10), 0xff, 0x00, 0x00, 0x00),
+				header(16, 0x11)...,
+			),
+			msgs: []netlink.Message{
+				{
+					Header: netlink.Header{
+						Length:   17,
+						Type:     0x10,
+						Sequence: 1,
+						PID:      2,
+					},
+					Data: []byte{0xff},
+				},
+				{
+					Header: netlink.Header{
+						Length:   16,
+						Type:     0x11,
+						Sequence: 1,
+						PID:      2,
+					},
+					Data: []byte{},
+				},
+			},
+			ok: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs, err := nlmsg.Parse(tt.b)
+
+			if err != nil && tt.ok {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err == nil && !tt.ok {
+				t.Fatal("expected an error, but none occurred")
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.msgs, msgs); diff != "" {
+				t.Fatalf("unexpected messages (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseUnaligned(t *testing.T) {
+	b := make([]byte, 0, 16)
+	b = append(b, nlenc.Uint32Bytes(20)...)
+	b = append(b, nlenc.Uint16Bytes(0x10)...)
+	b = append(b, nlenc.Uint16Bytes(0x2)...)
+	b = append(b, nlenc.Uint32Bytes(1)...)
+	b = append(b, nlenc.Uint32Bytes(2)...)
+	b = append(b, 0xff, 0xff, 0xff, 0xff)
+
+	// Parse a copy of the message which begins at an odd address, so that
+	// decoding which dereferences unaligned pointers faults on
+	// strict-alignment architectures such as mips.
+	buf := make([]byte, len(b)+1)
+	copy(buf[1:], b)
+
+	msgs, err := nlmsg.Parse(buf[1:])
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	want := []netlink.Message{{
+		Header: netlink.Header{
+			Length:   20,
+			Type:     0x10,
+			Flags:    0x2,
+			Sequence: 1,
+			PID:      2,
+		},
+		Data: []byte{0xff, 0xff, 0xff, 0xff},
+	}}
+
+	if diff := cmp.Diff(want, msgs); diff != "" {
+		t.Fatalf("unexpected messages (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,89 @@
+package genetlink
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+)
+
+// nlmsgHeaderLen is the length of a netlink message header.
+const nlmsgHeaderLen = 16 // unix.NLMSG_HDRLEN
+
+// ResponseLimits are limits on the replies accumulated by a single request
+// made using Execute, ExecutePriority, ExecuteFn, or Dump. A zero value for
+// a field means that there is no limit.
+type ResponseLimits struct {
+	// Messages is the maximum number of reply messages.
+	Messages int
+
+	// Bytes is the maximum total length of the reply messages, including
+	// their netlink headers.
+	Bytes int
+}
+
+// SetResponseLimits sets limits on the replies to each request made using
+// Execute, ExecutePriority, ExecuteFn, or Dump. When the replies to a
+// request exceed a limit, the replies are discarded and a
+// *ResponseLimitError is returned instead, protecting long-running
+// applications from unbounded dumps produced by a faulty or compromised
+// kernel module.
+//
+// When the Transport supports it, as a *netlink.Conn created by Dial does,
+// the parts of a multi-part reply are checked as they arrive, and once a
+// limit is exceeded the remaining parts are read and discarded rather than
+// accumulated. Such requests have exclusive use of the Conn's socket while
+// their replies are received. Otherwise, the Transport receives all of the
+// parts of a reply before they can be checked, so the limits bound only the
+// memory which is retained and decoded by this package and its callers.
+// Replies are never left unread on the socket, so the Conn remains usable
+// after a limit is exceeded.
+//
+// SetResponseLimits must be called before the Conn is used concurrently.
+func (c *Conn) SetResponseLimits(l ResponseLimits) {
+	c.limits = l
+}
+
+// A ResponseLimitError is returned when the replies to a request exceed the
+// limits set using Conn.SetResponseLimits.
+type ResponseLimitError struct {
+	// Messages and Bytes are the number and total length of the replies
+	// which were received and discarded when the limit was found to be
+	// exceeded. Replies which were read after that point are not counted.
+	Messages, Bytes int
+
+	// Limits are the limits which were exceeded.
+	Limits ResponseLimits
+}
+
+func (err *ResponseLimitError) Error() string {
+	return fmt.Sprintf("genetlink: response of %d messages and %d bytes exceeds limit of %d messages and %d bytes",
+		err.Messages, err.Bytes, err.Limits.Messages, err.Limits.Bytes)
+}
+
+// check verifies that msgs do not exceed l.
+func (l ResponseLimits) check(msgs []netlink.Message) error {
+	if l == (ResponseLimits{}) {
+		return nil
+	}
+
+	var n int
+	for _, m := range msgs {
+		n += nlmsgHeaderLen + len(m.Data)
+	}
+
+	if l.exceeded(len(msgs), n) {
+		return &ResponseLimitError{
+			Messages: len(msgs),
+			Bytes:    n,
+			Limits:   l,
+		}
+	}
+
+	return nil
+}
+
+// exceeded reports whether n messages with a total length of size bytes
+// exceed l.
+func (l ResponseLimits) exceeded(n, size int) bool {
+	return (l.Messages > 0 && n > l.Messages) || (l.Bytes > 0 && size > l.Bytes)
+}
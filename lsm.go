@@ -0,0 +1,130 @@
+package genetlink
+
+import (
+	"errors"
+	"os"
+
+	"github.com/mdlayher/netlink"
+)
+
+// socketClass is the SELinux security class of generic netlink sockets.
+const socketClass = "netlink_generic_socket"
+
+// An LSMError reports that an operation was denied with EACCES or EPERM
+// although the current process holds CAP_NET_ADMIN, which suggests that a
+// Linux Security Module policy, such as SELinux or AppArmor, denied it
+// rather than the kernel's capability checks.
+//
+// LSMErrors wrap the original error, so it can still be checked using
+// `errors.Is(err, os.ErrPermission)`.
+type LSMError struct {
+	// Class and Permission are the SELinux socket class and permission
+	// which were likely denied, such as "netlink_generic_socket" and
+	// "write". Permission is empty if it could not be determined.
+	Class, Permission string
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements error.
+func (e *LSMError) Error() string {
+	perm := e.Class
+	if e.Permission != "" {
+		perm += " { " + e.Permission + " }"
+	}
+
+	return "genetlink: permission denied although the process holds CAP_NET_ADMIN; " +
+		"an LSM policy such as SELinux or AppArmor may deny " + perm + ": " + e.Err.Error()
+}
+
+// Unwrap implements errors unwrapping.
+func (e *LSMError) Unwrap() error { return e.Err }
+
+// lsmError annotates err with an *LSMError if a system call was denied
+// although the current process holds CAP_NET_ADMIN. Otherwise, err is
+// returned unchanged. Errors reported by the kernel in reply to a request
+// are not annotated, since LSMs deny operations on the socket itself.
+//
+// If err is a *netlink.OpError, the *LSMError is stored in its Err field
+// so that callers can continue to inspect the OpError.
+func lsmError(err error) error {
+	return annotateLSM(err, hasNetAdmin)
+}
+
+// annotateLSM implements lsmError using the input function to check for
+// CAP_NET_ADMIN.
+func annotateLSM(err error, hasNetAdmin func() (bool, error)) error {
+	syscall, ok := deniedSyscall(err)
+	if !ok {
+		return err
+	}
+
+	var lerr *LSMError
+	if errors.As(err, &lerr) {
+		// Already annotated.
+		return err
+	}
+
+	if netAdmin, cerr := hasNetAdmin(); cerr != nil || !netAdmin {
+		// The denial is explained by missing capabilities, or capabilities
+		// cannot be checked on this platform.
+		return err
+	}
+
+	if oerr, ok := err.(*netlink.OpError); ok {
+		return &netlink.OpError{
+			Op:      oerr.Op,
+			Err:     newLSMError(syscall, oerr.Err),
+			Offset:  oerr.Offset,
+			Message: oerr.Message,
+		}
+	}
+
+	return newLSMError(syscall, err)
+}
+
+// deniedSyscall returns the name of a system call which failed with EACCES
+// or EPERM within err, and reports whether one was found.
+func deniedSyscall(err error) (string, bool) {
+	var (
+		serr *os.SyscallError
+		rerr *RestrictionError
+	)
+
+	switch {
+	case errors.As(err, &serr):
+		return serr.Syscall, errors.Is(serr.Err, os.ErrPermission)
+	case errors.As(err, &rerr):
+		// The system call error was unwrapped by restriction.
+		return rerr.Op, errors.Is(rerr.Err, os.ErrPermission)
+	default:
+		return "", false
+	}
+}
+
+// newLSMError creates an *LSMError for err, which occurred during the
+// system call syscall.
+func newLSMError(syscall string, err error) *LSMError {
+	var perm string
+	switch syscall {
+	case "socket":
+		perm = "create"
+	case "bind":
+		perm = "bind"
+	case "setsockopt":
+		perm = "setopt"
+	case "getsockopt":
+		perm = "getopt"
+	case "sendmsg", "sendto":
+		perm = "write"
+	case "recvmsg", "recvfrom":
+		perm = "read"
+	}
+
+	return &LSMError{
+		Class:      socketClass,
+		Permission: perm,
+		Err:        err,
+	}
+}
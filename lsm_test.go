@@ -0,0 +1,114 @@
+package genetlink
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/netlink"
+)
+
+func TestAnnotateLSM(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		netAdmin bool
+		want     *LSMError
+		op       bool
+	}{
+		{
+			name:     "nil",
+			netAdmin: true,
+		},
+		{
+			name:     "not denied",
+			err:      os.NewSyscallError("sendmsg", syscall.ENOBUFS),
+			netAdmin: true,
+		},
+		{
+			name: "no CAP_NET_ADMIN",
+			err:  os.NewSyscallError("socket", syscall.EACCES),
+		},
+		{
+			name: "kernel reply",
+			err: &netlink.OpError{
+				Op:  "receive",
+				Err: syscall.EPERM,
+			},
+			netAdmin: true,
+		},
+		{
+			name:     "socket",
+			err:      os.NewSyscallError("socket", syscall.EACCES),
+			netAdmin: true,
+			want:     &LSMError{Class: "netlink_generic_socket", Permission: "create"},
+		},
+		{
+			name: "restricted bind",
+			err: &RestrictionError{
+				Op:  "bind",
+				Err: syscall.EPERM,
+			},
+			netAdmin: true,
+			want:     &LSMError{Class: "netlink_generic_socket", Permission: "bind"},
+		},
+		{
+			name: "send",
+			err: &netlink.OpError{
+				Op:  "send",
+				Err: os.NewSyscallError("sendmsg", syscall.EACCES),
+			},
+			netAdmin: true,
+			want:     &LSMError{Class: "netlink_generic_socket", Permission: "write"},
+			op:       true,
+		},
+		{
+			name:     "unknown",
+			err:      os.NewSyscallError("ioctl", syscall.EACCES),
+			netAdmin: true,
+			want:     &LSMError{Class: "netlink_generic_socket"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := annotateLSM(tt.err, func() (bool, error) {
+				return tt.netAdmin, nil
+			})
+
+			var lerr *LSMError
+			if !errors.As(err, &lerr) {
+				if tt.want != nil {
+					t.Fatalf("expected an LSMError, but got: %v", err)
+				}
+				if err != tt.err {
+					t.Fatalf("error was modified: %v", err)
+				}
+
+				return
+			}
+			if tt.want == nil {
+				t.Fatalf("unexpected LSMError: %v", err)
+			}
+
+			got := &LSMError{Class: lerr.Class, Permission: lerr.Permission}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("unexpected LSMError (-want +got):\n%s", diff)
+			}
+
+			if _, ok := err.(*netlink.OpError); ok != tt.op {
+				t.Fatalf("unexpected OpError: %v", err)
+			}
+
+			if !errors.Is(err, os.ErrPermission) {
+				t.Fatalf("error does not wrap its cause: %v", err)
+			}
+
+			if again := annotateLSM(err, func() (bool, error) { return true, nil }); again != err {
+				t.Fatal("annotated error was annotated again")
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package genetlink
+
+// MemInfo contains the kernel's memory counters for a Conn's socket, as
+// reported by the SO_MEMINFO socket option. All sizes are in bytes.
+//
+// When RMemAlloc approaches RcvBuf, the socket's receive queue is nearly
+// full, and the kernel will soon begin dropping messages and reporting
+// ENOBUFS. Monitors can use MemInfo to alert or to increase the receive
+// buffer size using SetReadBuffer before messages are lost.
+type MemInfo struct {
+	// RMemAlloc is the memory allocated for received messages which have not
+	// yet been read.
+	RMemAlloc uint32
+
+	// RcvBuf is the size of the receive buffer.
+	RcvBuf uint32
+
+	// WMemAlloc is the memory allocated for messages being sent.
+	WMemAlloc uint32
+
+	// SndBuf is the size of the send buffer.
+	SndBuf uint32
+
+	// FwdAlloc is the memory reserved for future use by the socket.
+	FwdAlloc uint32
+
+	// WMemQueued is the memory used by messages queued for sending.
+	WMemQueued uint32
+
+	// OptMem is the memory used by socket options, such as BPF filters.
+	OptMem uint32
+
+	// Backlog is the memory used by the socket's backlog queue.
+	Backlog uint32
+
+	// Drops is the number of messages dropped by the socket. Drops is only
+	// reported by Linux 4.7+.
+	Drops uint32
+}
+
+// MemInfo retrieves the kernel's memory counters for the Conn's socket.
+func (c *Conn) MemInfo() (MemInfo, error) {
+	return c.memInfo()
+}
@@ -0,0 +1,55 @@
+//go:build linux
+// +build linux
+
+package genetlink
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// skMeminfoVars is the number of counters reported by SO_MEMINFO, as of
+// Linux 4.7.
+const skMeminfoVars = 9 // unix.SK_MEMINFO_VARS
+
+// memInfo retrieves the socket's memory counters using SO_MEMINFO.
+func (c *Conn) memInfo() (MemInfo, error) {
+	rc, err := c.SyscallConn()
+	if err != nil {
+		return MemInfo{}, err
+	}
+
+	var (
+		vars [skMeminfoVars]uint32
+		n    = uint32(unsafe.Sizeof(vars))
+		serr error
+	)
+	if err := rc.Control(func(fd uintptr) {
+		_, _, errno := unix.Syscall6(unix.SYS_GETSOCKOPT, fd,
+			unix.SOL_SOCKET, unix.SO_MEMINFO,
+			uintptr(unsafe.Pointer(&vars[0])), uintptr(unsafe.Pointer(&n)), 0)
+		if errno != 0 {
+			serr = errno
+		}
+	}); err != nil {
+		return MemInfo{}, err
+	}
+	if serr != nil {
+		return MemInfo{}, os.NewSyscallError("getsockopt", serr)
+	}
+
+	// Older kernels report fewer counters, leaving the rest zero.
+	return MemInfo{
+		RMemAlloc:  vars[0],
+		RcvBuf:     vars[1],
+		WMemAlloc:  vars[2],
+		SndBuf:     vars[3],
+		FwdAlloc:   vars[4],
+		WMemQueued: vars[5],
+		OptMem:     vars[6],
+		Backlog:    vars[7],
+		Drops:      vars[8],
+	}, nil
+}
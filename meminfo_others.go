@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package genetlink
+
+// memInfo always returns an error.
+func (c *Conn) memInfo() (MemInfo, error) {
+	return MemInfo{}, errUnimplemented
+}
@@ -1,6 +1,9 @@
 package genetlink
 
-import "errors"
+import (
+	"errors"
+	"sync"
+)
 
 // errInvalidMessage is returned when a Message is malformed.
 var errInvalidMessage = errors.New("generic netlink message is invalid or too short")
@@ -32,14 +35,17 @@ type Message struct {
 
 // MarshalBinary marshals a Message into a byte slice.
 func (m Message) MarshalBinary() ([]byte, error) {
-	b := make([]byte, headerLen)
+	// Allocate space for the header and data at once, so that data is only
+	// copied once.
+	b := make([]byte, headerLen+len(m.Data))
 
 	b[0] = m.Header.Command
 	b[1] = m.Header.Version
 
 	// b[2] and b[3] are padding bytes and set to zero
 
-	return append(b, m.Data...), nil
+	copy(b[headerLen:], m.Data)
+	return b, nil
 }
 
 // UnmarshalBinary unmarshals the contents of a byte slice into a Message.
@@ -59,3 +65,64 @@ func (m *Message) UnmarshalBinary(b []byte) error {
 	m.Data = b[4:]
 	return nil
 }
+
+// Clone returns a deep copy of m. Data in the returned Message does not share
+// memory with m, so the copy may be retained and modified independently of
+// the original, such as beyond the next call to Conn.Receive.
+func (m Message) Clone() Message {
+	c := Message{Header: m.Header}
+	if m.Data != nil {
+		c.Data = make([]byte, len(m.Data))
+		copy(c.Data, m.Data)
+	}
+
+	return c
+}
+
+// Reset clears m so that it can be reused. Data is set to nil rather than
+// truncated, because it may refer to memory owned by something else, such as
+// the receive buffer of a Conn, which appending to Data would overwrite. Use
+// a MessagePool to reuse the capacity of Data across Messages.
+func (m *Message) Reset() {
+	m.Header = Header{}
+	m.Data = nil
+}
+
+// A MessagePool is a pool of Messages which can be recycled across the
+// iterations of a tight loop to avoid allocating new Messages and Data. It
+// is safe for concurrent use.
+//
+// The zero value of a MessagePool is ready to use.
+type MessagePool struct {
+	p sync.Pool
+}
+
+// Get returns an empty Message from the pool, allocating one if the pool is
+// empty.
+func (p *MessagePool) Get() *Message {
+	if m, ok := p.p.Get().(*Message); ok {
+		return m
+	}
+
+	return &Message{}
+}
+
+// Put clears m and returns it to the pool, retaining the capacity of Data so
+// that Messages from Get can be refilled without allocating, such as with
+// `m.Data = append(m.Data, b...)`. m must not be used after it is returned
+// to the pool.
+//
+// Messages whose Data refers to memory owned by something else, such as
+// Messages returned by Conn.Receive, must not be put in the pool.
+func (p *MessagePool) Put(m *Message) {
+	m.Header = Header{}
+	m.Data = m.Data[:0]
+	p.p.Put(m)
+}
+
+// Equal reports whether m and x are equal. Headers must match exactly, and
+// Data is compared attribute by attribute as described by Diff, so that
+// differences in attribute ordering or padding do not affect the result.
+func (m Message) Equal(x Message) bool {
+	return len(Diff(m, x)) == 0
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"reflect"
 	"testing"
+
+	"github.com/mdlayher/netlink"
 )
 
 func TestMessageMarshalBinary(t *testing.T) {
@@ -145,3 +147,166 @@ func TestMessageUnmarshalBinary(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageEqual(t *testing.T) {
+	m := Message{
+		Header: Header{Command: 1, Version: 1},
+		Data: mustMarshalAttributes([]netlink.Attribute{
+			{Type: 1, Data: []byte{0x01}},
+			{Type: 2, Data: []byte{0x02}},
+		}),
+	}
+
+	tests := []struct {
+		name string
+		x    Message
+		ok   bool
+	}{
+		{
+			name: "equal",
+			x:    m,
+			ok:   true,
+		},
+		{
+			name: "reordered",
+			x: Message{
+				Header: Header{Command: 1, Version: 1},
+				Data: mustMarshalAttributes([]netlink.Attribute{
+					{Type: 2, Data: []byte{0x02}},
+					{Type: 1, Data: []byte{0x01}},
+				}),
+			},
+			ok: true,
+		},
+		{
+			name: "header",
+			x: Message{
+				Header: Header{Command: 2, Version: 1},
+				Data:   m.Data,
+			},
+		},
+		{
+			name: "data",
+			x: Message{
+				Header: Header{Command: 1, Version: 1},
+				Data: mustMarshalAttributes([]netlink.Attribute{
+					{Type: 1, Data: []byte{0x01}},
+				}),
+			},
+		},
+		{
+			name: "flags",
+			x: Message{
+				Header: Header{Command: 1, Version: 1},
+				Data: mustMarshalAttributes([]netlink.Attribute{
+					{Type: 1 | netlink.NetByteOrder, Data: []byte{0x01}},
+					{Type: 2, Data: []byte{0x02}},
+				}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Equal(tt.x); tt.ok != got {
+				t.Fatalf("unexpected equality: %v, want: %v", got, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMessageClone(t *testing.T) {
+	m := Message{
+		Header: Header{Command: 1, Version: 2},
+		Data:   []byte{0x01, 0x02},
+	}
+
+	c := m.Clone()
+	if !reflect.DeepEqual(m, c) {
+		t.Fatalf("unexpected clone:\n- want: %v\n-  got: %v", m, c)
+	}
+
+	// Modifying the clone must not affect the original.
+	c.Data[0] = 0xff
+	if m.Data[0] != 0x01 {
+		t.Fatal("clone shares memory with original message")
+	}
+
+	if c := (Message{}).Clone(); c.Data != nil {
+		t.Fatalf("expected nil data for empty clone, but got: %v", c.Data)
+	}
+}
+
+func TestMessageReset(t *testing.T) {
+	// Data refers to a buffer which is shared with other messages, as with
+	// those returned by Conn.Receive.
+	buf := []byte{0x01, 0x02, 0x03, 0x04, 0xff, 0xff}
+	m := Message{
+		Header: Header{Command: 1, Version: 2},
+		Data:   buf[:4],
+	}
+
+	m.Reset()
+
+	if m.Header != (Header{}) || m.Data != nil {
+		t.Fatalf("expected empty message, but got: %+v", m)
+	}
+
+	// Appending to a reset message must not overwrite the shared buffer.
+	m.Data = append(m.Data, 0x00)
+	if buf[0] != 0x01 {
+		t.Fatal("reset message shares memory with its previous Data")
+	}
+}
+
+func TestMessagePool(t *testing.T) {
+	var p MessagePool
+
+	m := p.Get()
+	if m.Header != (Header{}) || len(m.Data) != 0 {
+		t.Fatalf("expected empty message, but got: %+v", m)
+	}
+
+	m.Header.Command = 1
+	m.Data = append(make([]byte, 0, 8), 0x01, 0x02)
+	p.Put(m)
+
+	// The pool owns Data, so its capacity is retained.
+	if m.Header != (Header{}) || len(m.Data) != 0 || cap(m.Data) != 8 {
+		t.Fatalf("expected empty message with retained capacity, but got: %+v", m)
+	}
+
+	// The pool may or may not return the same Message, but it must always
+	// be empty.
+	m = p.Get()
+	if m.Header != (Header{}) || len(m.Data) != 0 {
+		t.Fatalf("expected empty message, but got: %+v", m)
+	}
+}
+
+func TestMessageBinaryAllocations(t *testing.T) {
+	m := Message{
+		Header: Header{Command: 1, Version: 2},
+		Data:   make([]byte, 512),
+	}
+
+	// Marshaling allocates only the output, and copies Data into it once.
+	if n := testing.AllocsPerRun(100, func() { _, _ = m.MarshalBinary() }); n != 1 {
+		t.Fatalf("unexpected allocations to marshal: %v", n)
+	}
+
+	b, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	// Unmarshaling parses the input in place.
+	var got Message
+	if n := testing.AllocsPerRun(100, func() { _ = got.UnmarshalBinary(b) }); n != 0 {
+		t.Fatalf("unexpected allocations to unmarshal: %v", n)
+	}
+
+	if &got.Data[0] != &b[headerLen] {
+		t.Fatal("expected unmarshaled Data to refer to the input")
+	}
+}
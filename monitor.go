@@ -0,0 +1,522 @@
+package genetlink
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mdlayher/netlink"
+)
+
+// Generic netlink controller values used to keep a Monitor's family cache
+// up to date.
+const (
+	ctrlID           = 0x10 // unix.GENL_ID_CTRL
+	ctrlCmdNewFamily = 0x1  // unix.CTRL_CMD_NEWFAMILY
+	ctrlCmdDelFamily = 0x2  // unix.CTRL_CMD_DELFAMILY
+
+	ctrlAttrFamilyID   = 0x1 // unix.CTRL_ATTR_FAMILY_ID
+	ctrlAttrFamilyName = 0x2 // unix.CTRL_ATTR_FAMILY_NAME
+)
+
+// A Monitor receives generic netlink multicast messages from a Conn and
+// delivers them as Events tagged with the name of their family, the
+// multicast group they were likely received from, and their command.
+//
+// A Monitor names the families of the multicast groups it has joined. Other
+// family IDs are resolved using a cache which is populated on demand by the
+// Monitor's Resolver, if it can list families (see SetResolver). The cache
+// is invalidated when the generic netlink controller reports that a family
+// was registered or unregistered.
+type Monitor struct {
+	c       *Conn
+	r       Resolver
+	schemas *SchemaRegistry
+
+	mu       sync.Mutex
+	families map[uint16]Family
+	groups   map[uint16][]MulticastGroup
+	joined   map[uint16]string
+	subs     []*Subscription
+
+	// Optional health checking while Run is active.
+	checkInterval time.Duration
+	check         func() error
+
+	// Optional recovery from receive buffer overflows.
+	resync func() error
+}
+
+// An Event is a generic netlink message received by a Monitor.
+type Event struct {
+	// Family is the name of the message's family, or empty if the family
+	// could not be resolved.
+	Family string
+
+	// Group is the name of the multicast group the message was received
+	// from. Netlink does not report the group of each message, so Group is
+	// only set when exactly one group of the family has been subscribed.
+	Group string
+
+	// Command is the generic netlink command of the message.
+	Command uint8
+
+	// Header and Message are the netlink header and generic netlink message.
+	Header  netlink.Header
+	Message Message
+
+	// Inspection describes the message using the Schema registered for its
+	// family, with named and typed attributes. Inspection is nil if no
+	// Schema is registered for the family. See Monitor.SetSchemas.
+	Inspection *Inspection
+
+	// Gap, if set, indicates that this Event does not carry a message, and
+	// instead marks a window during which Events may have been lost. See
+	// Monitor.SetResync.
+	Gap *Gap
+}
+
+// NewMonitor creates a Monitor which receives messages using c. The Monitor
+// owns the receive loop of c: the caller must not call Receive on c while
+// the Monitor is running.
+func NewMonitor(c *Conn) *Monitor {
+	return &Monitor{
+		c:       c,
+		r:       c,
+		schemas: DefaultSchemas,
+		groups:  make(map[uint16][]MulticastGroup),
+		joined:  make(map[uint16]string),
+	}
+}
+
+// SetResolver sets the Resolver used to resolve family names passed to
+// Subscribe and SubscribeGroup. By default, families are resolved using the
+// family cache of the Monitor's Conn, but requests are made using a separate
+// Conn to the same backend, because replies on the Monitor's Conn could be
+// consumed by Run or interleaved with multicast messages. If the Conn's
+// Transport cannot create a separate Conn, a Resolver must be set.
+//
+// If r also lists families using a ListFamilies method, such as a separate
+// Conn, it is used to name the families of Events from groups which were not
+// joined by the Monitor. The Monitor's own Conn is never used for this, as
+// its replies would be interleaved with the multicast messages received by
+// Run.
+//
+// SetResolver must be called before the Monitor is used concurrently.
+func (m *Monitor) SetResolver(r Resolver) {
+	m.r = r
+}
+
+// SetSchemas sets the SchemaRegistry used to describe the messages of each
+// Event. By default, DefaultSchemas is used. If r is nil, Events are not
+// described.
+//
+// SetSchemas must be called before the Monitor is used concurrently.
+func (m *Monitor) SetSchemas(r *SchemaRegistry) {
+	m.schemas = r
+}
+
+// SetHealthCheck enables periodic health checks of the Monitor's Conn while
+// Run is active, so that a socket which has failed is detected even if no
+// messages arrive. If interval is 0, health checks are disabled.
+//
+// If check is nil, the health check verifies the netlink path end-to-end
+// using Ping with a timeout of interval, on a separate Conn to the same
+// backend since Run is receiving using the Monitor's Conn. If the Conn's
+// Transport cannot create a separate Conn, check must be set.
+//
+// When a health check fails, Run returns an error wrapping the health check
+// error, which is also reported by the Errors channel of each Subscription.
+// Run is interrupted using a read deadline which applies only to Run, and
+// any deadline set on the Conn is restored before Run returns.
+//
+// SetHealthCheck must be called before Run.
+func (m *Monitor) SetHealthCheck(interval time.Duration, check func() error) {
+	m.checkInterval, m.check = interval, check
+}
+
+// Subscribe joins the multicast group with the specified name of the named
+// family.
+func (m *Monitor) Subscribe(family, group string) error {
+	_, err := m.join(nil, family, group)
+	return err
+}
+
+// join joins a multicast group and returns the group's family. If dc is not
+// nil, it is used to resolve the family when the Monitor has no Resolver
+// other than its own Conn.
+func (m *Monitor) join(dc *Conn, family, group string) (Family, error) {
+	f, err := m.resolve(dc, family)
+	if err != nil {
+		return Family{}, err
+	}
+
+	for _, g := range f.Groups {
+		if g.Name != group {
+			continue
+		}
+
+		if err := m.c.JoinGroup(g.ID); err != nil {
+			return Family{}, err
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.groups[f.ID] = append(m.groups[f.ID], g)
+		m.joined[f.ID] = f.Name
+		return f, nil
+	}
+
+	return Family{}, fmt.Errorf("genetlink: family %q has no multicast group %q", family, group)
+}
+
+// resolve resolves a family using the Monitor's Resolver. The Monitor's own
+// Conn is never used to make the request, because Run may be receiving
+// using it concurrently, so dc or a separate Conn is used instead.
+func (m *Monitor) resolve(dc *Conn, family string) (Family, error) {
+	if c, ok := m.r.(*Conn); !ok || c != m.c {
+		return m.r.Resolve(family)
+	}
+
+	if dc != nil {
+		return m.c.getFamilyUsing(family, dc.getFamily)
+	}
+
+	return m.c.getFamilyUsing(family, func(name string) (Family, error) {
+		sc, err := m.c.sibling()
+		if err != nil {
+			return Family{}, fmt.Errorf("genetlink: monitor cannot resolve family %q without a separate Conn, see Monitor.SetResolver: %w",
+				name, err)
+		}
+		defer sc.Close()
+
+		return sc.getFamily(name)
+	})
+}
+
+// Run receives messages and delivers them as Events to each Subscription
+// and then to fn, until fn or the underlying Conn returns an error, which is
+// then returned by Run. fn may be nil if Events are only consumed through
+// Subscriptions. To stop Run, return an error from fn, or close the Conn.
+//
+// When Run returns, all Subscriptions are closed.
+func (m *Monitor) Run(fn func(e Event) error) (err error) {
+	defer func() {
+		m.mu.Lock()
+		subs := m.subs
+		m.subs = nil
+		m.mu.Unlock()
+
+		for _, s := range subs {
+			s.close(err)
+		}
+	}()
+
+	hc := m.startHealthCheck()
+	defer hc.stop()
+
+	deliver := func(e Event) error {
+		for _, s := range m.subscriptions() {
+			s.deliver(e)
+		}
+
+		if fn == nil {
+			return nil
+		}
+
+		return fn(e)
+	}
+
+	last := m.c.now()
+	for {
+		msgs, nmsgs, err := m.receive(hc.ctx)
+		if err != nil {
+			if herr := hc.err(); herr != nil {
+				return fmt.Errorf("genetlink: monitor health check failed: %w", herr)
+			}
+
+			if m.resync == nil || !isOverrun(err) {
+				return err
+			}
+
+			gap := &Gap{Start: last, Detected: m.c.now()}
+			if err := m.resync(); err != nil {
+				return fmt.Errorf("genetlink: monitor resync failed: %w", err)
+			}
+			gap.Resynced = m.c.now()
+			last = gap.Resynced
+
+			if err := deliver(Event{Gap: gap}); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		last = m.c.now()
+		for i := range msgs {
+			if err := deliver(m.event(nmsgs[i].Header, msgs[i])); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// receive receives messages using the Monitor's Conn. If ctx can be
+// canceled, the receive is performed with exclusive use of the socket so
+// that cancelation interrupts it without affecting any other operation.
+func (m *Monitor) receive(ctx context.Context) ([]Message, []netlink.Message, error) {
+	if ctx.Done() == nil {
+		return m.c.Receive()
+	}
+
+	var (
+		msgs  []Message
+		nmsgs []netlink.Message
+	)
+
+	err := m.c.exclusive(ctx, func() error {
+		var err error
+		msgs, nmsgs, err = received(m.c.c.Receive())
+		return err
+	})
+
+	return msgs, nmsgs, err
+}
+
+// A healthCheck runs a Monitor's health check periodically.
+type healthCheck struct {
+	// ctx is canceled when a check fails, in order to interrupt Run.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	stopC, done chan struct{}
+
+	mu   sync.Mutex
+	herr error
+}
+
+// startHealthCheck starts the Monitor's health check, if enabled.
+func (m *Monitor) startHealthCheck() *healthCheck {
+	hc := &healthCheck{
+		ctx:    context.Background(),
+		cancel: func() {},
+		stopC:  make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	if m.checkInterval <= 0 {
+		close(hc.done)
+		return hc
+	}
+
+	hc.ctx, hc.cancel = context.WithCancel(context.Background())
+
+	check := m.check
+	if check == nil {
+		check = m.ping
+	}
+
+	go func() {
+		defer close(hc.done)
+
+		t := m.c.clock().NewTicker(m.checkInterval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-hc.stopC:
+				return
+			case <-t.Chan():
+			}
+
+			if err := check(); err != nil {
+				hc.mu.Lock()
+				hc.herr = err
+				hc.mu.Unlock()
+
+				hc.cancel()
+				return
+			}
+		}
+	}()
+
+	return hc
+}
+
+// ping is the default health check, which pings the kernel using a separate
+// Conn.
+func (m *Monitor) ping() error {
+	pc, err := m.c.sibling()
+	if err != nil {
+		return fmt.Errorf("genetlink: monitor health check requires a separate Conn, see Monitor.SetHealthCheck: %w", err)
+	}
+	defer pc.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.checkInterval)
+	defer cancel()
+
+	return pc.Ping(ctx)
+}
+
+// err returns the error of a failed health check, if any.
+func (hc *healthCheck) err() error {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	return hc.herr
+}
+
+// stop stops the health check and waits for it to finish.
+func (hc *healthCheck) stop() {
+	close(hc.stopC)
+	<-hc.done
+	hc.cancel()
+}
+
+// event creates an Event from a received message.
+func (m *Monitor) event(h netlink.Header, msg Message) Event {
+	id := uint16(h.Type)
+	if id == ctrlID {
+		switch msg.Header.Command {
+		case ctrlCmdNewFamily, ctrlCmdDelFamily:
+			// Family IDs may have changed; resolve them again on demand.
+			m.invalidate(msg)
+		}
+	}
+
+	e := Event{
+		Command: msg.Header.Command,
+		Header:  h,
+		Message: msg,
+	}
+
+	e.Family = m.familyName(id)
+	if m.schemas != nil && e.Family != "" {
+		if i, ok := m.schemas.Describe(Family{ID: id, Name: e.Family}, msg); ok {
+			e.Inspection = &i
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if gs := m.groups[id]; len(gs) == 1 {
+		e.Group = gs[0].Name
+	}
+
+	return e
+}
+
+// A familyLister is a Resolver which can also list all families, such as a
+// Conn.
+type familyLister interface {
+	ListFamilies() ([]Family, error)
+}
+
+// familyName resolves a family ID to its name. The families of joined
+// groups are known, and all others are resolved using the cache, which is
+// populated if it is empty.
+func (m *Monitor) familyName(id uint16) string {
+	m.mu.Lock()
+	name, ok := m.joined[id]
+	if !ok && m.families != nil {
+		name, ok = m.families[id].Name, true
+	}
+	m.mu.Unlock()
+	if ok {
+		return name
+	}
+
+	// Populate the cache without holding the lock, as listing families
+	// performs a request.
+	l, ok := m.r.(familyLister)
+	if !ok {
+		return ""
+	}
+	if c, ok := l.(*Conn); ok && c == m.c {
+		return ""
+	}
+
+	fs, err := l.ListFamilies()
+	if err != nil {
+		return ""
+	}
+
+	families := make(map[uint16]Family, len(fs))
+	for _, f := range fs {
+		families[f.ID] = f
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.families == nil {
+		m.families = families
+	}
+
+	return families[id].Name
+}
+
+// invalidate clears the family cache when the generic netlink controller
+// message msg reports that a family was registered or unregistered, and
+// forgets the joined groups of any family whose ID is no longer valid.
+func (m *Monitor) invalidate(msg Message) {
+	id, name, ok := parseCtrlFamily(msg)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.families = nil
+
+	for jid, jname := range m.joined {
+		var stale bool
+		switch {
+		case !ok:
+			// The family is unknown, so any joined family may be stale.
+			stale = true
+		case msg.Header.Command == ctrlCmdDelFamily:
+			stale = jid == id
+		default:
+			// A family registered with an ID or name which was previously
+			// used by another family.
+			stale = (jid == id) != (jname == name)
+		}
+
+		if stale {
+			delete(m.joined, jid)
+			delete(m.groups, jid)
+		}
+	}
+}
+
+// parseCtrlFamily parses the ID and name of the family described by the
+// generic netlink controller message msg.
+func parseCtrlFamily(msg Message) (uint16, string, bool) {
+	ad, err := netlink.NewAttributeDecoder(msg.Data)
+	if err != nil {
+		return 0, "", false
+	}
+
+	var (
+		id   uint16
+		name string
+	)
+
+	for ad.Next() {
+		switch ad.Type() {
+		case ctrlAttrFamilyID:
+			id = ad.Uint16()
+		case ctrlAttrFamilyName:
+			name = ad.String()
+		}
+	}
+
+	if err := ad.Err(); err != nil || id == 0 || name == "" {
+		return 0, "", false
+	}
+
+	return id, name, true
+}
@@ -0,0 +1,415 @@
+//go:build linux
+// +build linux
+
+package genetlink_test
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"github.com/mdlayher/netlink/nltest"
+	"golang.org/x/sys/unix"
+)
+
+func TestMonitorRun(t *testing.T) {
+	event := func(family uint16, command uint8) genltest.Response {
+		return genltest.Response{
+			Header: netlink.Header{Type: netlink.HeaderType(family)},
+			Message: genetlink.Message{
+				Header: genetlink.Header{Command: command},
+				Data:   []byte{0xff},
+			},
+		}
+	}
+
+	multicast := genltest.Respond(func(_ genetlink.Message, nreq netlink.Message) ([]genltest.Response, error) {
+		if nreq.Header.Type != 0 {
+			// Not a multicast interaction.
+			return nil, nil
+		}
+
+		return []genltest.Response{
+			event(0x15, 1),
+			event(0x20, 3),
+			event(0x99, 2),
+		}, nil
+	})
+
+	c := genltest.Dial(genltest.ServeFamilies(genltest.Families(), multicast))
+	defer c.Close()
+
+	m := genetlink.NewMonitor(c)
+	for _, s := range [][2]string{
+		{"ethtool", "monitor"},
+		{"nl80211", "config"},
+		{"nl80211", "scan"},
+	} {
+		if err := m.Subscribe(s[0], s[1]); err != nil {
+			t.Fatalf("failed to subscribe to %s/%s: %v", s[0], s[1], err)
+		}
+	}
+
+	errStop := errors.New("stop")
+
+	var got []genetlink.Event
+	err := m.Run(func(e genetlink.Event) error {
+		e.Header, e.Message = netlink.Header{}, genetlink.Message{}
+		got = append(got, e)
+		if len(got) == 3 {
+			return errStop
+		}
+
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []genetlink.Event{
+		{Family: "ethtool", Group: "monitor", Command: 1},
+		// Multiple groups subscribed, so the group is ambiguous.
+		{Family: "nl80211", Command: 3},
+		// Unknown family.
+		{Command: 2},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected events (-want +got):\n%s", diff)
+	}
+}
+
+func TestMonitorFamilyNames(t *testing.T) {
+	multicast := genltest.Respond(func(_ genetlink.Message, nreq netlink.Message) ([]genltest.Response, error) {
+		if nreq.Header.Type != 0 {
+			// Not a multicast interaction.
+			return nil, nil
+		}
+
+		// An event from a family whose groups were not joined.
+		return []genltest.Response{{
+			Header: netlink.Header{Type: 0x15},
+			Message: genetlink.Message{
+				Header: genetlink.Header{Command: 1},
+			},
+		}}, nil
+	})
+
+	tests := []struct {
+		name     string
+		resolver bool
+		family   string
+	}{
+		{name: "default"},
+		{name: "resolver", resolver: true, family: "ethtool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dumps int
+			c := genltest.Dial(func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+				if nreq.Header.Flags&netlink.Dump != 0 {
+					dumps++
+				}
+
+				return genltest.ServeFamilies(genltest.Families(), multicast)(greq, nreq)
+			})
+			defer c.Close()
+
+			m := genetlink.NewMonitor(c)
+			if tt.resolver {
+				r := genltest.Dial(genltest.ServeFamilies(genltest.Families(), multicast))
+				defer r.Close()
+
+				m.SetResolver(r)
+			}
+
+			errStop := errors.New("stop")
+
+			var got genetlink.Event
+			err := m.Run(func(e genetlink.Event) error {
+				got = e
+				return errStop
+			})
+			if !errors.Is(err, errStop) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.Family != tt.family {
+				t.Fatalf("unexpected family: %q", got.Family)
+			}
+
+			// The Monitor's Conn must only receive multicast messages.
+			if dumps != 0 {
+				t.Fatalf("unexpected dumps on the monitor's Conn: %d", dumps)
+			}
+		})
+	}
+}
+
+func TestMonitorFamilyUnregistered(t *testing.T) {
+	ae := netlink.NewAttributeEncoder()
+	ae.Uint16(1, 0x15)      // CTRL_ATTR_FAMILY_ID
+	ae.String(2, "ethtool") // CTRL_ATTR_FAMILY_NAME
+	b, err := ae.Encode()
+	if err != nil {
+		t.Fatalf("failed to encode attributes: %v", err)
+	}
+
+	multicast := genltest.Respond(func(_ genetlink.Message, nreq netlink.Message) ([]genltest.Response, error) {
+		if nreq.Header.Type != 0 {
+			// Not a multicast interaction.
+			return nil, nil
+		}
+
+		return []genltest.Response{
+			{
+				Header: netlink.Header{Type: 0x15},
+				Message: genetlink.Message{
+					Header: genetlink.Header{Command: 1},
+				},
+			},
+			{
+				// CTRL_CMD_DELFAMILY.
+				Header: netlink.Header{Type: 0x10},
+				Message: genetlink.Message{
+					Header: genetlink.Header{Command: 2},
+					Data:   b,
+				},
+			},
+			{
+				Header: netlink.Header{Type: 0x15},
+				Message: genetlink.Message{
+					Header: genetlink.Header{Command: 1},
+				},
+			},
+		}, nil
+	})
+
+	c := genltest.Dial(genltest.ServeFamilies(genltest.Families(), multicast))
+	defer c.Close()
+
+	m := genetlink.NewMonitor(c)
+	if err := m.Subscribe("ethtool", "monitor"); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	errStop := errors.New("stop")
+
+	var got []genetlink.Event
+	err = m.Run(func(e genetlink.Event) error {
+		e.Header, e.Message = netlink.Header{}, genetlink.Message{}
+		got = append(got, e)
+		if len(got) == 3 {
+			return errStop
+		}
+
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []genetlink.Event{
+		{Family: "ethtool", Group: "monitor", Command: 1},
+		{Command: 2},
+		// The family's ID is no longer valid once it is unregistered.
+		{Command: 1},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected events (-want +got):\n%s", diff)
+	}
+}
+
+func TestMonitorSubscribeUnknownGroup(t *testing.T) {
+	noop := func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, nil
+	}
+
+	c := genltest.Dial(genltest.ServeFamilies(genltest.Families(), noop))
+	defer c.Close()
+
+	if err := genetlink.NewMonitor(c).Subscribe("nl80211", "foo"); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+}
+
+func TestMonitorSchemas(t *testing.T) {
+	multicast := genltest.Respond(func(_ genetlink.Message, nreq netlink.Message) ([]genltest.Response, error) {
+		if nreq.Header.Type != 0 {
+			// Not a multicast interaction.
+			return nil, nil
+		}
+
+		return []genltest.Response{{
+			Header: netlink.Header{Type: 0x15},
+			Message: genetlink.Message{
+				Header: genetlink.Header{Command: 1, Version: 1},
+				Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+					{Type: 1, Data: nlenc.Uint32Bytes(2)},
+				}),
+			},
+		}}, nil
+	})
+
+	c := genltest.Dial(genltest.ServeFamilies(genltest.Families(), multicast))
+	defer c.Close()
+
+	r := genetlink.NewSchemaRegistry()
+	r.Register("ethtool", genetlink.Schema{
+		Commands: map[uint8]genetlink.CommandSchema{
+			1: {Name: "strset_get"},
+		},
+		Attributes: genetlink.AttributeSet{
+			1: {Name: "header", Kind: genetlink.AttributeU32},
+		},
+	})
+
+	m := genetlink.NewMonitor(c)
+	m.SetSchemas(r)
+	if err := m.Subscribe("ethtool", "monitor"); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	errStop := errors.New("stop")
+
+	var got *genetlink.Inspection
+	err := m.Run(func(e genetlink.Event) error {
+		got = e.Inspection
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil {
+		t.Fatal("expected an inspection, but none was set")
+	}
+
+	want := `ethtool (0x15): command 1 strset_get, version 1
+  1 header (u32): 2
+`
+
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Fatalf("unexpected inspection (-want +got):\n%s", diff)
+	}
+}
+
+func TestMonitorHealthCheck(t *testing.T) {
+	// No messages arrive until the gate is released.
+	g := genltest.NewGate()
+	c := genltest.Dial(g.Block(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, nil
+	}))
+	defer c.Close()
+
+	errCheck := errors.New("socket is dead")
+
+	var checks int
+	m := genetlink.NewMonitor(c)
+	m.SetHealthCheck(time.Millisecond, func() error {
+		checks++
+		if checks == 3 {
+			return errCheck
+		}
+
+		return nil
+	})
+
+	s := m.NewSubscription(nil)
+
+	err := m.Run(nil)
+	if !errors.Is(err, errCheck) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := <-s.Errors(); !errors.Is(err, errCheck) {
+		t.Fatalf("unexpected subscription error: %v", err)
+	}
+
+	if _, ok := <-s.Events(); ok {
+		t.Fatal("expected subscription to be closed")
+	}
+
+	// The deadline used to interrupt Run no longer applies, so a receive
+	// blocks until a message arrives.
+	errC := make(chan error, 1)
+	go func() {
+		_, _, err := c.Receive()
+		errC <- err
+	}()
+
+	select {
+	case err := <-errC:
+		t.Fatalf("receive did not block after health check: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	g.Release()
+	if err := <-errC; err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+}
+
+func TestMonitorHealthCheckDefault(t *testing.T) {
+	// No messages ever arrive, and the controller cannot be reached, so the
+	// default health check, which pings it, fails.
+	g := genltest.NewGate()
+	none := g.Block(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, nil
+	})
+
+	c := genltest.Dial(func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+		if nreq.Header.Type == unix.GENL_ID_CTRL {
+			return nil, genltest.ErrorPermission()
+		}
+
+		return none(greq, nreq)
+	})
+	defer c.Close()
+
+	m := genetlink.NewMonitor(c)
+	m.SetHealthCheck(time.Millisecond, nil)
+
+	if err := m.Run(nil); !errors.Is(err, os.ErrPermission) {
+		t.Fatalf("expected permission denied error, but got: %v", err)
+	}
+}
+
+func TestMonitorHealthCheckClock(t *testing.T) {
+	// No messages ever arrive.
+	g := genltest.NewGate()
+	c := genltest.Dial(g.Block(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, nil
+	}))
+	defer c.Close()
+
+	clk := genltest.NewClock(time.Unix(0, 0))
+	c.SetClock(clk)
+
+	errCheck := errors.New("socket is dead")
+
+	m := genetlink.NewMonitor(c)
+	m.SetHealthCheck(time.Hour, func() error { return errCheck })
+
+	errC := make(chan error, 1)
+	go func() { errC <- m.Run(nil) }()
+
+	// Advance the clock by a full interval once the health check starts, so
+	// no real time elapses.
+	for clk.Tickers() == 0 {
+		runtime.Gosched()
+	}
+	clk.Advance(time.Hour)
+
+	if err := <-errC; !errors.Is(err, errCheck) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package genetlink
+
+import (
+	"sync"
+
+	"github.com/mdlayher/netlink"
+)
+
+// A Handler handles a generic netlink message routed to it by a Mux.
+type Handler func(h netlink.Header, m Message) error
+
+// A Mux routes generic netlink messages received by a single Conn to
+// Handlers registered by family ID and, optionally, command. This allows one
+// socket to serve multicast messages from several families at once.
+//
+// The zero value of a Mux is ready to use.
+type Mux struct {
+	mu       sync.RWMutex
+	handlers map[muxKey]Handler
+}
+
+// A muxKey identifies the Handler for a family and command.
+type muxKey struct {
+	family  uint16
+	command uint8
+	any     bool
+}
+
+// Handle registers h to handle messages of the specified family ID and
+// command, replacing any existing Handler for them. A nil Handler removes
+// the registration.
+func (m *Mux) Handle(family uint16, command uint8, h Handler) {
+	m.handle(muxKey{family: family, command: command}, h)
+}
+
+// HandleFamily registers h to handle messages of the specified family ID for
+// which no command-specific Handler is registered, replacing any existing
+// Handler for the family. A nil Handler removes the registration.
+func (m *Mux) HandleFamily(family uint16, h Handler) {
+	m.handle(muxKey{family: family, any: true}, h)
+}
+
+func (m *Mux) handle(k muxKey, h Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.handlers == nil {
+		m.handlers = make(map[muxKey]Handler)
+	}
+
+	if h == nil {
+		delete(m.handlers, k)
+		return
+	}
+
+	m.handlers[k] = h
+}
+
+// Dispatch passes a message to the Handler registered for its family and
+// command, or for its family if none is registered for the command. Dispatch
+// reports whether a Handler was found, and returns the Handler's error.
+func (m *Mux) Dispatch(h netlink.Header, msg Message) (bool, error) {
+	k := muxKey{family: uint16(h.Type), command: msg.Header.Command}
+
+	m.mu.RLock()
+	fn, ok := m.handlers[k]
+	if !ok {
+		fn, ok = m.handlers[muxKey{family: k.family, any: true}]
+	}
+	m.mu.RUnlock()
+
+	if !ok {
+		return false, nil
+	}
+
+	return true, fn(h, msg)
+}
+
+// Serve receives messages from c and dispatches them to the registered
+// Handlers until c or a Handler returns an error, which is then returned by
+// Serve. Messages with no registered Handler are discarded.
+//
+// Serve owns the receive loop of c: the caller must not call Receive on c
+// while Serve is running. To stop Serve, close c or return an error from a
+// Handler.
+func (m *Mux) Serve(c *Conn) error {
+	for {
+		msgs, nmsgs, err := c.Receive()
+		if err != nil {
+			return err
+		}
+
+		for i := range msgs {
+			if _, err := m.Dispatch(nmsgs[i].Header, msgs[i]); err != nil {
+				return err
+			}
+		}
+	}
+}
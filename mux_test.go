@@ -0,0 +1,82 @@
+package genetlink_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestMuxServe(t *testing.T) {
+	event := func(family uint16, command uint8) genltest.Response {
+		return genltest.Response{
+			Header: netlink.Header{Type: netlink.HeaderType(family)},
+			Message: genetlink.Message{
+				Header: genetlink.Header{Command: command},
+			},
+		}
+	}
+
+	c := genltest.Dial(genltest.Respond(func(_ genetlink.Message, _ netlink.Message) ([]genltest.Response, error) {
+		return []genltest.Response{
+			event(0x20, 3),
+			event(0x20, 5),
+			event(0x99, 1),
+			event(0x10, 1),
+			event(0x15, 1),
+		}, nil
+	}))
+	defer c.Close()
+
+	var got []string
+	handler := func(name string) genetlink.Handler {
+		return func(_ netlink.Header, _ genetlink.Message) error {
+			got = append(got, name)
+			return nil
+		}
+	}
+
+	var m genetlink.Mux
+	m.HandleFamily(0x20, handler("nl80211"))
+	m.Handle(0x20, 5, handler("nl80211/5"))
+	m.Handle(0x10, 1, handler("nlctrl/1"))
+
+	errStop := errors.New("stop")
+	m.Handle(0x15, 1, func(_ netlink.Header, _ genetlink.Message) error {
+		return errStop
+	})
+
+	if err := m.Serve(c); !errors.Is(err, errStop) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"nl80211", "nl80211/5", "nlctrl/1"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected handlers (-want +got):\n%s", diff)
+	}
+}
+
+func TestMuxDispatch(t *testing.T) {
+	var m genetlink.Mux
+
+	h := netlink.Header{Type: 0x20}
+	if ok, err := m.Dispatch(h, genetlink.Message{}); ok || err != nil {
+		t.Fatalf("expected no handler, but got: %v, %v", ok, err)
+	}
+
+	m.HandleFamily(0x20, func(_ netlink.Header, _ genetlink.Message) error {
+		return nil
+	})
+	if ok, err := m.Dispatch(h, genetlink.Message{}); !ok || err != nil {
+		t.Fatalf("expected handler, but got: %v, %v", ok, err)
+	}
+
+	// Removing the handler restores the original behavior.
+	m.HandleFamily(0x20, nil)
+	if ok, _ := m.Dispatch(h, genetlink.Message{}); ok {
+		t.Fatal("expected handler to be removed")
+	}
+}
@@ -0,0 +1,220 @@
+// Package nlmon decodes generic netlink messages from packet captures taken
+// on a Linux nlmon interface.
+//
+// Captures must use the classic pcap file format (not pcapng) with the
+// LINKTYPE_NETLINK link type, as produced by tools such as tcpdump:
+//
+//	$ ip link add nlmon0 type nlmon
+//	$ ip link set nlmon0 up
+//	$ tcpdump -i nlmon0 -w genetlink.pcap
+//
+// Netlink messages are encoded using the byte order of the host which
+// captured them, so captures must be decoded on a host of the same byte order.
+package nlmon
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/internal/nlmsg"
+	"github.com/mdlayher/netlink"
+)
+
+// Constants used when parsing pcap files.
+const (
+	// pcap magic numbers for microsecond and nanosecond resolution files.
+	magicMicros = 0xa1b2c3d4
+	magicNanos  = 0xa1b23c4d
+
+	// linkTypeNetlink is LINKTYPE_NETLINK.
+	linkTypeNetlink = 253
+
+	// Sizes of the various pcap and Linux cooked capture headers.
+	fileHeaderLen   = 24
+	recordHeaderLen = 16
+	cookedHeaderLen = 16
+
+	// maxRecordLen bounds the size of a single captured packet.
+	maxRecordLen = 256 * 1024
+)
+
+// errNotNetlink is returned when a pcap file does not contain netlink traffic.
+var errNotNetlink = errors.New("nlmon: pcap file does not use LINKTYPE_NETLINK")
+
+// A Message is a generic netlink message decoded from a packet capture.
+type Message struct {
+	// Time is the time at which the message was captured.
+	Time time.Time
+
+	// Family is the generic netlink family of the message. If the family ID
+	// could not be resolved, only the Family's ID field is set.
+	Family genetlink.Family
+
+	// Header is the netlink header which wrapped Message.
+	Header netlink.Header
+
+	// Message is the decoded generic netlink message.
+	Message genetlink.Message
+}
+
+// A Reader reads generic netlink messages from a pcap file.
+type Reader struct {
+	r        io.Reader
+	order    binary.ByteOrder
+	nanos    bool
+	families map[uint16]genetlink.Family
+
+	// Messages and capture time of the current record.
+	pending []netlink.Message
+	time    time.Time
+}
+
+// NewReader creates a Reader which reads a pcap file from r. Generic netlink
+// family IDs are resolved using families, which typically originate from
+// genetlink.Conn.ListFamilies on the host which performed the capture.
+func NewReader(r io.Reader, families []genetlink.Family) (*Reader, error) {
+	b := make([]byte, fileHeaderLen)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, fmt.Errorf("nlmon: failed to read pcap file header: %w", err)
+	}
+
+	rd := &Reader{
+		r:        r,
+		families: make(map[uint16]genetlink.Family, len(families)),
+	}
+
+	// Detect the byte order and timestamp resolution using the magic number.
+	switch {
+	case binary.LittleEndian.Uint32(b[0:4]) == magicMicros:
+		rd.order = binary.LittleEndian
+	case binary.BigEndian.Uint32(b[0:4]) == magicMicros:
+		rd.order = binary.BigEndian
+	case binary.LittleEndian.Uint32(b[0:4]) == magicNanos:
+		rd.order, rd.nanos = binary.LittleEndian, true
+	case binary.BigEndian.Uint32(b[0:4]) == magicNanos:
+		rd.order, rd.nanos = binary.BigEndian, true
+	default:
+		return nil, fmt.Errorf("nlmon: unrecognized pcap magic number: %#x", b[0:4])
+	}
+
+	if lt := rd.order.Uint32(b[20:24]) & 0x0fffffff; lt != linkTypeNetlink {
+		return nil, errNotNetlink
+	}
+
+	for _, f := range families {
+		rd.families[f.ID] = f
+	}
+
+	return rd, nil
+}
+
+// Next returns the next generic netlink message in the capture. Packets for
+// other netlink protocols and netlink control messages are skipped. Next
+// returns io.EOF when no more messages remain.
+func (r *Reader) Next() (Message, error) {
+	for len(r.pending) == 0 {
+		if err := r.readRecord(); err != nil {
+			return Message{}, err
+		}
+	}
+
+	nm := r.pending[0]
+	r.pending = r.pending[1:]
+
+	var gm genetlink.Message
+	if err := gm.UnmarshalBinary(nm.Data); err != nil {
+		return Message{}, fmt.Errorf("nlmon: failed to decode generic netlink message: %w", err)
+	}
+
+	f, ok := r.families[uint16(nm.Header.Type)]
+	if !ok {
+		f = genetlink.Family{ID: uint16(nm.Header.Type)}
+	}
+
+	return Message{
+		Time:    r.time,
+		Family:  f,
+		Header:  nm.Header,
+		Message: gm,
+	}, nil
+}
+
+// readRecord reads the next pcap record and stores any generic netlink
+// messages it contains.
+func (r *Reader) readRecord() error {
+	h := make([]byte, recordHeaderLen)
+	if _, err := io.ReadFull(r.r, h); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return fmt.Errorf("nlmon: truncated pcap record header: %w", err)
+		}
+
+		// Clean io.EOF at a record boundary.
+		return err
+	}
+
+	var (
+		sec  = r.order.Uint32(h[0:4])
+		frac = r.order.Uint32(h[4:8])
+		n    = r.order.Uint32(h[8:12])
+	)
+
+	if n > maxRecordLen {
+		return fmt.Errorf("nlmon: pcap record length %d exceeds maximum of %d", n, maxRecordLen)
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		return fmt.Errorf("nlmon: truncated pcap record: %w", err)
+	}
+
+	if !r.nanos {
+		frac *= 1000
+	}
+	r.time = time.Unix(int64(sec), int64(frac))
+
+	if len(b) < cookedHeaderLen {
+		return fmt.Errorf("nlmon: pcap record too short for cooked header: %d bytes", len(b))
+	}
+
+	// The cooked capture header carries the netlink protocol in network byte
+	// order. Skip all traffic other than generic netlink.
+	if binary.BigEndian.Uint16(b[14:16]) != genetlink.Protocol {
+		return nil
+	}
+
+	msgs, err := parseMessages(b[cookedHeaderLen:])
+	if err != nil {
+		return err
+	}
+
+	r.pending = msgs
+	return nil
+}
+
+// parseMessages parses one or more netlink messages from b, skipping netlink
+// control messages which do not carry a generic netlink header.
+func parseMessages(b []byte) ([]netlink.Message, error) {
+	all, err := nlmsg.Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("nlmon: failed to decode netlink messages: %w", err)
+	}
+
+	msgs := make([]netlink.Message, 0, len(all))
+	for _, m := range all {
+		if m.Header.Type < netlinkMinType {
+			continue
+		}
+
+		msgs = append(msgs, m)
+	}
+
+	return msgs, nil
+}
+
+// netlinkMinType is the first netlink header type which is not reserved for
+// netlink control messages (unix.NLMSG_MIN_TYPE).
+const netlinkMinType = 0x10
@@ -0,0 +1,226 @@
+package nlmon_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/nlmon"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+)
+
+func TestReader(t *testing.T) {
+	var (
+		foo = genetlink.Family{
+			ID:      0x20,
+			Version: 1,
+			Name:    "foo",
+		}
+
+		t0 = time.Unix(1, 2000)
+		t1 = time.Unix(3, 4000)
+	)
+
+	b := pcapFile(t, []record{
+		{
+			time:     t0,
+			protocol: genetlink.Protocol,
+			msgs: []netlink.Message{
+				genlMessage(t, 0x20, genetlink.Message{
+					Header: genetlink.Header{Command: 1, Version: 1},
+					Data:   []byte{0xff},
+				}),
+				genlMessage(t, 0x21, genetlink.Message{
+					Header: genetlink.Header{Command: 2, Version: 1},
+				}),
+			},
+		},
+		{
+			// Not generic netlink, skipped.
+			time:     t1,
+			protocol: 0, // unix.NETLINK_ROUTE
+			msgs: []netlink.Message{
+				genlMessage(t, 0x20, genetlink.Message{}),
+			},
+		},
+		{
+			time:     t1,
+			protocol: genetlink.Protocol,
+			msgs: []netlink.Message{
+				// Control message, skipped.
+				{
+					Header: netlink.Header{Type: netlink.Done},
+					Data:   make([]byte, 4),
+				},
+				genlMessage(t, 0x20, genetlink.Message{
+					Header: genetlink.Header{Command: 3, Version: 1},
+				}),
+			},
+		},
+	})
+
+	r, err := nlmon.NewReader(bytes.NewReader(b), []genetlink.Family{foo})
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+
+	var got []nlmon.Message
+	for {
+		m, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read message: %v", err)
+		}
+
+		got = append(got, m)
+	}
+
+	want := []nlmon.Message{
+		{
+			Time:   t0,
+			Family: foo,
+			Header: netlink.Header{Length: 21, Type: 0x20},
+			Message: genetlink.Message{
+				Header: genetlink.Header{Command: 1, Version: 1},
+				Data:   []byte{0xff},
+			},
+		},
+		{
+			Time:   t0,
+			Family: genetlink.Family{ID: 0x21},
+			Header: netlink.Header{Length: 20, Type: 0x21},
+			Message: genetlink.Message{
+				Header: genetlink.Header{Command: 2, Version: 1},
+				Data:   []byte{},
+			},
+		},
+		{
+			Time:   t1,
+			Family: foo,
+			Header: netlink.Header{Length: 20, Type: 0x20},
+			Message: genetlink.Message{
+				Header: genetlink.Header{Command: 3, Version: 1},
+				Data:   []byte{},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected messages (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewReaderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		b    []byte
+	}{
+		{
+			name: "short",
+			b:    []byte{0xd4, 0xc3, 0xb2, 0xa1},
+		},
+		{
+			name: "bad magic",
+			b:    make([]byte, 24),
+		},
+		{
+			name: "not netlink",
+			b: func() []byte {
+				b := pcapFile(t, nil)
+				// LINKTYPE_ETHERNET.
+				binary.LittleEndian.PutUint32(b[20:24], 1)
+				return b
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := nlmon.NewReader(bytes.NewReader(tt.b), nil); err == nil {
+				t.Fatal("expected an error, but none occurred")
+			}
+		})
+	}
+}
+
+type record struct {
+	time     time.Time
+	protocol uint16
+	msgs     []netlink.Message
+}
+
+// pcapFile builds a little endian, microsecond resolution pcap file containing
+// the input records.
+func pcapFile(t *testing.T, records []record) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+	le := binary.LittleEndian
+
+	fh := make([]byte, 24)
+	le.PutUint32(fh[0:4], 0xa1b2c3d4)
+	le.PutUint16(fh[4:6], 2)
+	le.PutUint16(fh[6:8], 4)
+	le.PutUint32(fh[16:20], 65535)
+	le.PutUint32(fh[20:24], 253)
+	b.Write(fh)
+
+	for _, r := range records {
+		// Linux cooked capture header with ARPHRD_NETLINK and the protocol.
+		pkt := make([]byte, 16)
+		binary.BigEndian.PutUint16(pkt[2:4], 824)
+		binary.BigEndian.PutUint16(pkt[14:16], r.protocol)
+
+		for _, m := range r.msgs {
+			// Encode the header manually as netlink.Message.MarshalBinary does
+			// not permit unaligned lengths, which the kernel does produce.
+			l := m.Header.Length
+			if l == 0 {
+				l = uint32(16 + len(m.Data))
+			}
+
+			pkt = append(pkt, nlenc.Uint32Bytes(l)...)
+			pkt = append(pkt, nlenc.Uint16Bytes(uint16(m.Header.Type))...)
+			pkt = append(pkt, nlenc.Uint16Bytes(uint16(m.Header.Flags))...)
+			pkt = append(pkt, nlenc.Uint32Bytes(m.Header.Sequence)...)
+			pkt = append(pkt, nlenc.Uint32Bytes(m.Header.PID)...)
+			pkt = append(pkt, m.Data...)
+			pkt = append(pkt, make([]byte, (4-len(m.Data)%4)%4)...)
+		}
+
+		rh := make([]byte, 16)
+		le.PutUint32(rh[0:4], uint32(r.time.Unix()))
+		le.PutUint32(rh[4:8], uint32(r.time.Nanosecond()/1000))
+		le.PutUint32(rh[8:12], uint32(len(pkt)))
+		le.PutUint32(rh[12:16], uint32(len(pkt)))
+		b.Write(rh)
+		b.Write(pkt)
+	}
+
+	return b.Bytes()
+}
+
+// genlMessage wraps a generic netlink message in a netlink message.
+func genlMessage(t *testing.T, family uint16, m genetlink.Message) netlink.Message {
+	t.Helper()
+
+	b, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to marshal generic netlink message: %v", err)
+	}
+
+	return netlink.Message{
+		Header: netlink.Header{
+			Length: uint32(16 + len(b)),
+			Type:   netlink.HeaderType(family),
+		},
+		Data: b,
+	}
+}
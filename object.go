@@ -0,0 +1,46 @@
+package genetlink
+
+// An Object is a single logical object which a family sends in a dump,
+// along with the Messages which describe it. Several families split one
+// object across multiple messages, such as nl80211 when it describes a
+// wireless device with split dumps.
+type Object[K comparable] struct {
+	// Key identifies the object, such as its interface index.
+	Key K
+
+	// Messages are the Messages which describe the object, in the order
+	// they were received.
+	Messages []Message
+}
+
+// GroupObjects groups msgs, such as the replies of Conn.Dump, into Objects
+// using the key returned by key for each Message. Objects are returned in
+// the order in which their first Message appears in msgs. Messages with the
+// same key are grouped into one Object even if other Messages appear between
+// them.
+//
+// If key returns an error, GroupObjects stops and returns that error.
+func GroupObjects[K comparable](msgs []Message, key func(m Message) (K, error)) ([]Object[K], error) {
+	var (
+		objs []Object[K]
+		idx  = make(map[K]int)
+	)
+
+	for _, m := range msgs {
+		k, err := key(m)
+		if err != nil {
+			return nil, err
+		}
+
+		i, ok := idx[k]
+		if !ok {
+			i = len(objs)
+			idx[k] = i
+			objs = append(objs, Object[K]{Key: k})
+		}
+
+		objs[i].Messages = append(objs[i].Messages, m)
+	}
+
+	return objs, nil
+}
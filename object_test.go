@@ -0,0 +1,60 @@
+package genetlink_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"github.com/mdlayher/netlink/nltest"
+)
+
+func TestGroupObjects(t *testing.T) {
+	const attrIndex = 1
+
+	msg := func(index uint32, data byte) genetlink.Message {
+		return genetlink.Message{
+			Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+				{Type: attrIndex, Data: nlenc.Uint32Bytes(index)},
+				{Type: 2, Data: []byte{data}},
+			}),
+		}
+	}
+
+	key := func(m genetlink.Message) (uint32, error) {
+		index, ok := m.Attributes().Uint32(attrIndex)
+		if !ok {
+			return 0, errors.New("no index")
+		}
+
+		return index, nil
+	}
+
+	msgs := []genetlink.Message{
+		msg(2, 0),
+		msg(2, 1),
+		msg(1, 0),
+		// Not consecutive with its object's first message.
+		msg(2, 2),
+	}
+
+	objs, err := genetlink.GroupObjects(msgs, key)
+	if err != nil {
+		t.Fatalf("failed to group objects: %v", err)
+	}
+
+	want := []genetlink.Object[uint32]{
+		{Key: 2, Messages: []genetlink.Message{msgs[0], msgs[1], msgs[3]}},
+		{Key: 1, Messages: []genetlink.Message{msgs[2]}},
+	}
+
+	if diff := cmp.Diff(want, objs); diff != "" {
+		t.Fatalf("unexpected objects (-want +got):\n%s", diff)
+	}
+
+	if _, err := genetlink.GroupObjects([]genetlink.Message{{}}, key); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+}
@@ -0,0 +1,149 @@
+package genetlink
+
+import (
+	"context"
+	"sync"
+)
+
+// ProcessOrdered receives Events from events, such as those of a
+// Subscription, and processes them concurrently using fn in a pool of
+// workers, while preserving the order of Events with the same key, such as
+// an interface index returned by key. Naive fan-out either serializes every
+// Event or may reorder updates to the same object; ProcessOrdered does
+// neither.
+//
+// An Event whose key has no Events in progress is assigned to the next
+// worker in turn, and subsequent Events with the same key are assigned to
+// the same worker until all of them have been processed. If workers is less
+// than 1, one worker is used.
+//
+// ProcessOrdered returns nil when events is closed and every Event has been
+// processed. If fn returns an error or ctx is canceled, ProcessOrdered stops
+// receiving Events, waits for the Events in progress, and returns the error.
+func ProcessOrdered[K comparable](ctx context.Context, events <-chan Event, workers int,
+	key func(e Event) K, fn func(e Event) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	p := &orderedPool[K]{
+		active: make(map[K]*orderedKey),
+		queues: make([]chan orderedEvent[K], workers),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := range p.queues {
+		p.queues[i] = make(chan orderedEvent[K], 1)
+
+		go func(q <-chan orderedEvent[K]) {
+			defer wg.Done()
+			p.work(ctx, cancel, q, fn)
+		}(p.queues[i])
+	}
+
+	err := p.dispatch(ctx, events, key)
+
+	for _, q := range p.queues {
+		close(q)
+	}
+	wg.Wait()
+
+	if p.err != nil {
+		// An error from fn caused the context to be canceled, so report the
+		// error rather than the cancelation.
+		return p.err
+	}
+
+	return err
+}
+
+// An orderedPool assigns Events to workers for ProcessOrdered.
+type orderedPool[K comparable] struct {
+	queues []chan orderedEvent[K]
+	next   int
+
+	mu     sync.Mutex
+	active map[K]*orderedKey
+	err    error
+}
+
+// An orderedKey tracks the worker and the number of Events in progress for
+// a key.
+type orderedKey struct {
+	worker, n int
+}
+
+// An orderedEvent is an Event and its key.
+type orderedEvent[K comparable] struct {
+	key K
+	e   Event
+}
+
+// dispatch assigns Events to workers until events is closed or ctx is
+// canceled.
+func (p *orderedPool[K]) dispatch(ctx context.Context, events <-chan Event, key func(e Event) K) error {
+	for {
+		var (
+			e  Event
+			ok bool
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok = <-events:
+			if !ok {
+				return nil
+			}
+		}
+
+		k := key(e)
+
+		p.mu.Lock()
+		ak, ok := p.active[k]
+		if !ok {
+			ak = &orderedKey{worker: p.next}
+			p.active[k] = ak
+			p.next = (p.next + 1) % len(p.queues)
+		}
+		ak.n++
+		w := ak.worker
+		p.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case p.queues[w] <- orderedEvent[K]{key: k, e: e}:
+		}
+	}
+}
+
+// work processes the Events in q using fn. If fn returns an error, the error
+// is recorded and cancel is invoked to stop the pool.
+func (p *orderedPool[K]) work(ctx context.Context, cancel func(), q <-chan orderedEvent[K], fn func(e Event) error) {
+	for oe := range q {
+		if ctx.Err() != nil {
+			// Discard the remaining Events after an error.
+			continue
+		}
+
+		err := fn(oe.e)
+
+		p.mu.Lock()
+		if ak := p.active[oe.key]; ak != nil {
+			ak.n--
+			if ak.n == 0 {
+				delete(p.active, oe.key)
+			}
+		}
+		if err != nil && p.err == nil {
+			p.err = err
+			cancel()
+		}
+		p.mu.Unlock()
+	}
+}
@@ -0,0 +1,137 @@
+package genetlink_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+)
+
+func TestProcessOrdered(t *testing.T) {
+	const (
+		keys   = 8
+		perKey = 50
+	)
+
+	events := make(chan genetlink.Event)
+	go func() {
+		defer close(events)
+		for i := 0; i < perKey; i++ {
+			for k := 0; k < keys; k++ {
+				events <- genetlink.Event{
+					Family:  "foo",
+					Command: uint8(k),
+					Message: genetlink.Message{Data: []byte{byte(i)}},
+				}
+			}
+		}
+	}()
+
+	var (
+		mu  sync.Mutex
+		got = make(map[uint8][]byte)
+	)
+
+	err := genetlink.ProcessOrdered(context.Background(), events, 4,
+		func(e genetlink.Event) uint8 { return e.Command },
+		func(e genetlink.Event) error {
+			if e.Command%2 == 0 {
+				// Slow down some keys so that others overtake them.
+				time.Sleep(100 * time.Microsecond)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			got[e.Command] = append(got[e.Command], e.Message.Data[0])
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("failed to process events: %v", err)
+	}
+
+	want := make([]byte, perKey)
+	for i := range want {
+		want[i] = byte(i)
+	}
+
+	for k := uint8(0); k < keys; k++ {
+		if diff := cmp.Diff(want, got[k]); diff != "" {
+			t.Fatalf("unexpected order for key %d (-want +got):\n%s", k, diff)
+		}
+	}
+}
+
+func TestProcessOrderedConcurrent(t *testing.T) {
+	events := make(chan genetlink.Event, 2)
+	events <- genetlink.Event{Command: 1}
+	events <- genetlink.Event{Command: 2}
+	close(events)
+
+	// Each event waits for the other to begin, so the events can only be
+	// processed if they are processed concurrently.
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	err := genetlink.ProcessOrdered(context.Background(), events, 2,
+		func(e genetlink.Event) uint8 { return e.Command },
+		func(_ genetlink.Event) error {
+			wg.Done()
+			wg.Wait()
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("failed to process events: %v", err)
+	}
+}
+
+func TestProcessOrderedError(t *testing.T) {
+	var (
+		events = make(chan genetlink.Event)
+		done   = make(chan struct{})
+	)
+	defer close(done)
+
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case events <- genetlink.Event{Command: uint8(i)}:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	errFoo := errors.New("foo")
+	err := genetlink.ProcessOrdered(context.Background(), events, 4,
+		func(e genetlink.Event) uint8 { return e.Command },
+		func(e genetlink.Event) error {
+			if e.Command == 10 {
+				return errFoo
+			}
+
+			return nil
+		},
+	)
+	if !errors.Is(err, errFoo) {
+		t.Fatalf("expected foo error, but got: %v", err)
+	}
+}
+
+func TestProcessOrderedContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := genetlink.ProcessOrdered(ctx, make(chan genetlink.Event), 1,
+		func(e genetlink.Event) uint8 { return e.Command },
+		func(_ genetlink.Event) error { return nil },
+	)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled error, but got: %v", err)
+	}
+}
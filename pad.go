@@ -0,0 +1,62 @@
+package genetlink
+
+import (
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+)
+
+// AttributeOffset is the offset within a netlink message of the first
+// attribute of a Message, for families without a family-specific header.
+// Families with such a header begin their attributes after it, aligned to
+// 4 bytes.
+const AttributeOffset = nlmsgHeaderLen + headerLen
+
+// AppendUint64 appends an attribute of type typ with the 64-bit value v in
+// native byte order to attrs, which begin at offset within a netlink
+// message, such as AttributeOffset.
+//
+// Following the kernel's nla_put_u64_64bit convention, if the value would
+// not be aligned to 8 bytes, it is preceded by an empty attribute of type
+// pad, such as a family's *_PAD attribute. The kernel only pads values on
+// architectures without efficient unaligned memory access, while
+// AppendUint64 always pads them, so its layout is valid on every
+// architecture. Decoders must accept values with and without padding; see
+// StripPadding.
+func AppendUint64(attrs []netlink.Attribute, offset int, typ, pad uint16, v uint64) []netlink.Attribute {
+	// The value follows the attribute header.
+	if (NextAttributeOffset(attrs, offset)+nlaHeaderLen)%8 != 0 {
+		attrs = append(attrs, netlink.Attribute{Type: pad, Data: []byte{}})
+	}
+
+	return append(attrs, netlink.Attribute{
+		Type: typ,
+		Data: nlenc.Uint64Bytes(v),
+	})
+}
+
+// NextAttributeOffset returns the offset within a netlink message of an
+// attribute appended to attrs, which begin at offset. The attributes nested
+// within that attribute begin 4 bytes later, following its header.
+func NextAttributeOffset(attrs []netlink.Attribute, offset int) int {
+	for _, a := range attrs {
+		offset += nlaAlign(nlaHeaderLen + len(a.Data))
+	}
+
+	return offset
+}
+
+// StripPadding returns attrs without the empty attributes of type pad used
+// to align 64-bit values. attrs is modified in place. Attribute types are
+// compared without their flags.
+func StripPadding(attrs []netlink.Attribute, pad uint16) []netlink.Attribute {
+	out := attrs[:0]
+	for _, a := range attrs {
+		if AttributeType(a.Type) == pad && len(a.Data) == 0 {
+			continue
+		}
+
+		out = append(out, a)
+	}
+
+	return out
+}
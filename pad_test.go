@@ -0,0 +1,58 @@
+package genetlink_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+)
+
+func TestAppendUint64(t *testing.T) {
+	const (
+		attrA   = 1
+		attrB   = 2
+		attrPad = 3
+	)
+
+	var attrs []netlink.Attribute
+	attrs = genetlink.AppendUint64(attrs, genetlink.AttributeOffset, attrA, attrPad, 1)
+	attrs = genetlink.AppendUint64(attrs, genetlink.AttributeOffset, attrB, attrPad, 2)
+
+	want := []netlink.Attribute{
+		// Value at offset 24.
+		{Type: attrA, Data: nlenc.Uint64Bytes(1)},
+		// Padding to place the next value at offset 40, rather than 36.
+		{Type: attrPad, Data: []byte{}},
+		{Type: attrB, Data: nlenc.Uint64Bytes(2)},
+	}
+
+	if diff := cmp.Diff(want, attrs); diff != "" {
+		t.Fatalf("unexpected attributes (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(48, genetlink.NextAttributeOffset(attrs, genetlink.AttributeOffset)); diff != "" {
+		t.Fatalf("unexpected next offset (-want +got):\n%s", diff)
+	}
+
+	got := genetlink.StripPadding(attrs, attrPad)
+	if diff := cmp.Diff([]netlink.Attribute{want[0], want[2]}, got); diff != "" {
+		t.Fatalf("unexpected stripped attributes (-want +got):\n%s", diff)
+	}
+}
+
+func TestAppendUint64Nested(t *testing.T) {
+	// The attributes nested within the first attribute begin at offset 24,
+	// following its header, so a value must be padded to offset 32.
+	offset := genetlink.NextAttributeOffset(nil, genetlink.AttributeOffset) + 4
+
+	want := []netlink.Attribute{
+		{Type: 2, Data: []byte{}},
+		{Type: 1, Data: nlenc.Uint64Bytes(1)},
+	}
+
+	if diff := cmp.Diff(want, genetlink.AppendUint64(nil, offset, 1, 2, 1)); diff != "" {
+		t.Fatalf("unexpected attributes (-want +got):\n%s", diff)
+	}
+}
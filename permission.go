@@ -0,0 +1,174 @@
+package genetlink
+
+import (
+	"fmt"
+	"math"
+	"os"
+)
+
+// A Permission describes whether the current process is permitted to issue a
+// generic netlink command to a family.
+type Permission struct {
+	// Family and Command identify the operation which was checked.
+	Family  string
+	Command uint8
+
+	// AdminRequired reports whether the kernel requires CAP_NET_ADMIN in order
+	// to issue Command.
+	AdminRequired bool
+
+	// NetAdmin reports whether the current process holds CAP_NET_ADMIN.
+	NetAdmin bool
+}
+
+// Allowed reports whether the current process is permitted to issue the
+// command.
+func (p Permission) Allowed() bool {
+	return !p.AdminRequired || p.NetAdmin
+}
+
+// Err returns an error describing why the command is not permitted, or nil if
+// it is. The error value can be checked using
+// `errors.Is(err, os.ErrPermission)`.
+func (p Permission) Err() error {
+	if p.Allowed() {
+		return nil
+	}
+
+	return fmt.Errorf("genetlink: family %q command %d requires CAP_NET_ADMIN: %w",
+		p.Family, p.Command, os.ErrPermission)
+}
+
+// CheckPermission reports whether command requires administrative permission
+// for Family f, and whether the current process holds CAP_NET_ADMIN. f must
+// have been retrieved using GetFamily or ListFamilies so that its Ops are
+// populated.
+//
+// CheckPermission is intended for tools which would prefer to fail early with
+// a clear message, rather than receiving a bare EPERM partway through an
+// operation.
+//
+// If f does not support command, the error value can be checked using
+// `errors.Is(err, os.ErrNotExist)`.
+func CheckPermission(f Family, command uint8) (Permission, error) {
+	netAdmin, err := hasNetAdmin()
+	if err != nil {
+		return Permission{}, err
+	}
+
+	return checkPermission(f, command, netAdmin)
+}
+
+// checkPermission implements CheckPermission using the input netAdmin
+// capability value.
+func checkPermission(f Family, command uint8, netAdmin bool) (Permission, error) {
+	o, ok := f.op(command)
+	if !ok {
+		return Permission{}, fmt.Errorf("genetlink: family %q does not support command %d: %w",
+			f.Name, command, os.ErrNotExist)
+	}
+
+	return Permission{
+		Family:        f.Name,
+		Command:       command,
+		AdminRequired: o.Flags&(OpAdminPerm|OpUnsAdminPerm) != 0,
+		NetAdmin:      netAdmin,
+	}, nil
+}
+
+// A CapabilityReport describes which operations of a generic netlink family
+// the current process will and will not be permitted to use.
+type CapabilityReport struct {
+	// Family is the family which was inspected.
+	Family string
+
+	// NetAdmin reports whether the current process holds CAP_NET_ADMIN.
+	NetAdmin bool
+
+	// Ops contains a Permission for each operation supported by the family,
+	// except for any whose ID does not fit in a generic netlink command.
+	Ops []Permission
+
+	// Groups contains the multicast groups of the family. Generic netlink
+	// does not report per-group permission requirements, so joining these
+	// groups may still fail for families which restrict them.
+	Groups []MulticastGroup
+}
+
+// Allowed returns the Permissions for operations which the current process is
+// permitted to use.
+func (r CapabilityReport) Allowed() []Permission {
+	return r.filter(true)
+}
+
+// Denied returns the Permissions for operations which the current process is
+// not permitted to use.
+func (r CapabilityReport) Denied() []Permission {
+	return r.filter(false)
+}
+
+// RequiredCapabilities returns the names of the Linux capabilities which are
+// required to use every operation of the family, suitable for use in
+// configuration such as a systemd unit's AmbientCapabilities directive.
+func (r CapabilityReport) RequiredCapabilities() []string {
+	for _, p := range r.Ops {
+		if p.AdminRequired {
+			return []string{"CAP_NET_ADMIN"}
+		}
+	}
+
+	return nil
+}
+
+// filter returns the Permissions in r whose Allowed value matches allowed.
+func (r CapabilityReport) filter(allowed bool) []Permission {
+	var ps []Permission
+	for _, p := range r.Ops {
+		if p.Allowed() == allowed {
+			ps = append(ps, p)
+		}
+	}
+
+	return ps
+}
+
+// ReportCapabilities inspects the capabilities of the current process and the
+// operations of Family f, and reports which operations will and will not be
+// permitted. f must have been retrieved using GetFamily or ListFamilies so
+// that its Ops are populated.
+func ReportCapabilities(f Family) (CapabilityReport, error) {
+	netAdmin, err := hasNetAdmin()
+	if err != nil {
+		return CapabilityReport{}, err
+	}
+
+	return reportCapabilities(f, netAdmin)
+}
+
+// reportCapabilities implements ReportCapabilities using the input netAdmin
+// capability value.
+func reportCapabilities(f Family, netAdmin bool) (CapabilityReport, error) {
+	r := CapabilityReport{
+		Family:   f.Name,
+		NetAdmin: netAdmin,
+		Ops:      make([]Permission, 0, len(f.Ops)),
+		Groups:   f.Groups,
+	}
+
+	for _, o := range f.Ops {
+		if o.ID > math.MaxUint8 {
+			// Generic netlink commands are 8-bit values on the wire, so
+			// the operation cannot be requested.
+			continue
+		}
+
+		p, err := checkPermission(f, uint8(o.ID), netAdmin)
+		if err != nil {
+			return CapabilityReport{}, err
+		}
+
+		r.Ops = append(r.Ops, p)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,30 @@
+//go:build linux
+// +build linux
+
+package genetlink
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// hasNetAdmin reports whether the current process holds CAP_NET_ADMIN in its
+// effective capability set.
+func hasNetAdmin() (bool, error) {
+	return hasCapability(unix.CAP_NET_ADMIN)
+}
+
+// hasCapability reports whether the current process holds capability c in
+// its effective capability set.
+func hasCapability(c int) (bool, error) {
+	hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+
+	// Version 3 capabilities are split across two 32-bit words.
+	var data [2]unix.CapUserData
+	if err := unix.Capget(&hdr, &data[0]); err != nil {
+		return false, os.NewSyscallError("capget", err)
+	}
+
+	return data[c/32].Effective&(1<<(uint(c)%32)) != 0, nil
+}
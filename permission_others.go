@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package genetlink
+
+// hasNetAdmin always returns an error.
+func hasNetAdmin() (bool, error) {
+	return false, errUnimplemented
+}
@@ -0,0 +1,151 @@
+package genetlink
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckPermission(t *testing.T) {
+	f := Family{
+		Name: "foo",
+		Ops: []Op{
+			{ID: 1, Flags: OpCapDo},
+			{ID: 2, Flags: OpCapDo | OpAdminPerm},
+			{ID: 3, Flags: OpCapDump | OpUnsAdminPerm},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		command  uint8
+		netAdmin bool
+		p        Permission
+		allowed  bool
+		err      error
+	}{
+		{
+			name:    "unknown command",
+			command: 4,
+			err:     os.ErrNotExist,
+		},
+		{
+			name:    "unprivileged",
+			command: 1,
+			p: Permission{
+				Family:  "foo",
+				Command: 1,
+			},
+			allowed: true,
+		},
+		{
+			name:    "admin, denied",
+			command: 2,
+			p: Permission{
+				Family:        "foo",
+				Command:       2,
+				AdminRequired: true,
+			},
+		},
+		{
+			name:     "admin, allowed",
+			command:  2,
+			netAdmin: true,
+			p: Permission{
+				Family:        "foo",
+				Command:       2,
+				AdminRequired: true,
+				NetAdmin:      true,
+			},
+			allowed: true,
+		},
+		{
+			name:    "namespace admin, denied",
+			command: 3,
+			p: Permission{
+				Family:        "foo",
+				Command:       3,
+				AdminRequired: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := checkPermission(f, tt.command, tt.netAdmin)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.p, p); diff != "" {
+				t.Fatalf("unexpected permission (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.allowed, p.Allowed()); diff != "" {
+				t.Fatalf("unexpected allowed value (-want +got):\n%s", diff)
+			}
+
+			if err := p.Err(); tt.allowed != (err == nil) || (err != nil && !errors.Is(err, os.ErrPermission)) {
+				t.Fatalf("unexpected permission error: %v", err)
+			}
+		})
+	}
+}
+
+func TestReportCapabilities(t *testing.T) {
+	f := Family{
+		Name: "foo",
+		Groups: []MulticastGroup{{
+			ID:   1,
+			Name: "events",
+		}},
+		Ops: []Op{
+			{ID: 1, Flags: OpCapDo},
+			{ID: 2, Flags: OpCapDo | OpAdminPerm},
+			// Cannot be requested, and must not be mistaken for command 1.
+			{ID: 0x101, Flags: OpCapDo | OpAdminPerm},
+		},
+	}
+
+	r, err := reportCapabilities(f, false)
+	if err != nil {
+		t.Fatalf("failed to report capabilities: %v", err)
+	}
+
+	want := CapabilityReport{
+		Family: "foo",
+		Ops: []Permission{
+			{
+				Family:  "foo",
+				Command: 1,
+			},
+			{
+				Family:        "foo",
+				Command:       2,
+				AdminRequired: true,
+			},
+		},
+		Groups: f.Groups,
+	}
+
+	if diff := cmp.Diff(want, r); diff != "" {
+		t.Fatalf("unexpected report (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(want.Ops[:1], r.Allowed()); diff != "" {
+		t.Fatalf("unexpected allowed operations (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(want.Ops[1:], r.Denied()); diff != "" {
+		t.Fatalf("unexpected denied operations (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{"CAP_NET_ADMIN"}, r.RequiredCapabilities()); diff != "" {
+		t.Fatalf("unexpected required capabilities (-want +got):\n%s", diff)
+	}
+}
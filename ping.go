@@ -0,0 +1,22 @@
+package genetlink
+
+import "context"
+
+// Ping verifies that the Conn can exchange messages with the kernel by
+// retrieving the generic netlink controller (nlctrl) family. Ping is a cheap
+// end-to-end health check for use by health endpoints and supervisors.
+//
+// Ping returns ctx.Err() if ctx is canceled or its deadline expires before
+// the kernel replies. The context is applied as described for
+// ExecuteContext, so a Ping with a short deadline never interrupts other
+// operations in progress on the Conn, though it may wait for them to finish
+// before the nlctrl request is sent.
+func (c *Conn) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Bypass the family cache to reach the kernel.
+	_, err := c.getFamilyContext(ctx, "nlctrl")
+	return contextError(ctx, err)
+}
@@ -0,0 +1,104 @@
+//go:build linux
+// +build linux
+
+package genetlink_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestConnPing(t *testing.T) {
+	noop := func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, nil
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		c := genltest.Dial(genltest.ServeFamilies(genltest.Families(), noop))
+		defer c.Close()
+
+		if err := c.Ping(context.Background()); err != nil {
+			t.Fatalf("failed to ping: %v", err)
+		}
+	})
+
+	t.Run("no nlctrl", func(t *testing.T) {
+		c := genltest.Dial(genltest.ServeFamilies(nil, noop))
+		defer c.Close()
+
+		if err := c.Ping(context.Background()); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected not exist error, but got: %v", err)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		g := genltest.NewGate()
+		c := genltest.Dial(g.Block(genltest.ServeFamilies(genltest.Families(), noop)))
+		defer c.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if err := c.Ping(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, but got: %v", err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		g := genltest.NewGate()
+		c := genltest.Dial(g.Block(genltest.ServeFamilies(genltest.Families(), noop)))
+		defer c.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-g.Waiting()
+			cancel()
+		}()
+
+		if err := c.Ping(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected canceled, but got: %v", err)
+		}
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		g := genltest.NewGate()
+		slow := g.Block(func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			return []genetlink.Message{{Header: greq.Header}}, nil
+		})
+
+		c := genltest.Dial(genltest.ServeFamilies(genltest.Families(), slow))
+		defer c.Close()
+
+		errC := make(chan error, 1)
+		go func() {
+			req := genetlink.Message{Header: genetlink.Header{Command: 1}}
+			msgs, err := c.Execute(req, 0x20, netlink.Request)
+			errC <- checkCommand(msgs, err, 1)
+		}()
+
+		// A ping with a short deadline must not interrupt the request in
+		// progress in another goroutine.
+		<-g.Waiting()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if err := c.Ping(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, but got: %v", err)
+		}
+
+		g.Release()
+		if err := <-errC; err != nil {
+			t.Fatalf("unexpected error from concurrent Execute: %v", err)
+		}
+
+		if err := c.Ping(context.Background()); err != nil {
+			t.Fatalf("failed to ping: %v", err)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package genetlink
+
+// A Policy is the set of attribute validation policies the kernel applies to
+// requests for a generic netlink family.
+type Policy struct {
+	// Sets contains the attribute policy sets for a family. Sets refer to
+	// each other by index for nested attributes.
+	Sets []PolicySet
+
+	// Ops contains the policy sets used to validate each operation.
+	Ops []OpPolicy
+}
+
+// A PolicySet is a set of AttributePolicies identified by an index.
+type PolicySet struct {
+	Index      uint32
+	Attributes []AttributePolicy
+}
+
+// An OpPolicy associates an operation with the PolicySet indices used to
+// validate its "do" and "dump" requests. An index of -1 indicates that the
+// operation has no policy for that request type.
+type OpPolicy struct {
+	Command uint32
+	Do      int
+	Dump    int
+}
+
+// An AttributePolicy describes how the kernel validates a single attribute.
+// Fields which do not apply to an attribute's Kind are left unset.
+type AttributePolicy struct {
+	// Type is the netlink attribute type this policy applies to.
+	Type uint16
+
+	// Kind is the kind of data the attribute carries.
+	Kind AttributeKind
+
+	// Minimum and maximum values for signed and unsigned integer attributes.
+	MinSigned, MaxSigned     int64
+	MinUnsigned, MaxUnsigned uint64
+
+	// Minimum and maximum lengths for binary and string attributes.
+	MinLength, MaxLength uint32
+
+	// NestedIndex and NestedMaxType describe the PolicySet which applies to
+	// nested attributes.
+	NestedIndex, NestedMaxType uint32
+
+	// BitfieldMask and Mask describe the valid bits for bitfield and integer
+	// attributes.
+	BitfieldMask uint32
+	Mask         uint64
+}
+
+// An AttributeKind is the kind of data carried by an attribute, as reported
+// by the kernel in an AttributePolicy.
+type AttributeKind uint32
+
+// Possible AttributeKind values.
+const (
+	AttributeInvalid     AttributeKind = 0x0 // unix.NL_ATTR_TYPE_INVALID
+	AttributeFlag        AttributeKind = 0x1 // unix.NL_ATTR_TYPE_FLAG
+	AttributeU8          AttributeKind = 0x2 // unix.NL_ATTR_TYPE_U8
+	AttributeU16         AttributeKind = 0x3 // unix.NL_ATTR_TYPE_U16
+	AttributeU32         AttributeKind = 0x4 // unix.NL_ATTR_TYPE_U32
+	AttributeU64         AttributeKind = 0x5 // unix.NL_ATTR_TYPE_U64
+	AttributeS8          AttributeKind = 0x6 // unix.NL_ATTR_TYPE_S8
+	AttributeS16         AttributeKind = 0x7 // unix.NL_ATTR_TYPE_S16
+	AttributeS32         AttributeKind = 0x8 // unix.NL_ATTR_TYPE_S32
+	AttributeS64         AttributeKind = 0x9 // unix.NL_ATTR_TYPE_S64
+	AttributeBinary      AttributeKind = 0xa // unix.NL_ATTR_TYPE_BINARY
+	AttributeString      AttributeKind = 0xb // unix.NL_ATTR_TYPE_STRING
+	AttributeNulString   AttributeKind = 0xc // unix.NL_ATTR_TYPE_NUL_STRING
+	AttributeNested      AttributeKind = 0xd // unix.NL_ATTR_TYPE_NESTED
+	AttributeNestedArray AttributeKind = 0xe // unix.NL_ATTR_TYPE_NESTED_ARRAY
+	AttributeBitfield32  AttributeKind = 0xf // unix.NL_ATTR_TYPE_BITFIELD32
+)
+
+// Set returns the PolicySet with the specified index, if one exists.
+func (p Policy) Set(index uint32) (PolicySet, bool) {
+	for _, s := range p.Sets {
+		if s.Index == index {
+			return s, true
+		}
+	}
+
+	return PolicySet{}, false
+}
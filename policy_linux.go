@@ -0,0 +1,174 @@
+//go:build linux
+// +build linux
+
+package genetlink
+
+import (
+	"sort"
+
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// getPolicy retrieves the attribute policies for the family with the
+// specified name.
+func (c *Conn) getPolicy(name string) (Policy, error) {
+	ae := netlink.NewAttributeEncoder()
+	ae.String(unix.CTRL_ATTR_FAMILY_NAME, name)
+
+	b, err := ae.Encode()
+	if err != nil {
+		return Policy{}, err
+	}
+
+	req := Message{
+		Header: Header{
+			Command: unix.CTRL_CMD_GETPOLICY,
+			// TODO(mdlayher): grab nlctrl version?
+			Version: 1,
+		},
+		Data: b,
+	}
+
+	msgs, err := c.Execute(req, unix.GENL_ID_CTRL, netlink.Request|netlink.Dump)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	return parsePolicy(msgs)
+}
+
+// parsePolicy parses a policy dump into a Policy. Each message carries either
+// a single attribute policy or the policy indices for a single operation.
+func parsePolicy(msgs []Message) (Policy, error) {
+	sets := make(map[uint32]*PolicySet)
+
+	var p Policy
+	for _, m := range msgs {
+		ad, err := netlink.NewAttributeDecoder(m.Data)
+		if err != nil {
+			return Policy{}, err
+		}
+
+		for ad.Next() {
+			switch ad.Type() {
+			case unix.CTRL_ATTR_POLICY:
+				ad.Nested(parsePolicySets(sets))
+			case unix.CTRL_ATTR_OP_POLICY:
+				ad.Nested(parseOpPolicies(&p.Ops))
+			}
+		}
+
+		if err := ad.Err(); err != nil {
+			return Policy{}, err
+		}
+	}
+
+	p.Sets = make([]PolicySet, 0, len(sets))
+	for _, s := range sets {
+		sort.Slice(s.Attributes, func(i, j int) bool {
+			return s.Attributes[i].Type < s.Attributes[j].Type
+		})
+
+		p.Sets = append(p.Sets, *s)
+	}
+
+	sort.Slice(p.Sets, func(i, j int) bool {
+		return p.Sets[i].Index < p.Sets[j].Index
+	})
+
+	return p, nil
+}
+
+// parsePolicySets parses nested policy set attributes, accumulating attribute
+// policies into sets by index.
+func parsePolicySets(sets map[uint32]*PolicySet) func(*netlink.AttributeDecoder) error {
+	return func(ad *netlink.AttributeDecoder) error {
+		for ad.Next() {
+			idx := uint32(ad.Type())
+
+			s, ok := sets[idx]
+			if !ok {
+				s = &PolicySet{Index: idx}
+				sets[idx] = s
+			}
+
+			ad.Nested(func(nad *netlink.AttributeDecoder) error {
+				for nad.Next() {
+					ap := AttributePolicy{Type: nad.Type()}
+					nad.Nested(parseAttributePolicy(&ap))
+					s.Attributes = append(s.Attributes, ap)
+				}
+
+				return nil
+			})
+		}
+
+		return nil
+	}
+}
+
+// parseAttributePolicy parses the fields of a single attribute policy.
+func parseAttributePolicy(ap *AttributePolicy) func(*netlink.AttributeDecoder) error {
+	return func(ad *netlink.AttributeDecoder) error {
+		for ad.Next() {
+			switch ad.Type() {
+			case unix.NL_POLICY_TYPE_ATTR_TYPE:
+				ap.Kind = AttributeKind(ad.Uint32())
+			case unix.NL_POLICY_TYPE_ATTR_MIN_VALUE_S:
+				ap.MinSigned = ad.Int64()
+			case unix.NL_POLICY_TYPE_ATTR_MAX_VALUE_S:
+				ap.MaxSigned = ad.Int64()
+			case unix.NL_POLICY_TYPE_ATTR_MIN_VALUE_U:
+				ap.MinUnsigned = ad.Uint64()
+			case unix.NL_POLICY_TYPE_ATTR_MAX_VALUE_U:
+				ap.MaxUnsigned = ad.Uint64()
+			case unix.NL_POLICY_TYPE_ATTR_MIN_LENGTH:
+				ap.MinLength = ad.Uint32()
+			case unix.NL_POLICY_TYPE_ATTR_MAX_LENGTH:
+				ap.MaxLength = ad.Uint32()
+			case unix.NL_POLICY_TYPE_ATTR_POLICY_IDX:
+				ap.NestedIndex = ad.Uint32()
+			case unix.NL_POLICY_TYPE_ATTR_POLICY_MAXTYPE:
+				ap.NestedMaxType = ad.Uint32()
+			case unix.NL_POLICY_TYPE_ATTR_BITFIELD32_MASK:
+				ap.BitfieldMask = ad.Uint32()
+			case unix.NL_POLICY_TYPE_ATTR_MASK:
+				ap.Mask = ad.Uint64()
+			}
+		}
+
+		return nil
+	}
+}
+
+// parseOpPolicies parses nested operation policy attributes into a slice of
+// OpPolicies.
+func parseOpPolicies(ops *[]OpPolicy) func(*netlink.AttributeDecoder) error {
+	return func(ad *netlink.AttributeDecoder) error {
+		for ad.Next() {
+			op := OpPolicy{
+				Command: uint32(ad.Type()),
+				Do:      -1,
+				Dump:    -1,
+			}
+
+			ad.Nested(func(nad *netlink.AttributeDecoder) error {
+				for nad.Next() {
+					switch nad.Type() {
+					case unix.CTRL_ATTR_POLICY_DO:
+						op.Do = int(nad.Uint32())
+					case unix.CTRL_ATTR_POLICY_DUMP:
+						op.Dump = int(nad.Uint32())
+					}
+				}
+
+				return nil
+			})
+
+			*ops = append(*ops, op)
+		}
+
+		return nil
+	}
+}
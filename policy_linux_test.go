@@ -0,0 +1,164 @@
+//go:build linux
+// +build linux
+
+package genetlink_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func TestConnGetPolicy(t *testing.T) {
+	const (
+		name  = "foo"
+		flags = netlink.Request | netlink.Dump
+	)
+
+	c := genltest.Dial(genltest.CheckRequest(unix.GENL_ID_CTRL, unix.CTRL_CMD_GETPOLICY, flags,
+		func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			ad, err := netlink.NewAttributeDecoder(greq.Data)
+			if err != nil {
+				t.Fatalf("failed to create attribute decoder: %v", err)
+			}
+
+			var got string
+			for ad.Next() {
+				if ad.Type() == unix.CTRL_ATTR_FAMILY_NAME {
+					got = ad.String()
+				}
+			}
+
+			if diff := cmp.Diff(name, got); diff != "" {
+				t.Fatalf("unexpected family name (-want +got):\n%s", diff)
+			}
+
+			// One message per attribute policy, followed by one message per
+			// operation policy, as the kernel does.
+			return []genetlink.Message{
+				policyMessage(t, func(ae *netlink.AttributeEncoder) {
+					ae.Nested(unix.CTRL_ATTR_POLICY, func(ae *netlink.AttributeEncoder) error {
+						ae.Nested(0, func(ae *netlink.AttributeEncoder) error {
+							ae.Nested(2, func(ae *netlink.AttributeEncoder) error {
+								ae.Uint32(unix.NL_POLICY_TYPE_ATTR_TYPE, unix.NL_ATTR_TYPE_STRING)
+								ae.Uint32(unix.NL_POLICY_TYPE_ATTR_MAX_LENGTH, 15)
+								return nil
+							})
+							return nil
+						})
+						return nil
+					})
+				}),
+				policyMessage(t, func(ae *netlink.AttributeEncoder) {
+					ae.Nested(unix.CTRL_ATTR_POLICY, func(ae *netlink.AttributeEncoder) error {
+						ae.Nested(0, func(ae *netlink.AttributeEncoder) error {
+							ae.Nested(1, func(ae *netlink.AttributeEncoder) error {
+								ae.Uint32(unix.NL_POLICY_TYPE_ATTR_TYPE, unix.NL_ATTR_TYPE_NESTED)
+								ae.Uint32(unix.NL_POLICY_TYPE_ATTR_POLICY_IDX, 1)
+								ae.Uint32(unix.NL_POLICY_TYPE_ATTR_POLICY_MAXTYPE, 3)
+								return nil
+							})
+							return nil
+						})
+						return nil
+					})
+				}),
+				policyMessage(t, func(ae *netlink.AttributeEncoder) {
+					ae.Nested(unix.CTRL_ATTR_POLICY, func(ae *netlink.AttributeEncoder) error {
+						ae.Nested(1, func(ae *netlink.AttributeEncoder) error {
+							ae.Nested(3, func(ae *netlink.AttributeEncoder) error {
+								ae.Uint32(unix.NL_POLICY_TYPE_ATTR_TYPE, unix.NL_ATTR_TYPE_S32)
+								ae.Int64(unix.NL_POLICY_TYPE_ATTR_MIN_VALUE_S, -10)
+								ae.Int64(unix.NL_POLICY_TYPE_ATTR_MAX_VALUE_S, 10)
+								return nil
+							})
+							return nil
+						})
+						return nil
+					})
+				}),
+				policyMessage(t, func(ae *netlink.AttributeEncoder) {
+					ae.Nested(unix.CTRL_ATTR_OP_POLICY, func(ae *netlink.AttributeEncoder) error {
+						ae.Nested(1, func(ae *netlink.AttributeEncoder) error {
+							ae.Uint32(unix.CTRL_ATTR_POLICY_DO, 0)
+							return nil
+						})
+						return nil
+					})
+				}),
+			}, nil
+		},
+	))
+	defer c.Close()
+
+	p, err := c.GetPolicy(name)
+	if err != nil {
+		t.Fatalf("failed to get policy: %v", err)
+	}
+
+	want := genetlink.Policy{
+		Sets: []genetlink.PolicySet{
+			{
+				Index: 0,
+				Attributes: []genetlink.AttributePolicy{
+					{
+						Type:          1,
+						Kind:          genetlink.AttributeNested,
+						NestedIndex:   1,
+						NestedMaxType: 3,
+					},
+					{
+						Type:      2,
+						Kind:      genetlink.AttributeString,
+						MaxLength: 15,
+					},
+				},
+			},
+			{
+				Index: 1,
+				Attributes: []genetlink.AttributePolicy{{
+					Type:      3,
+					Kind:      genetlink.AttributeS32,
+					MinSigned: -10,
+					MaxSigned: 10,
+				}},
+			},
+		},
+		Ops: []genetlink.OpPolicy{{
+			Command: 1,
+			Do:      0,
+			Dump:    -1,
+		}},
+	}
+
+	if diff := cmp.Diff(want, p); diff != "" {
+		t.Fatalf("unexpected policy (-want +got):\n%s", diff)
+	}
+}
+
+// policyMessage builds a policy dump reply message using attributes encoded
+// by fn.
+func policyMessage(t *testing.T, fn func(ae *netlink.AttributeEncoder)) genetlink.Message {
+	t.Helper()
+
+	ae := netlink.NewAttributeEncoder()
+	ae.Uint16(unix.CTRL_ATTR_FAMILY_ID, 0x20)
+	fn(ae)
+
+	b, err := ae.Encode()
+	if err != nil {
+		t.Fatalf("failed to encode attributes: %v", err)
+	}
+
+	return genetlink.Message{
+		Header: genetlink.Header{
+			Command: unix.CTRL_CMD_GETPOLICY,
+			Version: 1,
+		},
+		Data: b,
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package genetlink
+
+// getPolicy always returns an error.
+func (c *Conn) getPolicy(name string) (Policy, error) {
+	return Policy{}, errUnimplemented
+}
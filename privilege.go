@@ -0,0 +1,147 @@
+package genetlink
+
+import (
+	"fmt"
+	"os"
+)
+
+// A Workload declares the generic netlink families, commands, and multicast
+// groups which an application intends to use, so that the privileges it
+// requires can be reported by Conn.AuditPrivileges.
+type Workload struct {
+	Families []WorkloadFamily
+}
+
+// A WorkloadFamily declares the commands and multicast groups of a single
+// generic netlink family which an application intends to use.
+type WorkloadFamily struct {
+	// Name is the name of the family, such as "nl80211".
+	Name string
+
+	// Commands and Groups are the commands the application will issue and
+	// the names of the multicast groups it will join.
+	Commands []uint8
+	Groups   []string
+}
+
+// A PrivilegeReport describes the privileges required by a Workload.
+type PrivilegeReport struct {
+	// NetAdmin reports whether the current process holds CAP_NET_ADMIN.
+	NetAdmin bool
+
+	// Families contains a report for each family of the Workload, in the
+	// same order.
+	Families []FamilyPrivileges
+}
+
+// FamilyPrivileges describes the privileges required to use the declared
+// commands and multicast groups of a single family.
+type FamilyPrivileges struct {
+	// Family is the name of the family.
+	Family string
+
+	// Ops contains a Permission for each declared command.
+	Ops []Permission
+
+	// Groups contains the declared multicast groups. Generic netlink does
+	// not report per-group permission requirements, so joining these groups
+	// may still fail for families which restrict them.
+	Groups []MulticastGroup
+}
+
+// AdminOps returns the Permissions for declared commands which require
+// CAP_NET_ADMIN.
+func (r PrivilegeReport) AdminOps() []Permission {
+	var ps []Permission
+	for _, f := range r.Families {
+		for _, p := range f.Ops {
+			if p.AdminRequired {
+				ps = append(ps, p)
+			}
+		}
+	}
+
+	return ps
+}
+
+// Denied returns the Permissions for declared commands which the current
+// process is not permitted to use.
+func (r PrivilegeReport) Denied() []Permission {
+	var ps []Permission
+	for _, f := range r.Families {
+		for _, p := range f.Ops {
+			if !p.Allowed() {
+				ps = append(ps, p)
+			}
+		}
+	}
+
+	return ps
+}
+
+// RequiredCapabilities returns the names of the Linux capabilities which are
+// required by the Workload, suitable for generating least-privilege
+// configuration such as a systemd unit's AmbientCapabilities directive or a
+// container's capability set.
+func (r PrivilegeReport) RequiredCapabilities() []string {
+	if len(r.AdminOps()) > 0 {
+		return []string{"CAP_NET_ADMIN"}
+	}
+
+	return nil
+}
+
+// AuditPrivileges reports the privileges required by Workload w, by
+// retrieving each declared family and inspecting the flags of the operations
+// which implement its declared commands.
+//
+// If a declared family, command, or multicast group does not exist, the
+// error value can be checked using `errors.Is(err, os.ErrNotExist)`.
+func (c *Conn) AuditPrivileges(w Workload) (PrivilegeReport, error) {
+	netAdmin, err := hasNetAdmin()
+	if err != nil {
+		return PrivilegeReport{}, err
+	}
+
+	return auditPrivileges(w, c.GetFamily, netAdmin)
+}
+
+// auditPrivileges implements AuditPrivileges using the input function to
+// retrieve families and the input netAdmin capability value.
+func auditPrivileges(w Workload, getFamily func(name string) (Family, error), netAdmin bool) (PrivilegeReport, error) {
+	r := PrivilegeReport{
+		NetAdmin: netAdmin,
+		Families: make([]FamilyPrivileges, 0, len(w.Families)),
+	}
+
+	for _, wf := range w.Families {
+		f, err := getFamily(wf.Name)
+		if err != nil {
+			return PrivilegeReport{}, err
+		}
+
+		fp := FamilyPrivileges{Family: f.Name}
+		for _, cmd := range wf.Commands {
+			p, err := checkPermission(f, cmd, netAdmin)
+			if err != nil {
+				return PrivilegeReport{}, err
+			}
+
+			fp.Ops = append(fp.Ops, p)
+		}
+
+		for _, name := range wf.Groups {
+			g, ok := f.group(name)
+			if !ok {
+				return PrivilegeReport{}, fmt.Errorf("genetlink: family %q does not have multicast group %q: %w",
+					f.Name, name, os.ErrNotExist)
+			}
+
+			fp.Groups = append(fp.Groups, g)
+		}
+
+		r.Families = append(r.Families, fp)
+	}
+
+	return r, nil
+}
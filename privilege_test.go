@@ -0,0 +1,146 @@
+package genetlink
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAuditPrivileges(t *testing.T) {
+	families := map[string]Family{
+		"foo": {
+			Name: "foo",
+			Ops: []Op{
+				{ID: 1, Flags: OpCapDo},
+				{ID: 2, Flags: OpCapDo | OpAdminPerm},
+			},
+			Groups: []MulticastGroup{{ID: 10, Name: "events"}},
+		},
+		"bar": {
+			Name: "bar",
+			Ops:  []Op{{ID: 1, Flags: OpCapDump}},
+		},
+	}
+
+	getFamily := func(name string) (Family, error) {
+		f, ok := families[name]
+		if !ok {
+			return Family{}, fmt.Errorf("family %q: %w", name, os.ErrNotExist)
+		}
+
+		return f, nil
+	}
+
+	tests := []struct {
+		name     string
+		w        Workload
+		netAdmin bool
+		r        PrivilegeReport
+		caps     []string
+		denied   []Permission
+		err      error
+	}{
+		{
+			name: "unknown family",
+			w:    Workload{Families: []WorkloadFamily{{Name: "baz"}}},
+			err:  os.ErrNotExist,
+		},
+		{
+			name: "unknown command",
+			w: Workload{Families: []WorkloadFamily{{
+				Name:     "foo",
+				Commands: []uint8{3},
+			}}},
+			err: os.ErrNotExist,
+		},
+		{
+			name: "unknown group",
+			w: Workload{Families: []WorkloadFamily{{
+				Name:   "foo",
+				Groups: []string{"config"},
+			}}},
+			err: os.ErrNotExist,
+		},
+		{
+			name: "unprivileged",
+			w: Workload{Families: []WorkloadFamily{
+				{Name: "foo", Commands: []uint8{1}, Groups: []string{"events"}},
+				{Name: "bar", Commands: []uint8{1}},
+			}},
+			r: PrivilegeReport{
+				Families: []FamilyPrivileges{
+					{
+						Family: "foo",
+						Ops:    []Permission{{Family: "foo", Command: 1}},
+						Groups: []MulticastGroup{{ID: 10, Name: "events"}},
+					},
+					{
+						Family: "bar",
+						Ops:    []Permission{{Family: "bar", Command: 1}},
+					},
+				},
+			},
+		},
+		{
+			name: "admin, denied",
+			w: Workload{Families: []WorkloadFamily{
+				{Name: "foo", Commands: []uint8{1, 2}},
+			}},
+			r: PrivilegeReport{
+				Families: []FamilyPrivileges{{
+					Family: "foo",
+					Ops: []Permission{
+						{Family: "foo", Command: 1},
+						{Family: "foo", Command: 2, AdminRequired: true},
+					},
+				}},
+			},
+			caps:   []string{"CAP_NET_ADMIN"},
+			denied: []Permission{{Family: "foo", Command: 2, AdminRequired: true}},
+		},
+		{
+			name: "admin, allowed",
+			w: Workload{Families: []WorkloadFamily{
+				{Name: "foo", Commands: []uint8{2}},
+			}},
+			netAdmin: true,
+			r: PrivilegeReport{
+				NetAdmin: true,
+				Families: []FamilyPrivileges{{
+					Family: "foo",
+					Ops: []Permission{
+						{Family: "foo", Command: 2, AdminRequired: true, NetAdmin: true},
+					},
+				}},
+			},
+			caps: []string{"CAP_NET_ADMIN"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := auditPrivileges(tt.w, getFamily, tt.netAdmin)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.r, r); diff != "" {
+				t.Fatalf("unexpected report (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.caps, r.RequiredCapabilities()); diff != "" {
+				t.Fatalf("unexpected capabilities (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.denied, r.Denied()); diff != "" {
+				t.Fatalf("unexpected denied permissions (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
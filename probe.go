@@ -0,0 +1,110 @@
+package genetlink
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// A ProbeResult describes whether generic netlink, and optionally a set of
+// generic netlink families, can be used on the current machine.
+type ProbeResult struct {
+	// Supported reports whether a generic netlink connection could be dialed
+	// and used to query the generic netlink controller (nlctrl).
+	Supported bool
+
+	// PermissionDenied reports whether a probe operation failed due to a
+	// lack of permission, such as when sockets are blocked by a sandbox.
+	PermissionDenied bool
+
+	// Missing contains the names of the requested families which do not
+	// exist.
+	Missing []string
+
+	// Err is the error which caused generic netlink to be reported as not
+	// supported or as denied, if any.
+	Err error
+}
+
+// OK reports whether generic netlink and all of the requested families are
+// available.
+func (r ProbeResult) OK() bool {
+	return r.Supported && !r.PermissionDenied && len(r.Missing) == 0
+}
+
+// String returns a human-readable description of r, suitable for use as the
+// reason for skipping a test.
+func (r ProbeResult) String() string {
+	switch {
+	case r.PermissionDenied:
+		return fmt.Sprintf("generic netlink permission denied: %v", r.Err)
+	case !r.Supported:
+		return fmt.Sprintf("generic netlink not supported: %v", r.Err)
+	case len(r.Missing) > 0:
+		return fmt.Sprintf("generic netlink families not found: %s", strings.Join(r.Missing, ", "))
+	default:
+		return "generic netlink available"
+	}
+}
+
+// Probe dials a generic netlink connection and uses it to check whether
+// generic netlink and each of the named families are available.
+//
+// Probe never panics and returns no error: all failures are described by the
+// returned ProbeResult. Integration tests can use Probe to skip cleanly in
+// environments such as containers and build sandboxes:
+//
+//	if r := genetlink.Probe("nl80211"); !r.OK() {
+//		t.Skip(r)
+//	}
+func Probe(families ...string) ProbeResult {
+	c, err := Dial(nil)
+	if err != nil {
+		return probeError(err)
+	}
+	defer c.Close()
+
+	return c.Probe(families...)
+}
+
+// Available reports whether generic netlink and each of the named families
+// are available. See Probe for details.
+func Available(families ...string) bool {
+	return Probe(families...).OK()
+}
+
+// Probe uses c to check whether generic netlink and each of the named
+// families are available. See the package-level Probe function for details.
+func (c *Conn) Probe(families ...string) ProbeResult {
+	if _, err := c.GetFamily("nlctrl"); err != nil {
+		return probeError(err)
+	}
+
+	r := ProbeResult{Supported: true}
+	for _, name := range families {
+		_, err := c.GetFamily(name)
+		switch {
+		case err == nil:
+		case errors.Is(err, os.ErrNotExist):
+			r.Missing = append(r.Missing, name)
+		case errors.Is(err, os.ErrPermission):
+			r.PermissionDenied, r.Err = true, err
+			return r
+		default:
+			r.Supported, r.Err = false, err
+			return r
+		}
+	}
+
+	return r
+}
+
+// probeError creates a ProbeResult for an error which prevents the use of
+// generic netlink.
+func probeError(err error) ProbeResult {
+	return ProbeResult{
+		PermissionDenied: errors.Is(err, os.ErrPermission),
+		Err:              err,
+	}
+}
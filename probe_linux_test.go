@@ -0,0 +1,73 @@
+//go:build linux
+// +build linux
+
+package genetlink_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestConnProbe(t *testing.T) {
+	noop := func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return nil, nil
+	}
+
+	tests := []struct {
+		name     string
+		fn       genltest.Func
+		families []string
+		r        genetlink.ProbeResult
+		ok       bool
+	}{
+		{
+			name:     "OK",
+			fn:       genltest.ServeFamilies(genltest.Families(), noop),
+			families: []string{"nl80211", "ethtool"},
+			r:        genetlink.ProbeResult{Supported: true},
+			ok:       true,
+		},
+		{
+			name:     "missing",
+			fn:       genltest.ServeFamilies(genltest.Families(), noop),
+			families: []string{"nl80211", "foo", "bar"},
+			r: genetlink.ProbeResult{
+				Supported: true,
+				Missing:   []string{"foo", "bar"},
+			},
+		},
+		{
+			name: "no nlctrl",
+			fn:   genltest.ServeFamilies(nil, noop),
+			r:    genetlink.ProbeResult{},
+		},
+		{
+			name: "permission denied",
+			fn: func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+				return nil, genltest.ErrorPermission()
+			},
+			r: genetlink.ProbeResult{PermissionDenied: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := genltest.Dial(tt.fn)
+			defer c.Close()
+
+			r := c.Probe(tt.families...)
+			if diff := cmp.Diff(tt.r, r, cmpopts.IgnoreFields(genetlink.ProbeResult{}, "Err")); diff != "" {
+				t.Fatalf("unexpected probe result (-want +got):\n%s", diff)
+			}
+
+			if want, got := tt.ok, r.OK(); want != got {
+				t.Fatalf("unexpected OK: %v, want: %v (%s)", got, want, r)
+			}
+		})
+	}
+}
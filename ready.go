@@ -0,0 +1,44 @@
+package genetlink
+
+import (
+	"errors"
+
+	"github.com/mdlayher/netlink"
+)
+
+// ErrWouldBlock is returned by TryReceive when no messages are ready to be
+// received.
+var ErrWouldBlock = errors.New("genetlink: receive would block")
+
+// Readable reports whether a message is ready to be received, without
+// blocking and without consuming it. A pending socket error, such as
+// ENOBUFS when messages were dropped, is returned by Readable.
+//
+// Readable and TryReceive allow a Conn to be driven by an external event
+// loop. The Conn's file descriptor can be obtained using SyscallConn and
+// registered with the loop's own epoll instance (or similar) for read
+// readiness. When the loop reports that the descriptor is readable, call
+// TryReceive until it returns ErrWouldBlock. The file descriptor remains
+// owned by the Conn and must not be read from or closed directly.
+func (c *Conn) Readable() (bool, error) {
+	return c.readable()
+}
+
+// TryReceive is like Receive, but returns ErrWouldBlock immediately if no
+// messages are ready to be received. Once the first part of a multi-part
+// reply is ready, TryReceive receives the remaining parts, which the kernel
+// produces as the earlier parts are read.
+//
+// TryReceive must not be called concurrently with Receive or other calls
+// to TryReceive, which could consume the ready messages.
+func (c *Conn) TryReceive() ([]Message, []netlink.Message, error) {
+	ok, err := c.readable()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, ErrWouldBlock
+	}
+
+	return c.Receive()
+}
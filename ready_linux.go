@@ -0,0 +1,48 @@
+//go:build linux
+// +build linux
+
+package genetlink
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// readable peeks at the socket's receive queue without blocking.
+func (c *Conn) readable() (bool, error) {
+	rc, err := c.SyscallConn()
+	if err != nil {
+		return false, err
+	}
+
+	var (
+		b    [1]byte
+		ok   bool
+		serr error
+	)
+	if err := rc.Read(func(fd uintptr) bool {
+		_, _, err := unix.Recvfrom(int(fd), b[:], unix.MSG_PEEK|unix.MSG_DONTWAIT)
+		switch {
+		case err == nil:
+			ok = true
+		case errors.Is(err, unix.EAGAIN), errors.Is(err, unix.EINTR):
+		default:
+			// Pending socket errors such as ENOBUFS are reported and cleared
+			// by the kernel even when peeking.
+			serr = err
+		}
+
+		// Never wait for readiness using the runtime network poller.
+		return true
+	}); err != nil {
+		return false, err
+	}
+
+	if serr != nil {
+		return false, os.NewSyscallError("recvfrom", serr)
+	}
+
+	return ok, nil
+}
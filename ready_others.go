@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package genetlink
+
+// readable always returns an error.
+func (c *Conn) readable() (bool, error) {
+	return false, errUnimplemented
+}
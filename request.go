@@ -0,0 +1,115 @@
+package genetlink
+
+import "github.com/mdlayher/netlink"
+
+// A Request builds a generic netlink request message and its netlink header
+// flags, for use with Conn.Execute. Methods return the Request to allow
+// chaining:
+//
+//	msgs, err := genetlink.NewRequest(family, cmd).
+//		Dump().
+//		Attr(attrIfindex, nlenc.Uint32Bytes(1)).
+//		Execute(c)
+//
+// Any error which occurs while encoding attributes is returned by Build or
+// Execute.
+type Request struct {
+	family uint16
+	header Header
+	flags  netlink.HeaderFlags
+	ae     *netlink.AttributeEncoder
+	err    error
+}
+
+// NewRequest creates a Request for the specified command of family. The
+// request uses the family's ID and version, and sets the netlink.Request
+// flag.
+func NewRequest(family Family, command uint8) *Request {
+	return &Request{
+		family: family.ID,
+		header: Header{
+			Command: command,
+			Version: family.Version,
+		},
+		flags: netlink.Request,
+		ae:    netlink.NewAttributeEncoder(),
+	}
+}
+
+// Dump sets the netlink.Dump flag, requesting all matching objects.
+func (r *Request) Dump() *Request {
+	return r.Flags(netlink.Dump)
+}
+
+// Ack sets the netlink.Acknowledge flag, requesting an acknowledgement of
+// the request.
+func (r *Request) Ack() *Request {
+	return r.Flags(netlink.Acknowledge)
+}
+
+// Flags sets additional netlink header flags, such as netlink.Create or
+// netlink.Excl for requests which create objects.
+func (r *Request) Flags(flags netlink.HeaderFlags) *Request {
+	r.flags |= flags
+	return r
+}
+
+// Version overrides the generic netlink version of the request.
+func (r *Request) Version(v uint8) *Request {
+	r.header.Version = v
+	return r
+}
+
+// Attr appends an attribute with the specified type and data to the request.
+func (r *Request) Attr(typ uint16, data []byte) *Request {
+	r.ae.Bytes(typ, data)
+	return r
+}
+
+// AttrFlag appends a flag attribute with the specified type to the request if
+// v is true. Flag attributes carry no data: their presence alone indicates
+// that the flag is set, so no attribute is appended if v is false.
+func (r *Request) AttrFlag(typ uint16, v bool) *Request {
+	r.ae.Flag(typ, v)
+	return r
+}
+
+// Attributes invokes fn to append attributes to the request using a
+// netlink.AttributeEncoder. If fn returns an error, it is returned by Build
+// or Execute.
+func (r *Request) Attributes(fn func(ae *netlink.AttributeEncoder) error) *Request {
+	if r.err != nil {
+		return r
+	}
+
+	r.err = fn(r.ae)
+	return r
+}
+
+// Build returns the request's Message and netlink header flags.
+func (r *Request) Build() (Message, netlink.HeaderFlags, error) {
+	if r.err != nil {
+		return Message{}, 0, r.err
+	}
+
+	b, err := r.ae.Encode()
+	if err != nil {
+		return Message{}, 0, err
+	}
+
+	return Message{
+		Header: r.header,
+		Data:   b,
+	}, r.flags, nil
+}
+
+// Execute builds the request and executes it using c. See Conn.Execute for
+// details.
+func (r *Request) Execute(c *Conn) ([]Message, error) {
+	m, flags, err := r.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Execute(m, r.family, flags)
+}
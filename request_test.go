@@ -0,0 +1,119 @@
+package genetlink_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nltest"
+)
+
+func TestRequestBuild(t *testing.T) {
+	family := genetlink.Family{ID: 0x20, Version: 1}
+	errFoo := errors.New("foo")
+
+	tests := []struct {
+		name  string
+		r     *genetlink.Request
+		m     genetlink.Message
+		flags netlink.HeaderFlags
+		err   error
+	}{
+		{
+			name:  "default",
+			r:     genetlink.NewRequest(family, 1),
+			m:     genetlink.Message{Header: genetlink.Header{Command: 1, Version: 1}},
+			flags: netlink.Request,
+		},
+		{
+			name: "all",
+			r: genetlink.NewRequest(family, 2).
+				Dump().
+				Ack().
+				Flags(netlink.Echo).
+				Version(3).
+				Attr(1, []byte{0xff}).
+				Attributes(func(ae *netlink.AttributeEncoder) error {
+					ae.Uint16(2, 0xffff)
+					return nil
+				}),
+			m: genetlink.Message{
+				Header: genetlink.Header{Command: 2, Version: 3},
+				Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+					{Type: 1, Data: []byte{0xff}},
+					{Type: 2, Data: []byte{0xff, 0xff}},
+				}),
+			},
+			flags: netlink.Request | netlink.Dump | netlink.Acknowledge | netlink.Echo,
+		},
+		{
+			name: "flags",
+			r: genetlink.NewRequest(family, 1).
+				AttrFlag(1, true).
+				AttrFlag(2, false),
+			m: genetlink.Message{
+				Header: genetlink.Header{Command: 1, Version: 1},
+				Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+					{Type: 1},
+				}),
+			},
+			flags: netlink.Request,
+		},
+		{
+			name: "error",
+			r: genetlink.NewRequest(family, 1).
+				Attributes(func(_ *netlink.AttributeEncoder) error {
+					return errFoo
+				}),
+			err: errFoo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, flags, err := tt.r.Build()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.m, m); diff != "" {
+				t.Fatalf("unexpected message (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.flags, flags); diff != "" {
+				t.Fatalf("unexpected flags (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRequestExecute(t *testing.T) {
+	family := genetlink.Family{ID: 0x20, Version: 1}
+
+	c := genltest.Dial(genltest.CheckRequest(family.ID, 1, netlink.Request|netlink.Acknowledge,
+		func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			return []genetlink.Message{greq}, nil
+		},
+	))
+	defer c.Close()
+
+	msgs, err := genetlink.NewRequest(family, 1).Ack().Execute(c)
+	if err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+
+	want := []genetlink.Message{{
+		Header: genetlink.Header{Command: 1, Version: 1},
+		Data:   []byte{},
+	}}
+
+	if diff := cmp.Diff(want, msgs); diff != "" {
+		t.Fatalf("unexpected replies (-want +got):\n%s", diff)
+	}
+}
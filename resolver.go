@@ -0,0 +1,94 @@
+package genetlink
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// A Resolver resolves generic netlink families by name. Higher-level types
+// such as Monitor use a Resolver so that environments without access to the
+// generic netlink controller, such as tests and embedded systems, can supply
+// family information directly.
+type Resolver interface {
+	Resolve(name string) (Family, error)
+}
+
+var (
+	_ Resolver = &Conn{}
+	_ Resolver = &CachedResolver{}
+	_ Resolver = StaticResolver{}
+	_ Resolver = ResolverFunc(nil)
+)
+
+// Resolve implements Resolver by calling GetFamily, which queries the
+// generic netlink controller unless the family cache is enabled.
+func (c *Conn) Resolve(name string) (Family, error) {
+	return c.GetFamily(name)
+}
+
+// A ResolverFunc is a function which implements Resolver.
+type ResolverFunc func(name string) (Family, error)
+
+// Resolve implements Resolver.
+func (fn ResolverFunc) Resolve(name string) (Family, error) {
+	return fn(name)
+}
+
+// A StaticResolver is a Resolver which resolves families from a map of
+// family names to Families.
+type StaticResolver map[string]Family
+
+// Resolve implements Resolver. If the family does not exist, the error value
+// can be checked using `errors.Is(err, os.ErrNotExist)`.
+func (r StaticResolver) Resolve(name string) (Family, error) {
+	f, ok := r[name]
+	if !ok {
+		return Family{}, fmt.Errorf("genetlink: family %q: %w", name, os.ErrNotExist)
+	}
+
+	return copyFamily(f), nil
+}
+
+// A CachedResolver is a Resolver which caches the families resolved by
+// another Resolver. It is safe for concurrent use.
+type CachedResolver struct {
+	r     Resolver
+	cache *familyCache
+}
+
+// NewCachedResolver creates a CachedResolver which caches the families
+// resolved by r.
+func NewCachedResolver(r Resolver) *CachedResolver {
+	return &CachedResolver{
+		r:     r,
+		cache: newFamilyCache(time.Now),
+	}
+}
+
+// Resolve implements Resolver.
+func (r *CachedResolver) Resolve(name string) (Family, error) {
+	return r.cache.get(name, r.r.Resolve)
+}
+
+// SetTTL sets the time after which cached families expire and are resolved
+// again. A TTL of 0, the default, means that families never expire.
+func (r *CachedResolver) SetTTL(ttl time.Duration) {
+	r.cache.setTTL(ttl)
+}
+
+// SetClock sets the Clock used to expire cached families. By default,
+// SystemClock is used.
+func (r *CachedResolver) SetClock(clk Clock) {
+	r.cache.setClock(clk.Now)
+}
+
+// Invalidate removes the family with the specified name from the cache.
+func (r *CachedResolver) Invalidate(name string) {
+	r.cache.invalidate(name)
+}
+
+// InvalidateAll removes all families from the cache.
+func (r *CachedResolver) InvalidateAll() {
+	r.cache.invalidateAll()
+}
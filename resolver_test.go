@@ -0,0 +1,146 @@
+package genetlink_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+var testFamily = genetlink.Family{
+	ID:      0x20,
+	Version: 1,
+	Name:    "foo",
+	Groups:  []genetlink.MulticastGroup{{ID: 0x08, Name: "bar"}},
+}
+
+func TestStaticResolver(t *testing.T) {
+	r := genetlink.StaticResolver{"foo": testFamily}
+
+	f, err := r.Resolve("foo")
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+
+	if diff := cmp.Diff(testFamily, f); diff != "" {
+		t.Fatalf("unexpected family (-want +got):\n%s", diff)
+	}
+
+	if _, err := r.Resolve("baz"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not exist error, but got: %v", err)
+	}
+}
+
+func TestCachedResolver(t *testing.T) {
+	var calls int
+	r := genetlink.NewCachedResolver(genetlink.ResolverFunc(func(name string) (genetlink.Family, error) {
+		calls++
+		return genetlink.StaticResolver{"foo": testFamily}.Resolve(name)
+	}))
+
+	tests := []struct {
+		name   string
+		fn     func()
+		family string
+		calls  int
+		ok     bool
+	}{
+		{
+			name:   "miss",
+			family: "foo",
+			calls:  1,
+			ok:     true,
+		},
+		{
+			name:   "hit",
+			family: "foo",
+			ok:     true,
+		},
+		{
+			name:   "errors are not cached",
+			family: "baz",
+			calls:  1,
+		},
+		{
+			name:   "invalidate",
+			fn:     func() { r.Invalidate("foo") },
+			family: "foo",
+			calls:  1,
+			ok:     true,
+		},
+		{
+			name:   "invalidate all",
+			fn:     r.InvalidateAll,
+			family: "foo",
+			calls:  1,
+			ok:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.fn != nil {
+				tt.fn()
+			}
+
+			calls = 0
+			_, err := r.Resolve(tt.family)
+			if tt.ok && err != nil {
+				t.Fatalf("failed to resolve: %v", err)
+			}
+			if !tt.ok && err == nil {
+				t.Fatal("expected an error, but none occurred")
+			}
+
+			if diff := cmp.Diff(tt.calls, calls); diff != "" {
+				t.Fatalf("unexpected number of calls (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMonitorSetResolver(t *testing.T) {
+	var joined []uint32
+	c := genltest.DialConfig(genltest.Respond(func(_ genetlink.Message, _ netlink.Message) ([]genltest.Response, error) {
+		return []genltest.Response{{
+			Header:  netlink.Header{Type: netlink.HeaderType(testFamily.ID)},
+			Message: genetlink.Message{Header: genetlink.Header{Command: 1}},
+		}}, nil
+	}), &genltest.Config{
+		Membership: func(group uint32, join bool) error {
+			joined = append(joined, group)
+			return nil
+		},
+	})
+	defer c.Close()
+
+	m := genetlink.NewMonitor(c)
+	m.SetResolver(genetlink.StaticResolver{"foo": testFamily})
+
+	if err := m.Subscribe("foo", "bar"); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	if diff := cmp.Diff([]uint32{0x08}, joined); diff != "" {
+		t.Fatalf("unexpected joined groups (-want +got):\n%s", diff)
+	}
+
+	errStop := errors.New("stop")
+
+	var got genetlink.Event
+	err := m.Run(func(e genetlink.Event) error {
+		got = e
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.Family != "foo" || got.Group != "bar" {
+		t.Fatalf("unexpected event family and group: %q, %q", got.Family, got.Group)
+	}
+}
@@ -0,0 +1,134 @@
+package genetlink
+
+import (
+	"errors"
+	"os"
+
+	"github.com/mdlayher/netlink"
+)
+
+// A RestrictionError reports that a system call used to set up or configure
+// a generic netlink socket was denied by a restriction of the environment,
+// such as an Android SELinux policy or a seccomp filter, rather than failing
+// for another reason.
+type RestrictionError struct {
+	// Op is the name of the system call which was denied, such as "socket",
+	// "bind", or "setsockopt".
+	Op string
+
+	// Option is the socket option which could not be set, if Op is
+	// "setsockopt".
+	Option netlink.ConnOption
+
+	// Err is the underlying error, typically EPERM, EACCES, or ENOSYS.
+	Err error
+}
+
+// Error implements error.
+func (e *RestrictionError) Error() string {
+	return "genetlink: " + e.Op + " denied by the environment, possibly by a seccomp or SELinux policy: " +
+		e.Err.Error()
+}
+
+// Unwrap implements errors unwrapping.
+func (e *RestrictionError) Unwrap() error { return e.Err }
+
+// DialRestricted dials a generic netlink connection as Dial does, but
+// degrades gracefully in restricted environments such as Android apps and
+// seccomp sandboxes, which commonly deny some of the system calls Dial uses.
+// config is used as with Dial.
+//
+// If binding the socket to the port ID or multicast groups in config is
+// denied, DialRestricted binds it to a port ID chosen by the kernel instead.
+// The options enabled by the Strict field of config are enabled separately
+// after dialing, and if setting an option is denied or unsupported, the
+// option is left disabled. Each restriction which was worked around is
+// returned as a RestrictionError, so that callers can report their
+// effective configuration.
+//
+// If the socket cannot be created at all because the environment denied
+// it, the returned error is an *EnvironmentError wrapping a
+// *RestrictionError, rather than an opaque EPERM.
+func DialRestricted(config *netlink.Config) (*Conn, []*RestrictionError, error) {
+	var cfg netlink.Config
+	if config != nil {
+		cfg = *config
+	}
+
+	strict := cfg.Strict
+	cfg.Strict = false
+
+	var restrictions []*RestrictionError
+
+	c, err := Dial(&cfg)
+	if rerr := restriction(err); rerr != nil && rerr.Op == "bind" && (cfg.PID != 0 || cfg.Groups != 0) {
+		// Binding to a chosen address was denied, but the kernel may still
+		// allow an automatically assigned one.
+		restrictions = append(restrictions, rerr)
+
+		cfg.PID, cfg.Groups = 0, 0
+		c, err = Dial(&cfg)
+	}
+	if err != nil {
+		var eerr *EnvironmentError
+		if rerr := restriction(err); rerr != nil && !errors.As(err, &eerr) {
+			return nil, restrictions, rerr
+		}
+
+		return nil, restrictions, err
+	}
+
+	if !strict {
+		return c, restrictions, nil
+	}
+
+	for _, o := range []netlink.ConnOption{
+		netlink.ExtendedAcknowledge,
+		netlink.GetStrictCheck,
+	} {
+		err := c.SetOption(o, true)
+		if err == nil {
+			continue
+		}
+
+		rerr := restriction(err)
+		if rerr == nil && !isUnsupportedOption(err) {
+			_ = c.Close()
+			return nil, restrictions, err
+		}
+		if rerr == nil {
+			rerr = &RestrictionError{Op: "setsockopt", Err: unwrapSyscall(err)}
+		}
+
+		rerr.Option = o
+		restrictions = append(restrictions, rerr)
+	}
+
+	return c, restrictions, nil
+}
+
+// restriction returns a RestrictionError if err indicates that a system
+// call was denied by the environment, or nil otherwise.
+func restriction(err error) *RestrictionError {
+	if err == nil || !isRestricted(err) {
+		return nil
+	}
+
+	var serr *os.SyscallError
+	if !errors.As(err, &serr) {
+		return &RestrictionError{Op: "unknown", Err: err}
+	}
+
+	return &RestrictionError{Op: serr.Syscall, Err: serr.Err}
+}
+
+// unwrapSyscall returns the error wrapped by an *os.SyscallError within err,
+// or err itself.
+func unwrapSyscall(err error) error {
+	var serr *os.SyscallError
+	if errors.As(err, &serr) {
+		return serr.Err
+	}
+
+	return err
+}
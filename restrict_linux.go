@@ -0,0 +1,25 @@
+//go:build linux
+// +build linux
+
+package genetlink
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// isRestricted reports whether err indicates that a system call was denied
+// by a security policy: EPERM and EACCES by SELinux and other LSMs, and
+// ENOSYS by seccomp filters which pretend the system call does not exist.
+func isRestricted(err error) bool {
+	return errors.Is(err, unix.EPERM) ||
+		errors.Is(err, unix.EACCES) ||
+		errors.Is(err, unix.ENOSYS)
+}
+
+// isUnsupportedOption reports whether err indicates that the kernel does not
+// support a socket option.
+func isUnsupportedOption(err error) bool {
+	return errors.Is(err, unix.ENOPROTOOPT)
+}
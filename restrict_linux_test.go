@@ -0,0 +1,69 @@
+//go:build linux
+// +build linux
+
+package genetlink
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func TestRestriction(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want *RestrictionError
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "not restricted",
+			err:  os.NewSyscallError("bind", unix.EADDRINUSE),
+		},
+		{
+			name: "socket EACCES",
+			err:  os.NewSyscallError("socket", unix.EACCES),
+			want: &RestrictionError{Op: "socket", Err: unix.EACCES},
+		},
+		{
+			name: "setsockopt EPERM",
+			err: &netlink.OpError{
+				Op:  "set-option",
+				Err: os.NewSyscallError("setsockopt", unix.EPERM),
+			},
+			want: &RestrictionError{Op: "setsockopt", Err: unix.EPERM},
+		},
+		{
+			name: "seccomp ENOSYS",
+			err:  unix.ENOSYS,
+			want: &RestrictionError{Op: "unknown", Err: unix.ENOSYS},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := restriction(tt.err)
+			if tt.want == nil || got == nil {
+				if tt.want != got {
+					t.Fatalf("unexpected restriction: %v", got)
+				}
+
+				return
+			}
+
+			if diff := cmp.Diff(tt.want.Op, got.Op); diff != "" {
+				t.Fatalf("unexpected operation (-want +got):\n%s", diff)
+			}
+
+			if !errors.Is(got, tt.want.Err) {
+				t.Fatalf("restriction does not wrap its cause: %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package genetlink
+
+// isRestricted always reports false.
+func isRestricted(_ error) bool {
+	return false
+}
+
+// isUnsupportedOption always reports false.
+func isUnsupportedOption(_ error) bool {
+	return false
+}
@@ -0,0 +1,50 @@
+package genetlink
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+// A Gap describes a window during which a Monitor's Events may have been
+// lost, because the kernel dropped messages when the receive buffer of the
+// Monitor's Conn overflowed.
+type Gap struct {
+	// Start is the time at which the last messages before the overflow were
+	// received, or the time at which Run began if none were. Events received
+	// after Start and before the overflow was detected may be missing.
+	Start time.Time
+
+	// Detected is the time at which the overflow was detected, and Resynced
+	// is the time at which the Monitor's resync function completed. State
+	// retrieved by the resync function reflects any changes whose Events
+	// were lost, and Events delivered after the Gap continue from there.
+	Detected, Resynced time.Time
+}
+
+// SetResync enables recovery from receive buffer overflows, which the
+// kernel reports as ENOBUFS when it drops messages because the Monitor is
+// not keeping up. By default, Run returns the ENOBUFS error.
+//
+// When resync is set and an overflow occurs, Run invokes resync, which
+// typically dumps the state which is otherwise maintained from Events
+// using a separate Conn, and then delivers an Event whose Gap field
+// describes the window during which Events may be missing to each
+// Subscription and to the function passed to Run, so that consumers know
+// exactly which Events may have been lost. Run then continues receiving
+// Events. If resync returns an error, Run returns it.
+//
+// Events which are delivered with a Gap are delivered to every
+// Subscription regardless of its family, are never suppressed as
+// duplicates, and are never discarded by the DropNewest policy.
+//
+// SetResync must be called before Run.
+func (m *Monitor) SetResync(resync func() error) {
+	m.resync = resync
+}
+
+// isOverrun reports whether err indicates that the kernel dropped messages
+// because a socket's receive buffer overflowed.
+func isOverrun(err error) bool {
+	return errors.Is(err, syscall.ENOBUFS)
+}
@@ -0,0 +1,173 @@
+package genetlink_test
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestMonitorResync(t *testing.T) {
+	errStop := errors.New("stop")
+
+	tests := []struct {
+		name   string
+		resync bool
+		events []genetlink.Event
+		err    error
+	}{
+		{
+			name: "disabled",
+			events: []genetlink.Event{
+				{Command: 1},
+			},
+			err: syscall.ENOBUFS,
+		},
+		{
+			name:   "enabled",
+			resync: true,
+			events: []genetlink.Event{
+				{Command: 1},
+				{Gap: &genetlink.Gap{
+					Start:    time.Unix(1, 0),
+					Detected: time.Unix(2, 0),
+					Resynced: time.Unix(3, 0),
+				}},
+				{Command: 2},
+			},
+			err: errStop,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := genltest.NewClock(time.Unix(1, 0))
+
+			tr := &overrunTransport{
+				receives: []func() ([]netlink.Message, error){
+					multicast(1),
+					func() ([]netlink.Message, error) {
+						clk.Advance(time.Second)
+						return nil, &netlink.OpError{
+							Op:  "receive",
+							Err: os.NewSyscallError("recvmsg", syscall.ENOBUFS),
+						}
+					},
+					multicast(2),
+				},
+			}
+
+			c := genetlink.NewTransportConn(tr)
+			defer c.Close()
+			c.SetClock(clk)
+
+			m := genetlink.NewMonitor(c)
+
+			var resyncs int
+			if tt.resync {
+				m.SetResync(func() error {
+					resyncs++
+					clk.Advance(time.Second)
+					return nil
+				})
+			}
+
+			// Gaps are delivered to Subscriptions regardless of their family
+			// and policy.
+			s := m.NewSubscription(&genetlink.SubscriptionConfig{
+				Buffer: 8,
+				Policy: genetlink.DropNewest,
+			})
+
+			var got []genetlink.Event
+			err := m.Run(func(e genetlink.Event) error {
+				e.Header, e.Message = netlink.Header{}, genetlink.Message{}
+				got = append(got, e)
+				if e.Command == 2 {
+					return errStop
+				}
+
+				return nil
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.events, got); diff != "" {
+				t.Fatalf("unexpected events (-want +got):\n%s", diff)
+			}
+
+			var gaps int
+			for e := range s.Events() {
+				if e.Gap != nil {
+					gaps++
+				}
+			}
+
+			if diff := cmp.Diff(resyncs, gaps); diff != "" {
+				t.Fatalf("unexpected number of gaps (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMonitorResyncError(t *testing.T) {
+	tr := &overrunTransport{
+		receives: []func() ([]netlink.Message, error){
+			func() ([]netlink.Message, error) {
+				return nil, os.NewSyscallError("recvmsg", syscall.ENOBUFS)
+			},
+		},
+	}
+
+	c := genetlink.NewTransportConn(tr)
+	defer c.Close()
+
+	errResync := errors.New("resync")
+
+	m := genetlink.NewMonitor(c)
+	m.SetResync(func() error { return errResync })
+
+	if err := m.Run(nil); !errors.Is(err, errResync) {
+		t.Fatalf("expected resync error, but got: %v", err)
+	}
+}
+
+// multicast returns a receive function which returns a multicast message
+// with the specified command.
+func multicast(command uint8) func() ([]netlink.Message, error) {
+	return func() ([]netlink.Message, error) {
+		return []netlink.Message{{
+			Header: netlink.Header{Type: 0x20},
+			Data:   []byte{command, 1, 0, 0},
+		}}, nil
+	}
+}
+
+// An overrunTransport is a genetlink.Transport which returns the results of
+// a sequence of functions from Receive.
+type overrunTransport struct {
+	echoTransport
+	receives []func() ([]netlink.Message, error)
+}
+
+func (t *overrunTransport) Receive() ([]netlink.Message, error) {
+	if len(t.receives) == 0 {
+		return nil, os.ErrClosed
+	}
+
+	fn := t.receives[0]
+	t.receives = t.receives[1:]
+	return fn()
+}
+
+func (*overrunTransport) Execute(_ netlink.Message) ([]netlink.Message, error) {
+	// Families cannot be resolved.
+	return nil, os.ErrNotExist
+}
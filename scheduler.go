@@ -0,0 +1,145 @@
+package genetlink
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mdlayher/netlink"
+)
+
+// A Priority is the scheduling priority of an operation on a Conn which has
+// priority scheduling enabled.
+type Priority int
+
+// Possible Priority values. Operations with a higher Priority are started
+// before any waiting operations with a lower Priority.
+const (
+	// PriorityLow is the default Priority of dump requests.
+	PriorityLow Priority = iota
+
+	// PriorityNormal is the default Priority of all other requests.
+	PriorityNormal
+
+	// PriorityHigh is intended for latency-sensitive control operations.
+	PriorityHigh
+)
+
+// SetPriorityScheduling enables or disables priority scheduling of the
+// request/reply operations Execute, ExecuteAll, Dump, and ExecutePriority.
+//
+// Without scheduling, goroutines sharing a Conn contend for it in no
+// particular order, and a control operation may wait behind any number of
+// long-running background dumps. With scheduling, waiting operations are
+// started strictly in order of Priority, and in order of arrival within a
+// Priority. An operation which has already started is never interrupted,
+// but operations with a timeout or context, such as ExecuteTimeout, stop
+// waiting when it expires.
+//
+// SetPriorityScheduling must be called before the Conn is used concurrently.
+func (c *Conn) SetPriorityScheduling(enable bool) {
+	if !enable {
+		c.sched = nil
+		return
+	}
+
+	c.sched = &scheduler{}
+}
+
+// ExecutePriority is like Execute, but when priority scheduling is enabled,
+// the request is scheduled using the specified Priority rather than the
+// default Priority for its flags.
+func (c *Conn) ExecutePriority(p Priority, m Message, family uint16, flags netlink.HeaderFlags) ([]Message, error) {
+	release, err := c.sched.acquire(context.Background(), p)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return c.execute(context.Background(), m, family, flags)
+}
+
+// defaultPriority returns the default Priority for a request with flags.
+func defaultPriority(flags netlink.HeaderFlags) Priority {
+	if flags&netlink.Dump != 0 {
+		return PriorityLow
+	}
+
+	return PriorityNormal
+}
+
+// A scheduler grants exclusive use of a Conn to one operation at a time, in
+// order of Priority. A nil *scheduler grants use immediately.
+type scheduler struct {
+	mu      sync.Mutex
+	busy    bool
+	waiters [PriorityHigh + 1][]chan struct{}
+}
+
+// acquire blocks until the caller may start an operation with Priority p,
+// and returns a function which must be called when the operation completes.
+// If ctx is canceled first, the caller stops waiting and ctx.Err() is
+// returned.
+func (s *scheduler) acquire(ctx context.Context, p Priority) (release func(), err error) {
+	if s == nil {
+		return func() {}, nil
+	}
+
+	switch {
+	case p < PriorityLow:
+		p = PriorityLow
+	case p > PriorityHigh:
+		p = PriorityHigh
+	}
+
+	s.mu.Lock()
+	if !s.busy {
+		s.busy = true
+		s.mu.Unlock()
+		return s.release, nil
+	}
+
+	ready := make(chan struct{})
+	s.waiters[p] = append(s.waiters[p], ready)
+	s.mu.Unlock()
+
+	// Ownership is handed over directly by release.
+	select {
+	case <-ready:
+		return s.release, nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	for i, w := range s.waiters[p] {
+		if w == ready {
+			s.waiters[p] = append(s.waiters[p][:i:i], s.waiters[p][i+1:]...)
+			s.mu.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+	s.mu.Unlock()
+
+	// Ownership was handed over concurrently with cancelation, so pass it
+	// on to the next waiter.
+	s.release()
+	return nil, ctx.Err()
+}
+
+// release hands ownership to the highest Priority waiter, if any.
+func (s *scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for p := len(s.waiters) - 1; p >= 0; p-- {
+		if len(s.waiters[p]) == 0 {
+			continue
+		}
+
+		ready := s.waiters[p][0]
+		s.waiters[p] = s.waiters[p][1:]
+		close(ready)
+		return
+	}
+
+	s.busy = false
+}
@@ -0,0 +1,111 @@
+package genetlink
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSchedulerPriority(t *testing.T) {
+	var s scheduler
+
+	// Hold the scheduler while operations queue up behind it.
+	release, _ := s.acquire(context.Background(), PriorityNormal)
+
+	var (
+		mu    sync.Mutex
+		order []Priority
+		wg    sync.WaitGroup
+	)
+
+	for i, p := range []Priority{PriorityLow, PriorityNormal, PriorityLow, PriorityHigh} {
+		wg.Add(1)
+		go func(p Priority) {
+			defer wg.Done()
+			release, _ := s.acquire(context.Background(), p)
+			defer release()
+
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, p)
+		}(p)
+
+		// Wait for each operation to be queued so that arrival order is
+		// deterministic.
+		for s.waiting() != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	release()
+	wg.Wait()
+
+	want := []Priority{PriorityHigh, PriorityNormal, PriorityLow, PriorityLow}
+	if diff := cmp.Diff(want, order); diff != "" {
+		t.Fatalf("unexpected operation order (-want +got):\n%s", diff)
+	}
+
+	// The scheduler is idle again and grants use immediately.
+	release, _ = s.acquire(context.Background(), PriorityLow)
+	release()
+}
+
+func TestSchedulerCanceled(t *testing.T) {
+	var s scheduler
+	release, _ := s.acquire(context.Background(), PriorityNormal)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errC := make(chan error, 1)
+	go func() {
+		_, err := s.acquire(ctx, PriorityHigh)
+		errC <- err
+	}()
+
+	for s.waiting() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A canceled waiter stops waiting and is removed from the queue.
+	cancel()
+	if err := <-errC; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, but got: %v", err)
+	}
+
+	if diff := cmp.Diff(0, s.waiting()); diff != "" {
+		t.Fatalf("unexpected waiting operations (-want +got):\n%s", diff)
+	}
+
+	// The scheduler is not handed to the canceled waiter.
+	release()
+	release, err := s.acquire(context.Background(), PriorityLow)
+	if err != nil {
+		t.Fatalf("failed to acquire: %v", err)
+	}
+	release()
+}
+
+func TestSchedulerNil(t *testing.T) {
+	// A nil scheduler never blocks.
+	var s *scheduler
+	release, _ := s.acquire(context.Background(), PriorityHigh)
+	low, _ := s.acquire(context.Background(), PriorityLow)
+	low()
+	release()
+}
+
+// waiting returns the number of operations waiting to start.
+func (s *scheduler) waiting() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int
+	for _, w := range s.waiters {
+		n += len(w)
+	}
+
+	return n
+}
@@ -0,0 +1,117 @@
+package genetlink
+
+import "sync"
+
+// A Schema describes the commands and attributes of a generic netlink
+// family, so that messages of the family can be decoded with symbolic names
+// and types. Schemas are typically registered by applications or generated
+// bindings using RegisterSchema, and are used by Inspector, Monitor, and
+// SchemaRegistry.Describe.
+type Schema struct {
+	// Commands describes individual commands by command number. A command
+	// without its own attribute set uses Attributes.
+	Commands map[uint8]CommandSchema
+
+	// Attributes describes the attributes shared by the family's commands.
+	Attributes AttributeSet
+}
+
+// A CommandSchema describes a single command of a generic netlink family.
+type CommandSchema struct {
+	// Name is the symbolic name of the command.
+	Name string
+
+	// Attributes, if set, describes the attributes of the command's
+	// messages in place of the family's shared attributes.
+	Attributes AttributeSet
+}
+
+// An AttributeSet describes a set of attributes by attribute type, without
+// the nested and network byte order flags.
+type AttributeSet map[uint16]AttributeSchema
+
+// An AttributeSchema describes a single attribute.
+type AttributeSchema struct {
+	// Name is the symbolic name of the attribute.
+	Name string
+
+	// Kind is the kind of data carried by the attribute. If Kind is
+	// AttributeInvalid, the kind reported by the family's policy is used
+	// when available.
+	Kind AttributeKind
+
+	// Nested describes the attributes nested within a nested attribute, or
+	// within each element of a nested array attribute.
+	Nested AttributeSet
+}
+
+// attributes returns the AttributeSet which describes messages with the
+// specified command.
+func (s Schema) attributes(command uint8) AttributeSet {
+	if cs, ok := s.Commands[command]; ok && cs.Attributes != nil {
+		return cs.Attributes
+	}
+
+	return s.Attributes
+}
+
+// A SchemaRegistry holds Schemas for generic netlink families by family name.
+// It is safe for concurrent use.
+type SchemaRegistry struct {
+	mu      sync.RWMutex
+	schemas map[string]Schema
+}
+
+// DefaultSchemas is the SchemaRegistry used by RegisterSchema, and by
+// Inspectors and Monitors unless configured otherwise.
+var DefaultSchemas = NewSchemaRegistry()
+
+// RegisterSchema registers s as the Schema for the family with the specified
+// name in DefaultSchemas.
+func RegisterSchema(family string, s Schema) {
+	DefaultSchemas.Register(family, s)
+}
+
+// NewSchemaRegistry creates an empty SchemaRegistry.
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{schemas: make(map[string]Schema)}
+}
+
+// Register registers s as the Schema for the family with the specified name,
+// replacing any Schema registered previously.
+func (r *SchemaRegistry) Register(family string, s Schema) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.schemas[family] = s
+}
+
+// Unregister removes the Schema for the family with the specified name.
+func (r *SchemaRegistry) Unregister(family string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.schemas, family)
+}
+
+// Lookup returns the Schema for the family with the specified name, and
+// reports whether one is registered.
+func (r *SchemaRegistry) Lookup(family string) (Schema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.schemas[family]
+	return s, ok
+}
+
+// Describe describes m, a Message of family f, using the Schema registered
+// for f without consulting the kernel, and reports whether a Schema is
+// registered for f. Attributes are described as by Inspector.Inspect.
+func (r *SchemaRegistry) Describe(f Family, m Message) (Inspection, bool) {
+	s, ok := r.Lookup(f.Name)
+	if !ok {
+		return Inspection{}, false
+	}
+
+	return inspect(f, nil, &s, m), true
+}
@@ -0,0 +1,153 @@
+package genetlink_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/mdlayher/netlink/nlenc"
+	"github.com/mdlayher/netlink/nltest"
+)
+
+func TestSchemaRegistryDescribe(t *testing.T) {
+	r := genetlink.NewSchemaRegistry()
+	r.Register("foo", genetlink.Schema{
+		Commands: map[uint8]genetlink.CommandSchema{
+			1: {Name: "new"},
+			2: {
+				Name: "del",
+				Attributes: genetlink.AttributeSet{
+					1: {Name: "id", Kind: genetlink.AttributeU16},
+				},
+			},
+		},
+		Attributes: genetlink.AttributeSet{
+			1: {Name: "index", Kind: genetlink.AttributeU32},
+			2: {Name: "name", Kind: genetlink.AttributeString},
+			3: {
+				Name: "stats",
+				Kind: genetlink.AttributeNested,
+				Nested: genetlink.AttributeSet{
+					1: {Name: "packets", Kind: genetlink.AttributeU64},
+				},
+			},
+		},
+	})
+
+	f := genetlink.Family{ID: 0x20, Version: 1, Name: "foo"}
+
+	tests := []struct {
+		name string
+		m    genetlink.Message
+		want string
+	}{
+		{
+			name: "shared attributes",
+			m: genetlink.Message{
+				Header: genetlink.Header{Command: 1, Version: 1},
+				Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+					{Type: 1, Data: nlenc.Uint32Bytes(10)},
+					{Type: 2, Data: nlenc.Bytes("eth0")},
+					{
+						Type: 3 | netlink.Nested,
+						Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+							{Type: 1, Data: nlenc.Uint64Bytes(2)},
+						}),
+					},
+					{Type: 4, Data: []byte{0xff}},
+				}),
+			},
+			want: `foo (0x20): command 1 new, version 1
+  1 index (u32): 10
+  2 name (string): "eth0"
+  3 stats (nested):
+    1 packets (u64): 2
+  4 (unknown): [ff]
+`,
+		},
+		{
+			name: "command attributes",
+			m: genetlink.Message{
+				Header: genetlink.Header{Command: 2, Version: 1},
+				Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+					{Type: 1, Data: nlenc.Uint16Bytes(5)},
+				}),
+			},
+			want: `foo (0x20): command 2 del, version 1
+  1 id (u16): 5
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i, ok := r.Describe(f, tt.m)
+			if !ok {
+				t.Fatal("expected a registered schema")
+			}
+
+			if diff := cmp.Diff(tt.want, i.String()); diff != "" {
+				t.Fatalf("unexpected inspection (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, ok := r.Describe(genetlink.Family{ID: 0x21, Name: "bar"}, genetlink.Message{}); ok {
+		t.Fatal("expected no schema for unregistered family")
+	}
+
+	r.Unregister("foo")
+	if _, ok := r.Lookup("foo"); ok {
+		t.Fatal("expected no schema after unregistering")
+	}
+}
+
+func TestSchemaRegistryDescribeAttributeError(t *testing.T) {
+	r := genetlink.NewSchemaRegistry()
+	r.Register("foo", genetlink.Schema{
+		Attributes: genetlink.AttributeSet{
+			1: {
+				Name: "vfs",
+				Kind: genetlink.AttributeNestedArray,
+				Nested: genetlink.AttributeSet{
+					1: {Name: "index", Kind: genetlink.AttributeU32},
+				},
+			},
+		},
+	})
+
+	vf := func(index []byte) []byte {
+		return nltest.MustMarshalAttributes([]netlink.Attribute{{Type: 1, Data: index}})
+	}
+
+	m := genetlink.Message{
+		Data: nltest.MustMarshalAttributes([]netlink.Attribute{{
+			Type: 1 | netlink.Nested,
+			Data: nltest.MustMarshalAttributes([]netlink.Attribute{
+				{Type: 1 | netlink.Nested, Data: vf(nlenc.Uint32Bytes(1))},
+				{Type: 2 | netlink.Nested, Data: vf([]byte{0x02, 0x00})},
+			}),
+		}}),
+	}
+
+	i, ok := r.Describe(genetlink.Family{ID: 0x20, Name: "foo"}, m)
+	if !ok {
+		t.Fatal("expected a registered schema")
+	}
+
+	var aerr *genetlink.AttributeError
+	if !errors.As(i.Err, &aerr) {
+		t.Fatalf("expected an attribute error, but got: %v", i.Err)
+	}
+
+	if diff := cmp.Diff("vfs[2]/index", aerr.Path); diff != "" {
+		t.Fatalf("unexpected attribute path (-want +got):\n%s", diff)
+	}
+
+	want := "genetlink: attribute vfs[2]/index: length 2, want 4"
+	if diff := cmp.Diff(want, i.Err.Error()); diff != "" {
+		t.Fatalf("unexpected error string (-want +got):\n%s", diff)
+	}
+}
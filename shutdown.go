@@ -0,0 +1,91 @@
+package genetlink
+
+import (
+	"context"
+	"sync"
+)
+
+// Shutdown gracefully closes the Conn. Shutdown first stops the Conn from
+// sending new requests, as with CloseWrite, and then waits for requests
+// which are already in flight, such as Execute calls awaiting replies, to
+// complete before closing the Conn.
+//
+// If ctx is canceled or expires before the in-flight requests complete, the
+// Conn is closed immediately, unblocking those requests, and ctx.Err() is
+// returned. Otherwise, the result of Close is returned.
+//
+// Shutdown avoids racing Close against concurrent requests, which can
+// otherwise fail with errors such as EBADF.
+func (c *Conn) Shutdown(ctx context.Context) error {
+	c.reqs.closeWrite()
+
+	select {
+	case <-c.reqs.idle():
+		return c.Close()
+	case <-ctx.Done():
+		_ = c.Close()
+		return ctx.Err()
+	}
+}
+
+// A requestTracker counts in-flight requests, and rejects new requests once
+// closed for writing. The zero value is ready to use.
+type requestTracker struct {
+	mu     sync.Mutex
+	closed bool
+	n      int
+	idleC  chan struct{}
+}
+
+// begin starts a request, returning ErrWriteClosed if the tracker was
+// closed for writing. If begin succeeds, end must be called when the
+// request completes.
+func (t *requestTracker) begin() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ErrWriteClosed
+	}
+
+	t.n++
+	return nil
+}
+
+// end completes a request.
+func (t *requestTracker) end() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.n--
+	if t.n == 0 && t.idleC != nil {
+		close(t.idleC)
+		t.idleC = nil
+	}
+}
+
+// closeWrite rejects all future requests.
+func (t *requestTracker) closeWrite() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+}
+
+// idle returns a channel which is closed once no requests are in flight.
+func (t *requestTracker) idle() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.n == 0 {
+		c := make(chan struct{})
+		close(c)
+		return c
+	}
+
+	if t.idleC == nil {
+		t.idleC = make(chan struct{})
+	}
+
+	return t.idleC
+}
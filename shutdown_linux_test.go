@@ -0,0 +1,109 @@
+//go:build linux
+// +build linux
+
+package genetlink_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestConnShutdown(t *testing.T) {
+	reply := []genetlink.Message{{Header: genetlink.Header{Command: 1}}}
+	req := genetlink.Message{Header: genetlink.Header{Command: 1}}
+
+	t.Run("idle", func(t *testing.T) {
+		c := genltest.Dial(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			return reply, nil
+		})
+
+		if err := c.Shutdown(context.Background()); err != nil {
+			t.Fatalf("failed to shut down: %v", err)
+		}
+
+		if _, err := c.Execute(req, 0x20, netlink.Request); !errors.Is(err, genetlink.ErrWriteClosed) {
+			t.Fatalf("expected ErrWriteClosed, but got: %v", err)
+		}
+	})
+
+	t.Run("drain", func(t *testing.T) {
+		g := genltest.NewGate()
+		c := genltest.Dial(g.Block(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			return reply, nil
+		}))
+
+		type result struct {
+			msgs []genetlink.Message
+			err  error
+		}
+
+		resC := make(chan result, 1)
+		go func() {
+			msgs, err := c.Execute(req, 0x20, netlink.Request)
+			resC <- result{msgs, err}
+		}()
+
+		<-g.Waiting()
+
+		errC := make(chan error, 1)
+		go func() {
+			errC <- c.Shutdown(context.Background())
+		}()
+
+		// Shutdown must wait for the in-flight request.
+		select {
+		case err := <-errC:
+			t.Fatalf("shutdown returned early: %v", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		g.Release()
+
+		res := <-resC
+		if res.err != nil {
+			t.Fatalf("failed to execute: %v", res.err)
+		}
+		if diff := cmp.Diff(reply, res.msgs); diff != "" {
+			t.Fatalf("unexpected replies (-want +got):\n%s", diff)
+		}
+
+		if err := <-errC; err != nil {
+			t.Fatalf("failed to shut down: %v", err)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		g := genltest.NewGate()
+		c := genltest.Dial(g.Block(func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			return reply, nil
+		}))
+
+		errC := make(chan error, 1)
+		go func() {
+			_, err := c.Execute(req, 0x20, netlink.Request)
+			errC <- err
+		}()
+
+		<-g.Waiting()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, but got: %v", err)
+		}
+
+		// The in-flight request is unblocked by Close.
+		if err := <-errC; !errors.Is(err, os.ErrClosed) {
+			t.Fatalf("expected closed error, but got: %v", err)
+		}
+	})
+}
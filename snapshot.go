@@ -0,0 +1,89 @@
+package genetlink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// maxSnapshotDumps is the number of times Monitor.Snapshot dumps a family
+// whose dumps are interrupted before giving up.
+const maxSnapshotDumps = 8
+
+// A Snapshot is a coherent set of objects dumped from a family, along with
+// a live stream of the Events which follow the dump, created by
+// Monitor.Snapshot. It is the basis of nearly every cache of kernel state
+// which is maintained using generic netlink.
+type Snapshot struct {
+	// Messages are the replies to the dump, which describe the objects as
+	// of the snapshot point.
+	Messages []Message
+
+	// Subscription delivers the Events which follow the snapshot point.
+	*Subscription
+}
+
+// Snapshot dumps cmd of the named family using dc and subscribes to the
+// multicast group with the specified name, returning a Snapshot whose
+// Messages are a coherent set of objects and whose Subscription delivers
+// the Events which follow them, without any Events being lost between the
+// two. See DumpSubscribe for how Events which arrive during the dump are
+// handled; consumers can typically apply each Event to the state built
+// from the Messages in order.
+//
+// Dumps which the kernel reports as interrupted, because objects changed
+// while they were dumped, are retried so that the Messages are consistent,
+// and ErrDumpInterrupted is returned if they cannot be completed after
+// several attempts.
+//
+// ctx bounds both the dump and the lifetime of the Subscription: when ctx
+// is canceled, the Subscription is closed and ctx.Err() is reported by its
+// Errors channel. dc must be a Conn other than the Monitor's, and is also
+// used to resolve the family as with DumpSubscribe. The Subscription uses a
+// default SubscriptionConfig with a buffer large enough to hold the Events
+// which arrive during a typical dump.
+func (m *Monitor) Snapshot(ctx context.Context, dc *Conn, family string, cmd uint8, group string) (*Snapshot, error) {
+	if dc == m.c {
+		return nil, errMonitorConn
+	}
+
+	f, err := m.join(dc, family, group)
+	if err != nil {
+		return nil, err
+	}
+
+	s := m.newSubscription(&SubscriptionConfig{Buffer: 128}, &f.ID)
+
+	var msgs []Message
+	for i := 0; ; i++ {
+		// Events delivered so far were sent by the kernel before this dump,
+		// so the dump reflects them.
+		s.discardBuffered()
+
+		msgs, err = dc.DumpContext(ctx, cmd, f, nil)
+		if err == nil {
+			break
+		}
+
+		if !errors.Is(err, ErrDumpInterrupted) || i+1 == maxSnapshotDumps {
+			_ = s.Close()
+			return nil, fmt.Errorf("genetlink: failed to snapshot family %q: %w", family, err)
+		}
+	}
+
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				m.unsubscribe(s)
+				s.close(ctx.Err())
+			case <-s.done:
+			}
+		}()
+	}
+
+	return &Snapshot{
+		Messages:     msgs,
+		Subscription: s,
+	}, nil
+}
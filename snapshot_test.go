@@ -0,0 +1,139 @@
+package genetlink_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestMonitorSnapshot(t *testing.T) {
+	family := genetlink.Family{
+		ID:      0x20,
+		Version: 1,
+		Name:    "foo",
+		Groups:  []genetlink.MulticastGroup{{ID: 5, Name: "events"}},
+	}
+
+	var (
+		k     *genltest.Kernel
+		dumps int
+	)
+
+	dump := func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return []genetlink.Message{
+			{Header: genetlink.Header{Command: 1}, Data: []byte{1}},
+			{Header: genetlink.Header{Command: 1}, Data: []byte{2}},
+		}, nil
+	}
+
+	k = genltest.NewKernel(genltest.ServeFamily(family,
+		func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+			if nreq.Header.Flags&netlink.Dump == 0 {
+				return nil, genltest.ErrorNotExist()
+			}
+
+			dumps++
+			if dumps == 1 {
+				// The first dump is interrupted and must be retried.
+				return genltest.InterruptDump(0, dump)(greq, nreq)
+			}
+
+			event := genetlink.Message{Header: genetlink.Header{Command: 2}}
+			if _, err := k.Multicast(family.ID, 5, event); err != nil {
+				return nil, err
+			}
+
+			return dump(greq, nreq)
+		},
+	), nil)
+
+	ec, dc := k.Dial(), k.Dial()
+	defer dc.Close()
+
+	m := genetlink.NewMonitor(ec)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s, err := m.Snapshot(ctx, dc, "foo", 1, "events")
+	if err != nil {
+		t.Fatalf("failed to snapshot: %v", err)
+	}
+
+	if diff := cmp.Diff(2, dumps); diff != "" {
+		t.Fatalf("unexpected number of dumps (-want +got):\n%s", diff)
+	}
+
+	want := []genetlink.Message{
+		{Header: genetlink.Header{Command: 1}, Data: []byte{1}},
+		{Header: genetlink.Header{Command: 1}, Data: []byte{2}},
+	}
+
+	if diff := cmp.Diff(want, s.Messages); diff != "" {
+		t.Fatalf("unexpected snapshot (-want +got):\n%s", diff)
+	}
+
+	done := make(chan struct{})
+	defer func() {
+		_ = ec.Close()
+		<-done
+	}()
+
+	go func() {
+		defer close(done)
+		_ = m.Run(func(_ genetlink.Event) error { return nil })
+	}()
+
+	e, ok := <-s.Events()
+	if !ok {
+		t.Fatal("subscription closed before the replayed event")
+	}
+
+	if diff := cmp.Diff(uint8(2), e.Command); diff != "" {
+		t.Fatalf("unexpected event (-want +got):\n%s", diff)
+	}
+
+	// Canceling the context ends the stream of events.
+	cancel()
+
+	if err := <-s.Errors(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled error, but got: %v", err)
+	}
+
+	if _, ok := <-s.Events(); ok {
+		t.Fatal("expected events to be closed after cancelation")
+	}
+}
+
+func TestMonitorSnapshotInterrupted(t *testing.T) {
+	family := genetlink.Family{
+		ID:     0x20,
+		Name:   "foo",
+		Groups: []genetlink.MulticastGroup{{ID: 5, Name: "events"}},
+	}
+
+	k := genltest.NewKernel(genltest.ServeFamily(family, genltest.InterruptDump(0,
+		func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			return []genetlink.Message{{Header: genetlink.Header{Command: 1}}}, nil
+		},
+	)), nil)
+
+	ec, dc := k.Dial(), k.Dial()
+	defer ec.Close()
+	defer dc.Close()
+
+	m := genetlink.NewMonitor(ec)
+
+	s, err := m.Snapshot(context.Background(), dc, "foo", 1, "events")
+	if !errors.Is(err, genetlink.ErrDumpInterrupted) {
+		t.Fatalf("expected dump interrupted error, but got: %v", err)
+	}
+	if s != nil {
+		t.Fatal("expected no snapshot after a failed dump")
+	}
+}
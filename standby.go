@@ -0,0 +1,310 @@
+package genetlink
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/mdlayher/netlink"
+)
+
+// errStandbyClosed is returned when a Standby fails over after it is closed.
+var errStandbyClosed = errors.New("genetlink: standby is closed")
+
+// A StandbyGroup identifies a multicast group by the names of its family and
+// the group.
+type StandbyGroup struct {
+	Family, Group string
+}
+
+// A StandbyConfig configures a Standby.
+type StandbyConfig struct {
+	// Dial dials each Conn. If nil, Dial(nil) is used.
+	Dial func() (*Conn, error)
+
+	// Groups are the multicast groups which are joined by the primary Conn,
+	// and rejoined by each Conn which replaces it.
+	Groups []StandbyGroup
+
+	// Resync, if set, is invoked with the new primary Conn after a failover,
+	// once Groups have been rejoined. Events may have been missed while the
+	// previous primary was failing, so Resync typically dumps the state
+	// which is otherwise maintained from events. If Resync returns an
+	// error, it is returned by the operation which triggered the failover.
+	//
+	// Resync is invoked once the new primary is in use, so the operations
+	// of the Standby may run concurrently with it.
+	Resync func(c *Conn) error
+}
+
+// A Standby maintains a primary Conn and a warm standby Conn which has
+// already been dialed, and transparently fails over to the standby when the
+// primary dies, such as when its socket is closed or reports an
+// unrecoverable error. On failover, the multicast groups of the primary are
+// rejoined and a resync callback is invoked, so that control planes which
+// cannot tolerate event gaps can recover from socket failures without
+// redialing and rebuilding their state from scratch.
+//
+// A Standby is safe for concurrent use.
+type Standby struct {
+	cfg StandbyConfig
+
+	mu                 sync.Mutex
+	primary, secondary *Conn
+	failovers          int
+	closed             bool
+
+	// failing is closed when the failover in progress, if any, completes.
+	failing chan struct{}
+}
+
+// NewStandby dials a primary Conn which joins the multicast groups of cfg,
+// and a standby Conn.
+func NewStandby(cfg StandbyConfig) (*Standby, error) {
+	if cfg.Dial == nil {
+		cfg.Dial = func() (*Conn, error) { return Dial(nil) }
+	}
+
+	s := &Standby{cfg: cfg}
+
+	primary, err := s.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.join(primary); err != nil {
+		_ = primary.Close()
+		return nil, err
+	}
+
+	secondary, err := s.dial()
+	if err != nil {
+		_ = primary.Close()
+		return nil, err
+	}
+
+	s.primary, s.secondary = primary, secondary
+	return s, nil
+}
+
+// Conn returns the current primary Conn. The Conn may be replaced by a
+// failover, so callers should not retain it.
+func (s *Standby) Conn() *Conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.primary
+}
+
+// Failovers returns the number of failovers which have occurred.
+func (s *Standby) Failovers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failovers
+}
+
+// Execute executes a request using the primary Conn, as with Conn.Execute.
+// If the primary dies, Execute fails over to the standby. If the request
+// could not be sent, it is retried on the new primary; otherwise, the
+// request may or may not have been processed, and its error is returned.
+func (s *Standby) Execute(m Message, family uint16, flags netlink.HeaderFlags) ([]Message, error) {
+	c := s.Conn()
+	msgs, err := c.Execute(m, family, flags)
+	if err == nil || !isConnDead(err) {
+		return msgs, err
+	}
+
+	if ferr := s.failover(c); ferr != nil {
+		return nil, ferr
+	}
+
+	var oerr *netlink.OpError
+	if !errors.As(err, &oerr) || oerr.Op != "send" {
+		return nil, err
+	}
+
+	return s.Conn().Execute(m, family, flags)
+}
+
+// Receive receives messages using the primary Conn, as with Conn.Receive.
+// If the primary dies, Receive fails over to the standby and continues
+// receiving from the new primary.
+func (s *Standby) Receive() ([]Message, []netlink.Message, error) {
+	for {
+		c := s.Conn()
+		msgs, nmsgs, err := c.Receive()
+		if err == nil || !isConnDead(err) {
+			return msgs, nmsgs, err
+		}
+
+		if ferr := s.failover(c); ferr != nil {
+			return nil, nil, ferr
+		}
+	}
+}
+
+// Close closes the primary and standby Conns.
+func (s *Standby) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	err := s.primary.Close()
+	if s.secondary != nil {
+		if serr := s.secondary.Close(); err == nil {
+			err = serr
+		}
+	}
+
+	return err
+}
+
+// failover replaces the dead primary Conn c with the standby, unless c has
+// already been replaced by a concurrent failover. The replacement is
+// prepared without holding the lock, so that other operations are not
+// blocked by its I/O.
+func (s *Standby) failover(c *Conn) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return errStandbyClosed
+	}
+	if s.primary != c {
+		// Another operation already failed over.
+		s.mu.Unlock()
+		return nil
+	}
+	if failing := s.failing; failing != nil {
+		// Wait for the failover in progress, and try again if it failed.
+		s.mu.Unlock()
+		<-failing
+		return s.failover(c)
+	}
+
+	failing := make(chan struct{})
+	s.failing = failing
+
+	next := s.secondary
+	s.secondary = nil
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.failing = nil
+		close(failing)
+	}()
+
+	if next == nil {
+		// The previous attempt to replace the standby failed, so try again.
+		var err error
+		next, err = s.dial()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.join(next); err != nil {
+		_ = next.Close()
+		return err
+	}
+
+	// Prepare a new standby for the next failover. If dialing fails, the
+	// next failover dials instead.
+	secondary, err := s.dial()
+	if err != nil {
+		secondary = nil
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+
+		_ = next.Close()
+		if secondary != nil {
+			_ = secondary.Close()
+		}
+
+		return errStandbyClosed
+	}
+
+	s.primary, s.secondary = next, secondary
+	s.failovers++
+	s.mu.Unlock()
+
+	_ = c.Close()
+
+	if s.cfg.Resync != nil {
+		if err := s.cfg.Resync(next); err != nil {
+			return fmt.Errorf("genetlink: failed to resync after failover: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// dial dials a Conn using the configured Dial function.
+func (s *Standby) dial() (*Conn, error) {
+	c, err := s.cfg.Dial()
+	if err != nil {
+		return nil, fmt.Errorf("genetlink: failed to dial standby: %w", err)
+	}
+
+	return c, nil
+}
+
+// join joins the configured multicast groups using c.
+func (s *Standby) join(c *Conn) error {
+	for _, sg := range s.cfg.Groups {
+		f, err := c.GetFamily(sg.Family)
+		if err != nil {
+			return err
+		}
+
+		g, ok := f.group(sg.Group)
+		if !ok {
+			return fmt.Errorf("genetlink: family %q has no multicast group %q: %w",
+				sg.Family, sg.Group, os.ErrNotExist)
+		}
+
+		if err := c.JoinGroup(g.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isConnDead reports whether err indicates that a Conn's socket is closed or
+// has failed and can no longer be used.
+func isConnDead(err error) bool {
+	if errors.Is(err, os.ErrClosed) || errors.Is(err, net.ErrClosed) {
+		return true
+	}
+
+	var serr *os.SyscallError
+	if !errors.As(err, &serr) {
+		return false
+	}
+
+	for _, errno := range []syscall.Errno{
+		syscall.EBADF,
+		syscall.ENOTSOCK,
+		syscall.ENOTCONN,
+		syscall.ECONNRESET,
+		syscall.EPIPE,
+	} {
+		if errors.Is(serr.Err, errno) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,181 @@
+package genetlink_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestStandbyFailover(t *testing.T) {
+	family := genetlink.Family{
+		ID:      0x20,
+		Version: 1,
+		Name:    "foo",
+		Groups:  []genetlink.MulticastGroup{{ID: 5, Name: "events"}},
+	}
+
+	k := genltest.NewKernel(genltest.ServeFamily(family,
+		func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			return []genetlink.Message{greq}, nil
+		},
+	), nil)
+
+	var (
+		s       *genetlink.Standby
+		dials   int
+		resyncC = make(chan struct{}, 1)
+	)
+
+	s, err := genetlink.NewStandby(genetlink.StandbyConfig{
+		Dial: func() (*genetlink.Conn, error) {
+			dials++
+			return k.Dial(), nil
+		},
+		Groups: []genetlink.StandbyGroup{{Family: "foo", Group: "events"}},
+		Resync: func(c *genetlink.Conn) error {
+			// The new primary can be used during the resync.
+			if _, err := c.Execute(genetlink.Message{}, family.ID, netlink.Request); err != nil {
+				return err
+			}
+
+			// The Standby is not locked during the resync, and already uses
+			// the new primary.
+			connC := make(chan *genetlink.Conn, 1)
+			go func() { connC <- s.Conn() }()
+
+			select {
+			case pc := <-connC:
+				if pc != c {
+					return errors.New("resync before the new primary is in use")
+				}
+			case <-time.After(5 * time.Second):
+				return errors.New("standby is locked during resync")
+			}
+
+			resyncC <- struct{}{}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create standby: %v", err)
+	}
+	defer s.Close()
+
+	if diff := cmp.Diff(2, dials); diff != "" {
+		t.Fatalf("unexpected number of dials (-want +got):\n%s", diff)
+	}
+
+	event := genetlink.Message{Header: genetlink.Header{Command: 1}}
+	if _, err := k.Multicast(family.ID, 5, event); err != nil {
+		t.Fatalf("failed to multicast: %v", err)
+	}
+
+	msgs, _, err := s.Receive()
+	if err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+
+	// Kill the primary, and deliver another event once the new primary has
+	// rejoined the group and resynced.
+	_ = s.Conn().Close()
+	go func() {
+		<-resyncC
+		event.Header.Command = 2
+		_, _ = k.Multicast(family.ID, 5, event)
+	}()
+
+	more, _, err := s.Receive()
+	if err != nil {
+		t.Fatalf("failed to receive after failover: %v", err)
+	}
+	msgs = append(msgs, more...)
+
+	want := []genetlink.Message{
+		{Header: genetlink.Header{Command: 1}},
+		{Header: genetlink.Header{Command: 2}},
+	}
+
+	if diff := cmp.Diff(want, msgs); diff != "" {
+		t.Fatalf("unexpected messages (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(1, s.Failovers()); diff != "" {
+		t.Fatalf("unexpected number of failovers (-want +got):\n%s", diff)
+	}
+
+	// A new standby is dialed for the next failover.
+	if diff := cmp.Diff(3, dials); diff != "" {
+		t.Fatalf("unexpected number of dials (-want +got):\n%s", diff)
+	}
+}
+
+func TestStandbyExecuteRetry(t *testing.T) {
+	var (
+		dials int
+		tr    echoTransport
+	)
+
+	s, err := genetlink.NewStandby(genetlink.StandbyConfig{
+		Dial: func() (*genetlink.Conn, error) {
+			dials++
+			if dials == 1 {
+				return genetlink.NewTransportConn(&closedTransport{}), nil
+			}
+
+			return genetlink.NewTransportConn(&tr), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create standby: %v", err)
+	}
+	defer s.Close()
+
+	// The request cannot be sent on the closed primary, so it is retried
+	// on the standby.
+	m := genetlink.Message{Header: genetlink.Header{Command: 1}}
+	msgs, err := s.Execute(m, 0x20, netlink.Request)
+	if err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+
+	if diff := cmp.Diff([]genetlink.Message{m}, msgs); diff != "" {
+		t.Fatalf("unexpected messages (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(1, s.Failovers()); diff != "" {
+		t.Fatalf("unexpected number of failovers (-want +got):\n%s", diff)
+	}
+}
+
+func TestStandbyUnknownGroup(t *testing.T) {
+	family := genetlink.Family{ID: 0x20, Name: "foo"}
+
+	_, err := genetlink.NewStandby(genetlink.StandbyConfig{
+		Dial: func() (*genetlink.Conn, error) {
+			return genltest.Dial(genltest.ServeFamily(family, nil)), nil
+		},
+		Groups: []genetlink.StandbyGroup{{Family: "foo", Group: "events"}},
+	})
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not exist error, but got: %v", err)
+	}
+}
+
+// A closedTransport is a genetlink.Transport whose socket has been closed.
+type closedTransport struct {
+	echoTransport
+}
+
+func (*closedTransport) Send(_ netlink.Message) (netlink.Message, error) {
+	return netlink.Message{}, &netlink.OpError{Op: "send", Err: os.ErrClosed}
+}
+
+func (*closedTransport) Execute(_ netlink.Message) ([]netlink.Message, error) {
+	return nil, &netlink.OpError{Op: "send", Err: os.ErrClosed}
+}
@@ -0,0 +1,162 @@
+package genetlink
+
+import (
+	"sync/atomic"
+
+	"github.com/mdlayher/netlink"
+)
+
+// parts returns the partReceiver used to check replies against the response
+// limits as they arrive, if limits are set and the Transport supports it.
+func (c *Conn) parts() (partReceiver, bool) {
+	if c.limits == (ResponseLimits{}) {
+		return nil, false
+	}
+
+	switch t := c.c.(type) {
+	case partReceiver:
+		return t, true
+	case *netlink.Conn:
+		return socketParts(t)
+	default:
+		return nil, false
+	}
+}
+
+// stream sends a request and receives its replies one part at a time using
+// pr, checking them against the response limits as they arrive. When a limit
+// is exceeded, the remaining replies are read and discarded without being
+// retained, so that the socket is left ready for the next request.
+//
+// The caller must have exclusive use of the socket, because the parts are
+// read without the locking performed by the Transport.
+func (c *Conn) stream(pr partReceiver, nm netlink.Message) ([]netlink.Message, error) {
+	req, err := c.c.Send(nm)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		msgs []netlink.Message
+		n    int
+	)
+
+	for more := true; more; {
+		var part []netlink.Message
+		part, more, err = receivePart(pr)
+		if err != nil {
+			return nil, err
+		}
+
+		msgs = append(msgs, part...)
+		for _, m := range part {
+			n += nlmsgHeaderLen + len(m.Data)
+		}
+
+		if !c.limits.exceeded(len(msgs), n) {
+			continue
+		}
+
+		lerr := &ResponseLimitError{
+			Messages: len(msgs),
+			Bytes:    n,
+			Limits:   c.limits,
+		}
+
+		if more {
+			if err := discardParts(pr); err != nil {
+				if !isReceiveTimeout(err) {
+					return nil, err
+				}
+
+				// Leave the rest of the replies to be drained later.
+				c.abandon(req.Header)
+			}
+		}
+
+		return nil, lerr
+	}
+
+	if atomic.LoadInt32(&c.noValidate) == 0 {
+		if err := netlink.Validate(req, msgs); err != nil {
+			return nil, err
+		}
+	}
+
+	return msgs, nil
+}
+
+// discardParts reads and discards the remaining parts of a multi-part reply.
+func discardParts(pr partReceiver) error {
+	for {
+		_, more, err := receivePart(pr)
+		switch {
+		case isMessageError(err):
+			// An error message terminates the replies.
+			return nil
+		case err != nil:
+			return err
+		case !more:
+			return nil
+		}
+	}
+}
+
+// receivePart receives a single part of a reply using pr, and reports
+// whether more parts of a multi-part reply follow it.
+func receivePart(pr partReceiver) ([]netlink.Message, bool, error) {
+	raw, err := pr.ReceivePart()
+	if err != nil {
+		return nil, false, &netlink.OpError{
+			Op:  "receive",
+			Err: err,
+		}
+	}
+
+	// As with netlink.Conn, the last multi-part message of a read indicates
+	// whether more reads are needed.
+	var more bool
+	for _, m := range raw {
+		if m.Header.Flags&netlink.Multi != 0 {
+			more = m.Header.Type != netlink.Done
+		}
+	}
+
+	// Receive the part using a netlink.Conn so that error messages are
+	// converted to errors and the "multi-part done" message is trimmed
+	// exactly as they are for replies which are not streamed.
+	msgs, err := netlink.NewConn(&partSocket{msgs: raw}, 0).Receive()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return msgs, more, nil
+}
+
+// A partSocket is a netlink.Socket which returns a single part of a reply,
+// followed by a "multi-part done" message if the netlink.Conn reading from
+// it expects more parts.
+type partSocket struct {
+	msgs []netlink.Message
+	done bool
+}
+
+var _ netlink.Socket = &partSocket{}
+
+func (s *partSocket) Receive() ([]netlink.Message, error) {
+	if s.done {
+		return []netlink.Message{{
+			Header: netlink.Header{
+				Type:  netlink.Done,
+				Flags: netlink.Multi,
+			},
+		}}, nil
+	}
+
+	s.done = true
+	return s.msgs, nil
+}
+
+func (*partSocket) Close() error                           { return nil }
+func (*partSocket) Send(_ netlink.Message) error           { return nil }
+func (*partSocket) SendMessages(_ []netlink.Message) error { return nil }
@@ -0,0 +1,99 @@
+//go:build linux
+// +build linux
+
+package genetlink
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// socketParts returns a partReceiver which reads directly from the socket of
+// nc, or false if nc does not expose its socket, as is the case for a
+// netlink.Conn created with netlink.NewConn.
+func socketParts(nc *netlink.Conn) (partReceiver, bool) {
+	rc, err := nc.SyscallConn()
+	if err != nil {
+		return nil, false
+	}
+
+	return &socketReceiver{rc: rc}, true
+}
+
+// A socketReceiver receives the parts of a reply from a netlink socket.
+type socketReceiver struct {
+	rc syscall.RawConn
+}
+
+// ReceivePart reads the messages available from the socket using a single
+// read, growing the buffer as netlink.Conn does until they fit.
+func (r *socketReceiver) ReceivePart() ([]netlink.Message, error) {
+	b := make([]byte, os.Getpagesize())
+	for {
+		n, err := r.recv(b, unix.MSG_PEEK)
+		if err != nil {
+			return nil, err
+		}
+
+		if n < len(b) {
+			break
+		}
+
+		b = make([]byte, len(b)*2)
+	}
+
+	n, err := r.recv(b, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := syscall.ParseNetlinkMessage(b[:nlmsgAlign(n)])
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make([]netlink.Message, 0, len(raw))
+	for _, m := range raw {
+		msgs = append(msgs, netlink.Message{
+			Header: netlink.Header{
+				Length:   m.Header.Len,
+				Type:     netlink.HeaderType(m.Header.Type),
+				Flags:    netlink.HeaderFlags(m.Header.Flags),
+				Sequence: m.Header.Seq,
+				PID:      m.Header.Pid,
+			},
+			Data: m.Data,
+		})
+	}
+
+	return msgs, nil
+}
+
+// recv reads from the socket into b, waiting for data to become available
+// or for the socket's read deadline to expire.
+func (r *socketReceiver) recv(b []byte, flags int) (int, error) {
+	var (
+		n    int
+		rerr error
+	)
+	err := r.rc.Read(func(fd uintptr) bool {
+		n, _, rerr = unix.Recvfrom(int(fd), b, flags|unix.MSG_DONTWAIT)
+		return rerr != unix.EAGAIN
+	})
+	if err == nil {
+		err = rerr
+	}
+	if err != nil {
+		return 0, os.NewSyscallError("recvmsg", err)
+	}
+
+	return n, nil
+}
+
+// nlmsgAlign rounds n up to the alignment of netlink messages.
+func nlmsgAlign(n int) int {
+	return (n + unix.NLMSG_ALIGNTO - 1) & ^(unix.NLMSG_ALIGNTO - 1)
+}
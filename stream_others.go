@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package genetlink
+
+import "github.com/mdlayher/netlink"
+
+// socketParts always reports that the socket cannot be read directly.
+func socketParts(_ *netlink.Conn) (partReceiver, bool) {
+	return nil, false
+}
@@ -0,0 +1,385 @@
+package genetlink
+
+import (
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mdlayher/netlink"
+)
+
+// An OverflowPolicy determines how a Subscription handles an Event when its
+// buffer is full because the subscriber is not keeping up.
+type OverflowPolicy int
+
+// Possible OverflowPolicy values.
+const (
+	// Block waits for the subscriber to make room for the Event. A slow
+	// subscriber using Block delays delivery to all other subscribers and may
+	// cause the kernel to drop messages when the socket's receive buffer
+	// overflows.
+	Block OverflowPolicy = iota
+
+	// DropOldest discards the oldest buffered Event to make room for the new
+	// one.
+	DropOldest
+
+	// DropNewest discards the new Event.
+	DropNewest
+)
+
+// A SubscriptionConfig configures a Subscription. The zero value creates an
+// unbuffered Subscription which uses the Block policy.
+type SubscriptionConfig struct {
+	// Buffer is the number of Events which may be buffered for the
+	// subscriber. The DropOldest and DropNewest policies always buffer at
+	// least one Event, since an unbuffered Subscription could only deliver
+	// Events to a subscriber which is already waiting.
+	Buffer int
+
+	// Policy determines the behavior when the buffer is full.
+	Policy OverflowPolicy
+
+	// Dedup, if set, suppresses duplicate Events.
+	Dedup *DedupConfig
+}
+
+// A DedupConfig configures suppression of duplicate Events by a
+// Subscription, for families which emit redundant notifications under churn.
+//
+// Two Events are duplicates if they have the same family ID and command, and
+// the same values for each of the selected top-level attributes. An Event is
+// suppressed if a duplicate was delivered less than Window ago.
+type DedupConfig struct {
+	// Window is the period during which duplicates of a delivered Event are
+	// suppressed.
+	Window time.Duration
+
+	// Attributes are the types of the attributes whose values identify an
+	// Event, in addition to its family ID and command. If empty, all Events
+	// with the same family ID and command are duplicates.
+	Attributes []uint16
+}
+
+// A Subscription delivers the Events received by a Monitor on a channel.
+type Subscription struct {
+	// Accessed atomically; must remain 64-bit aligned.
+	dropped, suppressed uint64
+
+	m      *Monitor
+	family *uint16
+	policy OverflowPolicy
+	dedup  *dedup
+
+	closeOnce sync.Once
+	done      chan struct{}
+
+	mu     sync.Mutex
+	c      chan Event
+	errC   chan error
+	closed bool
+}
+
+// NewSubscription creates a Subscription which receives every Event handled
+// by m.Run. If cfg is nil, a default configuration is used.
+//
+// The Subscription's channel is closed when the Subscription is closed, or
+// when m.Run returns.
+func (m *Monitor) NewSubscription(cfg *SubscriptionConfig) *Subscription {
+	return m.newSubscription(cfg, nil)
+}
+
+// SubscribeGroup joins the multicast group with the specified name of the
+// named family, and creates a Subscription which receives only the Events of
+// that family. Each Subscription uses its own cfg, so that consumers with
+// different latency and loss tradeoffs can share a Monitor. If cfg is nil, a
+// default configuration is used.
+//
+// Netlink does not report the multicast group of each message, so if several
+// groups of the same family are joined, the Subscription receives Events
+// from all of them.
+func (m *Monitor) SubscribeGroup(family, group string, cfg *SubscriptionConfig) (*Subscription, error) {
+	f, err := m.join(nil, family, group)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.newSubscription(cfg, &f.ID), nil
+}
+
+// newSubscription creates a Subscription which receives Events of the
+// specified family ID, or all Events if family is nil.
+func (m *Monitor) newSubscription(cfg *SubscriptionConfig, family *uint16) *Subscription {
+	if cfg == nil {
+		cfg = &SubscriptionConfig{}
+	}
+
+	buffer := cfg.Buffer
+	if cfg.Policy != Block && buffer < 1 {
+		buffer = 1
+	}
+
+	s := &Subscription{
+		m:      m,
+		family: family,
+		policy: cfg.Policy,
+		dedup:  newDedup(cfg.Dedup),
+		done:   make(chan struct{}),
+		c:      make(chan Event, buffer),
+		errC:   make(chan error, 1),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.subs = append(m.subs, s)
+	return s
+}
+
+// Events returns the channel on which Events are delivered.
+func (s *Subscription) Events() <-chan Event { return s.c }
+
+// Errors returns a channel which receives the error which stopped the
+// Monitor, if any, such as a failed health check. The channel is closed
+// along with the channel returned by Events.
+func (s *Subscription) Errors() <-chan error { return s.errC }
+
+// Dropped returns the number of Events discarded by the Subscription's
+// OverflowPolicy.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Suppressed returns the number of Events discarded as duplicates by the
+// Subscription's DedupConfig.
+func (s *Subscription) Suppressed() uint64 {
+	return atomic.LoadUint64(&s.suppressed)
+}
+
+// Close stops delivery of Events to the Subscription and closes its channel.
+func (s *Subscription) Close() error {
+	s.m.unsubscribe(s)
+	s.close(nil)
+	return nil
+}
+
+// close unblocks any pending delivery and closes the Subscription's
+// channels, first reporting err if it is not nil.
+func (s *Subscription) close(err error) {
+	s.closeOnce.Do(func() { close(s.done) })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+	if err != nil {
+		s.errC <- err
+	}
+	close(s.errC)
+	close(s.c)
+}
+
+// deliver delivers e according to the Subscription's OverflowPolicy.
+func (s *Subscription) deliver(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	// Gaps apply to every family and must not be discarded.
+	policy := s.policy
+	if e.Gap == nil {
+		if s.family != nil && *s.family != uint16(e.Header.Type) {
+			return
+		}
+
+		if s.dedup != nil && s.dedup.duplicate(e, s.m.c.now()) {
+			atomic.AddUint64(&s.suppressed, 1)
+			return
+		}
+	} else if policy == DropNewest {
+		policy = DropOldest
+	}
+
+	switch policy {
+	case DropOldest:
+		for {
+			select {
+			case s.c <- e:
+				return
+			default:
+			}
+
+			select {
+			case <-s.c:
+				atomic.AddUint64(&s.dropped, 1)
+			default:
+			}
+		}
+	case DropNewest:
+		select {
+		case s.c <- e:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	default:
+		select {
+		case s.c <- e:
+		case <-s.done:
+		}
+	}
+}
+
+// A dedup tracks recently delivered Events to detect duplicates.
+type dedup struct {
+	cfg  DedupConfig
+	seen map[string]time.Time
+
+	// order holds the keys of seen in the order they were recorded, which
+	// is also the order in which they expire, starting at index head.
+	order []dedupEntry
+	head  int
+}
+
+// A dedupEntry is a key recorded by a dedup at a point in time.
+type dedupEntry struct {
+	key string
+	t   time.Time
+}
+
+// newDedup creates a dedup from cfg, or returns nil if cfg is nil.
+func newDedup(cfg *DedupConfig) *dedup {
+	if cfg == nil {
+		return nil
+	}
+
+	return &dedup{
+		cfg:  *cfg,
+		seen: make(map[string]time.Time),
+	}
+}
+
+// duplicate reports whether e is a duplicate of an Event delivered within
+// the window before now, and otherwise records e as delivered at now.
+func (d *dedup) duplicate(e Event, now time.Time) bool {
+	k, ok := d.key(e)
+	if !ok {
+		// Events which cannot be identified are always delivered.
+		return false
+	}
+
+	d.expire(now)
+
+	if _, ok := d.seen[k]; ok {
+		return true
+	}
+
+	d.seen[k] = now
+	d.order = append(d.order, dedupEntry{key: k, t: now})
+	return false
+}
+
+// expire forgets the keys recorded a full window or more before now. Keys
+// are recorded in time order, so only the oldest keys need be checked.
+func (d *dedup) expire(now time.Time) {
+	for d.head < len(d.order) && now.Sub(d.order[d.head].t) >= d.cfg.Window {
+		delete(d.seen, d.order[d.head].key)
+		d.order[d.head] = dedupEntry{}
+		d.head++
+	}
+
+	// Reclaim the space of expired keys once they make up half of order, so
+	// the cost of compaction is amortized across events.
+	if d.head > 0 && d.head >= len(d.order)/2 {
+		d.order = d.order[:copy(d.order, d.order[d.head:])]
+		d.head = 0
+	}
+}
+
+// key computes the identity of e, reporting false if its attributes cannot
+// be decoded.
+func (d *dedup) key(e Event) (string, bool) {
+	b := make([]byte, 3)
+	binary.BigEndian.PutUint16(b, uint16(e.Header.Type))
+	b[2] = e.Command
+
+	if len(d.cfg.Attributes) == 0 {
+		return string(b), true
+	}
+
+	values := make(map[uint16][]byte, len(d.cfg.Attributes))
+	ad, err := netlink.NewAttributeDecoder(e.Message.Data)
+	if err != nil {
+		return "", false
+	}
+	for ad.Next() {
+		if _, ok := values[ad.Type()]; !ok {
+			values[ad.Type()] = ad.Bytes()
+		}
+	}
+	if err := ad.Err(); err != nil {
+		return "", false
+	}
+
+	for _, typ := range d.cfg.Attributes {
+		v, ok := values[typ]
+
+		// Prefix each value with a presence marker and its length so that
+		// the key is unambiguous.
+		var hdr [7]byte
+		binary.BigEndian.PutUint16(hdr[:2], typ)
+		if ok {
+			hdr[2] = 1
+		}
+		binary.BigEndian.PutUint32(hdr[3:], uint32(len(v)))
+
+		b = append(b, hdr[:]...)
+		b = append(b, v...)
+	}
+
+	return string(b), true
+}
+
+// subscriptions returns the Monitor's current Subscriptions.
+func (m *Monitor) subscriptions() []*Subscription {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*Subscription(nil), m.subs...)
+}
+
+// unsubscribe removes s from the Monitor's Subscriptions.
+func (m *Monitor) unsubscribe(s *Subscription) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, sub := range m.subs {
+		if sub == s {
+			m.subs = append(m.subs[:i], m.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// discardBuffered discards the Events which are buffered for the subscriber,
+// or which are waiting to be delivered to it by a Block policy. s.mu is not
+// held, since a blocked delivery holds it.
+func (s *Subscription) discardBuffered() {
+	for {
+		select {
+		case _, ok := <-s.c:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,294 @@
+//go:build linux
+// +build linux
+
+package genetlink_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestMonitorSubscriptions(t *testing.T) {
+	multicast := genltest.Respond(func(_ genetlink.Message, nreq netlink.Message) ([]genltest.Response, error) {
+		if nreq.Header.Type != 0 {
+			// Not a multicast interaction.
+			return nil, nil
+		}
+
+		var res []genltest.Response
+		for _, cmd := range []uint8{1, 2, 3} {
+			res = append(res, genltest.Response{
+				Header: netlink.Header{Type: 0x15},
+				Message: genetlink.Message{
+					Header: genetlink.Header{Command: cmd},
+				},
+			})
+		}
+
+		return res, nil
+	})
+
+	c := genltest.Dial(genltest.ServeFamilies(genltest.Families(), multicast))
+	defer c.Close()
+
+	m := genetlink.NewMonitor(c)
+
+	tests := []struct {
+		name     string
+		cfg      *genetlink.SubscriptionConfig
+		commands []uint8
+		dropped  uint64
+	}{
+		{
+			name:     "block",
+			cfg:      &genetlink.SubscriptionConfig{Buffer: 3},
+			commands: []uint8{1, 2, 3},
+		},
+		{
+			name: "drop oldest",
+			cfg: &genetlink.SubscriptionConfig{
+				Buffer: 1,
+				Policy: genetlink.DropOldest,
+			},
+			commands: []uint8{3},
+			dropped:  2,
+		},
+		{
+			name: "drop newest",
+			cfg: &genetlink.SubscriptionConfig{
+				Buffer: 1,
+				Policy: genetlink.DropNewest,
+			},
+			commands: []uint8{1},
+			dropped:  2,
+		},
+		{
+			// A drop policy buffers at least one Event.
+			name:     "drop oldest unbuffered",
+			cfg:      &genetlink.SubscriptionConfig{Policy: genetlink.DropOldest},
+			commands: []uint8{3},
+			dropped:  2,
+		},
+		{
+			name:     "drop newest unbuffered",
+			cfg:      &genetlink.SubscriptionConfig{Policy: genetlink.DropNewest},
+			commands: []uint8{1},
+			dropped:  2,
+		},
+	}
+
+	subs := make([]*genetlink.Subscription, 0, len(tests))
+	for _, tt := range tests {
+		subs = append(subs, m.NewSubscription(tt.cfg))
+	}
+
+	// A closed Subscription receives no further Events.
+	closed := m.NewSubscription(nil)
+	if err := closed.Close(); err != nil {
+		t.Fatalf("failed to close subscription: %v", err)
+	}
+
+	errStop := errors.New("stop")
+
+	var n int
+	err := m.Run(func(_ genetlink.Event) error {
+		n++
+		if n == 3 {
+			return errStop
+		}
+
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := subs[i]
+
+			// Run closes all subscriptions on return, so the channel can be
+			// drained completely.
+			var commands []uint8
+			for e := range s.Events() {
+				commands = append(commands, e.Command)
+			}
+
+			if diff := cmp.Diff(tt.commands, commands); diff != "" {
+				t.Fatalf("unexpected commands (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.dropped, s.Dropped()); diff != "" {
+				t.Fatalf("unexpected dropped count (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, ok := <-closed.Events(); ok {
+		t.Fatal("expected closed subscription to receive no events")
+	}
+}
+
+func TestMonitorSubscribeGroup(t *testing.T) {
+	event := func(family uint16, command uint8) genltest.Response {
+		return genltest.Response{
+			Header: netlink.Header{Type: netlink.HeaderType(family)},
+			Message: genetlink.Message{
+				Header: genetlink.Header{Command: command},
+			},
+		}
+	}
+
+	multicast := genltest.Respond(func(_ genetlink.Message, nreq netlink.Message) ([]genltest.Response, error) {
+		if nreq.Header.Type != 0 {
+			// Not a multicast interaction.
+			return nil, nil
+		}
+
+		return []genltest.Response{
+			event(0x15, 1),
+			event(0x20, 2),
+			event(0x15, 3),
+		}, nil
+	})
+
+	c := genltest.Dial(genltest.ServeFamilies(genltest.Families(), multicast))
+	defer c.Close()
+
+	m := genetlink.NewMonitor(c)
+
+	ethtool, err := m.SubscribeGroup("ethtool", "monitor", &genetlink.SubscriptionConfig{
+		Buffer: 1,
+		Policy: genetlink.DropNewest,
+	})
+	if err != nil {
+		t.Fatalf("failed to subscribe to ethtool: %v", err)
+	}
+
+	nl80211, err := m.SubscribeGroup("nl80211", "config", &genetlink.SubscriptionConfig{
+		Buffer: 1,
+	})
+	if err != nil {
+		t.Fatalf("failed to subscribe to nl80211: %v", err)
+	}
+
+	if _, err := m.SubscribeGroup("nl80211", "foo", nil); err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+
+	errStop := errors.New("stop")
+
+	var n int
+	err = m.Run(func(_ genetlink.Event) error {
+		n++
+		if n == 3 {
+			return errStop
+		}
+
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		s        *genetlink.Subscription
+		commands []uint8
+		dropped  uint64
+	}{
+		{
+			name:     "ethtool",
+			s:        ethtool,
+			commands: []uint8{1},
+			dropped:  1,
+		},
+		{
+			name:     "nl80211",
+			s:        nl80211,
+			commands: []uint8{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var commands []uint8
+			for e := range tt.s.Events() {
+				commands = append(commands, e.Command)
+			}
+
+			if diff := cmp.Diff(tt.commands, commands); diff != "" {
+				t.Fatalf("unexpected commands (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.dropped, tt.s.Dropped()); diff != "" {
+				t.Fatalf("unexpected dropped count (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMonitorSubscribeGroupRunning(t *testing.T) {
+	family := genetlink.Family{
+		ID:      0x20,
+		Version: 1,
+		Name:    "foo",
+		Groups:  []genetlink.MulticastGroup{{ID: 5, Name: "events"}},
+	}
+
+	k := genltest.NewKernel(genltest.ServeFamily(family,
+		func(_ genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+			return nil, genltest.ErrorNotExist()
+		},
+	), nil)
+
+	c := k.Dial()
+	m := genetlink.NewMonitor(c)
+
+	runC := make(chan error, 1)
+	go func() { runC <- m.Run(nil) }()
+
+	// Give Run time to begin receiving using the Monitor's Conn, after which
+	// the family must be resolved without it.
+	time.Sleep(20 * time.Millisecond)
+	type result struct {
+		s   *genetlink.Subscription
+		err error
+	}
+
+	resC := make(chan result, 1)
+	go func() {
+		s, err := m.SubscribeGroup("foo", "events", &genetlink.SubscriptionConfig{Buffer: 1})
+		resC <- result{s, err}
+	}()
+
+	var s *genetlink.Subscription
+	select {
+	case r := <-resC:
+		if r.err != nil {
+			t.Fatalf("failed to subscribe: %v", r.err)
+		}
+		s = r.s
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out subscribing while Run is active")
+	}
+
+	event := genetlink.Message{Header: genetlink.Header{Command: 2}}
+	if _, err := k.Multicast(family.ID, 5, event); err != nil {
+		t.Fatalf("failed to multicast: %v", err)
+	}
+
+	e := <-s.Events()
+	if diff := cmp.Diff(uint8(2), e.Command); diff != "" {
+		t.Fatalf("unexpected event (-want +got):\n%s", diff)
+	}
+
+	_ = c.Close()
+	<-runC
+}
@@ -0,0 +1,125 @@
+package genetlink
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mdlayher/netlink"
+)
+
+func TestDedupDuplicate(t *testing.T) {
+	event := func(family uint16, command uint8, attrs ...netlink.Attribute) Event {
+		b, err := netlink.MarshalAttributes(attrs)
+		if err != nil {
+			t.Fatalf("failed to marshal attributes: %v", err)
+		}
+
+		return Event{
+			Command: command,
+			Header:  netlink.Header{Type: netlink.HeaderType(family)},
+			Message: Message{
+				Header: Header{Command: command},
+				Data:   b,
+			},
+		}
+	}
+
+	var (
+		ifindex1 = netlink.Attribute{Type: 1, Data: []byte{1, 0, 0, 0}}
+		ifindex2 = netlink.Attribute{Type: 1, Data: []byte{2, 0, 0, 0}}
+		other    = netlink.Attribute{Type: 2, Data: []byte{0xff}}
+	)
+
+	base := time.Unix(0, 0)
+
+	tests := []struct {
+		name   string
+		attrs  []uint16
+		events []Event
+		times  []time.Duration
+		want   []bool
+	}{
+		{
+			name: "command only",
+			events: []Event{
+				event(0x20, 1, ifindex1),
+				event(0x20, 1, ifindex2),
+				event(0x20, 2, ifindex1),
+				event(0x21, 1, ifindex1),
+			},
+			times: []time.Duration{0, 0, 0, 0},
+			want:  []bool{false, true, false, false},
+		},
+		{
+			name:  "attributes",
+			attrs: []uint16{1},
+			events: []Event{
+				event(0x20, 1, ifindex1),
+				event(0x20, 1, ifindex1, other),
+				event(0x20, 1, ifindex2),
+				event(0x20, 1),
+				event(0x20, 1),
+			},
+			times: []time.Duration{0, 0, 0, 0, 0},
+			want:  []bool{false, true, false, false, true},
+		},
+		{
+			name: "window",
+			events: []Event{
+				event(0x20, 1),
+				event(0x20, 1),
+				event(0x20, 1),
+				event(0x20, 1),
+			},
+			times: []time.Duration{0, 500 * time.Millisecond, time.Second, 1500 * time.Millisecond},
+			want:  []bool{false, true, false, true},
+		},
+		{
+			name:  "undecodable",
+			attrs: []uint16{1},
+			events: []Event{
+				{Message: Message{Data: []byte{0xff}}},
+				{Message: Message{Data: []byte{0xff}}},
+			},
+			times: []time.Duration{0, 0},
+			want:  []bool{false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDedup(&DedupConfig{
+				Window:     time.Second,
+				Attributes: tt.attrs,
+			})
+
+			for i, e := range tt.events {
+				if got := d.duplicate(e, base.Add(tt.times[i])); got != tt.want[i] {
+					t.Fatalf("unexpected duplicate result for event %d: want %v, got %v",
+						i, tt.want[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestDedupExpire(t *testing.T) {
+	d := newDedup(&DedupConfig{Window: time.Second})
+
+	base := time.Unix(0, 0)
+	for i := 0; i < 100; i++ {
+		e := Event{Header: netlink.Header{Type: netlink.HeaderType(i)}}
+		if d.duplicate(e, base.Add(time.Duration(i)*time.Millisecond)) {
+			t.Fatalf("unexpected duplicate for event %d", i)
+		}
+	}
+
+	// Every earlier key has expired by the time of this event.
+	if d.duplicate(Event{}, base.Add(2*time.Second)) {
+		t.Fatal("unexpected duplicate for final event")
+	}
+
+	if l, q := len(d.seen), len(d.order)-d.head; l != 1 || q != 1 {
+		t.Fatalf("unexpected retained keys: seen %d, queued %d", l, q)
+	}
+}
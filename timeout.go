@@ -0,0 +1,73 @@
+package genetlink
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/mdlayher/netlink"
+)
+
+// ExecuteTimeout is like Execute, but fails with an error wrapping
+// os.ErrDeadlineExceeded if the request cannot be sent or its replies
+// received within timeout.
+//
+// The timeout is applied using the Conn's deadline rather than a goroutine
+// which waits on a timer, so no goroutine or buffer is left behind when a
+// request times out, and ExecuteTimeout is suitable for servers which issue
+// many requests under load. The deadline is applied only while the request
+// and its replies are exchanged, during which other operations on the Conn
+// wait rather than being interrupted, and any deadline set using
+// SetDeadline is restored before ExecuteTimeout returns.
+//
+// If a request times out, the kernel may still reply to it later. See Drain
+// for how such replies are discarded.
+func (c *Conn) ExecuteTimeout(timeout time.Duration, m Message, family uint16, flags netlink.HeaderFlags) ([]Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	release, err := c.sched.acquire(ctx, defaultPriority(flags))
+	if err != nil {
+		// The timeout expired while waiting for the scheduler.
+		return nil, os.ErrDeadlineExceeded
+	}
+	defer release()
+
+	msgs, err := c.execute(ctx, m, family, flags)
+	if errors.Is(err, context.DeadlineExceeded) {
+		// The timeout expired while waiting for other operations to finish.
+		return nil, os.ErrDeadlineExceeded
+	}
+
+	return msgs, err
+}
+
+// ExecuteContext is like Execute, but returns ctx.Err() if ctx is canceled
+// or its deadline expires before the replies are received.
+//
+// The context's deadline is applied using the Conn's deadline, and
+// cancelation interrupts the receive by moving the deadline into the past.
+// As with ExecuteTimeout, the deadline is applied only while the request and
+// its replies are exchanged, and does not affect other operations on the
+// Conn. A context which can never be canceled, such as context.Background,
+// requires no goroutine. Otherwise, a single goroutine watches for
+// cancelation and exits before ExecuteContext returns, so none is leaked.
+func (c *Conn) ExecuteContext(ctx context.Context, m Message, family uint16, flags netlink.HeaderFlags) ([]Message, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	release, err := c.sched.acquire(ctx, defaultPriority(flags))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	msgs, err := c.execute(ctx, m, family, flags)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	return msgs, nil
+}
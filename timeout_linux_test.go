@@ -0,0 +1,247 @@
+//go:build linux
+// +build linux
+
+package genetlink_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/genetlink/genltest"
+	"github.com/mdlayher/netlink"
+)
+
+func TestConnExecuteTimeout(t *testing.T) {
+	req := genetlink.Message{Header: genetlink.Header{Command: 1}}
+	echo := func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return []genetlink.Message{{Header: greq.Header}}, nil
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		c := genltest.Dial(echo)
+		defer c.Close()
+
+		msgs, err := c.ExecuteTimeout(time.Second, req, 0x20, netlink.Request)
+		if err := checkCommand(msgs, err, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		g := genltest.NewGate()
+		c := genltest.Dial(g.Block(echo))
+		defer c.Close()
+
+		_, err := c.ExecuteTimeout(10*time.Millisecond, req, 0x20, netlink.Request)
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, but got: %v", err)
+		}
+
+		// The deadline is cleared, so a subsequent receive blocks until the
+		// late reply is released.
+		g.Release()
+		msgs, _, err := c.Receive()
+		if err := checkCommand(msgs, err, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		g := genltest.NewGate()
+		slow := g.Block(echo)
+		c := genltest.Dial(func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+			if greq.Header.Command == 2 {
+				return slow(greq, nreq)
+			}
+
+			return echo(greq, nreq)
+		})
+		defer c.Close()
+
+		errC := make(chan error, 1)
+		go func() {
+			msgs, err := c.Execute(genetlink.Message{Header: genetlink.Header{Command: 2}}, 0x20, netlink.Request)
+			errC <- checkCommand(msgs, err, 2)
+		}()
+
+		// The timeout expires while the slow request is in progress, but
+		// must not interrupt it.
+		<-g.Waiting()
+		_, err := c.ExecuteTimeout(10*time.Millisecond, req, 0x20, netlink.Request)
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, but got: %v", err)
+		}
+
+		g.Release()
+		if err := <-errC; err != nil {
+			t.Fatalf("unexpected error from concurrent Execute: %v", err)
+		}
+	})
+
+	t.Run("scheduled", func(t *testing.T) {
+		g := genltest.NewGate()
+		slow := g.Block(echo)
+		c := genltest.Dial(func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+			if nreq.Header.Flags&netlink.Dump != 0 {
+				return slow(greq, nreq)
+			}
+
+			return echo(greq, nreq)
+		})
+		defer c.Close()
+
+		c.SetPriorityScheduling(true)
+
+		errC := make(chan error, 1)
+		go func() {
+			msgs, err := c.Dump(2, genetlink.Family{ID: 0x20, Version: 1}, nil)
+			errC <- checkCommand(msgs, err, 2)
+		}()
+
+		// The timeout expires while waiting behind the dump, which holds the
+		// scheduler, and must still be honored.
+		<-g.Waiting()
+
+		timeoutC := make(chan error, 1)
+		go func() {
+			_, err := c.ExecuteTimeout(10*time.Millisecond, req, 0x20, netlink.Request)
+			timeoutC <- err
+		}()
+
+		select {
+		case err := <-timeoutC:
+			if !errors.Is(err, os.ErrDeadlineExceeded) {
+				t.Fatalf("expected deadline exceeded, but got: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("ExecuteTimeout did not return while a dump was in progress")
+		}
+
+		g.Release()
+		if err := <-errC; err != nil {
+			t.Fatalf("unexpected error from concurrent Dump: %v", err)
+		}
+	})
+
+	t.Run("restore deadline", func(t *testing.T) {
+		g := genltest.NewGate()
+		slow := g.Block(echo)
+		c := genltest.Dial(func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+			if greq.Header.Command == 2 {
+				return slow(greq, nreq)
+			}
+
+			return echo(greq, nreq)
+		})
+		defer c.Close()
+
+		if err := c.SetReadDeadline(time.Now().Add(50 * time.Millisecond)); err != nil {
+			t.Fatalf("failed to set read deadline: %v", err)
+		}
+
+		msgs, err := c.ExecuteTimeout(time.Second, req, 0x20, netlink.Request)
+		if err := checkCommand(msgs, err, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// The caller's deadline is restored, so a receive of a reply which
+		// is held back times out rather than blocking.
+		if _, err := c.Send(genetlink.Message{Header: genetlink.Header{Command: 2}}, 0x20, netlink.Request); err != nil {
+			t.Fatalf("failed to send: %v", err)
+		}
+
+		_, _, err = c.Receive()
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, but got: %v", err)
+		}
+	})
+}
+
+func TestConnExecuteContext(t *testing.T) {
+	req := genetlink.Message{Header: genetlink.Header{Command: 1}}
+	echo := func(greq genetlink.Message, _ netlink.Message) ([]genetlink.Message, error) {
+		return []genetlink.Message{{Header: greq.Header}}, nil
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		c := genltest.Dial(echo)
+		defer c.Close()
+
+		msgs, err := c.ExecuteContext(context.Background(), req, 0x20, netlink.Request)
+		if err := checkCommand(msgs, err, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		g := genltest.NewGate()
+		c := genltest.Dial(g.Block(echo))
+		defer c.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if _, err := c.ExecuteContext(ctx, req, 0x20, netlink.Request); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, but got: %v", err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		g := genltest.NewGate()
+		c := genltest.Dial(g.Block(echo))
+		defer c.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-g.Waiting()
+			cancel()
+		}()
+
+		if _, err := c.ExecuteContext(ctx, req, 0x20, netlink.Request); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected canceled, but got: %v", err)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		g := genltest.NewGate()
+		slow := g.Block(echo)
+		c := genltest.Dial(func(greq genetlink.Message, nreq netlink.Message) ([]genetlink.Message, error) {
+			if greq.Header.Command == 2 {
+				return slow(greq, nreq)
+			}
+
+			return echo(greq, nreq)
+		})
+		defer c.Close()
+
+		errC := make(chan error, 1)
+		go func() {
+			msgs, err := c.Execute(genetlink.Message{Header: genetlink.Header{Command: 2}}, 0x20, netlink.Request)
+			errC <- checkCommand(msgs, err, 2)
+		}()
+
+		// Canceling a request must not interrupt the slow request in
+		// progress in another goroutine.
+		<-g.Waiting()
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		if _, err := c.ExecuteContext(ctx, req, 0x20, netlink.Request); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected canceled, but got: %v", err)
+		}
+
+		g.Release()
+		if err := <-errC; err != nil {
+			t.Fatalf("unexpected error from concurrent Execute: %v", err)
+		}
+
+		msgs, err := c.ExecuteContext(context.Background(), req, 0x20, netlink.Request)
+		if err := checkCommand(msgs, err, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package genetlink
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mdlayher/netlink"
+	"golang.org/x/net/bpf"
+)
+
+// A Transport sends and receives netlink messages on behalf of a Conn. The
+// default Transport is a *netlink.Conn, but alternative backends such as
+// in-memory fakes, remote proxies, or recorded replays can be used by
+// creating a Conn with NewTransportConn.
+//
+// A Transport must be safe for concurrent use. Execute must send a request
+// and receive its replies atomically with respect to concurrent calls to
+// Send, SendMessages, and Receive, and must validate the replies against the
+// request, as netlink.Conn.Execute does.
+//
+// A Transport may optionally implement the following methods of
+// *netlink.Conn, which are used by the Conn methods of the same names:
+//
+//	JoinGroup(group uint32) error
+//	LeaveGroup(group uint32) error
+//	SetBPF(filter []bpf.RawInstruction) error
+//	RemoveBPF() error
+//	SetOption(option netlink.ConnOption, enable bool) error
+//	SetReadBuffer(bytes int) error
+//	SetWriteBuffer(bytes int) error
+//	SyscallConn() (syscall.RawConn, error)
+//	SetDeadline(t time.Time) error
+//	SetReadDeadline(t time.Time) error
+//	SetWriteDeadline(t time.Time) error
+//
+// When a Transport does not implement a method, the Conn method returns an
+// error which wraps ErrNotSupported.
+//
+// A Transport may also implement the following method, which returns the
+// messages read from the socket by a single read, as netlink.Socket.Receive
+// does, without waiting for the remaining parts of a multi-part reply:
+//
+//	ReceivePart() ([]netlink.Message, error)
+//
+// ReceivePart allows the Conn to check replies against the limits set using
+// Conn.SetResponseLimits as they arrive. The socket of a *netlink.Conn
+// created by netlink.Dial is read directly for the same purpose.
+//
+// A Transport may also implement the following method, which creates another
+// Transport to the same backend with a socket of its own:
+//
+//	Dial() (Transport, error)
+//
+// Dial allows types such as Monitor, which receive multicast messages using
+// a Conn, to make requests without sharing the Conn's socket. A Conn created
+// by Dial can create such Transports without it.
+type Transport interface {
+	Close() error
+	Send(m netlink.Message) (netlink.Message, error)
+	SendMessages(msgs []netlink.Message) ([]netlink.Message, error)
+	Receive() ([]netlink.Message, error)
+	Execute(m netlink.Message) ([]netlink.Message, error)
+}
+
+var _ Transport = &netlink.Conn{}
+
+// NewTransportConn creates a Conn which uses t to send and receive netlink
+// messages.
+func NewTransportConn(t Transport) *Conn {
+	return &Conn{
+		c: t,
+		d: newDebugger(debugArgs),
+	}
+}
+
+// Optional Transport capabilities.
+type (
+	groupJoinLeaver interface {
+		JoinGroup(group uint32) error
+		LeaveGroup(group uint32) error
+	}
+
+	bpfSetter interface {
+		SetBPF(filter []bpf.RawInstruction) error
+		RemoveBPF() error
+	}
+
+	optionSetter interface {
+		SetOption(option netlink.ConnOption, enable bool) error
+	}
+
+	bufferSetter interface {
+		SetReadBuffer(bytes int) error
+		SetWriteBuffer(bytes int) error
+	}
+
+	deadlineSetter interface {
+		SetDeadline(t time.Time) error
+		SetReadDeadline(t time.Time) error
+		SetWriteDeadline(t time.Time) error
+	}
+
+	partReceiver interface {
+		ReceivePart() ([]netlink.Message, error)
+	}
+
+	transportDialer interface {
+		Dial() (Transport, error)
+	}
+)
+
+// ErrNotSupported is wrapped by errors returned by Conn methods which are not
+// supported by the Conn's Transport.
+var ErrNotSupported = errors.New("genetlink: operation not supported by transport")
+
+// notSupported returns an error for an operation which the Conn's Transport
+// does not support.
+func notSupported(op string) error {
+	return &netlink.OpError{
+		Op:  op,
+		Err: ErrNotSupported,
+	}
+}
+
+// sibling creates a Conn to the same backend as c with a socket of its own,
+// for requests which must not share the socket of c, such as those made on
+// behalf of a Monitor which receives multicast messages using c.
+func (c *Conn) sibling() (*Conn, error) {
+	dial := c.dial
+	if dial == nil {
+		d, ok := c.c.(transportDialer)
+		if !ok {
+			return nil, notSupported("dial")
+		}
+
+		dial = d.Dial
+	}
+
+	t, err := dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTransportConn(t), nil
+}
@@ -0,0 +1,60 @@
+package genetlink_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+)
+
+func TestNewTransportConn(t *testing.T) {
+	var tr echoTransport
+	c := genetlink.NewTransportConn(&tr)
+	defer c.Close()
+
+	req := genetlink.Message{
+		Header: genetlink.Header{Command: 1, Version: 1},
+		Data:   []byte{0xff, 0xff, 0xff, 0xff},
+	}
+
+	msgs, err := c.Execute(req, 0x20, netlink.Request)
+	if err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+
+	if diff := cmp.Diff([]genetlink.Message{req}, msgs); diff != "" {
+		t.Fatalf("unexpected replies (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(1, tr.n); diff != "" {
+		t.Fatalf("unexpected number of requests (-want +got):\n%s", diff)
+	}
+
+	// Optional capabilities are reported as unsupported.
+	if err := c.JoinGroup(1); !errors.Is(err, genetlink.ErrNotSupported) {
+		t.Fatalf("expected not supported error, but got: %v", err)
+	}
+}
+
+// An echoTransport is a minimal genetlink.Transport which echoes requests
+// made using Execute.
+type echoTransport struct {
+	n int
+}
+
+func (*echoTransport) Close() error { return nil }
+
+func (*echoTransport) Send(m netlink.Message) (netlink.Message, error) { return m, nil }
+
+func (*echoTransport) SendMessages(msgs []netlink.Message) ([]netlink.Message, error) {
+	return msgs, nil
+}
+
+func (*echoTransport) Receive() ([]netlink.Message, error) { return nil, nil }
+
+func (t *echoTransport) Execute(m netlink.Message) ([]netlink.Message, error) {
+	t.n++
+	return []netlink.Message{m}, nil
+}
@@ -0,0 +1,86 @@
+package genetlink
+
+import (
+	"context"
+	"time"
+)
+
+// A WatermarkConfig configures Conn.WatchWatermarks.
+type WatermarkConfig struct {
+	// Interval is the time between samples of the receive queue. If zero,
+	// the receive queue is sampled every second.
+	Interval time.Duration
+
+	// High and Low are the receive queue watermarks, as fractions of the
+	// receive buffer size. The callback is invoked when the receive queue
+	// grows to at least High, and again when it later shrinks to at most
+	// Low. If zero, High is 0.8 and Low is 0.5.
+	High, Low float64
+
+	// Func is invoked with high set to true when the receive queue crosses
+	// the high watermark, and false when it crosses back below the low
+	// watermark. Func must not block for long, as samples are not taken
+	// while it runs.
+	Func func(high bool, info MemInfo)
+}
+
+// WatchWatermarks periodically samples the depth of the Conn's receive
+// queue using MemInfo, and invokes cfg.Func when it crosses the configured
+// watermarks. This gives daemons early warning to shed load or to increase
+// the receive buffer size before the kernel begins dropping messages.
+//
+// WatchWatermarks blocks until ctx is canceled, returning ctx.Err(), or
+// until MemInfo fails, returning its error. It is typically run in its own
+// goroutine alongside the goroutine receiving messages.
+func (c *Conn) WatchWatermarks(ctx context.Context, cfg WatermarkConfig) error {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+
+	t := c.clock().NewTicker(cfg.Interval)
+	defer t.Stop()
+
+	return watchWatermarks(ctx, cfg, c.MemInfo, t.Chan())
+}
+
+// watchWatermarks implements WatchWatermarks, taking a sample each time tick
+// fires.
+func watchWatermarks(ctx context.Context, cfg WatermarkConfig, sample func() (MemInfo, error), tick <-chan time.Time) error {
+	if cfg.High == 0 {
+		cfg.High = 0.8
+	}
+	if cfg.Low == 0 {
+		cfg.Low = 0.5
+	}
+
+	var high bool
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick:
+		}
+
+		info, err := sample()
+		if err != nil {
+			return err
+		}
+		if info.RcvBuf == 0 {
+			continue
+		}
+
+		depth := float64(info.RMemAlloc) / float64(info.RcvBuf)
+		switch {
+		case !high && depth >= cfg.High:
+			high = true
+		case high && depth <= cfg.Low:
+			high = false
+		default:
+			continue
+		}
+
+		if cfg.Func != nil {
+			cfg.Func(high, info)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package genetlink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWatchWatermarks(t *testing.T) {
+	// Receive queue depths in percent of the receive buffer.
+	samples := []uint32{10, 79, 80, 95, 60, 50, 70, 90, 0}
+
+	var (
+		got  []bool
+		tick = make(chan time.Time)
+		errC = make(chan error, 1)
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var i int
+	go func() {
+		errC <- watchWatermarks(ctx, WatermarkConfig{
+			Func: func(high bool, _ MemInfo) {
+				got = append(got, high)
+			},
+		}, func() (MemInfo, error) {
+			info := MemInfo{RMemAlloc: samples[i], RcvBuf: 100}
+			i++
+			return info, nil
+		}, tick)
+	}()
+
+	for range samples {
+		tick <- time.Time{}
+	}
+	cancel()
+
+	if err := <-errC; !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []bool{true, false, true, false}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected watermark crossings (-want +got):\n%s", diff)
+	}
+}
+
+func TestWatchWatermarksError(t *testing.T) {
+	errSample := errors.New("sample failed")
+
+	tick := make(chan time.Time, 1)
+	tick <- time.Time{}
+
+	err := watchWatermarks(context.Background(), WatermarkConfig{}, func() (MemInfo, error) {
+		return MemInfo{}, errSample
+	}, tick)
+	if !errors.Is(err, errSample) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}